@@ -0,0 +1,26 @@
+// Package autostart registers the resident app to start at user login.
+package autostart
+
+import (
+	"errors"
+	"strings"
+)
+
+// ValueName is the HKCU Run-key value used for the autostart entry.
+const ValueName = "ScreenOCRLLM"
+
+// ErrUnsupported is returned on platforms without a Run-key equivalent.
+var ErrUnsupported = errors.New("autostart is only supported on Windows")
+
+// Status describes the current autostart registration.
+type Status struct {
+	Enabled bool
+	Command string
+}
+
+// CommandLine returns the Run-key command for exePath. The path is always
+// quoted so that executables under directories containing spaces start
+// correctly.
+func CommandLine(exePath string) string {
+	return `"` + strings.Trim(exePath, `"`) + `"`
+}
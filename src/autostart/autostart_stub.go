@@ -0,0 +1,18 @@
+//go:build !windows
+
+package autostart
+
+// Install is not supported on non-Windows platforms.
+func Install(exePath string) error {
+	return ErrUnsupported
+}
+
+// Uninstall is not supported on non-Windows platforms.
+func Uninstall() error {
+	return ErrUnsupported
+}
+
+// Query is not supported on non-Windows platforms.
+func Query() (Status, error) {
+	return Status{}, ErrUnsupported
+}
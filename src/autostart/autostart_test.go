@@ -0,0 +1,23 @@
+package autostart
+
+import "testing"
+
+func TestCommandLineQuotesPath(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain path", in: `C:\Tools\screen-ocr-llm.exe`, want: `"C:\Tools\screen-ocr-llm.exe"`},
+		{name: "path with spaces", in: `C:\Program Files\Screen OCR\screen-ocr-llm.exe`, want: `"C:\Program Files\Screen OCR\screen-ocr-llm.exe"`},
+		{name: "already quoted", in: `"C:\Program Files\screen-ocr-llm.exe"`, want: `"C:\Program Files\screen-ocr-llm.exe"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommandLine(tt.in); got != tt.want {
+				t.Fatalf("CommandLine(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
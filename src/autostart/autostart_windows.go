@@ -0,0 +1,64 @@
+//go:build windows
+
+package autostart
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const runKeyPath = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+// Install creates or replaces the HKCU Run-key entry pointing at exePath.
+func Install(exePath string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("open Run key: %w", err)
+	}
+	defer key.Close()
+
+	if err := key.SetStringValue(ValueName, CommandLine(exePath)); err != nil {
+		return fmt.Errorf("write Run value: %w", err)
+	}
+	return nil
+}
+
+// Uninstall removes the HKCU Run-key entry. Removing a missing entry is not an error.
+func Uninstall() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("open Run key: %w", err)
+	}
+	defer key.Close()
+
+	if err := key.DeleteValue(ValueName); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("delete Run value: %w", err)
+	}
+	return nil
+}
+
+// Query reports whether the HKCU Run-key entry exists and its command.
+func Query() (Status, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return Status{}, nil
+		}
+		return Status{}, fmt.Errorf("open Run key: %w", err)
+	}
+	defer key.Close()
+
+	command, _, err := key.GetStringValue(ValueName)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return Status{}, nil
+		}
+		return Status{}, fmt.Errorf("read Run value: %w", err)
+	}
+	return Status{Enabled: true, Command: command}, nil
+}
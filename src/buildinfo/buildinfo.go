@@ -0,0 +1,75 @@
+// Package buildinfo reports the version, commit and build date of the running
+// binary. The values are set at link time, e.g.
+//
+//	go build -ldflags "-X screen-ocr-llm/src/buildinfo.Version=v2.6.1 \
+//	  -X screen-ocr-llm/src/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X screen-ocr-llm/src/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Unset values default to "dev".
+package buildinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set via -ldflags -X.
+var (
+	Version = "dev"
+	Commit  = "dev"
+	Date    = "dev"
+)
+
+// Info is the build information, also the --json output of the version
+// commands.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information. Without -ldflags the commit and date
+// fall back to the VCS stamp Go embeds when building from a checkout.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "dev" && s.Value != "":
+				info.Commit = shortCommit(s.Value)
+			case s.Key == "vcs.time" && info.Date == "dev" && s.Value != "":
+				info.Date = s.Value
+			}
+		}
+	}
+	return info
+}
+
+// String formats i on one line, e.g. "v2.6.1 (commit 1a2b3c4, built 2025-01-02T03:04:05Z, go1.25.1)".
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s)", i.Version, i.Commit, i.Date, i.GoVersion)
+}
+
+// Write prints the build information to w as text or, with jsonOutput, JSON.
+func Write(w io.Writer, jsonOutput bool) error {
+	info := Get()
+	if jsonOutput {
+		if err := json.NewEncoder(w).Encode(info); err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		return nil
+	}
+	_, err := fmt.Fprintln(w, info.String())
+	return err
+}
+
+func shortCommit(rev string) string {
+	if len(rev) > 7 {
+		return rev[:7]
+	}
+	return rev
+}
@@ -0,0 +1,55 @@
+package buildinfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGetUsesLinkerValues(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, Date = v, c, d }(Version, Commit, Date)
+	Version, Commit, Date = "v9.9.9", "abc1234", "2025-01-02T03:04:05Z"
+
+	info := Get()
+	if info.Version != "v9.9.9" || info.Commit != "abc1234" || info.Date != "2025-01-02T03:04:05Z" {
+		t.Fatalf("Get() = %+v", info)
+	}
+	if info.GoVersion == "" {
+		t.Error("expected Go version")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, Date = v, c, d }(Version, Commit, Date)
+	Version, Commit, Date = "v9.9.9", "abc1234", "2025-01-02T03:04:05Z"
+
+	var text bytes.Buffer
+	if err := Write(&text, false); err != nil {
+		t.Fatalf("Write text: %v", err)
+	}
+	if !strings.HasPrefix(text.String(), "v9.9.9 (commit abc1234, built 2025-01-02T03:04:05Z, go") {
+		t.Errorf("text output = %q", text.String())
+	}
+
+	var js bytes.Buffer
+	if err := Write(&js, true); err != nil {
+		t.Fatalf("Write json: %v", err)
+	}
+	var got Info
+	if err := json.Unmarshal(js.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", js.String(), err)
+	}
+	if got.Version != "v9.9.9" || got.Commit != "abc1234" {
+		t.Errorf("JSON output = %+v", got)
+	}
+}
+
+func TestShortCommit(t *testing.T) {
+	if got := shortCommit("0123456789abcdef"); got != "0123456" {
+		t.Errorf("shortCommit = %q", got)
+	}
+	if got := shortCommit("abc"); got != "abc" {
+		t.Errorf("shortCommit = %q", got)
+	}
+}
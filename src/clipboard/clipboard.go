@@ -3,6 +3,8 @@ package clipboard
 import (
 	"errors"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"golang.design/x/clipboard"
 )
@@ -12,22 +14,176 @@ var (
 	writeText = func(text string) <-chan struct{} {
 		return clipboard.Write(clipboard.FmtText, []byte(text))
 	}
+	readText = func() []byte {
+		return clipboard.Read(clipboard.FmtText)
+	}
+	readImage = func() []byte {
+		return clipboard.Read(clipboard.FmtImage)
+	}
+	writeRich = writeFormats
+
+	appendSeparator = "\n"
+	format          = FormatPlain
+
+	// lastWritten is the plain text the last write put on the clipboard
+	// and writtenAt when, for ClearIfUnchanged.
+	lastWritten string
+	writtenAt   time.Time
 )
 
+// ErrNoImage is returned by ReadImage when the clipboard holds no image.
+var ErrNoImage = errors.New("clipboard does not contain an image")
+
 func Init() error {
 	return clipboard.Init()
 }
 
 // Write performs a mutex-guarded clipboard write to prevent corruption under parallel writes.
+// With an html or rtf format set, text is read as Markdown and written in
+// that format alongside the plain text.
 func Write(text string) error {
+	text = sanitizeText(text)
+	switch currentFormat() {
+	case FormatHTML:
+		return WriteHTML(text, markdownToHTML(text))
+	case FormatRTF:
+		return WriteRTF(text, markdownToRTF(text))
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	var err error
+	if writeText(text) == nil {
+		err = errors.New("clipboard write failed")
+	}
+	return recordWrite(text, err)
+}
+
+// WriteHTML puts htmlFragment on the clipboard as CF_HTML and text as plain
+// text, so rich-text editors paste the formatting and others the text.
+// Outside Windows only the plain text is written.
+func WriteHTML(text, htmlFragment string) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	text = sanitizeText(text)
+	return recordWrite(text, writeRich(text, htmlFormatName, []byte(cfHTML(htmlFragment))))
+}
+
+// WriteRTF puts the RTF document rtf on the clipboard along with text as
+// plain text. Outside Windows only the plain text is written.
+func WriteRTF(text, rtf string) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	text = sanitizeText(text)
+	return recordWrite(text, writeRich(text, rtfFormatName, []byte(rtf)))
+}
+
+// SetFormat sets the format Write uses (CLIPBOARD_FORMAT): FormatPlain,
+// FormatHTML or FormatRTF. Unknown values mean plain text.
+func SetFormat(f string) {
 	writeMu.Lock()
 	defer writeMu.Unlock()
-	if writeText(sanitizeText(text)) == nil {
+	switch f {
+	case FormatHTML, FormatRTF:
+		format = f
+	default:
+		format = FormatPlain
+	}
+}
+
+// currentFormat returns the format set by SetFormat.
+func currentFormat() string {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	return format
+}
+
+// SetAppendSeparator sets the text Append puts between the existing
+// clipboard text and the new text (CLIPBOARD_APPEND_SEPARATOR).
+func SetAppendSeparator(sep string) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	appendSeparator = sep
+}
+
+// Append adds text to the end of the current clipboard text. If the clipboard
+// holds no text (it is empty or holds an image or other non-text data), text
+// replaces it as with Write.
+func Append(text string) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	text = sanitizeText(text)
+	if current := readText(); len(current) > 0 && utf8.Valid(current) {
+		text = string(current) + appendSeparator + text
+	}
+	if writeText(text) == nil {
 		return errors.New("clipboard write failed")
 	}
+	return recordWrite(text, nil)
+}
+
+// recordWrite remembers text as the clipboard contents when the write that
+// put it there succeeded, and returns err. The caller holds writeMu.
+func recordWrite(text string, err error) error {
+	if err == nil {
+		lastWritten, writtenAt = text, time.Now()
+	}
+	return err
+}
+
+// LastWrite returns the plain text the most recent successful Write,
+// Append, WriteHTML or WriteRTF put on the clipboard, and when. The time is
+// zero before the first write.
+func LastWrite() (string, time.Time) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	return lastWritten, writtenAt
+}
+
+// Clear empties the clipboard.
+func Clear() error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	return clearText()
+}
+
+// ClearIfUnchanged empties the clipboard only if it still holds exactly text,
+// so that whatever the user copied since is left alone. It reports whether
+// the clipboard was cleared.
+func ClearIfUnchanged(text string) (bool, error) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if string(readText()) != text {
+		return false, nil
+	}
+	if err := clearText(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// clearText empties the clipboard; the caller holds writeMu.
+func clearText() error {
+	if writeText("") == nil {
+		return errors.New("clipboard clear failed")
+	}
 	return nil
 }
 
+// ReadText returns the text on the clipboard, or "" if it holds none.
+func ReadText() string {
+	return string(readText())
+}
+
+// ReadImage returns the clipboard image as PNG bytes, or ErrNoImage.
+func ReadImage() ([]byte, error) {
+	data := readImage()
+	if len(data) == 0 {
+		return nil, ErrNoImage
+	}
+	return data, nil
+}
+
 func sanitizeText(text string) string {
 	clean := make([]rune, 0, len(text))
 	for _, r := range text {
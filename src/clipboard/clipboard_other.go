@@ -0,0 +1,14 @@
+//go:build !windows
+
+package clipboard
+
+import "errors"
+
+// writeFormats writes only the plain text: the rich formats are Windows
+// clipboard formats, and other platforms fall back to text.
+func writeFormats(text, _ string, _ []byte) error {
+	if writeText(text) == nil {
+		return errors.New("clipboard write failed")
+	}
+	return nil
+}
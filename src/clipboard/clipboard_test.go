@@ -3,6 +3,7 @@ package clipboard
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestWriteSanitizesUnprintableCharacters(t *testing.T) {
@@ -49,3 +50,117 @@ func TestSanitizeTextPreservesPrintableUnicode(t *testing.T) {
 		t.Fatalf("Expected printable text to be preserved, got %q", got)
 	}
 }
+
+func TestReadImage(t *testing.T) {
+	originalReadImage := readImage
+	defer func() { readImage = originalReadImage }()
+
+	readImage = func() []byte { return nil }
+	if _, err := ReadImage(); err != ErrNoImage {
+		t.Fatalf("Expected ErrNoImage for empty clipboard, got %v", err)
+	}
+
+	png := []byte{0x89, 'P', 'N', 'G'}
+	readImage = func() []byte { return png }
+	got, err := ReadImage()
+	if err != nil {
+		t.Fatalf("ReadImage returned error: %v", err)
+	}
+	if string(got) != string(png) {
+		t.Fatalf("Expected image bytes %v, got %v", png, got)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	originalWriteText, originalReadText := writeText, readText
+	defer func() { writeText, readText = originalWriteText, originalReadText }()
+	defer SetAppendSeparator("\n")
+
+	var got string
+	writeText = func(text string) <-chan struct{} {
+		got = text
+		return make(chan struct{})
+	}
+
+	tests := []struct {
+		name    string
+		current []byte
+		sep     string
+		want    string
+	}{
+		{"existing text", []byte("first"), "\n", "first\nsecond"},
+		{"custom separator", []byte("first"), " | ", "first | second"},
+		{"empty clipboard", nil, "\n", "second"},
+		{"non-text contents", []byte{0xff, 0xfe, 0x00}, "\n", "second"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readText = func() []byte { return tt.current }
+			SetAppendSeparator(tt.sep)
+			if err := Append("second\x00"); err != nil {
+				t.Fatalf("Append returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Expected clipboard %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// fakeClipboard replaces the clipboard with an in-memory string.
+func fakeClipboard(t *testing.T) *string {
+	t.Helper()
+	prevWrite, prevRead := writeText, readText
+	t.Cleanup(func() { writeText, readText = prevWrite, prevRead })
+	var contents string
+	writeText = func(text string) <-chan struct{} {
+		contents = text
+		return make(chan struct{})
+	}
+	readText = func() []byte { return []byte(contents) }
+	return &contents
+}
+
+func TestClearIfUnchanged(t *testing.T) {
+	contents := fakeClipboard(t)
+
+	before := time.Now()
+	if err := Write("123456"); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	written, at := LastWrite()
+	if written != "123456" || at.Before(before) {
+		t.Fatalf("LastWrite() = %q, %v", written, at)
+	}
+
+	*contents = "copied by the user"
+	if cleared, err := ClearIfUnchanged(written); err != nil || cleared {
+		t.Fatalf("ClearIfUnchanged() = %v, %v; want the user's text kept", cleared, err)
+	}
+	if *contents != "copied by the user" {
+		t.Fatalf("clipboard changed to %q", *contents)
+	}
+
+	*contents = written
+	if cleared, err := ClearIfUnchanged(written); err != nil || !cleared {
+		t.Fatalf("ClearIfUnchanged() = %v, %v; want cleared", cleared, err)
+	}
+	if *contents != "" {
+		t.Fatalf("clipboard not cleared: %q", *contents)
+	}
+}
+
+func TestLastWriteFollowsAppend(t *testing.T) {
+	contents := fakeClipboard(t)
+	*contents = "first"
+
+	if err := Append("second"); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+	if written, _ := LastWrite(); written != "first\nsecond" || written != *contents {
+		t.Fatalf("LastWrite() = %q, clipboard %q", written, *contents)
+	}
+	if got := ReadText(); got != *contents {
+		t.Fatalf("ReadText() = %q, want %q", got, *contents)
+	}
+}
@@ -0,0 +1,111 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	user32                       = syscall.NewLazyDLL("user32.dll")
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procOpenClipboard            = user32.NewProc("OpenClipboard")
+	procCloseClipboard           = user32.NewProc("CloseClipboard")
+	procEmptyClipboard           = user32.NewProc("EmptyClipboard")
+	procSetClipboardData         = user32.NewProc("SetClipboardData")
+	procRegisterClipboardFormatW = user32.NewProc("RegisterClipboardFormatW")
+	procGlobalAlloc              = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree               = kernel32.NewProc("GlobalFree")
+	procGlobalLock               = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock             = kernel32.NewProc("GlobalUnlock")
+	procRtlMoveMemory            = kernel32.NewProc("RtlMoveMemory")
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+// writeFormats puts text as CF_UNICODETEXT and data under the registered
+// clipboard format name in one clipboard transaction, replacing whatever
+// the clipboard held.
+func writeFormats(text, name string, data []byte) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	id, _, callErr := procRegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(namePtr)))
+	if id == 0 {
+		return fmt.Errorf("register clipboard format %q: %v", name, callErr)
+	}
+	utf16, err := syscall.UTF16FromString(text)
+	if err != nil {
+		return err
+	}
+
+	if err := openClipboard(); err != nil {
+		return err
+	}
+	defer procCloseClipboard.Call()
+	if r, _, callErr := procEmptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("empty clipboard: %v", callErr)
+	}
+	if err := setClipboardData(cfUnicodeText, unsafe.Slice((*byte)(unsafe.Pointer(&utf16[0])), len(utf16)*2)); err != nil {
+		return err
+	}
+	// Copy before adding the NUL so the caller's backing array is untouched
+	terminated := make([]byte, len(data)+1)
+	copy(terminated, data)
+	return setClipboardData(id, terminated)
+}
+
+// openClipboard retries for a moment: another application may be holding
+// the clipboard open.
+func openClipboard() error {
+	for i := 0; i < 10; i++ {
+		if r, _, _ := procOpenClipboard.Call(0); r != 0 {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return errors.New("clipboard write failed: clipboard is in use")
+}
+
+// setClipboardData copies data into a global memory block and hands it to
+// the clipboard, which owns it from then on.
+func setClipboardData(format uintptr, data []byte) error {
+	h, _, callErr := procGlobalAlloc.Call(gmemMoveable, uintptr(len(data)))
+	if h == 0 {
+		return fmt.Errorf("GlobalAlloc: %v", callErr)
+	}
+	p, _, callErr := procGlobalLock.Call(h)
+	if p == 0 {
+		procGlobalFree.Call(h)
+		return fmt.Errorf("GlobalLock: %v", callErr)
+	}
+	moveMemory(p, data)
+	procGlobalUnlock.Call(h)
+	if r, _, callErr := procSetClipboardData.Call(format, h); r == 0 {
+		procGlobalFree.Call(h)
+		return fmt.Errorf("SetClipboardData: %v", callErr)
+	}
+	return nil
+}
+
+// moveMemory copies data to the locked global memory at dst. The copy is
+// left to RtlMoveMemory so that the address never has to be turned back into
+// a Go pointer.
+func moveMemory(dst uintptr, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	procRtlMoveMemory.Call(dst, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)))
+}
@@ -0,0 +1,324 @@
+package clipboard
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Rich clipboard formats for CLIPBOARD_FORMAT. Plain text is always written
+// as well, so applications without rich-text support still paste the text.
+const (
+	FormatPlain = "plain"
+	FormatHTML  = "html"
+	FormatRTF   = "rtf"
+)
+
+// Registered Windows clipboard format names.
+const (
+	htmlFormatName = "HTML Format"
+	rtfFormatName  = "Rich Text Format"
+)
+
+// cfHTML wraps an HTML fragment in the CF_HTML header, whose byte offsets
+// tell the pasting application where the document and the fragment start
+// and end.
+func cfHTML(fragment string) string {
+	const header = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+	const prefix = "<html><body>\r\n<!--StartFragment-->"
+	const suffix = "<!--EndFragment-->\r\n</body></html>"
+	startHTML := len(fmt.Sprintf(header, 0, 0, 0, 0))
+	startFragment := startHTML + len(prefix)
+	endFragment := startFragment + len(fragment)
+	endHTML := endFragment + len(suffix)
+	return fmt.Sprintf(header, startHTML, endHTML, startFragment, endFragment) + prefix + fragment + suffix
+}
+
+// A block is one Markdown element: a heading, a list, a paragraph, a pipe
+// table or a fenced code block. Only the subset OCR_OUTPUT_FORMAT=markdown
+// asks the model for is recognized; anything else is a paragraph.
+type block struct {
+	kind    string // "heading", "ul", "ol", "p", "table", "code"
+	level   int
+	lines   []string
+	rows    [][]string
+	hasHead bool
+}
+
+var (
+	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	bulletRe   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedRe  = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	tableSepRe = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+)
+
+func parseBlocks(markdown string) []block {
+	var blocks []block
+	var cur *block
+	flush := func() {
+		if cur != nil {
+			blocks = append(blocks, *cur)
+			cur = nil
+		}
+	}
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if cur != nil && cur.kind == "code" {
+			if strings.HasPrefix(trimmed, "```") {
+				flush()
+				continue
+			}
+			cur.lines = append(cur.lines, line)
+			continue
+		}
+		switch m := headingRe.FindStringSubmatch(trimmed); {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			cur = &block{kind: "code"}
+		case m != nil:
+			flush()
+			blocks = append(blocks, block{kind: "heading", level: len(m[1]), lines: []string{m[2]}})
+		case strings.HasPrefix(trimmed, "|"):
+			if cur == nil || cur.kind != "table" {
+				flush()
+				cur = &block{kind: "table"}
+			}
+			if tableSepRe.MatchString(trimmed) {
+				cur.hasHead = len(cur.rows) == 1
+				continue
+			}
+			cur.rows = append(cur.rows, splitRow(trimmed))
+		case bulletRe.MatchString(line):
+			if cur == nil || cur.kind != "ul" {
+				flush()
+				cur = &block{kind: "ul"}
+			}
+			cur.lines = append(cur.lines, bulletRe.FindStringSubmatch(line)[1])
+		case orderedRe.MatchString(line):
+			if cur == nil || cur.kind != "ol" {
+				flush()
+				cur = &block{kind: "ol"}
+			}
+			cur.lines = append(cur.lines, orderedRe.FindStringSubmatch(line)[1])
+		default:
+			if cur == nil || cur.kind != "p" {
+				flush()
+				cur = &block{kind: "p"}
+			}
+			cur.lines = append(cur.lines, trimmed)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func splitRow(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i, c := range cells {
+		cells[i] = strings.TrimSpace(c)
+	}
+	return cells
+}
+
+// A span is a run of inline text with the same emphasis.
+type span struct {
+	text                 string
+	bold, italic, isCode bool
+}
+
+var inlineRe = regexp.MustCompile("`([^`]+)`|\\*\\*(.+?)\\*\\*|\\*([^*\\s][^*]*?)\\*|\\b_([^_]+)_\\b")
+
+func parseInline(s string) []span {
+	var spans []span
+	for s != "" {
+		loc := inlineRe.FindStringSubmatchIndex(s)
+		if loc == nil {
+			spans = append(spans, span{text: s})
+			break
+		}
+		if loc[0] > 0 {
+			spans = append(spans, span{text: s[:loc[0]]})
+		}
+		switch {
+		case loc[2] >= 0:
+			spans = append(spans, span{text: s[loc[2]:loc[3]], isCode: true})
+		case loc[4] >= 0:
+			for _, inner := range parseInline(s[loc[4]:loc[5]]) {
+				inner.bold = true
+				spans = append(spans, inner)
+			}
+		default:
+			start, end := loc[6], loc[7]
+			if start < 0 {
+				start, end = loc[8], loc[9]
+			}
+			for _, inner := range parseInline(s[start:end]) {
+				inner.italic = true
+				spans = append(spans, inner)
+			}
+		}
+		s = s[loc[1]:]
+	}
+	return spans
+}
+
+// markdownToHTML converts an OCR result in Markdown to an HTML fragment.
+// Plain text comes out as paragraphs with line breaks.
+func markdownToHTML(markdown string) string {
+	var b strings.Builder
+	for _, blk := range parseBlocks(markdown) {
+		switch blk.kind {
+		case "heading":
+			fmt.Fprintf(&b, "<h%d>%s</h%d>", blk.level, inlineHTML(blk.lines[0]), blk.level)
+		case "ul", "ol":
+			b.WriteString("<" + blk.kind + ">")
+			for _, item := range blk.lines {
+				b.WriteString("<li>" + inlineHTML(item) + "</li>")
+			}
+			b.WriteString("</" + blk.kind + ">")
+		case "table":
+			b.WriteString("<table border=\"1\">")
+			for i, row := range blk.rows {
+				tag := "td"
+				if i == 0 && blk.hasHead {
+					tag = "th"
+				}
+				b.WriteString("<tr>")
+				for _, cell := range row {
+					b.WriteString("<" + tag + ">" + inlineHTML(cell) + "</" + tag + ">")
+				}
+				b.WriteString("</tr>")
+			}
+			b.WriteString("</table>")
+		case "code":
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(blk.lines, "\n")) + "</code></pre>")
+		default:
+			parts := make([]string, len(blk.lines))
+			for i, line := range blk.lines {
+				parts[i] = inlineHTML(line)
+			}
+			b.WriteString("<p>" + strings.Join(parts, "<br>") + "</p>")
+		}
+	}
+	return b.String()
+}
+
+func inlineHTML(s string) string {
+	var b strings.Builder
+	for _, sp := range parseInline(s) {
+		text := html.EscapeString(sp.text)
+		if sp.isCode {
+			text = "<code>" + text + "</code>"
+		}
+		if sp.italic {
+			text = "<em>" + text + "</em>"
+		}
+		if sp.bold {
+			text = "<strong>" + text + "</strong>"
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// markdownToRTF converts an OCR result in Markdown to an RTF document.
+// Tables become tab-separated lines; RTF table markup is not worth it for
+// pasting into a word processor that reflows them anyway.
+func markdownToRTF(markdown string) string {
+	var b strings.Builder
+	b.WriteString(`{\rtf1\ansi\deff0{\fonttbl{\f0\fswiss Calibri;}{\f1\fmodern Consolas;}}\fs22 `)
+	for _, blk := range parseBlocks(markdown) {
+		switch blk.kind {
+		case "heading":
+			size := 36 - 4*blk.level
+			if size < 22 {
+				size = 22
+			}
+			fmt.Fprintf(&b, `{\b\fs%d %s}\par `, size, inlineRTF(blk.lines[0]))
+		case "ul":
+			for _, item := range blk.lines {
+				b.WriteString(`\bullet\tab ` + inlineRTF(item) + `\par `)
+			}
+		case "ol":
+			for i, item := range blk.lines {
+				fmt.Fprintf(&b, `%d.\tab %s\par `, i+1, inlineRTF(item))
+			}
+		case "table":
+			for i, row := range blk.rows {
+				cells := make([]string, len(row))
+				for j, cell := range row {
+					cells[j] = inlineRTF(cell)
+				}
+				line := strings.Join(cells, `\tab `)
+				if i == 0 && blk.hasHead {
+					line = `{\b ` + line + `}`
+				}
+				b.WriteString(line + `\par `)
+			}
+		case "code":
+			for _, line := range blk.lines {
+				b.WriteString(`{\f1 ` + escapeRTF(line) + `}\par `)
+			}
+		default:
+			parts := make([]string, len(blk.lines))
+			for i, line := range blk.lines {
+				parts[i] = inlineRTF(line)
+			}
+			b.WriteString(strings.Join(parts, `\line `) + `\par `)
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func inlineRTF(s string) string {
+	var b strings.Builder
+	for _, sp := range parseInline(s) {
+		text := escapeRTF(sp.text)
+		var ctl string
+		if sp.bold {
+			ctl += `\b`
+		}
+		if sp.italic {
+			ctl += `\i`
+		}
+		if sp.isCode {
+			ctl += `\f1`
+		}
+		if ctl == "" {
+			b.WriteString(text)
+			continue
+		}
+		b.WriteString("{" + ctl + " " + text + "}")
+	}
+	return b.String()
+}
+
+// escapeRTF escapes RTF control characters and writes non-ASCII text as
+// \uN escapes (signed 16-bit, surrogate pairs above the BMP), each followed
+// by a "?" for readers without Unicode support.
+func escapeRTF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '{' || r == '}':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString(`\tab `)
+		case r < 0x80:
+			b.WriteRune(r)
+		case r < 0x10000:
+			fmt.Fprintf(&b, `\u%d?`, int16(r))
+		default:
+			r -= 0x10000
+			fmt.Fprintf(&b, `\u%d?\u%d?`, int16(0xd800+(r>>10)), int16(0xdc00+(r&0x3ff)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package clipboard
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCFHTMLOffsets(t *testing.T) {
+	fragment := "<p>café</p>"
+	doc := cfHTML(fragment)
+
+	offset := func(key string) int {
+		i := strings.Index(doc, key+":")
+		if i < 0 {
+			t.Fatalf("header missing %s:\n%s", key, doc)
+		}
+		n, err := strconv.Atoi(doc[i+len(key)+1 : i+len(key)+11])
+		if err != nil {
+			t.Fatalf("bad %s offset: %v", key, err)
+		}
+		return n
+	}
+	if got := doc[offset("StartFragment"):offset("EndFragment")]; got != fragment {
+		t.Fatalf("fragment offsets select %q, want %q", got, fragment)
+	}
+	if !strings.HasPrefix(doc[offset("StartHTML"):], "<html>") || offset("EndHTML") != len(doc) {
+		t.Fatalf("document offsets are wrong:\n%s", doc)
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain text", "line one\nline <two>", "<p>line one<br>line &lt;two&gt;</p>"},
+		{"heading and emphasis", "# Title\n\n**bold** and *it* and `x*y`", "<h1>Title</h1><p><strong>bold</strong> and <em>it</em> and <code>x*y</code></p>"},
+		{"snake case is not emphasis", "a_b_c", "<p>a_b_c</p>"},
+		{"lists", "- one\n- two\n\n1. first\n2. second", "<ul><li>one</li><li>two</li></ul><ol><li>first</li><li>second</li></ol>"},
+		{"table", "| A | B |\n|---|---|\n| 1 | 2 |", `<table border="1"><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr></table>`},
+		{"code block", "```\n<a> *b*\n```", "<pre><code>&lt;a&gt; *b*</code></pre>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownToHTML(tt.in); got != tt.want {
+				t.Fatalf("markdownToHTML(%q) =\n%s\nwant\n%s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToRTF(t *testing.T) {
+	got := markdownToRTF("## Total {net}\n\n- **é** 😀")
+	for _, want := range []string{
+		`{\rtf1\ansi`,
+		`{\b\fs28 Total \{net\}}\par `,
+		`\bullet\tab {\b \u233?} \u-10179?\u-8704?\par `,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RTF missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "}") {
+		t.Errorf("RTF document not closed:\n%s", got)
+	}
+}
+
+func TestWriteUsesFormat(t *testing.T) {
+	originalWriteText, originalWriteRich := writeText, writeRich
+	defer func() { writeText, writeRich = originalWriteText, originalWriteRich }()
+	defer SetFormat(FormatPlain)
+
+	var plain, richText, richName, richData string
+	writeText = func(text string) <-chan struct{} {
+		plain = text
+		return make(chan struct{})
+	}
+	writeRich = func(text, name string, data []byte) error {
+		richText, richName, richData = text, name, string(data)
+		return nil
+	}
+
+	SetFormat(FormatHTML)
+	if err := Write("**hi**\x00"); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if plain != "" || richText != "**hi**" || richName != htmlFormatName || !strings.Contains(richData, "<strong>hi</strong>") {
+		t.Fatalf("unexpected html write: plain %q, rich %q %q %q", plain, richText, richName, richData)
+	}
+
+	SetFormat(FormatRTF)
+	if err := Write("hi"); err != nil || richName != rtfFormatName || !strings.HasPrefix(richData, `{\rtf1`) {
+		t.Fatalf("unexpected rtf write: %v, %q %q", err, richName, richData)
+	}
+
+	SetFormat("bogus")
+	if err := Write("hi"); err != nil || plain != "hi" {
+		t.Fatalf("expected plain write for unknown format, got %v, %q", err, plain)
+	}
+}
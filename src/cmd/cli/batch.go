@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/ocr"
+)
+
+// errSkipped is the Error of batch files not attempted after a --fail-fast
+// failure.
+const errSkipped = "skipped after an earlier failure (--fail-fast)"
+
+// batchInputs returns the --file values followed by the .png files in dir,
+// sorted by name. Stdin can only be used on its own.
+func batchInputs(files []string, dir string) ([]string, error) {
+	inputs := append([]string(nil), files...)
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+		var found []string
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".png") {
+				found = append(found, filepath.Join(dir, e.Name()))
+			}
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no .png files found in %s", dir)
+		}
+		sort.Strings(found)
+		inputs = append(inputs, found...)
+	}
+	if len(inputs) > 1 {
+		for _, in := range inputs {
+			if in == "-" {
+				return nil, fmt.Errorf("stdin (--file -) cannot be combined with other inputs")
+			}
+		}
+	}
+	return inputs, nil
+}
+
+// recognizeFile runs OCR on one batch input. Failures are reported in the
+// result's Error field.
+func recognizeFile(path string, verbose bool) OCRResult {
+	result := OCRResult{
+		Source:    path,
+		Format:    llm.OutputFormat(),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	imageData, err := readInput(path, verbose)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	start := time.Now()
+	text, spans, usage, err := ocr.RecognizeImageWithUncertainty(imageData)
+	result.Duration = time.Since(start).Seconds()
+	result.Usage = usage
+	if err != nil {
+		result.Error = fmt.Sprintf("OCR failed: %v", err)
+		return result
+	}
+	result.Text = text
+	result.CharCount = len(text)
+	result.UncertainSpans = spans
+	result.Entities = extractEntities(text)
+	return result
+}
+
+// runBatch OCRs every input using up to --concurrency parallel requests.
+// Results keep the input order. A failing file is recorded and the batch
+// continues unless --fail-fast is set.
+func runBatch(inputs []string, opts cliOptions) error {
+	var started atomic.Int64
+	results := recognizeAll(inputs, opts.concurrency, opts.failFast, func(path string) OCRResult {
+		if opts.verbose {
+			fmt.Fprintf(os.Stderr, "[verbose] Batch %d/%d: %s\n", started.Add(1), len(inputs), path)
+		}
+		return recognizeFile(path, opts.verbose)
+	})
+	return outputBatch(os.Stdout, os.Stderr, results, opts.jsonOutput)
+}
+
+// recognizeAll calls recognize for each input on at most concurrency
+// goroutines and returns one result per input, in input order. With failFast,
+// inputs not yet started when a file fails are marked as skipped.
+func recognizeAll(inputs []string, concurrency int, failFast bool, recognize func(path string) OCRResult) []OCRResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]OCRResult, len(inputs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var failed atomic.Bool
+	for i, path := range inputs {
+		sem <- struct{}{}
+		if failFast && failed.Load() {
+			<-sem
+			results[i] = OCRResult{Source: path, Format: llm.OutputFormat(), Error: errSkipped}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := recognize(path)
+			if result.Error != "" {
+				failed.Store(true)
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// outputBatch writes the batch results as a JSON array or as the texts
+// separated by blank lines, with errors on errOut. It returns an error if any
+// file failed or was skipped.
+func outputBatch(out, errOut io.Writer, results []OCRResult, jsonOutput bool) error {
+	failed, skipped := 0, 0
+	for _, r := range results {
+		switch r.Error {
+		case "":
+		case errSkipped:
+			skipped++
+		default:
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+	} else {
+		first := true
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Fprintf(errOut, "Error: %s: %s\n", r.Source, r.Error)
+				continue
+			}
+			if !first {
+				fmt.Fprint(out, "\n\n")
+			}
+			fmt.Fprint(out, r.Text)
+			first = false
+		}
+	}
+
+	switch {
+	case skipped > 0:
+		return fmt.Errorf("%d of %d files failed, %d skipped (--fail-fast)", failed, len(results), skipped)
+	case failed > 0:
+		return fmt.Errorf("%d of %d files failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"screen-ocr-llm/src/llm"
+)
+
+// HealthResult is the --json output of the healthcheck command.
+type HealthResult struct {
+	OK        bool   `json:"ok"`
+	LatencyMS int64  `json:"latency_ms"`
+	Model     string `json:"model"`
+	Error     string `json:"error,omitempty"`
+}
+
+func newHealthcheckCmd() *cobra.Command {
+	opts := &cliOptions{}
+	cmd := &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Verify the API key and model with a minimal LLM request",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.verbose {
+				log.SetOutput(os.Stderr)
+			} else {
+				log.SetOutput(io.Discard)
+			}
+			return runHealthcheck(cmd.OutOrStdout(), *opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "Output the result as JSON")
+	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", false, "Verbose output to stderr")
+	cmd.Flags().StringVar(&opts.configPath, "config", "", "Path to the .env file to load (overrides SCREEN_OCR_LLM and the executable-local .env)")
+	cmd.Flags().StringVar(&opts.apiKeyPath, "api-key-path", "", "Path to API key file (highest precedence)")
+
+	return cmd
+}
+
+func runHealthcheck(w io.Writer, opts cliOptions) error {
+	cfg, err := initLLM(opts)
+	if err != nil {
+		return reportHealth(w, "", 0, err, opts.jsonOutput)
+	}
+
+	start := time.Now()
+	err = llm.ValidateKey()
+	return reportHealth(w, cfg.Model, time.Since(start), err, opts.jsonOutput)
+}
+
+// reportHealth prints the healthcheck outcome and returns pingErr so the
+// process exits non-zero on failure.
+func reportHealth(w io.Writer, model string, latency time.Duration, pingErr error, jsonOutput bool) error {
+	if jsonOutput {
+		result := HealthResult{OK: pingErr == nil, LatencyMS: latency.Milliseconds(), Model: model}
+		if pingErr != nil {
+			result.Error = pingErr.Error()
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+	} else if pingErr == nil {
+		fmt.Fprintf(w, "OK %s (%d ms)\n", model, latency.Milliseconds())
+	} else {
+		fmt.Fprintf(w, "FAIL %s: %v\n", model, pingErr)
+	}
+
+	if pingErr != nil {
+		return fmt.Errorf("healthcheck failed: %w", pingErr)
+	}
+	return nil
+}
@@ -3,17 +3,21 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"screen-ocr-llm/src/buildinfo"
 	"screen-ocr-llm/src/config"
 	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/ocr"
 )
 
 const (
@@ -22,17 +26,43 @@ const (
 )
 
 type cliOptions struct {
-	filePath   string
-	jsonOutput bool
-	verbose    bool
-	apiKeyPath string
+	files       []string
+	dir         string
+	failFast    bool
+	concurrency int
+	jsonOutput  bool
+	verbose     bool
+	configPath  string
+	apiKeyPath  string
+	language    string
+	translate   string
+	describe    bool
+	stream      bool
+	mock        bool
+	timeout     time.Duration
 }
 
+// exitNoTextFound is the exit status when the image contains no text.
+const exitNoTextFound = 2
+
 func main() {
 	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		if errors.Is(err, llm.ErrNoTextFound) {
+			fmt.Fprintln(os.Stderr, "No text found")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode maps a run error to the process exit status: exitNoTextFound for
+// an image without text, 1 for any other failure.
+func exitCode(err error) int {
+	if errors.Is(err, llm.ErrNoTextFound) {
+		return exitNoTextFound
 	}
+	return 1
 }
 
 func run() error {
@@ -61,16 +91,61 @@ func newRootCmd(opts *cliOptions) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.filePath, "file", "", "Path to PNG file (use '-' for stdin)")
+	cmd.Flags().StringArrayVar(&opts.files, "file", nil, "Path to PNG file (use '-' for stdin); repeat for a batch")
+	cmd.Flags().StringVar(&opts.dir, "dir", "", "OCR every .png file in this directory")
+	cmd.Flags().BoolVar(&opts.failFast, "fail-fast", false, "Stop a batch at the first failing file")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 1, "Number of batch files to OCR in parallel")
 	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "Output results as JSON")
+	cmd.Flags().BoolVar(&opts.stream, "stream", false, "Print text as the model produces it (single file, text output only)")
 	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", false, "Verbose output to stderr")
+	cmd.Flags().StringVar(&opts.configPath, "config", "", "Path to the .env file to load (overrides SCREEN_OCR_LLM and the executable-local .env)")
 	cmd.Flags().StringVar(&opts.apiKeyPath, "api-key-path", "", "Path to API key file (highest precedence)")
-	_ = cmd.MarkFlagRequired("file")
+	cmd.Flags().StringVar(&opts.language, "lang", "", "Expected text language hint, e.g. Japanese (overrides OCR_LANGUAGE)")
+	cmd.Flags().BoolVar(&opts.describe, "describe", false, "Describe the image instead of extracting its text (same as MODE=describe)")
+	cmd.Flags().StringVar(&opts.translate, "translate", "", "Translate the result into this language, e.g. de or German (overrides TRANSLATE_TO)")
+	cmd.Flags().BoolVar(&opts.mock, "mock", false, "Use the offline mock LLM backend, which returns deterministic text (same as LLM_BACKEND=mock)")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", llm.DefaultRequestTimeout, "HTTP timeout for each LLM request attempt, e.g. 90s or 2m")
 
+	cmd.AddCommand(newHealthcheckCmd(), newSelftestCmd(), newVersionCmd())
+
+	return cmd
+}
+
+func newVersionCmd() *cobra.Command {
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, commit and build date",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return buildinfo.Write(cmd.OutOrStdout(), jsonOutput)
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the build information as JSON")
 	return cmd
 }
 
 func runWithOptions(opts cliOptions) error {
+	if len(opts.files) == 0 && opts.dir == "" {
+		return fmt.Errorf("required flag(s) \"file\" not set (or use --dir)")
+	}
+	inputs, err := batchInputs(opts.files, opts.dir)
+	if err != nil {
+		return err
+	}
+	if opts.concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if opts.timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	if opts.stream && opts.jsonOutput {
+		return fmt.Errorf("--stream cannot be combined with --json")
+	}
+	if opts.stream && (len(inputs) > 1 || opts.dir != "") {
+		return fmt.Errorf("--stream supports a single --file only")
+	}
+
 	// Configure logging BEFORE any other operations.
 	if !opts.verbose {
 		log.SetOutput(io.Discard)
@@ -79,36 +154,97 @@ func runWithOptions(opts cliOptions) error {
 		fmt.Fprintf(os.Stderr, "[verbose] Starting OCR tool\n")
 	}
 
-	loadOptions := config.LoadOptions{APIKeyPathOverride: opts.apiKeyPath}
+	cfg, err := initLLM(opts)
+	if err != nil {
+		return err
+	}
+
+	ocr.SetMaxImageEdge(cfg.OCRMaxImageEdge)
+	ocr.SetTiling(cfg.OCRTile)
+	ocr.SetNormalization(cfg.OCRTrim, cfg.OCRLineEndings)
+	replacements, err := ocr.LoadReplacements(cfg.OCRReplacements)
+	if err != nil {
+		return err
+	}
+	ocr.SetReplacements(replacements)
+	translateTo := cfg.TranslateTo
+	if opts.translate != "" {
+		translateTo = opts.translate
+	}
+	ocr.SetTranslation(translateTo, cfg.TranslateKeepOrig)
+	ocr.SetExtraction(cfg.OCRExtract)
+
+	if opts.verbose {
+		fmt.Fprintf(os.Stderr, "[verbose] LLM initialized\n")
+	}
+
+	if len(inputs) > 1 || opts.dir != "" {
+		return runBatch(inputs, opts)
+	}
+	if opts.stream {
+		return processOCRStream(inputs[0], opts.verbose)
+	}
+	return processOCR(inputs[0], opts.jsonOutput, opts.verbose)
+}
+
+// initLLM loads the configuration, applies CLI overrides and initializes the
+// LLM client.
+func initLLM(opts cliOptions) (*config.Config, error) {
+	loadOptions := config.LoadOptions{ConfigPath: opts.configPath, APIKeyPathOverride: opts.apiKeyPath}
 	cfg, err := config.LoadWithOptions(loadOptions)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
 	if opts.verbose {
-		fmt.Fprintf(os.Stderr, "[verbose] Config loaded: Model=%s\n", cfg.Model)
+		fmt.Fprintf(os.Stderr, "[verbose] Config loaded: Model=%s Models=%v\n", cfg.Model, cfg.Models)
 		fmt.Fprintf(os.Stderr, "[verbose] Effective API key path: %s\n", cfg.APIKeyPath)
+		if opts.timeout > 0 {
+			fmt.Fprintf(os.Stderr, "[verbose] Request timeout: %v\n", opts.timeout)
+		}
 	}
 
-	if cfg.APIKey == "" {
-		return fmt.Errorf("OPENROUTER_API_KEY not found. Checked key file %s and OPENROUTER_API_KEY env var", cfg.APIKeyPath)
+	if opts.language != "" {
+		cfg.OCRLanguage = opts.language
+	}
+	if opts.describe {
+		cfg.Mode = llm.ModeDescribe
+	}
+	if opts.mock {
+		cfg.UseMockLLM()
 	}
 
-	if cfg.Model == "" {
-		return fmt.Errorf("MODEL is required in .env file")
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("OPENROUTER_API_KEY not found. Checked key file %s and OPENROUTER_API_KEY env var", cfg.APIKeyPath)
 	}
 
-	llm.Init(&llm.Config{
-		APIKey:    cfg.APIKey,
-		Model:     cfg.Model,
-		Providers: cfg.Providers,
-	})
+	if err := cfg.Validate(nil); err != nil {
+		return nil, err
+	}
 
-	if opts.verbose {
-		fmt.Fprintf(os.Stderr, "[verbose] LLM initialized\n")
+	if err := llm.Init(&llm.Config{
+		APIKey:         cfg.APIKey,
+		Model:          cfg.Model,
+		Models:         cfg.Models,
+		Providers:      cfg.Providers,
+		AllowFallbacks: cfg.ProviderFallbacks,
+		Quantizations:  cfg.ProviderQuants,
+		Language:       cfg.OCRLanguage,
+		BaseURL:        cfg.BaseURL,
+		MaxTokens:      cfg.OCRMaxTokens,
+		Temperature:    &cfg.OCRTemperature,
+		OutputFormat:   cfg.OCROutputFormat,
+		Mode:           cfg.Mode,
+		Transport:      llmTransport(cfg),
+		HTTPReferer:    cfg.HTTPReferer,
+		AppTitle:       cfg.AppTitle,
+		MarkUncertain:  cfg.OCRMarkUncertain,
+		RequestTimeout: opts.timeout,
+	}); err != nil {
+		return nil, err
 	}
 
-	return processOCR(opts.filePath, opts.jsonOutput, opts.verbose)
+	return cfg, nil
 }
 
 func normalizeLegacyArgs(args []string) []string {
@@ -134,16 +270,33 @@ func normalizeLegacyArgs(args []string) []string {
 			normalized[i] = "--verbose"
 		case strings.HasPrefix(arg, "-verbose="):
 			normalized[i] = "--verbose=" + arg[len("-verbose="):]
+		case arg == "-config":
+			normalized[i] = "--config"
+		case strings.HasPrefix(arg, "-config="):
+			normalized[i] = "--config=" + arg[len("-config="):]
 		case arg == "-api-key-path":
 			normalized[i] = "--api-key-path"
 		case strings.HasPrefix(arg, "-api-key-path="):
 			normalized[i] = "--api-key-path=" + arg[len("-api-key-path="):]
+		case arg == "-lang":
+			normalized[i] = "--lang"
+		case strings.HasPrefix(arg, "-lang="):
+			normalized[i] = "--lang=" + arg[len("-lang="):]
 		}
 	}
 
 	return normalized
 }
 
+// llmTransport returns llm.MockTransport for LLM_BACKEND=mock or --mock and
+// nil (real HTTP) otherwise.
+func llmTransport(cfg *config.Config) http.RoundTripper {
+	if cfg.LLMBackend == config.LLMBackendMock {
+		return llm.MockTransport{}
+	}
+	return nil
+}
+
 // truncateSecret safely truncates a secret for display, showing only first N characters.
 func truncateSecret(secret string, maxLen int) string {
 	if len(secret) <= maxLen {
@@ -153,6 +306,44 @@ func truncateSecret(secret string, maxLen int) string {
 }
 
 func processOCR(filePath string, jsonOutput bool, verbose bool) error {
+	imageData, err := readInput(filePath, verbose)
+	if err != nil {
+		return err
+	}
+	return performOCR(imageData, filePath, jsonOutput, verbose)
+}
+
+// processOCRStream runs OCR on a single input and writes the text to stdout
+// as the model streams it.
+func processOCRStream(filePath string, verbose bool) error {
+	imageData, err := readInput(filePath, verbose)
+	if err != nil {
+		return err
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[verbose] Starting streamed OCR via ocr.RecognizeImageStream\n")
+	}
+
+	startTime := time.Now()
+	text, err := ocr.RecognizeImageStream(imageData, func(chunk string) {
+		fmt.Print(chunk)
+	})
+	elapsed := time.Since(startTime)
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[verbose] OCR failed after %v: %v\n", elapsed, err)
+		}
+		return fmt.Errorf("OCR failed: %w", err)
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[verbose] OCR completed in %v, extracted %d characters\n", elapsed, len(text))
+	}
+	return nil
+}
+
+// readInput reads a PNG from filePath ("-" for stdin) and checks its size and
+// signature.
+func readInput(filePath string, verbose bool) ([]byte, error) {
 	var imageData []byte
 	var err error
 
@@ -162,7 +353,7 @@ func processOCR(filePath string, jsonOutput bool, verbose bool) error {
 		}
 		imageData, err = io.ReadAll(os.Stdin)
 		if err != nil {
-			return fmt.Errorf("failed to read from stdin: %w", err)
+			return nil, fmt.Errorf("failed to read from stdin: %w", err)
 		}
 	} else {
 		if verbose {
@@ -170,15 +361,15 @@ func processOCR(filePath string, jsonOutput bool, verbose bool) error {
 		}
 		imageData, err = os.ReadFile(filePath)
 		if err != nil {
-			return fmt.Errorf("failed to read file %s: %w", filePath, err)
+			return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
 		}
 	}
 
 	if len(imageData) == 0 {
-		return fmt.Errorf("input file is empty")
+		return nil, fmt.Errorf("input file is empty")
 	}
 	if len(imageData) > maxFileSize {
-		return fmt.Errorf("input file exceeds maximum size of %d MB", maxFileSizeMB)
+		return nil, fmt.Errorf("input file exceeds maximum size of %d MB", maxFileSizeMB)
 	}
 
 	if verbose {
@@ -186,23 +377,23 @@ func processOCR(filePath string, jsonOutput bool, verbose bool) error {
 	}
 
 	if len(imageData) < 8 || !bytes.Equal(imageData[:8], []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a}) {
-		return fmt.Errorf("input is not a valid PNG file (invalid magic number)")
+		return nil, fmt.Errorf("input is not a valid PNG file (invalid magic number)")
 	}
 
 	if verbose {
 		fmt.Fprintf(os.Stderr, "[verbose] PNG validation passed\n")
 	}
 
-	return performOCR(imageData, filePath, jsonOutput, verbose)
+	return imageData, nil
 }
 
 func performOCR(imageData []byte, sourcePath string, jsonOutput bool, verbose bool) error {
 	if verbose {
-		fmt.Fprintf(os.Stderr, "[verbose] Starting OCR with model via llm.QueryVision\n")
+		fmt.Fprintf(os.Stderr, "[verbose] Starting OCR with model via ocr.RecognizeImageWithUncertainty\n")
 	}
 
 	startTime := time.Now()
-	text, err := llm.QueryVision(imageData)
+	text, spans, usage, err := ocr.RecognizeImageWithUncertainty(imageData)
 	elapsed := time.Since(startTime)
 
 	if err != nil {
@@ -214,27 +405,59 @@ func performOCR(imageData []byte, sourcePath string, jsonOutput bool, verbose bo
 
 	if verbose {
 		fmt.Fprintf(os.Stderr, "[verbose] OCR completed in %v, extracted %d characters\n", elapsed, len(text))
+		fmt.Fprintf(os.Stderr, "[verbose] Tokens used: prompt=%d completion=%d total=%d\n", usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+		if len(spans) > 0 {
+			fmt.Fprintf(os.Stderr, "[verbose] Uncertain spans: %q\n", spans)
+		}
 	}
 
-	return outputResult(text, sourcePath, elapsed, jsonOutput)
+	return outputResult(text, spans, sourcePath, elapsed, usage, jsonOutput)
 }
 
 type OCRResult struct {
-	Text      string  `json:"text"`
-	Source    string  `json:"source"`
-	Timestamp string  `json:"timestamp"`
-	Duration  float64 `json:"duration_seconds"`
-	CharCount int     `json:"character_count"`
+	Text      string    `json:"text"`
+	Format    string    `json:"format"`
+	Source    string    `json:"source"`
+	Timestamp string    `json:"timestamp"`
+	Duration  float64   `json:"duration_seconds"`
+	CharCount int       `json:"character_count"`
+	Usage     llm.Usage `json:"usage"`
+	// WindowTitle is the window the image was captured from. Images read
+	// from files or stdin have none, so it is always empty here.
+	WindowTitle string `json:"window_title"`
+	// UncertainSpans lists the text the model marked as unclear when
+	// OCR_MARK_UNCERTAIN is on, in order of appearance.
+	UncertainSpans []string `json:"uncertain_spans,omitempty"`
+	// Entities lists the URLs and email addresses in the text when
+	// OCR_EXTRACT is set.
+	Entities *ocr.Entities `json:"entities,omitempty"`
+	// Error is set for files that failed in a batch run.
+	Error string `json:"error,omitempty"`
+}
+
+// extractEntities returns the entities OCR_EXTRACT asks for, or nil when
+// extraction is off.
+func extractEntities(text string) *ocr.Entities {
+	kind := ocr.Extraction()
+	if kind == ocr.ExtractNone {
+		return nil
+	}
+	entities := ocr.Extract(text, kind)
+	return &entities
 }
 
-func outputResult(text string, sourcePath string, elapsed time.Duration, jsonOutput bool) error {
+func outputResult(text string, uncertainSpans []string, sourcePath string, elapsed time.Duration, usage llm.Usage, jsonOutput bool) error {
 	if jsonOutput {
 		result := OCRResult{
-			Text:      text,
-			Source:    sourcePath,
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-			Duration:  elapsed.Seconds(),
-			CharCount: len(text),
+			Text:           text,
+			Format:         llm.OutputFormat(),
+			Source:         sourcePath,
+			Timestamp:      time.Now().UTC().Format(time.RFC3339),
+			Duration:       elapsed.Seconds(),
+			CharCount:      len(text),
+			Usage:          usage,
+			UncertainSpans: uncertainSpans,
+			Entities:       extractEntities(text),
 		}
 
 		encoder := json.NewEncoder(os.Stdout)
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"screen-ocr-llm/src/config"
+	"screen-ocr-llm/src/selftest"
+)
+
+func newSelftestCmd() *cobra.Command {
+	opts := &cliOptions{}
+	cmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Check the configuration, API key, an OCR request with a bundled image and the clipboard",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.verbose {
+				log.SetOutput(os.Stderr)
+			} else {
+				log.SetOutput(io.Discard)
+			}
+			report := selftest.Run(config.LoadOptions{ConfigPath: opts.configPath, APIKeyPathOverride: opts.apiKeyPath})
+			report.Write(cmd.OutOrStdout())
+			return report.Err()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", false, "Verbose output to stderr")
+	cmd.Flags().StringVar(&opts.configPath, "config", "", "Path to the .env file to load (overrides SCREEN_OCR_LLM and the executable-local .env)")
+	cmd.Flags().StringVar(&opts.apiKeyPath, "api-key-path", "", "Path to API key file (highest precedence)")
+
+	return cmd
+}
@@ -2,9 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -66,9 +66,9 @@ func runWithOptions(opts stressOptions) error {
 			defer cancel()
 			client := singleinstance.NewClient()
 			stdout := opts.mode == "std"
-			delegated, _, err := client.TryRunOnce(ctx, stdout)
+			delegated, _, err := client.TryRunOnce(ctx, singleinstance.NewRequest(singleinstance.LegacyOutput(stdout), ""))
 			if err != nil {
-				if strings.Contains(strings.ToLower(err.Error()), "busy") {
+				if errors.Is(err, singleinstance.ErrResidentBusy) {
 					atomic.AddInt32(&busyCount, 1)
 					return
 				}
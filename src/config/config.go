@@ -1,10 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/joho/godotenv"
 )
@@ -18,34 +20,118 @@ const (
 )
 
 type LoadOptions struct {
-	APIKeyPathOverride  string
-	DefaultModeOverride string
+	// ConfigPath is an explicit .env file to load (--config). It must exist,
+	// and is used instead of SCREEN_OCR_LLM and the executable-local .env
+	// from then on, including by EnvPath and SetEnvValue.
+	ConfigPath                string
+	APIKeyPathOverride        string
+	DefaultModeOverride       string
+	SaveScreenshotDirOverride string
+	// DryRunOverride forces DryRun on (--dry-run).
+	DryRunOverride bool
 }
 
 type Config struct {
-	APIKey            string
-	APIKeyPath        string
-	Model             string
-	EnableFileLogging bool
-	Hotkey            string
-	DefaultMode       string
-	Providers         []string
-	OCRDeadlineSec    int
+	APIKey               string
+	APIKeyPath           string
+	Model                string
+	Models               []string
+	ModelChoices         []string
+	OCRHistoryEnabled    bool
+	OCRHTTPEnabled       bool
+	OCRHTTPPort          int
+	PresetRegions        []PresetRegion
+	OCRLanguage          string
+	PopupPosition        string
+	PopupWidth           int
+	PopupHeight          int
+	PopupDurationSec     int
+	PopupSticky          bool
+	PopupMaxChars        int
+	PopupTheme           string
+	NotifyOnComplete     string
+	EnableFileLogging    bool
+	LogLevel             string
+	LogDir               string
+	Hotkey               string
+	Hotkeys              []HotkeyBinding
+	HotkeyDebounceMs     int
+	DefaultMode          string
+	Providers            []string
+	ProviderStrategy     string
+	ProviderFallbacks    bool
+	ProviderQuants       []string
+	OCRDeadlineSec       int
+	OCRQueueDepth        int
+	OCRWorkers           int
+	OCRQueue             int
+	OverlayGuides        bool
+	OutputSink           string
+	ClipboardAppend      bool
+	ClipboardAppendSep   string
+	ClipboardFormat      string
+	ClipboardClearAfter  int
+	TypeDelayMs          int
+	OverlayBGScale       float64
+	OverlayMagnifier     bool
+	OCRContinuationChars int
+	TrayPreviewSec       int
+	TrayPreviewText      bool
+	OCRMaxImageEdge      int
+	OCRTile              bool
+	OCRPreprocess        string
+	BlankThreshold       float64
+	OCRAutoCrop          bool
+	OCRMarkUncertain     bool
+	DryRun               bool
+	SaveScreenshotDir    string
+	OCRMaxTokens         int
+	OCRTemperature       float64
+	OCROutputFormat      string
+	Mode                 string
+	OCRTrim              bool
+	OCRLineEndings       string
+	OCRReplacements      string
+	OCRExtract           string
+	TranslateTo          string
+	TranslateKeepOrig    bool
+	BaseURL              string
+	ReloadConfigOnGrab   bool
+	LLMBackend           string
+	HTTPReferer          string
+	AppTitle             string
+
+	// loadProblems lists settings Load replaced with a default; see Validate.
+	loadProblems []string
 }
 
+// LLM backends accepted by LLM_BACKEND.
+const (
+	LLMBackendOpenRouter = "openrouter"
+	LLMBackendMock       = "mock"
+)
+
 func Load() (*Config, error) {
 	return LoadWithOptions(LoadOptions{})
 }
 
 func LoadWithOptions(opts LoadOptions) (*Config, error) {
-	// Load configuration from sources in priority order:
-	// 1) .env in the application (executable) directory
-	// 2) If not found, use SCREEN_OCR_LLM env var as a path to a config file
+	// Load configuration from the first source found, in priority order:
+	// 1) the explicit --config path
+	// 2) SCREEN_OCR_LLM env var as a path to a config file
+	// 3) .env in the application (executable) directory
+	// 4) otherwise only the process environment
+	if opts.ConfigPath != "" {
+		if err := setConfigPath(opts.ConfigPath); err != nil {
+			return nil, err
+		}
+	}
 	envPath := resolveEnvPath()
 	dotenvValues := readDotenvValues(envPath)
-	if envPath != "" {
-		_ = godotenv.Load(envPath)
-	}
+	applyDotenv(dotenvValues)
+
+	// Settings that fell back to a default, reported by Validate
+	var loadProblems []string
 
 	// Parse providers from comma-separated string
 	var providers []string
@@ -54,35 +140,437 @@ func LoadWithOptions(opts LoadOptions) (*Config, error) {
 		for _, provider := range strings.Split(providersStr, ",") {
 			if trimmed := strings.TrimSpace(provider); trimmed != "" {
 				providers = append(providers, trimmed)
+			} else {
+				loadProblems = append(loadProblems, fmt.Sprintf("PROVIDERS %q has an empty entry", providersStr))
 			}
 		}
 	}
 
+	// Provider routing extras: OpenRouter quantization filter (e.g. fp16)
+	var providerQuants []string
+	for _, q := range strings.Split(os.Getenv("PROVIDER_QUANTIZATIONS"), ",") {
+		if trimmed := strings.ToLower(strings.TrimSpace(q)); trimmed != "" {
+			providerQuants = append(providerQuants, trimmed)
+		}
+	}
+
+	// Provider strategy: strict uses only PROVIDERS, fallback lets OpenRouter
+	// route past them in order. PROVIDER_ALLOW_FALLBACKS=true is the older
+	// spelling of fallback.
+	providerStrategy := strings.ToLower(strings.TrimSpace(os.Getenv("PROVIDER_STRATEGY")))
+	if providerStrategy != "strict" && providerStrategy != "fallback" {
+		providerStrategy = "strict"
+		if strings.ToLower(os.Getenv("PROVIDER_ALLOW_FALLBACKS")) == "true" {
+			providerStrategy = "fallback"
+		}
+	}
+
+	// Parse model fallback chain; MODEL alone is a one-entry chain. MODEL is
+	// tried first, as after a tray model switch, so the model reported as in
+	// use is the one that reads the text.
+	model := strings.TrimSpace(os.Getenv("MODEL"))
+	var models []string
+	if modelsStr := os.Getenv("MODELS"); modelsStr != "" {
+		for _, m := range strings.Split(modelsStr, ",") {
+			if trimmed := strings.TrimSpace(m); trimmed != "" {
+				models = append(models, trimmed)
+			}
+		}
+	}
+	if model == "" && len(models) > 0 {
+		model = models[0]
+	} else if model != "" {
+		chain := []string{model}
+		for _, m := range models {
+			if m != model {
+				chain = append(chain, m)
+			}
+		}
+		models = chain
+	}
+
+	// Models offered in the tray "Model" submenu
+	var modelChoices []string
+	for _, m := range strings.Split(os.Getenv("MODEL_CHOICES"), ",") {
+		if trimmed := strings.TrimSpace(m); trimmed != "" {
+			modelChoices = append(modelChoices, trimmed)
+		}
+	}
+
 	// Resolve OCR deadline (seconds) with env override and sane default
 	ocrDeadlineSec := 20
 	if v := os.Getenv("OCR_DEADLINE_SEC"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			ocrDeadlineSec = n
+		} else {
+			loadProblems = append(loadProblems, fmt.Sprintf("OCR_DEADLINE_SEC %q is not a positive number of seconds", v))
+		}
+	}
+
+	// Overlay background scale: (0, 1], anything else means full resolution
+	overlayBGScale := 1.0
+	if v := os.Getenv("OVERLAY_BG_SCALE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 && f <= 1 {
+			overlayBGScale = f
+		}
+	}
+
+	// Continuation OCR: pass the tail of the previous result as context
+	ocrContinuationChars := 0
+	if strings.ToLower(os.Getenv("OCR_CONTINUATION")) == "true" {
+		ocrContinuationChars = 200
+		if v := os.Getenv("OCR_CONTINUATION_CHARS"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				ocrContinuationChars = n
+			}
+		}
+	}
+
+	// Delegated run-once requests that may wait while the resident is busy
+	// (0 rejects them immediately with BUSY)
+	ocrQueueDepth := 0
+	if v := os.Getenv("OCR_QUEUE_DEPTH"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			ocrQueueDepth = n
+		}
+	}
+
+	// Presses of a hotkey within this window of the previous one are ignored
+	// (milliseconds, 0 disables)
+	hotkeyDebounceMs := 300
+	if v := os.Getenv("HOTKEY_DEBOUNCE_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			hotkeyDebounceMs = n
+		}
+	}
+
+	// Resident worker pool: OCR jobs that may run at once, and jobs the pool
+	// accepts beyond them to wait for a free worker
+	ocrWorkers := 1
+	if v := os.Getenv("OCR_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			ocrWorkers = n
+		}
+	}
+	ocrQueue := 0
+	if v := os.Getenv("OCR_QUEUE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			ocrQueue = n
+		}
+	}
+
+	// Tray tooltip last-result preview duration (seconds, 0 disables)
+	trayPreviewSec := 300
+	if v := os.Getenv("TRAY_PREVIEW_SEC"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			trayPreviewSec = n
+		}
+	}
+
+	// Longest image edge sent to the LLM (pixels, 0 disables downscaling)
+	ocrMaxImageEdge := 2000
+	if v := os.Getenv("OCR_MAX_IMAGE_EDGE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			ocrMaxImageEdge = n
+		}
+	}
+
+	// OCR completion limits; out-of-range values are rejected by llm.Init
+	ocrMaxTokens := 2000
+	if v := os.Getenv("OCR_MAX_TOKENS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			ocrMaxTokens = n
+		}
+	}
+	ocrTemperature := 0.1
+	if v := os.Getenv("OCR_TEMPERATURE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			ocrTemperature = f
+		}
+	}
+
+	// Loopback HTTP OCR endpoint port (only used when OCR_HTTP_ENABLED=true)
+	ocrHTTPPort := 49560
+	if v := os.Getenv("OCR_HTTP_PORT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 65535 {
+			ocrHTTPPort = n
+		}
+	}
+
+	// Invalid preset entries are dropped; the valid ones remain usable
+	presetRegions, _ := ParsePresetRegions(os.Getenv("PRESET_REGIONS"))
+
+	// Extra hotkeys with their own output (HOTKEYS); HOTKEY stays the default
+	hotkeys, err := ParseHotkeys(os.Getenv("HOTKEYS"))
+	if err != nil {
+		loadProblems = append(loadProblems, err.Error())
+	}
+
+	// Popup placement; unset keeps the 400x100 lower-left window
+	popupWidth := 400
+	if v := os.Getenv("POPUP_WIDTH"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			popupWidth = n
+		}
+	}
+	popupHeight := 100
+	if v := os.Getenv("POPUP_HEIGHT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			popupHeight = n
+		}
+	}
+
+	// Seconds the result popup stays visible (0 = until clicked)
+	popupDurationSec := 3
+	if v := os.Getenv("POPUP_DURATION_SEC"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			popupDurationSec = n
+		}
+	}
+
+	// Characters of a result shown in the popup (0 = all)
+	popupMaxChars := 1000
+	if v := os.Getenv("POPUP_MAX_CHARS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			popupMaxChars = n
+		}
+	}
+
+	// Popup colors: light, dark, or auto (follow the Windows app theme)
+	popupTheme := strings.ToLower(strings.TrimSpace(getEnvWithDefault("POPUP_THEME", "auto")))
+	if popupTheme != "light" && popupTheme != "dark" {
+		popupTheme = "auto"
+	}
+
+	// Cue when an OCR finishes: none, sound, flash (tray icon) or both
+	notifyOnComplete := strings.ToLower(strings.TrimSpace(getEnvWithDefault("NOTIFY_ON_COMPLETE", "none")))
+	switch notifyOnComplete {
+	case "sound", "flash", "both":
+	default:
+		notifyOnComplete = "none"
+	}
+
+	// Mean brightness (0-255) at or below which a capture counts as blank
+	// and is retried once; 0 disables the check
+	blankThreshold := 8.0
+	if v := os.Getenv("BLANK_CAPTURE_THRESHOLD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 255 {
+			blankThreshold = f
 		}
 	}
 
+	// Capture preprocessing before OCR: grayscale, contrast, both, or none
+	ocrPreprocess := strings.ToLower(strings.TrimSpace(getEnvWithDefault("OCR_PREPROCESS", "none")))
+	switch ocrPreprocess {
+	case "grayscale", "contrast", "both":
+	default:
+		ocrPreprocess = "none"
+	}
+
+	// OCR output line endings: lf, crlf, or keep what the model returned
+	ocrLineEndings := strings.ToLower(strings.TrimSpace(getEnvWithDefault("OCR_LINE_ENDINGS", "keep")))
+	if ocrLineEndings != "lf" && ocrLineEndings != "crlf" {
+		ocrLineEndings = "keep"
+	}
+
+	// Entities picked out of results: urls, emails or all (empty disables)
+	ocrExtract := strings.ToLower(strings.TrimSpace(os.Getenv("OCR_EXTRACT")))
+	if ocrExtract != "urls" && ocrExtract != "emails" && ocrExtract != "all" {
+		ocrExtract = ""
+	}
+
+	// Clipboard format written alongside plain text: plain, html or rtf
+	clipboardFormat := strings.ToLower(strings.TrimSpace(getEnvWithDefault("CLIPBOARD_FORMAT", "plain")))
+	if clipboardFormat != "html" && clipboardFormat != "rtf" {
+		clipboardFormat = "plain"
+	}
+
+	// Seconds after which the resident clears a result it copied (0 = never)
+	clipboardClearAfter := 0
+	if v := os.Getenv("CLIPBOARD_CLEAR_AFTER_SEC"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			clipboardClearAfter = n
+		}
+	}
+
+	// Pause between characters typed by the "type" output (milliseconds)
+	typeDelayMs := 5
+	if v := os.Getenv("TYPE_DELAY_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			typeDelayMs = n
+		}
+	}
+
+	// What to ask the vision model for: the image's text, or a description
+	mode := strings.ToLower(strings.TrimSpace(getEnvWithDefault("MODE", "ocr")))
+	if mode != "describe" {
+		mode = "ocr"
+	}
+
+	// OCR output: plain text, or tables as markdown / CSV
+	ocrOutputFormat := strings.ToLower(strings.TrimSpace(getEnvWithDefault("OCR_OUTPUT_FORMAT", "text")))
+	if ocrOutputFormat != "markdown" && ocrOutputFormat != "csv" {
+		ocrOutputFormat = "text"
+	}
+
+	// Log verbosity: debug adds per-key and per-window-message diagnostics
+	logLevel := strings.ToLower(strings.TrimSpace(getEnvWithDefault("LOG_LEVEL", "info")))
+	switch logLevel {
+	case "debug", "warn", "error":
+	case "warning":
+		logLevel = "warn"
+	default:
+		logLevel = "info"
+	}
+
 	apiKeyPath := resolveAPIKeyPath(opts, dotenvValues)
 
 	cfg := &Config{
-		APIKey:            resolveAPIKey(apiKeyPath),
-		APIKeyPath:        apiKeyPath,
-		Model:             os.Getenv("MODEL"),
-		EnableFileLogging: strings.ToLower(os.Getenv("ENABLE_FILE_LOGGING")) == "true",
-		Hotkey:            getEnvWithDefault("HOTKEY", "Ctrl+Alt+Q"),
-		DefaultMode:       resolveDefaultModeValue(opts),
-		Providers:         providers,
-		OCRDeadlineSec:    ocrDeadlineSec,
+		APIKey:               resolveAPIKey(apiKeyPath),
+		APIKeyPath:           apiKeyPath,
+		Model:                model,
+		Models:               models,
+		ModelChoices:         modelChoices,
+		OCRHistoryEnabled:    strings.ToLower(os.Getenv("OCR_HISTORY_ENABLED")) == "true",
+		OCRHTTPEnabled:       strings.ToLower(os.Getenv("OCR_HTTP_ENABLED")) == "true",
+		OCRHTTPPort:          ocrHTTPPort,
+		PresetRegions:        presetRegions,
+		OCRLanguage:          strings.TrimSpace(os.Getenv("OCR_LANGUAGE")),
+		PopupPosition:        strings.ToLower(strings.TrimSpace(getEnvWithDefault("POPUP_POSITION", "bottom-left"))),
+		PopupWidth:           popupWidth,
+		PopupHeight:          popupHeight,
+		PopupDurationSec:     popupDurationSec,
+		PopupSticky:          strings.ToLower(os.Getenv("POPUP_STICKY")) == "true",
+		PopupMaxChars:        popupMaxChars,
+		PopupTheme:           popupTheme,
+		NotifyOnComplete:     notifyOnComplete,
+		EnableFileLogging:    strings.ToLower(os.Getenv("ENABLE_FILE_LOGGING")) == "true",
+		LogLevel:             logLevel,
+		LogDir:               strings.TrimSpace(os.Getenv("LOG_DIR")),
+		Hotkey:               getEnvWithDefault("HOTKEY", "Ctrl+Alt+Q"),
+		Hotkeys:              hotkeys,
+		HotkeyDebounceMs:     hotkeyDebounceMs,
+		DefaultMode:          resolveDefaultModeValue(opts),
+		Providers:            providers,
+		ProviderStrategy:     providerStrategy,
+		ProviderFallbacks:    providerStrategy == "fallback",
+		ProviderQuants:       providerQuants,
+		OCRDeadlineSec:       ocrDeadlineSec,
+		OCRQueueDepth:        ocrQueueDepth,
+		OCRWorkers:           ocrWorkers,
+		OCRQueue:             ocrQueue,
+		OverlayGuides:        strings.ToLower(os.Getenv("OVERLAY_GUIDES")) == "true",
+		OutputSink:           getEnvWithDefault("OUTPUT_SINK", "clipboard"),
+		ClipboardAppend:      strings.ToLower(os.Getenv("CLIPBOARD_APPEND")) == "true",
+		ClipboardAppendSep:   unescapeSeparator(getEnvWithDefault("CLIPBOARD_APPEND_SEPARATOR", `\n`)),
+		ClipboardFormat:      clipboardFormat,
+		ClipboardClearAfter:  clipboardClearAfter,
+		TypeDelayMs:          typeDelayMs,
+		OverlayBGScale:       overlayBGScale,
+		OverlayMagnifier:     strings.ToLower(getEnvWithDefault("OVERLAY_MAGNIFIER", "true")) == "true",
+		OCRContinuationChars: ocrContinuationChars,
+		TrayPreviewSec:       trayPreviewSec,
+		TrayPreviewText:      strings.ToLower(getEnvWithDefault("TRAY_PREVIEW_TEXT", "true")) == "true",
+		OCRMaxImageEdge:      ocrMaxImageEdge,
+		OCRTile:              strings.ToLower(os.Getenv("OCR_TILE")) == "true",
+		OCRPreprocess:        ocrPreprocess,
+		BlankThreshold:       blankThreshold,
+		OCRAutoCrop:          strings.ToLower(os.Getenv("OCR_AUTOCROP")) == "true",
+		OCRMarkUncertain:     strings.ToLower(os.Getenv("OCR_MARK_UNCERTAIN")) == "true",
+		DryRun:               opts.DryRunOverride || strings.ToLower(os.Getenv("DRY_RUN")) == "true",
+		SaveScreenshotDir:    resolveSaveScreenshotDir(opts),
+		OCRMaxTokens:         ocrMaxTokens,
+		OCRTemperature:       ocrTemperature,
+		OCROutputFormat:      ocrOutputFormat,
+		Mode:                 mode,
+		OCRTrim:              strings.ToLower(os.Getenv("OCR_TRIM")) == "true",
+		OCRLineEndings:       ocrLineEndings,
+		OCRReplacements:      strings.TrimSpace(os.Getenv("OCR_REPLACEMENTS")),
+		OCRExtract:           ocrExtract,
+		TranslateTo:          strings.TrimSpace(os.Getenv("TRANSLATE_TO")),
+		TranslateKeepOrig:    strings.ToLower(os.Getenv("TRANSLATE_KEEP_ORIGINAL")) == "true",
+		BaseURL:              strings.TrimSpace(os.Getenv("OPENROUTER_BASE_URL")),
+		HTTPReferer:          strings.TrimSpace(os.Getenv("HTTP_REFERER")),
+		AppTitle:             strings.TrimSpace(os.Getenv("APP_TITLE")),
+		ReloadConfigOnGrab:   strings.ToLower(getEnvWithDefault("RELOAD_CONFIG_ON_GRAB", "true")) == "true",
+		loadProblems:         loadProblems,
+	}
+	if strings.ToLower(strings.TrimSpace(os.Getenv("LLM_BACKEND"))) == LLMBackendMock {
+		cfg.UseMockLLM()
+	} else {
+		cfg.LLMBackend = LLMBackendOpenRouter
 	}
 
 	return cfg, nil
 }
 
+// UseMockLLM switches cfg to the offline mock LLM backend. A missing API key
+// or model is filled with a placeholder, since the mock needs neither.
+func (c *Config) UseMockLLM() {
+	c.LLMBackend = LLMBackendMock
+	if c.APIKey == "" {
+		c.APIKey = "mock"
+	}
+	if c.Model == "" {
+		c.Model = "mock"
+	}
+}
+
+// unescapeSeparator expands \n and \t in CLIPBOARD_APPEND_SEPARATOR so a
+// newline can be written on one .env line.
+func unescapeSeparator(sep string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(sep)
+}
+
+// resolveSaveScreenshotDir prefers --save-screenshot over SAVE_SCREENSHOT_DIR.
+func resolveSaveScreenshotDir(opts LoadOptions) string {
+	if override := strings.TrimSpace(opts.SaveScreenshotDirOverride); override != "" {
+		return override
+	}
+	return strings.TrimSpace(os.Getenv("SAVE_SCREENSHOT_DIR"))
+}
+
+// EnvPath returns the .env file LoadWithOptions reads, or "" when there is
+// none.
+func EnvPath() string {
+	return resolveEnvPath()
+}
+
+// configPath is the explicit config file from LoadOptions.ConfigPath.
+var (
+	configPathMu sync.Mutex
+	configPath   string
+)
+
+// setConfigPath makes path the config file for the rest of the process.
+func setConfigPath(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	configPathMu.Lock()
+	defer configPathMu.Unlock()
+	configPath = abs
+	return nil
+}
+
 func resolveEnvPath() string {
+	configPathMu.Lock()
+	explicit := configPath
+	configPathMu.Unlock()
+	if explicit != "" {
+		return explicit
+	}
+
+	if alt := os.Getenv("SCREEN_OCR_LLM"); alt != "" {
+		if _, err := os.Stat(alt); err == nil {
+			return alt
+		}
+	}
+
 	execPath, err := os.Executable()
 	if err != nil {
 		return ""
@@ -94,12 +582,6 @@ func resolveEnvPath() string {
 		return exeEnv
 	}
 
-	if alt := os.Getenv("SCREEN_OCR_LLM"); alt != "" {
-		if _, err := os.Stat(alt); err == nil {
-			return alt
-		}
-	}
-
 	return ""
 }
 
@@ -134,6 +616,8 @@ func resolveAPIKeyPath(opts LoadOptions, dotenvValues map[string]string) string
 	return keyPath
 }
 
+// resolveAPIKey prefers the key file, then the Windows Credential Manager
+// entry (CredentialTarget), then OPENROUTER_API_KEY.
 func resolveAPIKey(keyPath string) string {
 	if data, err := os.ReadFile(keyPath); err == nil {
 		if fileKey := strings.TrimSpace(string(data)); fileKey != "" {
@@ -141,6 +625,10 @@ func resolveAPIKey(keyPath string) string {
 		}
 	}
 
+	if credKey, err := readAPIKeyCredential(); err == nil && credKey != "" {
+		return credKey
+	}
+
 	return os.Getenv("OPENROUTER_API_KEY")
 }
 
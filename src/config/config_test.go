@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -12,6 +14,50 @@ func TestLoad(t *testing.T) {
 	t.Setenv("ENABLE_FILE_LOGGING", "true")
 	t.Setenv("HOTKEY", "Ctrl+Shift+T")
 	t.Setenv("DEFAULT_MODE", "lasso")
+	t.Setenv("OVERLAY_GUIDES", "true")
+	t.Setenv("OVERLAY_BG_SCALE", "0.5")
+	t.Setenv("OCR_CONTINUATION", "true")
+	t.Setenv("TRAY_PREVIEW_SEC", "0")
+	t.Setenv("TRAY_PREVIEW_TEXT", "false")
+	t.Setenv("OCR_MAX_IMAGE_EDGE", "1500")
+	t.Setenv("OCR_HTTP_ENABLED", "true")
+	t.Setenv("OCR_HTTP_PORT", "8089")
+	t.Setenv("OCR_PREPROCESS", "Contrast")
+	t.Setenv("BLANK_CAPTURE_THRESHOLD", "12.5")
+	t.Setenv("OCR_AUTOCROP", "TRUE")
+	t.Setenv("OCR_MARK_UNCERTAIN", "true")
+	t.Setenv("OCR_MAX_TOKENS", "8000")
+	t.Setenv("OCR_TEMPERATURE", "0")
+	t.Setenv("OCR_OUTPUT_FORMAT", "CSV")
+	t.Setenv("OCR_QUEUE_DEPTH", "3")
+	t.Setenv("OCR_WORKERS", "4")
+	t.Setenv("OCR_QUEUE", "2")
+	t.Setenv("HOTKEY_DEBOUNCE_MS", "150")
+	t.Setenv("MODEL_CHOICES", " cheap/model, ,accurate/model ")
+	t.Setenv("NOTIFY_ON_COMPLETE", " Both ")
+	t.Setenv("CLIPBOARD_FORMAT", " HTML ")
+	t.Setenv("POPUP_STICKY", "TRUE")
+	t.Setenv("POPUP_MAX_CHARS", "250")
+	t.Setenv("CLIPBOARD_CLEAR_AFTER_SEC", "30")
+	t.Setenv("TYPE_DELAY_MS", "12")
+	t.Setenv("OCR_EXTRACT", " URLs ")
+	t.Setenv("OCR_TILE", "true")
+	t.Setenv("LOG_LEVEL", "Warning")
+	t.Setenv("LOG_DIR", " /var/log/ocr ")
+	t.Setenv("RELOAD_CONFIG_ON_GRAB", "false")
+	t.Setenv("PROVIDER_ALLOW_FALLBACKS", "true")
+	t.Setenv("PROVIDER_QUANTIZATIONS", " FP16, ,int8")
+	t.Setenv("CLIPBOARD_APPEND", "TRUE")
+	t.Setenv("OCR_TRIM", "true")
+	t.Setenv("HTTP_REFERER", " https://example.com/fork ")
+	t.Setenv("APP_TITLE", "My OCR")
+	t.Setenv("LLM_BACKEND", "Mock")
+	t.Setenv("MODE", " Describe ")
+	t.Setenv("TRANSLATE_TO", " de ")
+	t.Setenv("TRANSLATE_KEEP_ORIGINAL", "true")
+	t.Setenv("OCR_REPLACEMENTS", " rules.txt ")
+	t.Setenv("OCR_LINE_ENDINGS", " CRLF ")
+	t.Setenv("CLIPBOARD_APPEND_SEPARATOR", `\n---\n`)
 
 	// Load the configuration
 	cfg, err := LoadWithOptions(LoadOptions{APIKeyPathOverride: filepath.Join(t.TempDir(), "missing.key")})
@@ -35,6 +81,158 @@ func TestLoad(t *testing.T) {
 	if cfg.DefaultMode != DefaultModeLasso {
 		t.Errorf("Expected DefaultMode to be '%s', got '%s'", DefaultModeLasso, cfg.DefaultMode)
 	}
+	if !cfg.OverlayGuides {
+		t.Errorf("Expected OverlayGuides to be true, got %v", cfg.OverlayGuides)
+	}
+	if cfg.OverlayBGScale != 0.5 {
+		t.Errorf("Expected OverlayBGScale to be 0.5, got %v", cfg.OverlayBGScale)
+	}
+	if cfg.OCRContinuationChars != 200 {
+		t.Errorf("Expected OCRContinuationChars to be 200, got %d", cfg.OCRContinuationChars)
+	}
+	if cfg.TrayPreviewSec != 0 || cfg.TrayPreviewText {
+		t.Errorf("Expected tray preview disabled, got sec=%d text=%v", cfg.TrayPreviewSec, cfg.TrayPreviewText)
+	}
+	if !cfg.OCRTile {
+		t.Error("Expected OCRTile to be true")
+	}
+	if cfg.OCRMaxImageEdge != 1500 {
+		t.Errorf("Expected OCRMaxImageEdge to be 1500, got %d", cfg.OCRMaxImageEdge)
+	}
+	if !cfg.OCRHTTPEnabled || cfg.OCRHTTPPort != 8089 {
+		t.Errorf("Expected OCR HTTP endpoint enabled on 8089, got enabled=%v port=%d", cfg.OCRHTTPEnabled, cfg.OCRHTTPPort)
+	}
+	if cfg.OCRPreprocess != "contrast" {
+		t.Errorf("Expected OCRPreprocess to be 'contrast', got '%s'", cfg.OCRPreprocess)
+	}
+	if cfg.BlankThreshold != 12.5 {
+		t.Errorf("Expected BlankThreshold to be 12.5, got %g", cfg.BlankThreshold)
+	}
+	if !cfg.OCRAutoCrop {
+		t.Error("Expected OCRAutoCrop to be true")
+	}
+	if !cfg.OCRMarkUncertain {
+		t.Error("Expected OCRMarkUncertain to be true")
+	}
+	if cfg.OCRMaxTokens != 8000 || cfg.OCRTemperature != 0 {
+		t.Errorf("Expected OCR max tokens 8000 and temperature 0, got %d and %g", cfg.OCRMaxTokens, cfg.OCRTemperature)
+	}
+	if cfg.OCROutputFormat != "csv" {
+		t.Errorf("Expected OCROutputFormat to be 'csv', got '%s'", cfg.OCROutputFormat)
+	}
+	if cfg.OCRQueueDepth != 3 {
+		t.Errorf("Expected OCRQueueDepth to be 3, got %d", cfg.OCRQueueDepth)
+	}
+	if cfg.OCRWorkers != 4 || cfg.OCRQueue != 2 {
+		t.Errorf("Expected OCRWorkers 4 and OCRQueue 2, got %d and %d", cfg.OCRWorkers, cfg.OCRQueue)
+	}
+	if cfg.HotkeyDebounceMs != 150 {
+		t.Errorf("Expected HotkeyDebounceMs to be 150, got %d", cfg.HotkeyDebounceMs)
+	}
+	if !cfg.PopupSticky {
+		t.Error("Expected PopupSticky to be true")
+	}
+	if cfg.PopupMaxChars != 250 {
+		t.Errorf("Expected PopupMaxChars to be 250, got %d", cfg.PopupMaxChars)
+	}
+	if cfg.ClipboardClearAfter != 30 {
+		t.Errorf("Expected ClipboardClearAfter to be 30, got %d", cfg.ClipboardClearAfter)
+	}
+	if cfg.TypeDelayMs != 12 {
+		t.Errorf("Expected TypeDelayMs to be 12, got %d", cfg.TypeDelayMs)
+	}
+	if cfg.OCRExtract != "urls" {
+		t.Errorf("Expected OCRExtract to be 'urls', got '%s'", cfg.OCRExtract)
+	}
+	if cfg.ClipboardFormat != "html" {
+		t.Errorf("Expected ClipboardFormat to be 'html', got '%s'", cfg.ClipboardFormat)
+	}
+	if cfg.NotifyOnComplete != "both" {
+		t.Errorf("Expected NotifyOnComplete to be 'both', got '%s'", cfg.NotifyOnComplete)
+	}
+	if len(cfg.ModelChoices) != 2 || cfg.ModelChoices[0] != "cheap/model" || cfg.ModelChoices[1] != "accurate/model" {
+		t.Errorf("Expected ModelChoices [cheap/model accurate/model], got %v", cfg.ModelChoices)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Errorf("Expected LogLevel to be 'warn', got '%s'", cfg.LogLevel)
+	}
+	if cfg.LogDir != "/var/log/ocr" {
+		t.Errorf("Expected LogDir to be '/var/log/ocr', got '%s'", cfg.LogDir)
+	}
+	if cfg.ReloadConfigOnGrab {
+		t.Error("Expected ReloadConfigOnGrab to be false")
+	}
+	if !cfg.ProviderFallbacks || cfg.ProviderStrategy != "fallback" {
+		t.Errorf("Expected PROVIDER_ALLOW_FALLBACKS to select the fallback strategy, got %q", cfg.ProviderStrategy)
+	}
+	if len(cfg.ProviderQuants) != 2 || cfg.ProviderQuants[0] != "fp16" || cfg.ProviderQuants[1] != "int8" {
+		t.Errorf("Expected ProviderQuants [fp16 int8], got %v", cfg.ProviderQuants)
+	}
+	if cfg.OCRReplacements != "rules.txt" {
+		t.Errorf("Expected OCRReplacements 'rules.txt', got '%s'", cfg.OCRReplacements)
+	}
+	if cfg.TranslateTo != "de" || !cfg.TranslateKeepOrig {
+		t.Errorf("Expected TranslateTo 'de' keeping the original, got '%s', %v", cfg.TranslateTo, cfg.TranslateKeepOrig)
+	}
+	if cfg.HTTPReferer != "https://example.com/fork" || cfg.AppTitle != "My OCR" {
+		t.Errorf("Expected attribution headers from env, got %q, %q", cfg.HTTPReferer, cfg.AppTitle)
+	}
+	if cfg.LLMBackend != LLMBackendMock {
+		t.Errorf("Expected LLMBackend 'mock', got '%s'", cfg.LLMBackend)
+	}
+	if cfg.Mode != "describe" {
+		t.Errorf("Expected Mode 'describe', got '%s'", cfg.Mode)
+	}
+	if !cfg.OCRTrim {
+		t.Error("Expected OCRTrim to be true")
+	}
+	if cfg.OCRLineEndings != "crlf" {
+		t.Errorf("Expected OCRLineEndings 'crlf', got '%s'", cfg.OCRLineEndings)
+	}
+	if !cfg.ClipboardAppend {
+		t.Error("Expected ClipboardAppend to be true")
+	}
+	if cfg.ClipboardAppendSep != "\n---\n" {
+		t.Errorf("Expected ClipboardAppendSep %q, got %q", "\n---\n", cfg.ClipboardAppendSep)
+	}
+}
+
+func TestLoadPicksUpEnvFileChanges(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), ".env")
+	t.Setenv("SCREEN_OCR_LLM", envPath)
+	t.Setenv("OCR_LANGUAGE", "German")
+	t.Cleanup(func() { applyDotenv(nil) })
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(envPath, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write env file: %v", err)
+		}
+	}
+
+	write("OCR_OUTPUT_FORMAT=markdown\nOCR_PREPROCESS=grayscale\nOCR_LANGUAGE=French\n")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.OCROutputFormat != "markdown" || cfg.OCRPreprocess != "grayscale" {
+		t.Fatalf("Expected values from .env, got %q, %q", cfg.OCROutputFormat, cfg.OCRPreprocess)
+	}
+
+	write("OCR_OUTPUT_FORMAT=csv\nOCR_LANGUAGE=French\n")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.OCROutputFormat != "csv" {
+		t.Errorf("Expected changed OCR_OUTPUT_FORMAT to be reloaded, got %q", cfg.OCROutputFormat)
+	}
+	if cfg.OCRPreprocess != "none" {
+		t.Errorf("Expected removed OCR_PREPROCESS to fall back to default, got %q", cfg.OCRPreprocess)
+	}
+	if cfg.OCRLanguage != "German" {
+		t.Errorf("Expected environment OCR_LANGUAGE to win over .env, got %q", cfg.OCRLanguage)
+	}
 }
 
 func TestResolveDefaultMode(t *testing.T) {
@@ -85,6 +283,55 @@ func TestLoadWithOptionsDefaultModeOverride(t *testing.T) {
 	})
 }
 
+func TestLoadWithOptionsSaveScreenshotDir(t *testing.T) {
+	t.Setenv("SAVE_SCREENSHOT_DIR", " /tmp/env-shots ")
+
+	cfg, err := LoadWithOptions(LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadWithOptions failed: %v", err)
+	}
+	if cfg.SaveScreenshotDir != "/tmp/env-shots" {
+		t.Fatalf("Expected SaveScreenshotDir from env, got %q", cfg.SaveScreenshotDir)
+	}
+
+	cfg, err = LoadWithOptions(LoadOptions{SaveScreenshotDirOverride: "/tmp/cli-shots"})
+	if err != nil {
+		t.Fatalf("LoadWithOptions failed: %v", err)
+	}
+	if cfg.SaveScreenshotDir != "/tmp/cli-shots" {
+		t.Fatalf("Expected --save-screenshot to win, got %q", cfg.SaveScreenshotDir)
+	}
+}
+
+func TestLoadWithOptionsDryRun(t *testing.T) {
+	t.Setenv("DRY_RUN", "")
+
+	cfg, err := LoadWithOptions(LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadWithOptions failed: %v", err)
+	}
+	if cfg.DryRun {
+		t.Fatal("Expected DryRun off by default")
+	}
+
+	cfg, err = LoadWithOptions(LoadOptions{DryRunOverride: true})
+	if err != nil {
+		t.Fatalf("LoadWithOptions failed: %v", err)
+	}
+	if !cfg.DryRun {
+		t.Fatal("Expected --dry-run to enable DryRun")
+	}
+
+	t.Setenv("DRY_RUN", "TRUE")
+	cfg, err = LoadWithOptions(LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadWithOptions failed: %v", err)
+	}
+	if !cfg.DryRun {
+		t.Fatal("Expected DRY_RUN=true to enable DryRun")
+	}
+}
+
 func TestLoadWithOptionsAPIKeyPathPrecedence(t *testing.T) {
 	t.Setenv("OPENROUTER_API_KEY", "fallback-env-key")
 	t.Setenv("OPENROUTER_API_KEY_FILE", "/env/path.key")
@@ -167,4 +414,255 @@ func TestLoadWithOptionsAPIKeyResolution(t *testing.T) {
 			t.Fatalf("Expected env API key, got %q", cfg.APIKey)
 		}
 	})
+
+	t.Run("Prefers credential over OPENROUTER_API_KEY", func(t *testing.T) {
+		defer func(prev func() (string, error)) { readAPIKeyCredential = prev }(readAPIKeyCredential)
+		readAPIKeyCredential = func() (string, error) { return "cred-key", nil }
+		t.Setenv("OPENROUTER_API_KEY", "env-key")
+
+		cfg, err := LoadWithOptions(LoadOptions{APIKeyPathOverride: filepath.Join(t.TempDir(), "missing.key")})
+		if err != nil {
+			t.Fatalf("LoadWithOptions failed: %v", err)
+		}
+		if cfg.APIKey != "cred-key" {
+			t.Fatalf("Expected credential API key, got %q", cfg.APIKey)
+		}
+
+		cfg, err = LoadWithOptions(LoadOptions{APIKeyPathOverride: keyFile})
+		if err != nil {
+			t.Fatalf("LoadWithOptions failed: %v", err)
+		}
+		if cfg.APIKey != "file-key" {
+			t.Fatalf("Expected key file to win over credential, got %q", cfg.APIKey)
+		}
+	})
+
+	t.Run("Ignores credential read errors", func(t *testing.T) {
+		defer func(prev func() (string, error)) { readAPIKeyCredential = prev }(readAPIKeyCredential)
+		readAPIKeyCredential = func() (string, error) { return "", errors.New("access denied") }
+		t.Setenv("OPENROUTER_API_KEY", "env-key")
+
+		cfg, err := LoadWithOptions(LoadOptions{APIKeyPathOverride: filepath.Join(t.TempDir(), "missing.key")})
+		if err != nil {
+			t.Fatalf("LoadWithOptions failed: %v", err)
+		}
+		if cfg.APIKey != "env-key" {
+			t.Fatalf("Expected env API key, got %q", cfg.APIKey)
+		}
+	})
+}
+
+func TestCredentialBlobRoundTrip(t *testing.T) {
+	for _, key := range []string{"sk-or-v1-abc123", "sk-or-v1-ключ"} {
+		if got := decodeCredentialBlob(encodeCredentialBlob(key)); got != key {
+			t.Errorf("round trip %q = %q", key, got)
+		}
+	}
+	if got := decodeCredentialBlob([]byte("sk-or-v1-utf8key")); got != "sk-or-v1-utf8key" {
+		t.Errorf("UTF-8 blob decoded as %q", got)
+	}
+}
+
+func TestParsePresetRegions(t *testing.T) {
+	presets, err := ParsePresetRegions("hud:10,20,300,40; status : 0,1040,1920,40")
+	if err != nil {
+		t.Fatalf("ParsePresetRegions failed: %v", err)
+	}
+	if len(presets) != 2 {
+		t.Fatalf("Expected 2 presets, got %d", len(presets))
+	}
+	want := PresetRegion{Name: "status", X: 0, Y: 1040, Width: 1920, Height: 40}
+	if presets[1] != want {
+		t.Fatalf("Expected %+v, got %+v", want, presets[1])
+	}
+
+	presets, err = ParsePresetRegions("ok:1,2,3,4;bad:1,2,3;neg:0,0,-5,10;ok:5,6,7,8")
+	if err == nil {
+		t.Fatal("Expected error for malformed entries")
+	}
+	if len(presets) != 1 || presets[0].Name != "ok" {
+		t.Fatalf("Expected only the valid preset to be kept, got %+v", presets)
+	}
+}
+
+func TestFindPresetRegion(t *testing.T) {
+	presets := []PresetRegion{{Name: "hud", Width: 1, Height: 1}, {Name: "status", Width: 1, Height: 1}}
+
+	if p, err := FindPresetRegion(presets, "HUD"); err != nil || p.Name != "hud" {
+		t.Fatalf("Expected case-insensitive match, got %+v, %v", p, err)
+	}
+
+	_, err := FindPresetRegion(presets, "missing")
+	if err == nil || !strings.Contains(err.Error(), "available: hud, status") {
+		t.Fatalf("Expected error listing available presets, got %v", err)
+	}
+}
+
+func TestParseHotkeys(t *testing.T) {
+	bindings, err := ParseHotkeys("Ctrl+Alt+W=file:ocr.txt, Ctrl+Alt+E = clipboard+stdout")
+	if err != nil {
+		t.Fatalf("ParseHotkeys failed: %v", err)
+	}
+	want := []HotkeyBinding{{Combo: "Ctrl+Alt+W", Output: "file:ocr.txt"}, {Combo: "Ctrl+Alt+E", Output: "clipboard+stdout"}}
+	if len(bindings) != len(want) || bindings[0] != want[0] || bindings[1] != want[1] {
+		t.Fatalf("ParseHotkeys = %+v, want %+v", bindings, want)
+	}
+
+	bindings, err = ParseHotkeys("Ctrl+Alt+W=clipboard,Ctrl+Alt+X,=stdout,ctrl + alt+w=stdout")
+	if err == nil {
+		t.Fatal("expected error for malformed and duplicate entries")
+	}
+	if len(bindings) != 1 || bindings[0].Combo != "Ctrl+Alt+W" {
+		t.Fatalf("expected only the valid entry to remain, got %+v", bindings)
+	}
+
+	bindings, err = ParseHotkeys("Ctrl+K,O=clipboard, Ctrl+Alt+W=stdout")
+	if err != nil {
+		t.Fatalf("ParseHotkeys failed: %v", err)
+	}
+	want = []HotkeyBinding{{Combo: "Ctrl+K,O", Output: "clipboard"}, {Combo: "Ctrl+Alt+W", Output: "stdout"}}
+	if len(bindings) != len(want) || bindings[0] != want[0] || bindings[1] != want[1] {
+		t.Fatalf("ParseHotkeys = %+v, want %+v", bindings, want)
+	}
+	if NormalizeHotkey("Ctrl + K, O") != NormalizeHotkey("ctrl+k,o") {
+		t.Fatal("expected sequences to normalize alike")
+	}
+}
+
+func TestSetEnvLine(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{name: "empty file", content: "", want: "HOTKEY=F13\n"},
+		{name: "append", content: "# keys\nMODEL=x\n", want: "# keys\nMODEL=x\nHOTKEY=F13\n"},
+		{name: "replace", content: "HOTKEY=Ctrl+Alt+q\nMODEL=x\n", want: "HOTKEY=F13\nMODEL=x\n"},
+		{name: "drop duplicates", content: "HOTKEY=a\nHOTKEY=b\n", want: "HOTKEY=F13\n"},
+		{name: "keep comments and CRLF", content: "# HOTKEY=old\r\nexport HOTKEY=a\r\n", want: "# HOTKEY=old\r\nHOTKEY=F13\r\n"},
+		{name: "no trailing newline", content: "MODEL=x", want: "MODEL=x\nHOTKEY=F13\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setEnvLine(tt.content, "HOTKEY", "F13"); got != tt.want {
+				t.Fatalf("setEnvLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadWithOptionsConfigPathPrecedence(t *testing.T) {
+	t.Cleanup(func() {
+		configPath = ""
+		applyDotenv(nil)
+	})
+	prevLanguage, hadLanguage := os.LookupEnv("OCR_LANGUAGE")
+	_ = os.Unsetenv("OCR_LANGUAGE")
+	t.Cleanup(func() {
+		if hadLanguage {
+			_ = os.Setenv("OCR_LANGUAGE", prevLanguage)
+		}
+	})
+
+	writeEnv := func(path, language string) string {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("OCR_LANGUAGE="+language+"\n"), 0o600); err != nil {
+			t.Fatalf("Failed to write env file: %v", err)
+		}
+		return path
+	}
+	load := func(opts LoadOptions) string {
+		t.Helper()
+		cfg, err := LoadWithOptions(opts)
+		if err != nil {
+			t.Fatalf("LoadWithOptions failed: %v", err)
+		}
+		return cfg.OCRLanguage
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable failed: %v", err)
+	}
+	exeEnv := writeEnv(filepath.Join(filepath.Dir(execPath), ".env"), "exe")
+	t.Cleanup(func() { _ = os.Remove(exeEnv) })
+	altEnv := writeEnv(filepath.Join(t.TempDir(), "alt.env"), "alt")
+	flagEnv := writeEnv(filepath.Join(t.TempDir(), "flag.env"), "flag")
+
+	t.Setenv("SCREEN_OCR_LLM", filepath.Join(t.TempDir(), "missing.env"))
+	if got := load(LoadOptions{}); got != "exe" {
+		t.Fatalf("Expected executable-local .env, got %q", got)
+	}
+	t.Setenv("SCREEN_OCR_LLM", altEnv)
+	if got := load(LoadOptions{}); got != "alt" {
+		t.Fatalf("Expected SCREEN_OCR_LLM to win over executable-local .env, got %q", got)
+	}
+	if got := load(LoadOptions{ConfigPath: flagEnv}); got != "flag" {
+		t.Fatalf("Expected --config to win over SCREEN_OCR_LLM, got %q", got)
+	}
+	if got := load(LoadOptions{}); got != "flag" || EnvPath() != flagEnv {
+		t.Fatalf("Expected --config to stay in effect, got %q from %q", got, EnvPath())
+	}
+
+	configPath = ""
+	_ = os.Remove(exeEnv)
+	t.Setenv("SCREEN_OCR_LLM", "")
+	applyDotenv(nil)
+	t.Setenv("OCR_LANGUAGE", "process")
+	if got := load(LoadOptions{}); got != "process" {
+		t.Fatalf("Expected the process environment without a file, got %q", got)
+	}
+
+	if _, err := LoadWithOptions(LoadOptions{ConfigPath: filepath.Join(t.TempDir(), "missing.env")}); err == nil {
+		t.Fatal("Expected an error for a missing --config file")
+	}
+}
+
+func TestLoadProviderStrategy(t *testing.T) {
+	tests := []struct {
+		strategy, allowFallbacks string
+		want                     string
+	}{
+		{"", "", "strict"},
+		{"fallback", "", "fallback"},
+		{" Strict ", "true", "strict"},
+		{"", "true", "fallback"},
+		{"bogus", "", "strict"},
+	}
+	for _, tt := range tests {
+		t.Setenv("PROVIDER_STRATEGY", tt.strategy)
+		t.Setenv("PROVIDER_ALLOW_FALLBACKS", tt.allowFallbacks)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.ProviderStrategy != tt.want || cfg.ProviderFallbacks != (tt.want == "fallback") {
+			t.Errorf("PROVIDER_STRATEGY=%q PROVIDER_ALLOW_FALLBACKS=%q: got %q (fallbacks %v), want %q",
+				tt.strategy, tt.allowFallbacks, cfg.ProviderStrategy, cfg.ProviderFallbacks, tt.want)
+		}
+	}
+}
+
+func TestLoadModelChain(t *testing.T) {
+	tests := []struct {
+		model, models string
+		wantModel     string
+		wantModels    []string
+	}{
+		{"a/one", "", "a/one", []string{"a/one"}},
+		{"", "a/one, b/two", "a/one", []string{"a/one", "b/two"}},
+		{"a/one", "a/one,b/two", "a/one", []string{"a/one", "b/two"}},
+		{"b/two", "a/one,b/two", "b/two", []string{"b/two", "a/one"}},
+		{"c/three", "a/one,b/two", "c/three", []string{"c/three", "a/one", "b/two"}},
+	}
+	for _, tt := range tests {
+		t.Setenv("MODEL", tt.model)
+		t.Setenv("MODELS", tt.models)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.Model != tt.wantModel || strings.Join(cfg.Models, ",") != strings.Join(tt.wantModels, ",") {
+			t.Errorf("MODEL=%q MODELS=%q: got %q %v, want %q %v",
+				tt.model, tt.models, cfg.Model, cfg.Models, tt.wantModel, tt.wantModels)
+		}
+	}
 }
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// CredentialTarget is the Windows Credential Manager generic credential
+	// holding the API key, e.g. written by `screen-ocr-llm set-key` or
+	// `cmdkey /generic:screen-ocr-llm/openrouter /user:openrouter /pass:<key>`.
+	CredentialTarget = "screen-ocr-llm/openrouter"
+	credentialUser   = "openrouter"
+)
+
+// ErrCredentialUnsupported is returned by StoreAPIKeyCredential on platforms
+// without the Windows Credential Manager.
+var ErrCredentialUnsupported = errors.New("credential manager is only supported on Windows")
+
+// readAPIKeyCredential is ReadAPIKeyCredential; tests replace it.
+var readAPIKeyCredential = ReadAPIKeyCredential
+
+// encodeCredentialBlob stores the key as UTF-16LE, the encoding cmdkey and
+// the Credential Manager UI use for passwords.
+func encodeCredentialBlob(key string) []byte {
+	units := utf16.Encode([]rune(key))
+	blob := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		blob = append(blob, byte(u), byte(u>>8))
+	}
+	return blob
+}
+
+// decodeCredentialBlob accepts UTF-16LE blobs as written by
+// encodeCredentialBlob and cmdkey, and falls back to raw bytes (UTF-8) for
+// credentials written by other tools. API keys are ASCII, so their UTF-16
+// form always contains NUL bytes and UTF-8 text never does.
+func decodeCredentialBlob(blob []byte) string {
+	if len(blob)%2 != 0 || !bytes.Contains(blob, []byte{0}) {
+		return string(blob)
+	}
+	units := make([]uint16, 0, len(blob)/2)
+	for i := 0; i < len(blob); i += 2 {
+		units = append(units, uint16(blob[i])|uint16(blob[i+1])<<8)
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+}
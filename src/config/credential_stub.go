@@ -0,0 +1,13 @@
+//go:build !windows
+
+package config
+
+// ReadAPIKeyCredential reports no stored key on non-Windows platforms.
+func ReadAPIKeyCredential() (string, error) {
+	return "", nil
+}
+
+// StoreAPIKeyCredential is not supported on non-Windows platforms.
+func StoreAPIKeyCredential(key string) error {
+	return ErrCredentialUnsupported
+}
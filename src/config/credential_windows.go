@@ -0,0 +1,94 @@
+//go:build windows
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+var (
+	advapi32       = windows.NewLazySystemDLL("advapi32.dll")
+	procCredReadW  = advapi32.NewProc("CredReadW")
+	procCredWriteW = advapi32.NewProc("CredWriteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+// credential mirrors the Win32 CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// ReadAPIKeyCredential returns the API key stored under CredentialTarget, or
+// "" when there is no such credential.
+func ReadAPIKeyCredential() (string, error) {
+	target, err := windows.UTF16PtrFromString(CredentialTarget)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, callErr := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if errors.Is(callErr, windows.ERROR_NOT_FOUND) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read credential %s: %w", CredentialTarget, callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	if cred.CredentialBlobSize == 0 || cred.CredentialBlob == nil {
+		return "", nil
+	}
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return strings.TrimSpace(decodeCredentialBlob(blob)), nil
+}
+
+// StoreAPIKeyCredential creates or replaces the CredentialTarget credential
+// with key, persisted for the current user on this machine.
+func StoreAPIKeyCredential(key string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return errors.New("API key is empty")
+	}
+	target, err := windows.UTF16PtrFromString(CredentialTarget)
+	if err != nil {
+		return err
+	}
+	user, err := windows.UTF16PtrFromString(credentialUser)
+	if err != nil {
+		return err
+	}
+	blob := encodeCredentialBlob(key)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if r, _, callErr := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return fmt.Errorf("write credential %s: %w", CredentialTarget, callErr)
+	}
+	return nil
+}
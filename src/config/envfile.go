@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SetEnvValue persists KEY=value to the .env file the config was loaded from
+// (or a new .env next to the executable) and updates the process environment,
+// so the next Load sees the new value. Other lines and comments are kept.
+func SetEnvValue(key, value string) error {
+	envPath := resolveEnvPath()
+	if envPath == "" {
+		execPath, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("locate .env: %w", err)
+		}
+		envPath = filepath.Join(filepath.Dir(execPath), ".env")
+	}
+
+	content, err := os.ReadFile(envPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read %s: %w", envPath, err)
+	}
+	updated := setEnvLine(string(content), key, value)
+	if err := os.WriteFile(envPath, []byte(updated), 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", envPath, err)
+	}
+	markDotenvKey(key)
+	return os.Setenv(key, value)
+}
+
+// dotenvKeys records the variables set from the .env file rather than the
+// real environment, so a reload can update or clear them while variables
+// from the real environment keep taking precedence.
+var (
+	dotenvMu   sync.Mutex
+	dotenvKeys = map[string]bool{}
+)
+
+// applyDotenv sets the .env values that the real environment doesn't
+// override, and unsets .env variables that are no longer in the file.
+func applyDotenv(values map[string]string) {
+	dotenvMu.Lock()
+	defer dotenvMu.Unlock()
+	for key := range dotenvKeys {
+		if _, ok := values[key]; !ok {
+			_ = os.Unsetenv(key)
+			delete(dotenvKeys, key)
+		}
+	}
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); set && !dotenvKeys[key] {
+			continue
+		}
+		_ = os.Setenv(key, value)
+		dotenvKeys[key] = true
+	}
+}
+
+func markDotenvKey(key string) {
+	dotenvMu.Lock()
+	defer dotenvMu.Unlock()
+	if _, set := os.LookupEnv(key); !set {
+		dotenvKeys[key] = true
+	}
+}
+
+// setEnvLine replaces the first KEY= assignment in a dotenv file (dropping
+// any later duplicates) or appends one. The file's line endings are kept.
+func setEnvLine(content, key, value string) string {
+	eol := "\n"
+	if strings.Contains(content, "\r\n") {
+		eol = "\r\n"
+	}
+	line := key + "=" + value
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	replaced := false
+	out := lines[:0]
+	for _, l := range lines {
+		name, _, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(l), "export "), "=")
+		if ok && strings.TrimSpace(name) == key {
+			if replaced {
+				continue
+			}
+			l = line
+			replaced = true
+		}
+		out = append(out, l)
+	}
+	if !replaced {
+		if n := len(out); n > 0 && out[n-1] == "" {
+			out[n-1] = line
+			out = append(out, "")
+		} else {
+			out = append(out, line, "")
+		}
+	}
+	return strings.Join(out, eol)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HotkeyBinding maps an extra hotkey combo from HOTKEYS to an output sink
+// spec (same syntax as OUTPUT_SINK, e.g. "clipboard" or "file:ocr.txt").
+type HotkeyBinding struct {
+	Combo  string
+	Output string
+}
+
+// ParseHotkeys parses a HOTKEYS spec of the form
+// "Ctrl+Alt+Q=clipboard,Ctrl+Alt+W=file:ocr.txt". A combo may be a sequence
+// such as "Ctrl+K,O=clipboard". Malformed entries are skipped and reported in
+// the returned error; well-formed entries are always returned.
+func ParseHotkeys(spec string) ([]HotkeyBinding, error) {
+	var bindings []HotkeyBinding
+	var bad []string
+	seen := map[string]bool{}
+	for _, entry := range splitHotkeyEntries(spec) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		combo, output, ok := strings.Cut(entry, "=")
+		combo, output = strings.TrimSpace(combo), strings.TrimSpace(output)
+		var err error
+		switch {
+		case !ok || combo == "" || output == "":
+			err = fmt.Errorf("expected combo=output")
+		case seen[NormalizeHotkey(combo)]:
+			err = fmt.Errorf("duplicate hotkey %q", combo)
+		}
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%q: %v", entry, err))
+			continue
+		}
+		seen[NormalizeHotkey(combo)] = true
+		bindings = append(bindings, HotkeyBinding{Combo: combo, Output: output})
+	}
+	if len(bad) > 0 {
+		return bindings, fmt.Errorf("invalid HOTKEYS entries: %s", strings.Join(bad, "; "))
+	}
+	return bindings, nil
+}
+
+// splitHotkeyEntries splits a HOTKEYS spec on commas, rejoining the pieces of
+// a sequence: in "Ctrl+K,O=clipboard" the piece "Ctrl+K" has no output and
+// continues in "O=clipboard".
+func splitHotkeyEntries(spec string) []string {
+	pieces := strings.Split(spec, ",")
+	var entries []string
+	for i := 0; i < len(pieces); i++ {
+		entry := pieces[i]
+		for !strings.Contains(entry, "=") && strings.TrimSpace(entry) != "" && i+1 < len(pieces) {
+			next, _, _ := strings.Cut(pieces[i+1], "=")
+			if strings.TrimSpace(next) == "" {
+				break
+			}
+			entry += "," + pieces[i+1]
+			i++
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// NormalizeHotkey returns a comparable form of a combo ("Ctrl + Alt+q" and
+// "ctrl+alt+Q" normalize to the same string, as do "Ctrl+K, O" and "ctrl+k,o").
+func NormalizeHotkey(combo string) string {
+	parts := strings.Split(strings.ToLower(combo), "+")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	steps := strings.Split(strings.Join(parts, "+"), ",")
+	for i, s := range steps {
+		steps[i] = strings.TrimSpace(s)
+	}
+	return strings.Join(steps, ",")
+}
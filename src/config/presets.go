@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PresetRegion is a named, fixed capture rectangle from PRESET_REGIONS, in
+// virtual-screen coordinates.
+type PresetRegion struct {
+	Name   string
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// ParsePresetRegions parses a PRESET_REGIONS spec of the form
+// "name:x,y,w,h;name2:x,y,w,h". Malformed entries are skipped and reported
+// in the returned error; well-formed entries are always returned.
+func ParsePresetRegions(spec string) ([]PresetRegion, error) {
+	var presets []PresetRegion
+	var bad []string
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		p, err := parsePresetRegion(entry)
+		if err == nil && seen[strings.ToLower(p.Name)] {
+			err = fmt.Errorf("duplicate name %q", p.Name)
+		}
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%q: %v", entry, err))
+			continue
+		}
+		seen[strings.ToLower(p.Name)] = true
+		presets = append(presets, p)
+	}
+	if len(bad) > 0 {
+		return presets, fmt.Errorf("invalid PRESET_REGIONS entries: %s", strings.Join(bad, "; "))
+	}
+	return presets, nil
+}
+
+func parsePresetRegion(entry string) (PresetRegion, error) {
+	name, coords, ok := strings.Cut(entry, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return PresetRegion{}, fmt.Errorf("expected name:x,y,w,h")
+	}
+	parts := strings.Split(coords, ",")
+	if len(parts) != 4 {
+		return PresetRegion{}, fmt.Errorf("expected 4 coordinates, got %d", len(parts))
+	}
+	var v [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return PresetRegion{}, fmt.Errorf("invalid number %q", strings.TrimSpace(part))
+		}
+		v[i] = n
+	}
+	if v[2] <= 0 || v[3] <= 0 {
+		return PresetRegion{}, fmt.Errorf("width and height must be positive")
+	}
+	return PresetRegion{Name: name, X: v[0], Y: v[1], Width: v[2], Height: v[3]}, nil
+}
+
+// FindPresetRegion looks up a preset by name (case-insensitive). The error
+// lists the available names.
+func FindPresetRegion(presets []PresetRegion, name string) (PresetRegion, error) {
+	names := make([]string, 0, len(presets))
+	for _, p := range presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	if len(names) == 0 {
+		return PresetRegion{}, fmt.Errorf("unknown region preset %q: no PRESET_REGIONS configured", name)
+	}
+	return PresetRegion{}, fmt.Errorf("unknown region preset %q (available: %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// modelNameRe matches an OpenRouter model id such as "google/gemini-2.5-flash".
+var modelNameRe = regexp.MustCompile(`^[^/\s]+/\S+$`)
+
+// Validate checks the loaded settings and returns a single error listing
+// every problem, or nil. Values that Load replaced with a default (such as
+// an unparseable OCR_DEADLINE_SEC) are reported too. validHotkey, when not
+// nil, checks that a hotkey combo maps to key codes (hotkey.Validate); the
+// CLI tool has no hotkeys and passes nil.
+func (c *Config) Validate(validHotkey func(string) error) error {
+	problems := append([]string(nil), c.loadProblems...)
+
+	if c.LLMBackend != LLMBackendMock {
+		if c.Model == "" {
+			problems = append(problems, "MODEL is required (set MODEL or MODELS)")
+		}
+		// Proxies behind OPENROUTER_BASE_URL use their own model names.
+		if c.BaseURL == "" {
+			for _, m := range append([]string{c.Model}, c.Models...) {
+				if m != "" && !modelNameRe.MatchString(m) {
+					problems = append(problems, fmt.Sprintf("model %q is not of the form vendor/model, e.g. google/gemini-2.5-flash", m))
+				}
+			}
+		}
+	}
+	if c.OCRDeadlineSec <= 0 {
+		problems = append(problems, fmt.Sprintf("OCR_DEADLINE_SEC must be a positive number of seconds, got %d", c.OCRDeadlineSec))
+	}
+	for _, p := range c.Providers {
+		if strings.TrimSpace(p) == "" {
+			problems = append(problems, "PROVIDERS has an empty entry")
+			break
+		}
+	}
+	if validHotkey != nil {
+		if err := validHotkey(c.Hotkey); err != nil {
+			problems = append(problems, fmt.Sprintf("HOTKEY: %v", err))
+		}
+		for _, b := range c.Hotkeys {
+			if err := validHotkey(b.Combo); err != nil {
+				problems = append(problems, fmt.Sprintf("HOTKEYS: %v", err))
+			}
+		}
+	}
+
+	problems = dedupe(problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+}
+
+// dedupe drops repeated problems, e.g. MODEL also listed first in MODELS.
+func dedupe(problems []string) []string {
+	seen := map[string]bool{}
+	out := problems[:0]
+	for _, p := range problems {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
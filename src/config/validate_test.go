@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{Model: "google/gemini-2.5-flash", OCRDeadlineSec: 20, Hotkey: "Ctrl+Alt+Q", Providers: []string{"google-vertex"}}
+	}
+	badHotkey := func(combo string) error {
+		if strings.Contains(combo, "Nope") {
+			return errors.New("unknown key")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{"valid", func(*Config) {}, nil},
+		{"missing model", func(c *Config) { c.Model = "" }, []string{"MODEL is required"}},
+		{"model without vendor", func(c *Config) { c.Models = []string{"gpt-4o"} }, []string{`model "gpt-4o" is not of the form vendor/model`}},
+		{"custom base URL accepts any model name", func(c *Config) { c.Model, c.BaseURL = "gpt-4o", "http://localhost:4000" }, nil},
+		{"mock backend needs no model", func(c *Config) { c.Model, c.LLMBackend = "", LLMBackendMock }, nil},
+		{"deadline", func(c *Config) { c.OCRDeadlineSec = 0 }, []string{"OCR_DEADLINE_SEC must be a positive number"}},
+		{"empty provider", func(c *Config) { c.Providers = []string{"a", " "} }, []string{"PROVIDERS has an empty entry"}},
+		{"hotkeys", func(c *Config) {
+			c.Hotkey = "Ctrl+Nope"
+			c.Hotkeys = []HotkeyBinding{{Combo: "Alt+Nope", Output: "stdout"}}
+		}, []string{"HOTKEY: unknown key", "HOTKEYS: unknown key"}},
+		{"all problems listed", func(c *Config) { c.Model, c.OCRDeadlineSec, c.Hotkey = "", -1, "Nope" }, []string{"MODEL is required", "OCR_DEADLINE_SEC", "HOTKEY:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			err := cfg.Validate(badHotkey)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate returned error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate returned nil, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateReportsLoadFallbacks(t *testing.T) {
+	t.Setenv("MODEL", "google/gemini-2.5-flash")
+	t.Setenv("OCR_DEADLINE_SEC", "soon")
+	t.Setenv("PROVIDERS", "a,,b")
+	t.Setenv("HOTKEYS", "Ctrl+Alt+W")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.OCRDeadlineSec != 20 {
+		t.Fatalf("Expected Load to keep the default deadline, got %d", cfg.OCRDeadlineSec)
+	}
+	err = cfg.Validate(nil)
+	if err == nil {
+		t.Fatal("Expected Validate to report the ignored settings")
+	}
+	for _, want := range []string{`OCR_DEADLINE_SEC "soon"`, `PROVIDERS "a,,b" has an empty entry`, "invalid HOTKEYS entries"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
@@ -5,10 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"screen-ocr-llm/src/clipboard"
 	"screen-ocr-llm/src/config"
+	"screen-ocr-llm/src/gui"
+	"screen-ocr-llm/src/history"
 	"screen-ocr-llm/src/hotkey"
+	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/logutil"
+	"screen-ocr-llm/src/metrics"
+	"screen-ocr-llm/src/notification"
+	"screen-ocr-llm/src/ocr"
 	"screen-ocr-llm/src/overlay"
 	"screen-ocr-llm/src/popup"
 	"screen-ocr-llm/src/screenshot"
@@ -18,16 +29,114 @@ import (
 	"screen-ocr-llm/src/worker"
 )
 
+// shutdownGrace is how long Run waits on shutdown for an in-flight OCR
+// result before answering its client with ErrShuttingDown.
+const shutdownGrace = 3 * time.Second
+
+// ErrShuttingDown is sent to clients whose request can't be completed
+// because the resident is exiting.
+var ErrShuttingDown = errors.New("server shutting down")
+
 // Loop is the single-threaded coordinator for IPC-based run-once and hotkey flows.
 type Loop struct {
 	selector       overlay.Selector
 	pool           *worker.Pool
 	srv            singleinstance.Server
-	busy           bool
 	results        chan result
-	hotkeyCh       chan struct{}
+	hotkeyCh       chan hotkeyAction
+	hotkeys        []hotkeyAction
+	rebindCh       chan string
+	modelCh        chan string
+	pauseCh        chan bool
+	httpCh         chan httpJob
+	clipImageCh    chan struct{}
+	presetCh       chan string
+	presets        []config.PresetRegion
+	httpPort       int
 	defaultTooltip string
 	deadline       time.Duration
+	sink           session.ResultTarget
+
+	// Hotkey presses within debounce of the previous one are dropped
+	// (HOTKEY_DEBOUNCE_MS); lastPress is written from the listener goroutine.
+	debounce  time.Duration
+	lastPress atomic.Int64
+	// hotkeyPaused makes handleHotkey ignore presses (tray "Pause OCR hotkey")
+	hotkeyPaused bool
+
+	// Completion cue (NOTIFY_ON_COMPLETE): none, sound, flash or both
+	notifyOnComplete string
+
+	// Results copied to the clipboard are cleared after clipboardClearAfter
+	// if still there (CLIPBOARD_CLEAR_AFTER_SEC); 0 leaves them.
+	clipboardClearAfter time.Duration
+
+	// OCR counters served at GET /metrics; updated from Run, read from
+	// HTTP handler goroutines.
+	metrics *metrics.Metrics
+
+	// Jobs in the worker pool. The loop is busy once active reaches
+	// capacity, the pool's workers plus queue slots (OCR_WORKERS, OCR_QUEUE).
+	// active is atomic so it can be read off the Run goroutine; go through
+	// isBusy, isIdle, startJob and finishJob rather than touching it.
+	active   atomic.Int32
+	capacity int
+
+	// In-flight jobs by id, answered with ErrShuttingDown if they outlive
+	// shutdownGrace. stopped is closed when Run returns so late worker
+	// callbacks don't block on results.
+	inflight map[int]inflightJob
+	nextJob  int
+	stopped  chan struct{}
+
+	// Delegated requests waiting while busy (OCR_QUEUE_DEPTH), served in
+	// order; each is answered BUSY if still queued after queueWait.
+	queueDepth int
+	queueWait  time.Duration
+	queue      []queuedConn
+
+	// Last-result tooltip preview (TRAY_PREVIEW_SEC / TRAY_PREVIEW_TEXT) of
+	// lastResult. lastAt is when the result arrived, and zero once the
+	// preview has ended: a new capture started or previewFor passed.
+	previewFor  time.Duration
+	previewText bool
+	lastAt      time.Time
+
+	// Model picked from the tray "Model" submenu, shown in the tooltip
+	activeModel string
+
+	// Most recent successful result for the tray "Copy last result" item and
+	// the tooltip preview. Read from the tray goroutine, so guarded by
+	// resultMu.
+	resultMu   sync.Mutex
+	lastResult string
+
+	// HOTKEY combo shown in the "Change hotkey..." dialog; read from the tray goroutine
+	hotkeyMu      sync.Mutex
+	defaultHotkey string
+
+	// Config reload (RELOAD_CONFIG_ON_GRAB): .env is polled and re-read
+	// before each capture; changes are applied by refreshRuntimeConfig.
+	reloadConfigOnGrab bool
+	reloadCh           chan struct{}
+	loadOptions        config.LoadOptions
+	loadConfig         func(config.LoadOptions) (*config.Config, error)
+	llmInit            func(*llm.Config)
+	cfg                *config.Config
+	configSource       configSourceState
+	configLoaded       bool
+	defaultMode        string
+	overlayOpts        overlay.Options
+}
+
+type inflightJob struct {
+	target resultTarget
+	cancel context.CancelFunc
+}
+
+type queuedConn struct {
+	conn    singleinstance.Conn
+	expires time.Time
 }
 
 type result struct {
@@ -35,6 +144,16 @@ type result struct {
 	err    error
 	target resultTarget
 	cancel context.CancelFunc
+	quiet  bool // no countdown popup (HTTP requests)
+	// delivered is set for captures run through session.Execute, which has
+	// already answered the target and updated the popup.
+	delivered bool
+	// windowTitle is the window the region was captured from, for history.
+	windowTitle string
+	// started is when the job was handed to the worker pool, for metrics.
+	started time.Time
+	// job is the id of the job in Loop.inflight.
+	job int
 }
 
 type resultTarget interface {
@@ -44,15 +163,44 @@ type resultTarget interface {
 	Close()
 }
 
-type hotkeyResultTarget struct{}
+// hotkeyAction is a registered hotkey and where its result is delivered.
+// A nil sink uses the loop's default OUTPUT_SINK. With window set, the hotkey
+// captures the window whose title contains it instead of showing the overlay.
+type hotkeyAction struct {
+	combo  string
+	sink   session.ResultTarget
+	window string
+}
+
+// windowOutputPrefix marks a HOTKEYS entry that captures a window by title,
+// e.g. "Ctrl+Alt+N=window:Notepad".
+const windowOutputPrefix = "window:"
 
-func (hotkeyResultTarget) OnSuccess(text string) error {
-	return session.ClipboardTarget{}.OnSuccess(text)
+type hotkeyResultTarget struct {
+	sink session.ResultTarget
+}
+
+func (t hotkeyResultTarget) OnSuccess(text string) error {
+	if t.sink == nil {
+		return session.ClipboardTarget{}.OnSuccess(text)
+	}
+	return t.sink.OnSuccess(text)
 }
 
-func (hotkeyResultTarget) OnProcessError(err error) {}
+func (hotkeyResultTarget) OnProcessError(err error) {
+	switch {
+	case errors.Is(err, llm.ErrNoTextFound):
+		_ = popup.Show("No text found")
+	case errors.Is(err, screenshot.ErrBlankCapture):
+		_ = popup.Show(err.Error())
+	}
+}
 
 func (hotkeyResultTarget) OnDeliveryError(err error) {
+	if session.IsDeliveryError(err) {
+		_ = popup.Show(err.Error())
+		return
+	}
 	_ = popup.Show("Clipboard error")
 }
 
@@ -63,9 +211,9 @@ type delegatedResultTarget struct {
 	conn singleinstance.Conn
 }
 
-func newDelegatedResultTarget(conn singleinstance.Conn, outputToStdout bool) delegatedResultTarget {
+func newDelegatedResultTarget(conn singleinstance.Conn, outputToStdout bool, sink session.ResultTarget) delegatedResultTarget {
 	return delegatedResultTarget{
-		sink: session.DelegatedTarget{Conn: conn, OutputToStdout: outputToStdout},
+		sink: session.DelegatedTarget{Conn: conn, OutputToStdout: outputToStdout, Sink: sink},
 		conn: conn,
 	}
 }
@@ -79,6 +227,10 @@ func (t delegatedResultTarget) OnProcessError(err error) {
 }
 
 func (t delegatedResultTarget) OnDeliveryError(err error) {
+	var outErr *session.OutputError
+	if !errors.As(err, &outErr) {
+		err = &session.OutputError{Err: err}
+	}
 	_ = t.sink.OnFailure(err)
 }
 
@@ -88,6 +240,62 @@ func (t delegatedResultTarget) Close() {
 	}
 }
 
+// sessionTarget lets session.Execute deliver the result of a loop capture
+// to target. Execute reports recognition and delivery failures alike through
+// OnFailure, so OnSuccess remembers that delivery was attempted. target is
+// answered at most once, since shutdown may give up on the job (through
+// OnProcessError) while Execute is still running.
+type sessionTarget struct {
+	target resultTarget
+
+	mu         sync.Mutex
+	answered   bool
+	delivering bool
+}
+
+func (t *sessionTarget) OnSuccess(text string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.answered {
+		return ErrShuttingDown
+	}
+	t.delivering = true
+	if err := t.target.OnSuccess(text); err != nil {
+		return err
+	}
+	t.answered = true
+	return nil
+}
+
+func (t *sessionTarget) OnFailure(err error) error {
+	if t.delivering {
+		t.OnDeliveryError(err)
+	} else {
+		t.OnProcessError(err)
+	}
+	return nil
+}
+
+func (t *sessionTarget) OnProcessError(err error) {
+	t.answer(func() { t.target.OnProcessError(err) })
+}
+
+func (t *sessionTarget) OnDeliveryError(err error) {
+	t.answer(func() { t.target.OnDeliveryError(err) })
+}
+
+func (t *sessionTarget) Close() { t.target.Close() }
+
+func (t *sessionTarget) answer(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.answered {
+		return
+	}
+	t.answered = true
+	fn()
+}
+
 type requestCallbacks struct {
 	onBusy        func()
 	onSelectError func(err error)
@@ -105,40 +313,421 @@ func New(cfg *config.Config) *Loop {
 	if cfg != nil && cfg.DefaultMode != "" {
 		defaultMode = cfg.DefaultMode
 	}
+	overlayOpts := overlay.Options{DefaultMode: defaultMode}
+	if cfg != nil {
+		overlayOpts.Guides = cfg.OverlayGuides
+		overlayOpts.BackgroundScale = cfg.OverlayBGScale
+		overlayOpts.Magnifier = cfg.OverlayMagnifier
+	}
+	previewFor := time.Duration(0)
+	previewText := false
+	notifyOnComplete := "none"
+	clipboardClearAfter := time.Duration(0)
+	httpPort := 0
+	var presets []config.PresetRegion
+	var sink session.ResultTarget
+	var hotkeys []hotkeyAction
+	defaultHotkey := ""
+	debounce := time.Duration(0)
+	queueDepth := 0
+	workers, poolQueue := 1, 0
+	if cfg != nil {
+		previewFor = time.Duration(cfg.TrayPreviewSec) * time.Second
+		previewText = cfg.TrayPreviewText
+		notifyOnComplete = cfg.NotifyOnComplete
+		clipboardClearAfter = time.Duration(cfg.ClipboardClearAfter) * time.Second
+		if cfg.OCRHTTPEnabled {
+			httpPort = cfg.OCRHTTPPort
+		}
+		presets = cfg.PresetRegions
+		spec := cfg.OutputSink
+		if cfg.ClipboardAppend {
+			spec = session.WithClipboardAppend(spec)
+		}
+		s, err := session.ParseSink(spec)
+		if err != nil {
+			log.Printf("Invalid OUTPUT_SINK %q: %v; using clipboard", cfg.OutputSink, err)
+		} else {
+			sink = s
+		}
+		hotkeys = hotkeyActions(cfg.Hotkey, cfg.Hotkeys)
+		defaultHotkey = cfg.Hotkey
+		debounce = time.Duration(cfg.HotkeyDebounceMs) * time.Millisecond
+		queueDepth = cfg.OCRQueueDepth
+		if cfg.OCRWorkers > 0 {
+			workers = cfg.OCRWorkers
+		}
+		poolQueue = cfg.OCRQueue
+	}
+	pool := worker.NewWithQueue(workers, poolQueue)
+	reloadOnGrab := cfg != nil && cfg.ReloadConfigOnGrab
+	var configSource configSourceState
+	if reloadOnGrab {
+		configSource, _ = loadConfigSourceState(configSourceState{}, config.EnvPath())
+	}
 
 	return &Loop{
-		selector:       overlay.NewSelector(defaultMode),
-		pool:           worker.New(0),
+		selector:       overlay.NewSelectorWithOptions(overlayOpts),
+		overlayOpts:    overlayOpts,
+		defaultMode:    defaultMode,
+		pool:           pool,
+		capacity:       pool.Capacity(),
 		results:        make(chan result, 1),
-		hotkeyCh:       make(chan struct{}, 4),
+		httpCh:         make(chan httpJob),
+		clipImageCh:    make(chan struct{}, 1),
+		presetCh:       make(chan string, 1),
+		presets:        presets,
+		httpPort:       httpPort,
+		hotkeyCh:       make(chan hotkeyAction, 4),
+		hotkeys:        hotkeys,
+		debounce:       debounce,
+		rebindCh:       make(chan string, 1),
+		modelCh:        make(chan string, 1),
+		pauseCh:        make(chan bool, 4),
+		defaultHotkey:  defaultHotkey,
 		defaultTooltip: "Screen OCR Tool",
 		deadline:       time.Duration(deadlineSec) * time.Second,
+		queueDepth:     queueDepth,
+		queueWait:      time.Duration(deadlineSec) * time.Second,
+		sink:           sink,
+		metrics:        metrics.New(),
+		previewFor:     previewFor,
+		previewText:    previewText,
+		stopped:        make(chan struct{}),
+
+		reloadConfigOnGrab: reloadOnGrab,
+		reloadCh:           make(chan struct{}, 1),
+		loadConfig:         config.LoadWithOptions,
+		llmInit:            initLLM,
+		cfg:                cfg,
+		configSource:       configSource,
+		configLoaded:       reloadOnGrab,
+		notifyOnComplete:   notifyOnComplete,
+
+		clipboardClearAfter: clipboardClearAfter,
+	}
+}
+
+// lastResultText returns the most recent successful result, or "".
+func (l *Loop) lastResultText() string {
+	l.resultMu.Lock()
+	defer l.resultMu.Unlock()
+	return l.lastResult
+}
+
+// CopyLastResult writes the most recent successful OCR result back to the
+// clipboard. It is safe to call from any goroutine (e.g. the tray menu).
+func (l *Loop) CopyLastResult() {
+	text := l.lastResultText()
+	if text == "" {
+		_ = popup.Show("No result yet")
+		return
+	}
+	if err := clipboard.Write(text); err != nil {
+		log.Printf("CopyLastResult: clipboard write failed: %v", err)
+		_ = popup.Show("Clipboard error")
+		return
+	}
+	log.Printf("CopyLastResult: copied %d characters", len(text))
+	_ = popup.Show("Copied last result")
+}
+
+// OCRClipboardImage requests OCR of the image on the clipboard. It is safe to
+// call from any goroutine (e.g. the tray menu); the loop performs the work.
+func (l *Loop) OCRClipboardImage() {
+	select {
+	case l.clipImageCh <- struct{}{}:
+	default:
+	}
+}
+
+// PresetNames returns the configured PRESET_REGIONS names in order.
+func (l *Loop) PresetNames() []string {
+	names := make([]string, 0, len(l.presets))
+	for _, p := range l.presets {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// CapturePreset requests OCR of the named preset region without showing the
+// selection overlay. It is safe to call from any goroutine.
+func (l *Loop) CapturePreset(name string) {
+	select {
+	case l.presetCh <- name:
+	default:
 	}
 }
 
 // SetDefaultTooltip optionally sets the tray tooltip base text.
 func (l *Loop) SetDefaultTooltip(tt string) { l.defaultTooltip = tt }
 
-func (l *Loop) setBusy(b bool) {
-	l.busy = b
-	if b {
-		tray.UpdateTooltip("Screen OCR: processing...")
-	} else {
-		tray.UpdateTooltip(l.defaultTooltip)
+// isBusy reports whether every worker pool slot is taken, so new requests
+// are rejected or queued.
+func (l *Loop) isBusy() bool {
+	return int(l.active.Load()) >= max(l.capacity, 1)
+}
+
+// isIdle reports whether no job is running.
+func (l *Loop) isIdle() bool {
+	return l.active.Load() == 0
+}
+
+// startJob takes a worker pool slot for a new job.
+func (l *Loop) startJob() {
+	l.active.Add(1)
+	l.metrics.SetBusy(l.isBusy())
+	// A new capture ends the last-result preview
+	l.lastAt = time.Time{}
+	tray.UpdateTooltip("Screen OCR: processing...")
+}
+
+// finishJob releases a worker pool slot. The tooltip returns to idle once no
+// job is left.
+func (l *Loop) finishJob() {
+	for {
+		n := l.active.Load()
+		if n == 0 || l.active.CompareAndSwap(n, n-1) {
+			break
+		}
+	}
+	l.metrics.SetBusy(l.isBusy())
+	if l.isIdle() {
+		tray.UpdateTooltip(l.idleTooltip())
+	}
+}
+
+// trackJob records a job handed to the worker pool for shutdown and returns
+// its id.
+func (l *Loop) trackJob(target resultTarget, cancel context.CancelFunc) int {
+	if l.inflight == nil {
+		l.inflight = make(map[int]inflightJob)
 	}
+	l.nextJob++
+	l.inflight[l.nextJob] = inflightJob{target: target, cancel: cancel}
+	return l.nextJob
 }
 
-// StartHotkey registers a global hotkey and posts events into the loop.
-func (l *Loop) StartHotkey(combo string) {
-	if combo == "" {
+// idleTooltip returns the default hint, or a preview of the last result
+// while it is younger than previewFor.
+func (l *Loop) idleTooltip() string {
+	base := l.defaultTooltip
+	if l.activeModel != "" {
+		base += "\nModel: " + l.activeModel
+	}
+	if l.hotkeyPaused {
+		base += "\nHotkey paused"
+	}
+	if l.previewFor <= 0 || l.lastAt.IsZero() {
+		return base
+	}
+	age := time.Since(l.lastAt)
+	if age >= l.previewFor {
+		l.lastAt = time.Time{}
+		return base
+	}
+	return lastResultTooltip(base, l.lastResultText(), l.previewText, age)
+}
+
+// hotkeyActions combines HOTKEY (default sink) with the HOTKEYS bindings.
+// Bindings with an invalid output or a combo already in use are skipped.
+// A "window:<title>" output captures that window and uses the default sink.
+func hotkeyActions(defaultCombo string, bindings []config.HotkeyBinding) []hotkeyAction {
+	var actions []hotkeyAction
+	seen := map[string]bool{}
+	add := func(action hotkeyAction) {
+		key := config.NormalizeHotkey(action.combo)
+		if seen[key] {
+			log.Printf("Hotkey %s is already bound, skipping", action.combo)
+			return
+		}
+		seen[key] = true
+		actions = append(actions, action)
+	}
+	// HOTKEYS entries take precedence so they can rebind the default combo
+	for _, b := range bindings {
+		if len(b.Output) > len(windowOutputPrefix) && strings.EqualFold(b.Output[:len(windowOutputPrefix)], windowOutputPrefix) {
+			title := strings.TrimSpace(b.Output[len(windowOutputPrefix):])
+			if title == "" {
+				log.Printf("Invalid HOTKEYS output for %s: empty window title; skipping", b.Combo)
+				continue
+			}
+			add(hotkeyAction{combo: b.Combo, window: title})
+			continue
+		}
+		s, err := session.ParseSink(b.Output)
+		if err != nil {
+			log.Printf("Invalid HOTKEYS output for %s: %v; skipping", b.Combo, err)
+			continue
+		}
+		add(hotkeyAction{combo: b.Combo, sink: s})
+	}
+	if defaultCombo != "" {
+		add(hotkeyAction{combo: defaultCombo})
+	}
+	return actions
+}
+
+// StartHotkeys registers the global hotkeys (HOTKEY and HOTKEYS) and posts
+// events into the loop.
+func (l *Loop) StartHotkeys() {
+	if len(l.hotkeys) == 0 {
 		return
 	}
-	hotkey.Listen(combo, func() {
+	for _, action := range l.hotkeys {
+		warnHotkeyConflict(action.combo)
+	}
+	hotkey.ListenAll(l.hotkeyBindings())
+}
+
+// warnHotkeyConflict logs and shows a popup when combo is a shortcut Windows
+// reserves; the hotkey is still registered.
+func warnHotkeyConflict(combo string) {
+	warnings := hotkey.CheckConflict(combo)
+	for _, w := range warnings {
+		log.Printf("WARNING: %s", w)
+	}
+	if len(warnings) > 0 {
+		_ = popup.Show(strings.Join(warnings, "\n"))
+	}
+}
+
+// hotkeyBindings turns the loop's hotkey actions into listener bindings that
+// post to hotkeyCh.
+func (l *Loop) hotkeyBindings() []hotkey.Binding {
+	bindings := make([]hotkey.Binding, 0, len(l.hotkeys))
+	for _, action := range l.hotkeys {
+		action := action
+		bindings = append(bindings, hotkey.Binding{Combo: action.combo, Callback: func() {
+			if !l.acceptPress(time.Now()) {
+				logutil.Debug("hotkey: ignoring %s inside debounce window", action.combo)
+				return
+			}
+			select {
+			case l.hotkeyCh <- action:
+			default:
+			}
+		}})
+	}
+	return bindings
+}
+
+// acceptPress reports whether a hotkey press at now is far enough from the
+// previous press to count. Every press restarts the window, so a held combo
+// that auto-repeats triggers once.
+func (l *Loop) acceptPress(now time.Time) bool {
+	prev := l.lastPress.Swap(now.UnixNano())
+	return l.debounce <= 0 || prev == 0 || time.Duration(now.UnixNano()-prev) >= l.debounce
+}
+
+// drainHotkeys drops presses queued in hotkeyCh, so presses made before or
+// during a capture don't open another selection right after it.
+func (l *Loop) drainHotkeys() {
+	dropped := 0
+	for {
 		select {
-		case l.hotkeyCh <- struct{}{}:
+		case <-l.hotkeyCh:
+			dropped++
 		default:
+			if dropped > 0 {
+				logutil.Debug("hotkey: dropped %d queued presses", dropped)
+			}
+			return
 		}
-	})
+	}
+}
+
+// SetHotkeyPaused pauses or resumes the capture hotkeys (tray "Pause OCR
+// hotkey"); the listener keeps running and presses are ignored. It is safe
+// to call from any goroutine.
+func (l *Loop) SetHotkeyPaused(paused bool) {
+	select {
+	case l.pauseCh <- paused:
+	case <-l.stopped:
+	}
+}
+
+// handlePause applies SetHotkeyPaused and shows the state in the tray icon
+// and tooltip.
+func (l *Loop) handlePause(paused bool) {
+	if paused == l.hotkeyPaused {
+		return
+	}
+	l.hotkeyPaused = paused
+	if paused {
+		log.Printf("Hotkey paused")
+	} else {
+		log.Printf("Hotkey resumed")
+	}
+	tray.SetPaused(paused)
+	if l.isIdle() {
+		tray.UpdateTooltip(l.idleTooltip())
+	}
+}
+
+// ChangeHotkey asks for a new HOTKEY combo and rebinds it without a restart.
+// It blocks on the input dialog, so call it from its own goroutine (the tray
+// runs menu callbacks that way); the loop performs the rebind.
+func (l *Loop) ChangeHotkey() {
+	l.hotkeyMu.Lock()
+	current := l.defaultHotkey
+	l.hotkeyMu.Unlock()
+
+	combo, ok := tray.PromptText("Change hotkey", "New capture hotkey (e.g. Ctrl+Alt+Q, Win+Shift+S, F13):", current)
+	combo = strings.TrimSpace(combo)
+	if !ok || combo == "" || config.NormalizeHotkey(combo) == config.NormalizeHotkey(current) {
+		return
+	}
+	if err := hotkey.Validate(combo); err != nil {
+		log.Printf("ChangeHotkey: %v", err)
+		_ = popup.Show(fmt.Sprintf("Invalid hotkey: %v", err))
+		return
+	}
+	select {
+	case l.rebindCh <- combo:
+	default:
+	}
+}
+
+// handleRebind replaces the HOTKEY combo, restarts the listener and persists
+// the new value to .env.
+func (l *Loop) handleRebind(combo string) {
+	l.hotkeyMu.Lock()
+	old := l.defaultHotkey
+	l.hotkeyMu.Unlock()
+
+	actions := make([]hotkeyAction, 0, len(l.hotkeys)+1)
+	for _, a := range l.hotkeys {
+		if a.sink == nil && a.combo == old {
+			continue
+		}
+		if config.NormalizeHotkey(a.combo) == config.NormalizeHotkey(combo) {
+			_ = popup.Show(fmt.Sprintf("%s is already used by HOTKEYS", combo))
+			return
+		}
+		actions = append(actions, a)
+	}
+	actions = append(actions, hotkeyAction{combo: combo})
+
+	warnHotkeyConflict(combo)
+	previous := l.hotkeys
+	l.hotkeys = actions
+	if err := hotkey.Restart(l.hotkeyBindings()); err != nil {
+		log.Printf("handleRebind: %v", err)
+		l.hotkeys = previous
+		_ = popup.Show(fmt.Sprintf("Invalid hotkey: %v", err))
+		return
+	}
+
+	l.setDefaultHotkey(combo)
+	log.Printf("handleRebind: hotkey changed from %s to %s", old, combo)
+
+	if err := config.SetEnvValue("HOTKEY", combo); err != nil {
+		log.Printf("handleRebind: failed to save HOTKEY: %v", err)
+		_ = popup.Show(fmt.Sprintf("Hotkey changed to %s (not saved: %v)", combo, err))
+		return
+	}
+	_ = popup.Show(fmt.Sprintf("Hotkey changed to %s", combo))
 }
 
 // Run starts the singleinstance server and processes client requests.
@@ -153,7 +742,35 @@ func (l *Loop) Run(ctx context.Context) error {
 		log.Printf("Resident listening on 127.0.0.1:%d", p)
 		tray.SetAboutExtra(fmt.Sprintf("Resident TCP port: %d", p))
 	}
+	// Deferred calls run in reverse: unblock late callbacks, then wait for
+	// the workers.
 	defer l.pool.Close()
+	defer close(l.stopped)
+
+	if l.httpPort > 0 {
+		l.startHTTP(ctx)
+	}
+
+	// Refresh the relative time in the last-result tooltip and revert it
+	// to the default hint once the preview period has passed.
+	var tooltipTick <-chan time.Time
+	if l.previewFor > 0 {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		tooltipTick = ticker.C
+	}
+
+	// Answer queued run-once clients whose wait has run out.
+	var queueTick <-chan time.Time
+	if l.queueDepth > 0 {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		queueTick = ticker.C
+	}
+
+	if l.reloadConfigOnGrab {
+		go l.watchConfig(ctx)
+	}
 
 	// Accept loop in background to avoid blocking result handling
 	reqCh := make(chan singleinstance.Conn, 4)
@@ -171,29 +788,122 @@ func (l *Loop) Run(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
+			l.shutdown(reqCh)
 			return ctx.Err()
-		case <-l.hotkeyCh:
-			l.handleHotkey(ctx)
+		case action := <-l.hotkeyCh:
+			l.handleHotkey(ctx, action)
 		case conn, ok := <-reqCh:
 			if !ok {
+				l.shutdown(nil)
 				return nil
 			}
 			l.handleConn(ctx, conn)
 		case res := <-l.results:
 			l.handleResult(res)
+			l.serveQueue(ctx)
+		case job := <-l.httpCh:
+			l.handleHTTPJob(ctx, job)
+		case <-l.clipImageCh:
+			l.handleClipboardImage(ctx)
+		case name := <-l.presetCh:
+			l.handlePreset(ctx, name)
+		case combo := <-l.rebindCh:
+			l.handleRebind(combo)
+		case model := <-l.modelCh:
+			l.handleModelSwitch(model)
+		case paused := <-l.pauseCh:
+			l.handlePause(paused)
+		case <-l.reloadCh:
+			// While busy, the next capture picks the change up instead
+			if l.isIdle() {
+				l.reloadConfig()
+			}
+		case <-tooltipTick:
+			if l.isIdle() && !l.lastAt.IsZero() {
+				tray.UpdateTooltip(l.idleTooltip())
+			}
+		case now := <-queueTick:
+			l.expireQueue(now)
+		}
+	}
+}
+
+// shutdown gives in-flight OCR jobs up to shutdownGrace to finish and
+// deliver their results, then answers the rest and any clients still queued,
+// waiting in reqCh or on the HTTP endpoint with ErrShuttingDown.
+func (l *Loop) shutdown(reqCh <-chan singleinstance.Conn) {
+	if len(l.inflight) > 0 {
+		log.Printf("Shutdown: waiting up to %v for %d in-flight OCR job(s)", shutdownGrace, len(l.inflight))
+		timer := time.NewTimer(shutdownGrace)
+	wait:
+		for len(l.inflight) > 0 {
+			select {
+			case res := <-l.results:
+				l.handleResult(res)
+			case <-timer.C:
+				break wait
+			}
+		}
+		timer.Stop()
+		for id, job := range l.inflight {
+			log.Printf("Shutdown: in-flight OCR did not finish in time")
+			job.cancel()
+			delete(l.inflight, id)
+			job.target.OnProcessError(ErrShuttingDown)
+			job.target.Close()
+		}
+	}
+
+	if len(l.queue) > 0 {
+		log.Printf("Shutdown: rejecting %d queued clients", len(l.queue))
+	}
+	for _, q := range l.queue {
+		target := newDelegatedResultTarget(q.conn, false, nil)
+		target.OnProcessError(ErrShuttingDown)
+		target.Close()
+	}
+	l.queue = nil
+
+	for {
+		select {
+		case conn, ok := <-reqCh:
+			if !ok {
+				reqCh = nil
+				continue
+			}
+			log.Printf("Shutdown: rejecting pending client")
+			target := newDelegatedResultTarget(conn, false, nil)
+			target.OnProcessError(ErrShuttingDown)
+			target.Close()
+		case job := <-l.httpCh:
+			job.reply <- httpReply{err: ErrShuttingDown}
+		default:
+			return
 		}
 	}
 }
 
 func (l *Loop) handleConn(ctx context.Context, conn singleinstance.Conn) {
-	target := newDelegatedResultTarget(conn, conn.Request().OutputToStdout)
+	if l.isBusy() && l.enqueue(conn) {
+		return
+	}
+	req := conn.Request()
+	sink := l.sink
+	switch req.Output {
+	case singleinstance.OutputFile:
+		sink = &session.StagedFileTarget{Path: req.OutputFile}
+	case singleinstance.OutputType:
+		sink = session.TypeTarget{}
+	}
+	target := newDelegatedResultTarget(conn, req.OutputToStdout, sink)
 	l.startRequest(ctx, target, requestCallbacks{
 		onBusy: func() {
-			target.OnProcessError(errors.New("Busy, please retry"))
+			log.Printf("handleConn: busy, answering BUSY")
+			_ = conn.RespondBusy()
 			target.Close()
 		},
 		onSelectError: func(err error) {
-			target.OnProcessError(fmt.Errorf("Failed to select region: %w", err))
+			target.OnProcessError(&session.SelectionError{Err: fmt.Errorf("Failed to select region: %w", err)})
 			target.Close()
 		},
 		onCancelled: func() {
@@ -203,43 +913,227 @@ func (l *Loop) handleConn(ctx context.Context, conn singleinstance.Conn) {
 	})
 }
 
+// enqueue holds conn until the loop is idle. It returns false when the queue
+// is disabled or full, in which case the caller answers BUSY.
+func (l *Loop) enqueue(conn singleinstance.Conn) bool {
+	if len(l.queue) >= l.queueDepth {
+		return false
+	}
+	l.queue = append(l.queue, queuedConn{conn: conn, expires: time.Now().Add(l.queueWait)})
+	log.Printf("handleConn: busy, queued request (%d/%d)", len(l.queue), l.queueDepth)
+	return true
+}
+
+// serveQueue starts queued requests in order until one keeps the loop busy.
+func (l *Loop) serveQueue(ctx context.Context) {
+	for !l.isBusy() && len(l.queue) > 0 {
+		q := l.queue[0]
+		l.queue = l.queue[1:]
+		log.Printf("serveQueue: starting queued request (%d left)", len(l.queue))
+		l.handleConn(ctx, q.conn)
+	}
+}
+
+// expireQueue answers BUSY to queued requests that waited past queueWait.
+func (l *Loop) expireQueue(now time.Time) {
+	kept := l.queue[:0]
+	for _, q := range l.queue {
+		if now.Before(q.expires) {
+			kept = append(kept, q)
+			continue
+		}
+		log.Printf("expireQueue: queued request timed out, answering BUSY")
+		l.metrics.RecordBusyRejection()
+		_ = q.conn.RespondBusy()
+		_ = q.conn.Close()
+	}
+	l.queue = kept
+}
+
 func (l *Loop) handleResult(res result) {
-	log.Printf("handleResult: called with text length=%d, err=%v", len(res.text), res.err)
+	logutil.Debug("handleResult: called with text length=%d, err=%v", len(res.text), res.err)
+	closePopup := func() {
+		if !res.quiet {
+			_ = popup.Close()
+		}
+	}
+	delete(l.inflight, res.job)
 	defer func() {
-		l.setBusy(false)
+		l.finishJob()
 		if res.cancel != nil {
 			res.cancel()
 		}
 	}()
 	if res.target == nil {
 		log.Printf("handleResult: missing target")
-		_ = popup.Close()
+		closePopup()
 		return
 	}
 	defer res.target.Close()
 
+	if res.delivered {
+		l.recordOutcome(res, res.err)
+		if res.err == nil {
+			l.rememberResult(res.text, res.windowTitle)
+			l.scheduleClipboardClear(res.started)
+		}
+		return
+	}
+
 	if res.err != nil {
 		log.Printf("handleResult: processing error: %v", res.err)
-		_ = popup.Close()
+		l.recordOutcome(res, res.err)
+		closePopup()
 		res.target.OnProcessError(res.err)
 		return
 	}
 
 	if err := res.target.OnSuccess(res.text); err != nil {
 		log.Printf("handleResult: delivery error: %v", err)
-		_ = popup.Close()
+		l.recordOutcome(res, err)
+		closePopup()
 		res.target.OnDeliveryError(err)
 		return
 	}
 
+	l.recordOutcome(res, nil)
+	// HTTP results belong to the caller: they stay out of the history, the
+	// tray preview and "Open link"
+	if _, fromHTTP := res.target.(httpResultTarget); !fromHTTP {
+		l.rememberResult(res.text, "")
+	}
+	l.scheduleClipboardClear(res.started)
+
+	if res.quiet {
+		return
+	}
+
 	// Update countdown popup with result text
-	log.Printf("handleResult: updating popup with result")
+	logutil.Debug("handleResult: updating popup with result")
 	_ = popup.UpdateText(res.text)
 }
 
-func (l *Loop) handleHotkey(ctx context.Context) {
-	log.Printf("handleHotkey: called")
-	l.startRequest(ctx, hotkeyResultTarget{}, requestCallbacks{
+// Clipboard clearing hooks, replaceable in tests.
+var (
+	clearClipboardAfter       = time.AfterFunc
+	lastClipboardWrite        = clipboard.LastWrite
+	clearClipboardIfUnchanged = clipboard.ClearIfUnchanged
+)
+
+// scheduleClipboardClear arranges for the clipboard to be emptied after
+// CLIPBOARD_CLEAR_AFTER_SEC when the job started at started put text there,
+// so a captured password or 2FA code does not linger. The clipboard is left
+// alone if it no longer holds that text, e.g. the user copied something else.
+func (l *Loop) scheduleClipboardClear(started time.Time) {
+	if l.clipboardClearAfter <= 0 {
+		return
+	}
+	text, at := lastClipboardWrite()
+	if at.IsZero() || at.Before(started) {
+		return
+	}
+	log.Printf("Clipboard: Clearing the result in %v", l.clipboardClearAfter)
+	clearClipboardAfter(l.clipboardClearAfter, func() {
+		cleared, err := clearClipboardIfUnchanged(text)
+		switch {
+		case err != nil:
+			log.Printf("Clipboard: Failed to clear the result: %v", err)
+		case cleared:
+			log.Printf("Clipboard: Cleared the result")
+		default:
+			log.Printf("Clipboard: Not cleared, its contents changed")
+		}
+	})
+}
+
+// recordOutcome counts res as a success, or as a failure with err, in the
+// metrics and gives the completion cue for captures the user started.
+func (l *Loop) recordOutcome(res result, err error) {
+	var latency time.Duration
+	if !res.started.IsZero() {
+		latency = time.Since(res.started)
+	}
+	if !res.quiet {
+		l.completionCue(err == nil)
+	}
+	if err != nil {
+		l.metrics.RecordFailure(latency, err)
+		return
+	}
+	l.metrics.RecordSuccess(latency)
+}
+
+// Completion cues, replaceable in tests.
+var (
+	playCompletionSound = notification.PlayCompletionSound
+	flashTrayIcon       = tray.Flash
+)
+
+// completionCue plays a sound and/or flashes the tray icon per
+// NOTIFY_ON_COMPLETE, with a different cue for failures.
+func (l *Loop) completionCue(success bool) {
+	switch l.notifyOnComplete {
+	case "sound":
+		playCompletionSound(success)
+	case "flash":
+		flashTrayIcon(success)
+	case "both":
+		playCompletionSound(success)
+		flashTrayIcon(success)
+	}
+}
+
+// rememberResult keeps a successful result for the tray tooltip preview
+// (applied by finishJob) and "Copy last result", and records it in the
+// history along with the source window's title, if known. Dry-run
+// placeholders are not recorded.
+func (l *Loop) rememberResult(text, windowTitle string) {
+	l.lastAt = time.Now()
+	l.resultMu.Lock()
+	l.lastResult = text
+	l.resultMu.Unlock()
+	offerLink(text)
+
+	if ocr.DryRun() {
+		return
+	}
+	if err := history.RecordWithWindow(text, llm.LastModel(), windowTitle); err != nil {
+		log.Printf("handleResult: failed to record history: %v", err)
+	}
+}
+
+// offerLink puts the URL of text in the tray's "Open link" item when
+// OCR_EXTRACT covers URLs and text holds exactly one, and clears the item
+// otherwise.
+func offerLink(text string) {
+	kind := ocr.Extraction()
+	if kind != ocr.ExtractURLs && kind != ocr.ExtractAll {
+		return
+	}
+	url := ocr.Extract(text, kind).OnlyURL()
+	if url != "" {
+		log.Printf("Result contains a link; open it from the tray menu")
+	}
+	tray.SetLink(url)
+}
+
+func (l *Loop) handleHotkey(ctx context.Context, action hotkeyAction) {
+	logutil.Debug("handleHotkey: called for %s", action.combo)
+	if l.hotkeyPaused {
+		logutil.Debug("handleHotkey: paused, ignoring %s", action.combo)
+		return
+	}
+	l.drainHotkeys()
+	defer l.drainHotkeys()
+	if action.window != "" {
+		l.handleWindow(ctx, action.window)
+		return
+	}
+	sink := l.sink
+	if action.sink != nil {
+		sink = action.sink
+	}
+	l.startRequest(ctx, hotkeyResultTarget{sink: sink}, requestCallbacks{
 		onBusy: func() {
 			log.Printf("handleHotkey: busy, skipping")
 			_ = popup.Show("Busy, please retry")
@@ -254,14 +1148,88 @@ func (l *Loop) handleHotkey(ctx context.Context) {
 	})
 }
 
+// handleWindow captures the client area of the window whose title contains
+// title, without the selection overlay, and delivers the result to the
+// default sink.
+func (l *Loop) handleWindow(ctx context.Context, title string) {
+	if l.isBusy() {
+		l.metrics.RecordBusyRejection()
+		_ = popup.Show("Busy, please retry")
+		return
+	}
+	l.reloadConfig()
+	window, err := gui.FindWindowByTitle(title)
+	if err != nil {
+		log.Printf("handleWindow: %v", err)
+		_ = popup.Show(err.Error())
+		return
+	}
+	l.submitRegion(ctx, window.Region, window.Title, hotkeyResultTarget{sink: l.sink}, func() {
+		_ = popup.Show("Busy, please retry")
+	})
+}
+
+func (l *Loop) handlePreset(ctx context.Context, name string) {
+	logutil.Debug("handlePreset: called for %q", name)
+	preset, err := config.FindPresetRegion(l.presets, name)
+	if err != nil {
+		log.Printf("handlePreset: %v", err)
+		_ = popup.Show(err.Error())
+		return
+	}
+	if l.isBusy() {
+		l.metrics.RecordBusyRejection()
+		_ = popup.Show("Busy, please retry")
+		return
+	}
+	l.reloadConfig()
+	region := screenshot.Region{X: preset.X, Y: preset.Y, Width: preset.Width, Height: preset.Height}
+	l.submitRegion(ctx, region, gui.ForegroundWindowTitle(), hotkeyResultTarget{sink: l.sink}, func() {
+		_ = popup.Show("Busy, please retry")
+	})
+}
+
+func (l *Loop) handleClipboardImage(ctx context.Context) {
+	logutil.Debug("handleClipboardImage: called")
+	if l.isBusy() {
+		l.metrics.RecordBusyRejection()
+		_ = popup.Show("Busy, please retry")
+		return
+	}
+	l.reloadConfig()
+
+	image, err := clipboard.ReadImage()
+	if err != nil {
+		log.Printf("handleClipboardImage: %v", err)
+		_ = popup.Show("No image on clipboard")
+		return
+	}
+
+	target := hotkeyResultTarget{sink: l.sink}
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.deadline)
+	_ = popup.StartCountdown(int(l.deadline.Seconds()))
+
+	submitted := l.submitJob(target, cancel, false, func(cb worker.ResultCallback) bool {
+		return l.pool.SubmitImage(jobCtx, image, cb)
+	})
+	if !submitted {
+		_ = popup.Close()
+		_ = popup.Show("Busy, please retry")
+	}
+}
+
 func (l *Loop) startRequest(ctx context.Context, target resultTarget, callbacks requestCallbacks) {
-	if l.busy {
+	if l.isBusy() {
+		l.metrics.RecordBusyRejection()
 		if callbacks.onBusy != nil {
 			callbacks.onBusy()
 		}
 		return
 	}
+	l.reloadConfig()
 
+	// The overlay takes the foreground, so note the user's window first
+	windowTitle := gui.ForegroundWindowTitle()
 	region, cancelled, err := l.selectRegion(ctx)
 	if err != nil {
 		if callbacks.onSelectError != nil {
@@ -276,19 +1244,90 @@ func (l *Loop) startRequest(ctx context.Context, target resultTarget, callbacks
 		return
 	}
 
-	jobCtx, cancel := context.WithTimeout(ctx, l.deadline)
-	_ = popup.StartCountdown(int(l.deadline.Seconds()))
+	l.submitRegion(ctx, region, windowTitle, target, callbacks.onBusy)
+}
+
+// submitRegion queues OCR of region and runs the rest of the capture through
+// session.Execute, which shows the countdown popup, waits for the worker and
+// delivers the result to target; Run then records the outcome, with
+// windowTitle as the source window in the history. onBusy, if set, is called
+// when the worker pool rejects the job.
+// The job is not cancelled by shutdown; Run gives it shutdownGrace to finish.
+func (l *Loop) submitRegion(ctx context.Context, region screenshot.Region, windowTitle string, target resultTarget, onBusy func()) {
+	deadline := l.deadline
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deadline)
+	type outcome struct {
+		text string
+		err  error
+	}
+	done := make(chan outcome, 1)
 
-	l.setBusy(true)
+	l.startJob()
+	started := time.Now()
 	submitted := l.pool.Submit(jobCtx, region, func(text string, err error) {
-		l.results <- result{text: text, err: err, target: target, cancel: cancel}
+		done <- outcome{text: text, err: err}
 	})
 	if !submitted {
 		cancel()
-		l.setBusy(false)
-		_ = popup.Close()
-		if callbacks.onBusy != nil {
-			callbacks.onBusy()
+		l.finishJob()
+		l.metrics.RecordBusyRejection()
+		if onBusy != nil {
+			onBusy()
+		}
+		return
+	}
+	st := &sessionTarget{target: target}
+	id := l.trackJob(st, cancel)
+
+	go func() {
+		res, err := session.Execute(jobCtx, session.Options{
+			Deadline: deadline,
+			SelectRegion: func(context.Context) (screenshot.Region, bool, error) {
+				return region, false, nil
+			},
+			Recognize: func(ctx context.Context, _ screenshot.Region) (string, error) {
+				select {
+				case o := <-done:
+					return o.text, o.err
+				case <-ctx.Done():
+					return "", ctx.Err()
+				}
+			},
+			Target: st,
+		})
+		l.postResult(result{text: res.Text, err: err, target: st, cancel: cancel, delivered: true, windowTitle: windowTitle, started: started, job: id})
+	}()
+}
+
+// submitJob takes a worker pool slot and hands a job to the pool via submit,
+// with a callback that posts the outcome back to Run. It returns false,
+// releasing the slot and cancelling the job, if the pool rejected it.
+func (l *Loop) submitJob(target resultTarget, cancel context.CancelFunc, quiet bool, submit func(cb worker.ResultCallback) bool) bool {
+	l.startJob()
+	started := time.Now()
+	id := l.trackJob(target, cancel)
+	submitted := submit(func(text string, err error) {
+		l.postResult(result{text: text, err: err, target: target, cancel: cancel, quiet: quiet, started: started, job: id})
+	})
+	if !submitted {
+		delete(l.inflight, id)
+		cancel()
+		l.finishJob()
+		l.metrics.RecordBusyRejection()
+		return false
+	}
+	return true
+}
+
+// postResult hands a worker result to Run. Once Run has returned the result
+// is dropped rather than blocking the worker.
+func (l *Loop) postResult(res result) {
+	select {
+	case l.results <- res:
+	case <-l.stopped:
+		log.Printf("postResult: loop stopped, dropping result")
+		if res.cancel != nil {
+			res.cancel()
 		}
 	}
 }
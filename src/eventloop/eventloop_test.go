@@ -1,14 +1,19 @@
 package eventloop
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"screen-ocr-llm/src/config"
 	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/metrics"
+	"screen-ocr-llm/src/screenshot"
+	"screen-ocr-llm/src/singleinstance"
 )
 
 func TestRefreshRuntimeConfigDisabledSkipsLoad(t *testing.T) {
@@ -252,3 +257,302 @@ func TestRefreshRuntimeConfigValidatesRequiredFields(t *testing.T) {
 		})
 	}
 }
+
+type recordingTarget struct {
+	successErr error
+	calls      []string
+}
+
+func (t *recordingTarget) OnSuccess(text string) error {
+	t.calls = append(t.calls, "success:"+text)
+	return t.successErr
+}
+func (t *recordingTarget) OnProcessError(err error) {
+	t.calls = append(t.calls, "process:"+err.Error())
+}
+func (t *recordingTarget) OnDeliveryError(err error) {
+	t.calls = append(t.calls, "delivery:"+err.Error())
+}
+func (t *recordingTarget) Close() { t.calls = append(t.calls, "close") }
+
+func TestSessionTarget(t *testing.T) {
+	t.Run("recognition failure", func(t *testing.T) {
+		rt := &recordingTarget{}
+		st := &sessionTarget{target: rt}
+		_ = st.OnFailure(errors.New("timeout"))
+		if len(rt.calls) != 1 || rt.calls[0] != "process:timeout" {
+			t.Fatalf("calls = %q", rt.calls)
+		}
+	})
+
+	t.Run("delivery failure", func(t *testing.T) {
+		rt := &recordingTarget{successErr: errors.New("clipboard busy")}
+		st := &sessionTarget{target: rt}
+		if err := st.OnSuccess("text"); err == nil {
+			t.Fatal("expected delivery error")
+		}
+		_ = st.OnFailure(errors.New("clipboard busy"))
+		if len(rt.calls) != 2 || rt.calls[1] != "delivery:clipboard busy" {
+			t.Fatalf("calls = %q", rt.calls)
+		}
+	})
+
+	t.Run("answered once after shutdown", func(t *testing.T) {
+		rt := &recordingTarget{}
+		st := &sessionTarget{target: rt}
+		st.OnProcessError(ErrShuttingDown)
+		if err := st.OnSuccess("late"); !errors.Is(err, ErrShuttingDown) {
+			t.Fatalf("OnSuccess after shutdown = %v", err)
+		}
+		_ = st.OnFailure(context.Canceled)
+		if len(rt.calls) != 1 || rt.calls[0] != "process:"+ErrShuttingDown.Error() {
+			t.Fatalf("calls = %q", rt.calls)
+		}
+	})
+}
+
+func TestHotkeyActionsWindowBinding(t *testing.T) {
+	actions := hotkeyActions("Ctrl+Alt+Q", []config.HotkeyBinding{
+		{Combo: "Ctrl+Alt+N", Output: "Window: Notepad "},
+		{Combo: "Ctrl+Alt+E", Output: "window:"},
+		{Combo: "Ctrl+Alt+W", Output: "file:ocr.txt"},
+	})
+	if len(actions) != 3 {
+		t.Fatalf("actions = %+v", actions)
+	}
+	if actions[0].combo != "Ctrl+Alt+N" || actions[0].window != "Notepad" || actions[0].sink != nil {
+		t.Fatalf("window action = %+v", actions[0])
+	}
+	if actions[1].window != "" || actions[1].sink == nil {
+		t.Fatalf("sink action = %+v", actions[1])
+	}
+	if actions[2].combo != "Ctrl+Alt+Q" || actions[2].window != "" {
+		t.Fatalf("default action = %+v", actions[2])
+	}
+}
+
+func TestHandleResultRecordsMetrics(t *testing.T) {
+	l := &Loop{metrics: metrics.New()}
+	rt := &recordingTarget{}
+
+	l.handleResult(result{text: "hello", target: rt, quiet: true, started: time.Now().Add(-time.Second)})
+	l.handleResult(result{err: errors.New("API request failed"), target: rt, quiet: true})
+	l.handleResult(result{text: "x", target: &recordingTarget{successErr: errors.New("clipboard busy")}, quiet: true})
+
+	l.active.Store(1)
+	l.startRequest(context.Background(), rt, requestCallbacks{})
+
+	s := l.metrics.Snapshot()
+	if s.Total != 3 || s.Successes != 1 || s.Failures != 2 || s.BusyRejections != 1 {
+		t.Fatalf("unexpected counters: %+v", s)
+	}
+	if s.LastError != "clipboard busy" || s.AverageLatency <= 0 {
+		t.Fatalf("unexpected snapshot: %+v", s)
+	}
+	if s.Busy {
+		t.Fatal("expected loop to be idle after the results")
+	}
+}
+
+func TestHandleResultSchedulesClipboardClear(t *testing.T) {
+	prevAfter, prevLast, prevClear := clearClipboardAfter, lastClipboardWrite, clearClipboardIfUnchanged
+	defer func() {
+		clearClipboardAfter, lastClipboardWrite, clearClipboardIfUnchanged = prevAfter, prevLast, prevClear
+	}()
+
+	var delay time.Duration
+	var clearFn func()
+	clearClipboardAfter = func(d time.Duration, f func()) *time.Timer {
+		delay, clearFn = d, f
+		return nil
+	}
+	written := time.Now()
+	lastClipboardWrite = func() (string, time.Time) { return "493817", written }
+	var clearedText string
+	clearClipboardIfUnchanged = func(text string) (bool, error) {
+		clearedText = text
+		return true, nil
+	}
+
+	l := &Loop{metrics: metrics.New(), clipboardClearAfter: 30 * time.Second}
+	l.handleResult(result{text: "493817", target: &recordingTarget{}, quiet: true, started: written.Add(-time.Second)})
+	if delay != 30*time.Second || clearFn == nil {
+		t.Fatalf("expected a clear in 30s, got %v", delay)
+	}
+	clearFn()
+	if clearedText != "493817" {
+		t.Fatalf("cleared %q, want the written result", clearedText)
+	}
+
+	// The clipboard was last written before this job: nothing to clear
+	clearFn = nil
+	l.handleResult(result{text: "x", target: &recordingTarget{}, quiet: true, started: written.Add(time.Second)})
+	if clearFn != nil {
+		t.Fatal("expected no clear for a result the job did not copy")
+	}
+
+	l.clipboardClearAfter = 0
+	l.handleResult(result{text: "x", target: &recordingTarget{}, quiet: true, started: written.Add(-time.Second)})
+	if clearFn != nil {
+		t.Fatal("expected no clear with CLIPBOARD_CLEAR_AFTER_SEC=0")
+	}
+}
+
+func TestLoopBusyOncePoolSlotsAreTaken(t *testing.T) {
+	l := &Loop{capacity: 3}
+	for i := 0; i < 3; i++ {
+		if l.isBusy() {
+			t.Fatalf("busy with %d of 3 slots taken", i)
+		}
+		l.startJob()
+	}
+	if !l.isBusy() {
+		t.Fatal("expected busy with every slot taken")
+	}
+	l.finishJob()
+	if l.isBusy() || l.active.Load() != 2 {
+		t.Fatalf("expected a free slot, active = %d", l.active.Load())
+	}
+}
+
+type fixedSelector struct{}
+
+func (fixedSelector) Select(ctx context.Context) (screenshot.Region, bool, error) {
+	return screenshot.Region{Width: 8, Height: 8}, false, nil
+}
+
+// TestLoopConcurrentHotkeyAndConnEvents is meant for go test -race: hotkey
+// and run-once events arrive together while other goroutines read the busy
+// state.
+func TestLoopConcurrentHotkeyAndConnEvents(t *testing.T) {
+	t.Setenv("SINGLEINSTANCE_PORT_START", "49620")
+	t.Setenv("SINGLEINSTANCE_PORT_END", "49640")
+
+	l := New(&config.Config{OCRDeadlineSec: 2, OCRWorkers: 2})
+	l.selector = fixedSelector{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- l.Run(ctx) }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			select {
+			case l.hotkeyCh <- hotkeyAction{combo: "Ctrl+Alt+Q"}:
+			case <-ctx.Done():
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			reqCtx, reqCancel := context.WithTimeout(ctx, 3*time.Second)
+			defer reqCancel()
+			_, _, _ = singleinstance.NewClient().TryRunOnce(reqCtx, singleinstance.NewRequest(singleinstance.OutputStdout, ""))
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = l.isBusy()
+				_ = l.isIdle()
+				_ = l.metrics.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	cancel()
+	select {
+	case <-runErr:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+	if n := l.active.Load(); n < 0 || int(n) > l.capacity {
+		t.Fatalf("active = %d out of range [0, %d]", n, l.capacity)
+	}
+}
+
+func TestAcceptPressDebounces(t *testing.T) {
+	l := &Loop{debounce: 300 * time.Millisecond}
+	start := time.Now()
+	if !l.acceptPress(start) {
+		t.Fatal("expected the first press to count")
+	}
+	// Auto-repeat while the combo is held keeps restarting the window
+	for _, d := range []time.Duration{100, 200, 300, 400} {
+		if l.acceptPress(start.Add(d * time.Millisecond)) {
+			t.Fatalf("expected the press at +%dms to be ignored", d)
+		}
+	}
+	if !l.acceptPress(start.Add(800 * time.Millisecond)) {
+		t.Fatal("expected a press after the window to count")
+	}
+
+	l = &Loop{}
+	if !l.acceptPress(start) || !l.acceptPress(start) {
+		t.Fatal("expected every press to count with debounce disabled")
+	}
+}
+
+func TestDrainHotkeys(t *testing.T) {
+	l := &Loop{hotkeyCh: make(chan hotkeyAction, 4)}
+	for i := 0; i < 3; i++ {
+		l.hotkeyCh <- hotkeyAction{combo: "Ctrl+Alt+Q"}
+	}
+	l.drainHotkeys()
+	if n := len(l.hotkeyCh); n != 0 {
+		t.Fatalf("expected an empty channel, %d presses left", n)
+	}
+}
+
+type countingSelector struct{ calls int }
+
+func (s *countingSelector) Select(ctx context.Context) (screenshot.Region, bool, error) {
+	s.calls++
+	return screenshot.Region{}, true, nil
+}
+
+func TestHandleHotkeyIgnoredWhilePaused(t *testing.T) {
+	sel := &countingSelector{}
+	l := &Loop{selector: sel, capacity: 1, hotkeyCh: make(chan hotkeyAction, 4), defaultTooltip: "Screen OCR Tool"}
+
+	l.handlePause(true)
+	l.handleHotkey(context.Background(), hotkeyAction{combo: "Ctrl+Alt+Q"})
+	if sel.calls != 0 {
+		t.Fatalf("selector called %d times while paused", sel.calls)
+	}
+	if got := l.idleTooltip(); got != "Screen OCR Tool\nHotkey paused" {
+		t.Fatalf("tooltip = %q", got)
+	}
+
+	l.handlePause(false)
+	l.handleHotkey(context.Background(), hotkeyAction{combo: "Ctrl+Alt+Q"})
+	if sel.calls != 1 {
+		t.Fatalf("selector called %d times after resuming, want 1", sel.calls)
+	}
+}
+
+func TestRecordOutcomeCompletionCue(t *testing.T) {
+	var sounds, flashes []bool
+	prevSound, prevFlash := playCompletionSound, flashTrayIcon
+	defer func() { playCompletionSound, flashTrayIcon = prevSound, prevFlash }()
+	playCompletionSound = func(success bool) { sounds = append(sounds, success) }
+	flashTrayIcon = func(success bool) { flashes = append(flashes, success) }
+
+	l := &Loop{notifyOnComplete: "both"}
+	l.recordOutcome(result{}, nil)
+	l.recordOutcome(result{}, errors.New("boom"))
+	l.recordOutcome(result{quiet: true}, nil)
+	if len(sounds) != 2 || !sounds[0] || sounds[1] || len(flashes) != 2 || !flashes[0] || flashes[1] {
+		t.Fatalf("unexpected cues: sounds %v, flashes %v", sounds, flashes)
+	}
+
+	sounds, flashes = nil, nil
+	l = &Loop{notifyOnComplete: "none"}
+	l.recordOutcome(result{}, nil)
+	if len(sounds) != 0 || len(flashes) != 0 {
+		t.Fatalf("expected no cue by default, got sounds %v, flashes %v", sounds, flashes)
+	}
+}
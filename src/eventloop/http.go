@@ -0,0 +1,94 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"screen-ocr-llm/src/httpapi"
+	"screen-ocr-llm/src/tray"
+	"screen-ocr-llm/src/worker"
+)
+
+// httpJob is an OCR request from the HTTP endpoint, handed to the loop so
+// it shares the busy flag and worker pool with hotkey and run-once captures.
+type httpJob struct {
+	ctx   context.Context
+	image []byte
+	reply chan httpReply
+}
+
+type httpReply struct {
+	text string
+	err  error
+}
+
+// httpResultTarget answers the waiting HTTP handler instead of writing to
+// the clipboard.
+type httpResultTarget struct {
+	reply chan httpReply
+}
+
+func (t httpResultTarget) OnSuccess(text string) error {
+	t.reply <- httpReply{text: text}
+	return nil
+}
+
+func (t httpResultTarget) OnProcessError(err error) {
+	t.reply <- httpReply{err: err}
+}
+
+func (t httpResultTarget) OnDeliveryError(err error) {
+	t.reply <- httpReply{err: err}
+}
+
+func (httpResultTarget) Close() {}
+
+// startHTTP starts the loopback OCR endpoint (OCR_HTTP_ENABLED). Failure to
+// bind is logged and leaves the rest of the resident running.
+func (l *Loop) startHTTP(ctx context.Context) {
+	addr, err := httpapi.Start(ctx, l.httpPort, l.recognizeHTTP, l.metrics.Snapshot)
+	if err != nil {
+		log.Printf("OCR HTTP endpoint disabled: %v", err)
+		return
+	}
+	log.Printf("OCR HTTP endpoint listening on http://%s/ocr (metrics at /metrics)", addr)
+	tray.SetAboutExtra(fmt.Sprintf("Resident TCP port: %d\nOCR HTTP endpoint: http://%s/ocr", l.srv.Port(), addr))
+}
+
+// recognizeHTTP is called from HTTP handler goroutines and waits for the
+// loop to run the job.
+func (l *Loop) recognizeHTTP(ctx context.Context, image []byte) (string, error) {
+	reply := make(chan httpReply, 1)
+	select {
+	case l.httpCh <- httpJob{ctx: ctx, image: image, reply: reply}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	select {
+	case r := <-reply:
+		return r.text, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (l *Loop) handleHTTPJob(ctx context.Context, job httpJob) {
+	if l.isBusy() {
+		log.Printf("handleHTTPJob: busy, rejecting")
+		l.metrics.RecordBusyRejection()
+		job.reply <- httpReply{err: httpapi.ErrBusy}
+		return
+	}
+	l.reloadConfig()
+
+	jobCtx, cancel := context.WithTimeout(job.ctx, l.deadline)
+	target := httpResultTarget{reply: job.reply}
+
+	submitted := l.submitJob(target, cancel, true, func(cb worker.ResultCallback) bool {
+		return l.pool.SubmitImage(jobCtx, job.image, cb)
+	})
+	if !submitted {
+		job.reply <- httpReply{err: httpapi.ErrBusy}
+	}
+}
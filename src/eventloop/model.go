@@ -0,0 +1,66 @@
+package eventloop
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"screen-ocr-llm/src/config"
+	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/popup"
+	"screen-ocr-llm/src/tray"
+)
+
+// SwitchModel asks the loop to send subsequent OCR requests to model (tray
+// "Model" submenu, MODEL_CHOICES). It is safe to call from any goroutine.
+func (l *Loop) SwitchModel(model string) {
+	select {
+	case l.modelCh <- model:
+	default:
+	}
+}
+
+// handleModelSwitch makes model the active LLM model, shows it in the tray
+// and persists it to .env. Jobs already running finish with the previous
+// model.
+func (l *Loop) handleModelSwitch(model string) {
+	if err := llm.SetModel(model); err != nil {
+		log.Printf("handleModelSwitch: %v", err)
+		_ = popup.Show(fmt.Sprintf("Model not changed: %v", err))
+		return
+	}
+	chain := llm.ModelChain()
+	if l.cfg != nil {
+		// Keep a config reload from reporting the switch as a change
+		next := *l.cfg
+		next.Model = model
+		next.Models = chain
+		l.cfg = &next
+	}
+
+	l.activeModel = model
+	tray.SetAboutModel(model)
+	if l.isIdle() {
+		tray.UpdateTooltip(l.idleTooltip())
+	}
+
+	if err := persistModel(model, chain); err != nil {
+		log.Printf("handleModelSwitch: %v", err)
+		_ = popup.Show(fmt.Sprintf("Model switched to %s (not saved: %v)", model, err))
+		return
+	}
+	_ = popup.Show(fmt.Sprintf("Model switched to %s", model))
+}
+
+// persistModel saves model as MODEL and, when a MODELS chain is configured,
+// saves the chain with model first so a restart or config reload keeps it.
+func persistModel(model string, chain []string) error {
+	if err := config.SetEnvValue("MODEL", model); err != nil {
+		return err
+	}
+	if strings.TrimSpace(os.Getenv("MODELS")) == "" {
+		return nil
+	}
+	return config.SetEnvValue("MODELS", strings.Join(chain, ","))
+}
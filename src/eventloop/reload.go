@@ -0,0 +1,298 @@
+package eventloop
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"screen-ocr-llm/src/clipboard"
+	"screen-ocr-llm/src/config"
+	"screen-ocr-llm/src/hotkey"
+	"screen-ocr-llm/src/keyboard"
+	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/logutil"
+	"screen-ocr-llm/src/ocr"
+	"screen-ocr-llm/src/overlay"
+	"screen-ocr-llm/src/popup"
+	"screen-ocr-llm/src/runtimeinit"
+	"screen-ocr-llm/src/tray"
+)
+
+// configPollInterval is how often the resident checks .env for changes
+// (RELOAD_CONFIG_ON_GRAB).
+const configPollInterval = 2 * time.Second
+
+// configSourceState identifies the contents of the .env file a config was
+// loaded from. size and modTime only avoid rehashing an untouched file;
+// states compare by path and content hash.
+type configSourceState struct {
+	path    string
+	exists  bool
+	size    int64
+	modTime time.Time
+	hash    [sha256.Size]byte
+}
+
+// loadConfigSourceState reads the state of the .env file at path. prev is
+// reused when the file's size and modification time are unchanged. An empty
+// path (no .env) yields the zero state.
+func loadConfigSourceState(prev configSourceState, path string) (configSourceState, error) {
+	if path == "" {
+		return configSourceState{}, nil
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return configSourceState{path: path}, nil
+	}
+	if err != nil {
+		return configSourceState{}, err
+	}
+	if prev.exists && prev.path == path && prev.size == info.Size() && prev.modTime.Equal(info.ModTime()) {
+		return prev, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return configSourceState{}, err
+	}
+	return configSourceState{
+		path:    path,
+		exists:  true,
+		size:    info.Size(),
+		modTime: info.ModTime(),
+		hash:    sha256.Sum256(data),
+	}, nil
+}
+
+func configSourceStateEqual(a, b configSourceState) bool {
+	return a.path == b.path && a.exists == b.exists && a.hash == b.hash
+}
+
+// SetLoadOptions sets the CLI overrides (--api-key-path, --default-mode, ...)
+// that are reapplied when the config is reloaded.
+func (l *Loop) SetLoadOptions(opts config.LoadOptions) { l.loadOptions = opts }
+
+// reloadConfig applies .env changes, keeping the current settings if the new
+// ones fail to load.
+func (l *Loop) reloadConfig() {
+	if err := l.refreshRuntimeConfig(); err != nil {
+		log.Printf("Config reload failed, keeping current settings: %v", err)
+		_ = popup.Show(fmt.Sprintf("Config reload failed: %v", err))
+	}
+}
+
+// refreshRuntimeConfig reloads the config when the .env file changed since
+// the last load and applies it. A failed load is not retried until the file
+// changes again.
+func (l *Loop) refreshRuntimeConfig() error {
+	if !l.reloadConfigOnGrab {
+		return nil
+	}
+	state, err := loadConfigSourceState(l.configSource, config.EnvPath())
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	if l.configLoaded && configSourceStateEqual(l.configSource, state) {
+		return nil
+	}
+	l.configSource, l.configLoaded = state, true
+
+	cfg, err := l.loadConfig(l.loadOptions)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.APIKey == "" {
+		return fmt.Errorf("OPENROUTER_API_KEY is required. Checked key file %s and OPENROUTER_API_KEY env var", cfg.APIKeyPath)
+	}
+	if cfg.Model == "" {
+		return errors.New("MODEL is required")
+	}
+	replacements, err := ocr.LoadReplacements(cfg.OCRReplacements)
+	if err != nil {
+		return err
+	}
+	l.applyConfig(cfg)
+	ocr.SetReplacements(replacements)
+	return nil
+}
+
+// applyConfig switches the loop to cfg: LLM model, providers and prompt
+// settings, output normalization and translation, selection overlay, OCR
+// deadline, clipboard append separator, format and clearing, and hotkeys.
+// Other settings take effect on restart.
+func (l *Loop) applyConfig(cfg *config.Config) {
+	prev := l.cfg
+	if prev == nil {
+		prev = &config.Config{}
+	}
+	changes := configChanges(prev, cfg)
+	if len(changes) == 0 {
+		log.Printf("Config reloaded: no changes")
+	}
+	for _, change := range changes {
+		log.Printf("Config reloaded: %s", change)
+	}
+
+	if l.llmInit != nil {
+		l.llmInit(runtimeinit.LLMConfig(cfg))
+	}
+	ocr.SetNormalization(cfg.OCRTrim, cfg.OCRLineEndings)
+	ocr.SetTranslation(cfg.TranslateTo, cfg.TranslateKeepOrig)
+
+	l.defaultMode = cfg.DefaultMode
+	if l.defaultMode == "" {
+		l.defaultMode = config.DefaultModeRect
+	}
+	overlayOpts := overlay.Options{
+		DefaultMode:     l.defaultMode,
+		Guides:          cfg.OverlayGuides,
+		BackgroundScale: cfg.OverlayBGScale,
+		Magnifier:       cfg.OverlayMagnifier,
+	}
+	if l.selector == nil || overlayOpts != l.overlayOpts {
+		l.overlayOpts = overlayOpts
+		l.selector = overlay.NewSelectorWithOptions(overlayOpts)
+	}
+
+	deadlineSec := cfg.OCRDeadlineSec
+	if deadlineSec <= 0 {
+		deadlineSec = 20
+	}
+	l.deadline = time.Duration(deadlineSec) * time.Second
+	l.queueWait = l.deadline
+	l.reloadConfigOnGrab = cfg.ReloadConfigOnGrab
+	clipboard.SetAppendSeparator(cfg.ClipboardAppendSep)
+	clipboard.SetFormat(cfg.ClipboardFormat)
+	l.clipboardClearAfter = time.Duration(cfg.ClipboardClearAfter) * time.Second
+	keyboard.SetDelay(time.Duration(cfg.TypeDelayMs) * time.Millisecond)
+
+	l.hotkeyMu.Lock()
+	currentHotkey := l.defaultHotkey
+	l.hotkeyMu.Unlock()
+	if cfg.Hotkey != "" && (cfg.Hotkey != currentHotkey || !slices.Equal(cfg.Hotkeys, prev.Hotkeys)) {
+		l.applyHotkeys(cfg.Hotkey, cfg.Hotkeys)
+	}
+
+	l.cfg = cfg
+}
+
+// applyHotkeys restarts the listener with HOTKEY combo and the HOTKEYS
+// bindings. On error the previous hotkeys stay active.
+func (l *Loop) applyHotkeys(combo string, bindings []config.HotkeyBinding) {
+	previous := l.hotkeys
+	l.hotkeys = hotkeyActions(combo, bindings)
+	if err := hotkey.Restart(l.hotkeyBindings()); err != nil {
+		log.Printf("Config reload: hotkeys not changed: %v", err)
+		l.hotkeys = previous
+		_ = popup.Show(fmt.Sprintf("Invalid hotkey: %v", err))
+		return
+	}
+	for _, action := range l.hotkeys {
+		warnHotkeyConflict(action.combo)
+	}
+	l.setDefaultHotkey(combo)
+}
+
+// watchConfig polls the .env file and asks Run to reload once a change has
+// been stable for one poll, so an editor's burst of writes triggers a single
+// reload.
+func (l *Loop) watchConfig(ctx context.Context) {
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	last, _ := loadConfigSourceState(configSourceState{}, config.EnvPath())
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		state, err := loadConfigSourceState(last, config.EnvPath())
+		if err != nil {
+			logutil.Debug("watchConfig: %v", err)
+			continue
+		}
+		if !configSourceStateEqual(last, state) {
+			last, pending = state, true
+			continue
+		}
+		if pending {
+			pending = false
+			select {
+			case l.reloadCh <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// configChanges describes the settings that differ between two configs for
+// the reload log. The API key itself is never logged.
+func configChanges(prev, next *config.Config) []string {
+	var changes []string
+	add := func(name string, from, to any) {
+		if a, b := fmt.Sprint(from), fmt.Sprint(to); a != b {
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", name, a, b))
+		}
+	}
+	if prev.APIKey != next.APIKey {
+		changes = append(changes, "API key changed")
+	}
+	add("LLM_BACKEND", prev.LLMBackend, next.LLMBackend)
+	add("MODEL", prev.Model, next.Model)
+	add("MODELS", prev.Models, next.Models)
+	add("PROVIDERS", prev.Providers, next.Providers)
+	add("PROVIDER_STRATEGY", prev.ProviderStrategy, next.ProviderStrategy)
+	add("PROVIDER_QUANTIZATIONS", prev.ProviderQuants, next.ProviderQuants)
+	add("OCR_LANGUAGE", prev.OCRLanguage, next.OCRLanguage)
+	add("OCR_OUTPUT_FORMAT", prev.OCROutputFormat, next.OCROutputFormat)
+	add("MODE", prev.Mode, next.Mode)
+	add("OCR_MAX_TOKENS", prev.OCRMaxTokens, next.OCRMaxTokens)
+	add("OCR_TEMPERATURE", prev.OCRTemperature, next.OCRTemperature)
+	add("OCR_TRIM", prev.OCRTrim, next.OCRTrim)
+	add("OCR_LINE_ENDINGS", prev.OCRLineEndings, next.OCRLineEndings)
+	add("OCR_REPLACEMENTS", prev.OCRReplacements, next.OCRReplacements)
+	add("TRANSLATE_TO", prev.TranslateTo, next.TranslateTo)
+	add("TRANSLATE_KEEP_ORIGINAL", prev.TranslateKeepOrig, next.TranslateKeepOrig)
+	add("OPENROUTER_BASE_URL", prev.BaseURL, next.BaseURL)
+	add("HTTP_REFERER", prev.HTTPReferer, next.HTTPReferer)
+	add("APP_TITLE", prev.AppTitle, next.AppTitle)
+	add("DEFAULT_MODE", prev.DefaultMode, next.DefaultMode)
+	add("OCR_DEADLINE_SEC", prev.OCRDeadlineSec, next.OCRDeadlineSec)
+	add("CLIPBOARD_APPEND_SEPARATOR", strconv.Quote(prev.ClipboardAppendSep), strconv.Quote(next.ClipboardAppendSep))
+	add("CLIPBOARD_FORMAT", prev.ClipboardFormat, next.ClipboardFormat)
+	add("CLIPBOARD_CLEAR_AFTER_SEC", prev.ClipboardClearAfter, next.ClipboardClearAfter)
+	add("TYPE_DELAY_MS", prev.TypeDelayMs, next.TypeDelayMs)
+	add("HOTKEY", prev.Hotkey, next.Hotkey)
+	add("HOTKEYS", prev.Hotkeys, next.Hotkeys)
+	return changes
+}
+
+// initLLM is the production llmInit; Init keeps the previous LLM settings
+// when the new ones are invalid.
+func initLLM(cfg *llm.Config) {
+	if err := llm.Init(cfg); err != nil {
+		log.Printf("Config reload: LLM settings not changed: %v", err)
+		_ = popup.Show(fmt.Sprintf("Config reload failed: %v", err))
+	}
+}
+
+// setDefaultHotkey records combo as HOTKEY and updates the tray About
+// dialog and tooltip.
+func (l *Loop) setDefaultHotkey(combo string) {
+	l.hotkeyMu.Lock()
+	l.defaultHotkey = combo
+	l.hotkeyMu.Unlock()
+
+	tray.SetAboutHotkey(combo)
+	l.defaultTooltip = fmt.Sprintf("Screen OCR Tool - Press %s to capture", combo)
+	if l.isIdle() {
+		tray.UpdateTooltip(l.idleTooltip())
+	}
+}
@@ -0,0 +1,39 @@
+package eventloop
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const tooltipPreviewRunes = 40
+
+// lastResultTooltip formats the idle tooltip shown after a successful OCR.
+// When withText is false only the size and age of the result are shown.
+func lastResultTooltip(base, text string, withText bool, ago time.Duration) string {
+	if !withText {
+		return fmt.Sprintf("%s\nLast OCR: %d chars (%s)", base, len([]rune(text)), formatAgo(ago))
+	}
+	return fmt.Sprintf("%s\nLast: %q (%s)", base, previewText(text, tooltipPreviewRunes), formatAgo(ago))
+}
+
+// previewText collapses whitespace and truncates text to max runes.
+func previewText(text string, max int) string {
+	collapsed := strings.Join(strings.Fields(text), " ")
+	r := []rune(collapsed)
+	if len(r) <= max {
+		return collapsed
+	}
+	return string(r[:max]) + "…"
+}
+
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	default:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	}
+}
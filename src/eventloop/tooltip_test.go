@@ -0,0 +1,49 @@
+package eventloop
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastResultTooltip(t *testing.T) {
+	text := "Hello\nworld, this is a fairly long OCR result that needs truncation"
+
+	got := lastResultTooltip("Screen OCR Tool", text, true, 2*time.Minute)
+	want := "Screen OCR Tool\nLast: \"Hello world, this is a fairly long OCR r…\" (2m ago)"
+	if got != want {
+		t.Fatalf("tooltip = %q, want %q", got, want)
+	}
+
+	got = lastResultTooltip("Screen OCR Tool", text, false, 10*time.Second)
+	if strings.Contains(got, "Hello") {
+		t.Fatalf("tooltip without text preview leaked text: %q", got)
+	}
+	if !strings.Contains(got, "just now") {
+		t.Fatalf("tooltip missing relative time: %q", got)
+	}
+}
+
+func TestFormatAgo(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second: "just now",
+		5 * time.Minute:  "5m ago",
+		3 * time.Hour:    "3h ago",
+	}
+	for d, want := range tests {
+		if got := formatAgo(d); got != want {
+			t.Fatalf("formatAgo(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestIdleTooltipShowsSwitchedModel(t *testing.T) {
+	l := &Loop{defaultTooltip: "Screen OCR Tool"}
+	if got := l.idleTooltip(); got != "Screen OCR Tool" {
+		t.Fatalf("tooltip = %q, want the default", got)
+	}
+	l.activeModel = "cheap/model"
+	if got := l.idleTooltip(); got != "Screen OCR Tool\nModel: cheap/model" {
+		t.Fatalf("tooltip = %q, want the model on a second line", got)
+	}
+}
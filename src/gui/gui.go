@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"errors"
 	"fmt"
 	"log"
 
@@ -9,6 +10,15 @@ import (
 	"github.com/getlantern/systray"
 )
 
+// ErrUnsupported is returned by region selection on platforms without an
+// overlay implementation.
+var ErrUnsupported = errors.New("region selection not supported on this platform")
+
+// ErrSelectionCancelled is returned by region selection when the user
+// dismisses the overlay with ESC. It is not a failure and should not be
+// reported as one.
+var ErrSelectionCancelled = errors.New("selection cancelled")
+
 func Init() {
 	// Initialize GUI package if needed
 }
@@ -18,12 +28,35 @@ func StartRegionSelection() (screenshot.Region, error) {
 	return StartRegionSelectionWithMode("rectangle")
 }
 
+// SelectionOptions configures the interactive region selection overlay.
+type SelectionOptions struct {
+	// Mode is the initial selection mode ("rectangle" or "lasso").
+	Mode string
+	// Guides draws a cursor crosshair and rule-of-thirds lines.
+	Guides bool
+	// BackgroundScale renders the overlay background at reduced resolution
+	// (0 < s < 1) and stretches it to fit. Zero or 1 means full resolution.
+	BackgroundScale float64
+	// Magnifier draws an 8x zoom of the pixels around the cursor in a
+	// corner of the monitor, away from the cursor.
+	Magnifier bool
+}
+
 // StartRegionSelectionWithMode starts region selection with an initial mode.
 func StartRegionSelectionWithMode(defaultMode string) (screenshot.Region, error) {
+	return StartRegionSelectionWithOptions(SelectionOptions{Mode: defaultMode})
+}
+
+// StartRegionSelectionWithOptions starts region selection configured by opts.
+func StartRegionSelectionWithOptions(opts SelectionOptions) (screenshot.Region, error) {
 	log.Printf("Starting interactive region selection...")
 
 	// Use platform-specific region selection
-	region, err := StartInteractiveRegionSelectionWithMode(defaultMode)
+	region, err := StartInteractiveRegionSelectionWithOptions(opts)
+	if errors.Is(err, ErrSelectionCancelled) {
+		log.Printf("Region selection cancelled")
+		return screenshot.Region{}, err
+	}
 	if err != nil {
 		log.Printf("Interactive region selection failed: %v", err)
 		return screenshot.Region{}, err
@@ -0,0 +1,29 @@
+package gui
+
+const (
+	// magnifierZoom is the magnification factor of the overlay loupe.
+	magnifierZoom = 8
+	// magnifierSource is the side, in screen pixels, of the area magnified.
+	magnifierSource = 21
+	// magnifierSize is the side of the loupe on screen.
+	magnifierSize = magnifierSource * magnifierZoom
+	// magnifierMargin keeps the loupe off the monitor edges.
+	magnifierMargin = 16
+)
+
+// magnifierPosition places the loupe in the corner of the monitor
+// (left, top, right, bottom) diagonally opposite the cursor, so it never
+// covers the area being selected.
+func magnifierPosition(cursorX, cursorY, left, top, right, bottom int) (x, y int) {
+	if cursorX < (left+right)/2 {
+		x = right - magnifierMargin - magnifierSize
+	} else {
+		x = left + magnifierMargin
+	}
+	if cursorY < (top+bottom)/2 {
+		y = bottom - magnifierMargin - magnifierSize
+	} else {
+		y = top + magnifierMargin
+	}
+	return x, y
+}
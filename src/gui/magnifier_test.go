@@ -0,0 +1,29 @@
+package gui
+
+import "testing"
+
+func TestMagnifierPosition(t *testing.T) {
+	// 1920x1080 monitor at the origin; loupe is 168px with a 16px margin
+	tests := []struct {
+		name             string
+		cursorX, cursorY int
+		wantX, wantY     int
+	}{
+		{name: "cursor top-left, loupe bottom-right", cursorX: 100, cursorY: 100, wantX: 1736, wantY: 896},
+		{name: "cursor bottom-right, loupe top-left", cursorX: 1800, cursorY: 1000, wantX: 16, wantY: 16},
+		{name: "cursor top-right, loupe bottom-left", cursorX: 1800, cursorY: 100, wantX: 16, wantY: 896},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := magnifierPosition(tt.cursorX, tt.cursorY, 0, 0, 1920, 1080)
+			if x != tt.wantX || y != tt.wantY {
+				t.Fatalf("magnifierPosition() = (%d,%d), want (%d,%d)", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+
+	// Secondary monitor left of the primary (negative client offset)
+	if x, y := magnifierPosition(-100, 900, -1920, 0, 0, 1080); x != -1904 || y != 16 {
+		t.Fatalf("magnifierPosition() on left monitor = (%d,%d), want (-1904,16)", x, y)
+	}
+}
@@ -2,10 +2,7 @@
 
 package gui
 
-import (
-	"fmt"
-	"screen-ocr-llm/src/screenshot"
-)
+import "screen-ocr-llm/src/screenshot"
 
 // StartInteractiveRegionSelection is a stub for non-Windows platforms
 func StartInteractiveRegionSelection() (screenshot.Region, error) {
@@ -14,5 +11,10 @@ func StartInteractiveRegionSelection() (screenshot.Region, error) {
 
 // StartInteractiveRegionSelectionWithMode is a stub for non-Windows platforms.
 func StartInteractiveRegionSelectionWithMode(defaultMode string) (screenshot.Region, error) {
-	return screenshot.Region{}, fmt.Errorf("interactive region selection not implemented for this platform")
+	return StartInteractiveRegionSelectionWithOptions(SelectionOptions{Mode: defaultMode})
+}
+
+// StartInteractiveRegionSelectionWithOptions is a stub for non-Windows platforms.
+func StartInteractiveRegionSelectionWithOptions(opts SelectionOptions) (screenshot.Region, error) {
+	return screenshot.Region{}, ErrUnsupported
 }
@@ -24,23 +24,31 @@ var embeddedLassoCursorData []byte
 
 // Global state for the simple overlay
 var (
-	simpleOverlayHwnd          win.HWND
-	simpleIsSelecting          bool
-	simpleSelectionMode        selectionMode
-	simpleLastModeToggle       time.Time
-	simpleSpaceWasDown         bool
-	simpleEscapeWasDown        bool
-	simpleStartX, simpleStartY int32
-	simpleEndX, simpleEndY     int32
-	simpleLassoPoints          []screenshot.Point
-	simpleScreenWidth          int32
-	simpleScreenHeight         int32
-	simpleVirtualScreenX       int32
-	simpleVirtualScreenY       int32
-	simpleCrossCursor          win.HCURSOR
-	simpleHandCursor           win.HCURSOR
-	simpleLassoCursorInit      bool
-	simpleSelectionResult      chan screenshot.Region
+	simpleOverlayHwnd            win.HWND
+	simpleIsSelecting            bool
+	simpleSelectionMode          selectionMode
+	simpleLastModeToggle         time.Time
+	simpleSpaceWasDown           bool
+	simpleEscapeWasDown          bool
+	simpleStartX, simpleStartY   int32
+	simpleEndX, simpleEndY       int32
+	simpleLassoPoints            []screenshot.Point
+	simpleScreenWidth            int32
+	simpleScreenHeight           int32
+	simpleVirtualScreenX         int32
+	simpleVirtualScreenY         int32
+	simpleCrossCursor            win.HCURSOR
+	simpleHandCursor             win.HCURSOR
+	simpleLassoCursorInit        bool
+	simpleSelectionResult        chan screenshot.Region
+	simpleSelectionCancel        chan struct{}
+	simpleShowGuides             bool
+	simpleCursorX, simpleCursorY int32
+	simpleCursorKnown            bool
+	simpleShowMagnifier          bool
+	// simpleAdjusting is set once arrow keys have nudged the rectangle; the
+	// mouse no longer moves it and ENTER (not mouse-up) commits it
+	simpleAdjusting bool
 )
 
 type selectionMode int
@@ -65,6 +73,11 @@ var (
 	user32DLL                    = syscall.NewLazyDLL("user32.dll")
 	procAllowSetForegroundWindow = user32DLL.NewProc("AllowSetForegroundWindow")
 	procGetAsyncKeyState         = user32DLL.NewProc("GetAsyncKeyState")
+	procMonitorFromRect          = user32DLL.NewProc("MonitorFromRect")
+
+	shcoreDLL                  = syscall.NewLazyDLL("shcore.dll")
+	procGetProcessDpiAwareness = shcoreDLL.NewProc("GetProcessDpiAwareness")
+	procGetDpiForMonitor       = shcoreDLL.NewProc("GetDpiForMonitor")
 )
 
 // Global variables for screen capture
@@ -72,6 +85,14 @@ var (
 	screenImage   *image.RGBA
 	screenHDC     win.HDC
 	screenHBitmap win.HBITMAP
+	screenOldBmp  win.HGDIOBJ
+	// screenBgWidth and screenBgHeight are the size of the background
+	// bitmap, smaller than the window when OVERLAY_BG_SCALE is set
+	screenBgWidth  int32
+	screenBgHeight int32
+	// overlayCaptureStart is when the capture began, cleared by the first
+	// WM_PAINT once it has logged the time to first paint
+	overlayCaptureStart time.Time
 )
 
 // StartInteractiveRegionSelection creates a working overlay with screen background
@@ -81,7 +102,13 @@ func StartInteractiveRegionSelection() (screenshot.Region, error) {
 
 // StartInteractiveRegionSelectionWithMode creates a working overlay with a configured initial mode.
 func StartInteractiveRegionSelectionWithMode(defaultMode string) (screenshot.Region, error) {
+	return StartInteractiveRegionSelectionWithOptions(SelectionOptions{Mode: defaultMode})
+}
+
+// StartInteractiveRegionSelectionWithOptions creates a working overlay configured by opts.
+func StartInteractiveRegionSelectionWithOptions(opts SelectionOptions) (screenshot.Region, error) {
 	log.Printf("Starting WORKING Windows region selection...")
+	selectionStart := time.Now()
 
 	// Get screen dimensions
 	simpleScreenWidth = win.GetSystemMetrics(win.SM_CXSCREEN)
@@ -100,12 +127,11 @@ func StartInteractiveRegionSelectionWithMode(defaultMode string) (screenshot.Reg
 	log.Printf("Screen dimensions: %dx%d", simpleScreenWidth, simpleScreenHeight)
 
 	// Capture the screen first (use full virtual screen size)
-	var err error
-	screenImage, err = captureScreen(int(vw), int(vh))
-	if err != nil {
+	overlayCaptureStart = selectionStart
+	if err := captureBackground(vx, vy, vw, vh, opts.BackgroundScale); err != nil {
 		return screenshot.Region{}, fmt.Errorf("failed to capture screen: %v", err)
 	}
-	log.Printf("Screen captured successfully")
+	log.Printf("Screen captured successfully in %v", time.Since(selectionStart))
 
 	// Load cross cursor
 	simpleCrossCursor = win.LoadCursor(0, win.MAKEINTRESOURCE(win.IDC_CROSS))
@@ -125,13 +151,18 @@ func StartInteractiveRegionSelectionWithMode(defaultMode string) (screenshot.Reg
 
 	// Initialize selection state
 	simpleSelectionResult = make(chan screenshot.Region, 1)
+	simpleSelectionCancel = make(chan struct{}, 1)
 	simpleIsSelecting = false
-	simpleSelectionMode = parseSelectionMode(defaultMode)
+	simpleSelectionMode = parseSelectionMode(opts.Mode)
 	simpleLastModeToggle = time.Time{}
 	simpleSpaceWasDown = false
 	simpleEscapeWasDown = false
 	simpleLassoPoints = nil
-	log.Printf("OVERLAY: Initial selection mode: %s", selectionModeString(simpleSelectionMode))
+	simpleShowGuides = opts.Guides
+	simpleShowMagnifier = opts.Magnifier
+	simpleCursorKnown = false
+	simpleAdjusting = false
+	log.Printf("OVERLAY: Initial selection mode: %s, guides: %v", selectionModeString(simpleSelectionMode), simpleShowGuides)
 
 	// Register window class with unique name to avoid conflicts
 	classNameStr := fmt.Sprintf("WorkingOverlay_%d", time.Now().UnixNano())
@@ -188,6 +219,7 @@ func StartInteractiveRegionSelectionWithMode(defaultMode string) (screenshot.Reg
 	log.Printf("OVERLAY: SetFocus returned: %v", focusRet)
 	log.Printf("OVERLAY: Calling UpdateWindow")
 	win.UpdateWindow(simpleOverlayHwnd)
+	log.Printf("OVERLAY: Selection UI shown after %v", time.Since(selectionStart))
 
 	if timerID := win.SetTimer(simpleOverlayHwnd, overlayKeyPollTimerID, overlayKeyPollIntervalMs, 0); timerID == 0 {
 		log.Printf("OVERLAY: Failed to start keyboard poll timer")
@@ -197,6 +229,7 @@ func StartInteractiveRegionSelectionWithMode(defaultMode string) (screenshot.Reg
 
 	// Message loop
 	var msg win.MSG
+	drainQuitMessages(&msg)
 	for {
 		ret := win.GetMessage(&msg, 0, 0, 0)
 		if ret == 0 { // WM_QUIT
@@ -217,12 +250,24 @@ func StartInteractiveRegionSelectionWithMode(defaultMode string) (screenshot.Reg
 			win.DestroyWindow(simpleOverlayHwnd)
 			log.Printf("Selection completed: %+v", region)
 			return region, nil
+		case <-simpleSelectionCancel:
+			win.DestroyWindow(simpleOverlayHwnd)
+			log.Printf("Selection cancelled")
+			return screenshot.Region{}, ErrSelectionCancelled
 		default:
 		}
 	}
 
 	win.DestroyWindow(simpleOverlayHwnd)
-	return screenshot.Region{}, fmt.Errorf("selection cancelled")
+	return screenshot.Region{}, ErrSelectionCancelled
+}
+
+// drainQuitMessages removes WM_QUIT messages left in the thread queue, so a
+// stray quit cannot end the next selection before it is shown.
+func drainQuitMessages(msg *win.MSG) {
+	for win.PeekMessage(msg, 0, win.WM_QUIT, win.WM_QUIT, win.PM_REMOVE) {
+		log.Printf("OVERLAY: Discarded leftover WM_QUIT")
+	}
 }
 
 // captureScreen captures the entire screen as an RGBA image
@@ -251,6 +296,77 @@ func captureScreen(width, height int) (*image.RGBA, error) {
 	return img, nil
 }
 
+// captureBackground captures the virtual screen for the overlay background.
+// A scale below 1 (OVERLAY_BG_SCALE) is rendered straight into a reduced
+// bitmap; otherwise the full-size capture is kept in screenImage and
+// converted on the first WM_PAINT.
+func captureBackground(vx, vy, vw, vh int32, scale float64) error {
+	if scale > 0 && scale < 1 {
+		screenImage = nil
+		return captureScaledBackground(vx, vy, vw, vh, scale)
+	}
+	img, err := captureScreen(int(vw), int(vh))
+	if err != nil {
+		return err
+	}
+	screenImage = img
+	screenBgWidth = int32(img.Bounds().Dx())
+	screenBgHeight = int32(img.Bounds().Dy())
+	return nil
+}
+
+// captureScaledBackground renders the virtual screen straight into a DIB of
+// scale times its size with one StretchBlt, and keeps it as the cached
+// background, so a reduced background needs neither a full-size capture nor
+// a resize on the CPU.
+func captureScaledBackground(vx, vy, vw, vh int32, scale float64) error {
+	releaseScreenBackground()
+	width := simpleMax(1, int32(float64(vw)*scale))
+	height := simpleMax(1, int32(float64(vh)*scale))
+
+	screenDC := win.GetDC(0)
+	if screenDC == 0 {
+		return fmt.Errorf("failed to get screen DC")
+	}
+	defer win.ReleaseDC(0, screenDC)
+
+	memDC := win.CreateCompatibleDC(screenDC)
+	if memDC == 0 {
+		return fmt.Errorf("failed to create memory DC")
+	}
+	bitmapInfo := win.BITMAPINFO{
+		BmiHeader: win.BITMAPINFOHEADER{
+			BiSize:        uint32(unsafe.Sizeof(win.BITMAPINFOHEADER{})),
+			BiWidth:       width,
+			BiHeight:      -height, // Negative for top-down
+			BiPlanes:      1,
+			BiBitCount:    32,
+			BiCompression: win.BI_RGB,
+		},
+	}
+	var pBits unsafe.Pointer
+	hBitmap := win.CreateDIBSection(memDC, &bitmapInfo.BmiHeader, win.DIB_RGB_COLORS, &pBits, 0, 0)
+	if hBitmap == 0 {
+		win.DeleteDC(memDC)
+		return fmt.Errorf("failed to create %dx%d background bitmap", width, height)
+	}
+	screenOldBmp = win.SelectObject(memDC, win.HGDIOBJ(hBitmap))
+	screenHDC = memDC
+	screenHBitmap = hBitmap
+
+	// HALFTONE averages the source pixels, so text stays legible when shrunk
+	win.SetStretchBltMode(memDC, win.HALFTONE)
+	win.SetBrushOrgEx(memDC, 0, 0, nil)
+	if !win.StretchBlt(memDC, 0, 0, width, height, screenDC, vx, vy, vw, vh, win.SRCCOPY|win.CAPTUREBLT) {
+		releaseScreenBackground()
+		return fmt.Errorf("failed to copy the screen into the background bitmap")
+	}
+	screenBgWidth = width
+	screenBgHeight = height
+	log.Printf("OVERLAY: Background captured at %.2f scale: %dx%d", scale, width, height)
+	return nil
+}
+
 // workingWndProc handles window messages for the working overlay
 func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	// Log all messages for debugging
@@ -260,17 +376,19 @@ func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 
 	// Special logging for mouse events
 	if msg == win.WM_LBUTTONDOWN || msg == win.WM_LBUTTONUP || msg == win.WM_RBUTTONDOWN {
-		log.Printf("MOUSE EVENT: 0x%x at (%d, %d)", msg, win.LOWORD(uint32(lParam)), win.HIWORD(uint32(lParam)))
+		x, y := lparamPoint(lParam)
+		log.Printf("MOUSE EVENT: 0x%x at (%d, %d)", msg, x, y)
 	}
 
 	switch msg {
 	case win.WM_LBUTTONDOWN:
-		x := int32(win.LOWORD(uint32(lParam)))
-		y := int32(win.HIWORD(uint32(lParam)))
+		x, y := lparamPoint(lParam)
 		log.Printf("Mouse down at (%d, %d), mode=%s", x, y, selectionModeString(simpleSelectionMode))
 
 		win.SetCapture(hwnd)
 		simpleIsSelecting = true
+		simpleAdjusting = false
+		simpleCursorX, simpleCursorY, simpleCursorKnown = x, y, true
 		if simpleSelectionMode == modeLasso {
 			simpleLassoPoints = []screenshot.Point{{X: int(x), Y: int(y)}}
 			simpleStartX = x
@@ -290,9 +408,12 @@ func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 		return 0
 
 	case win.WM_MOUSEMOVE:
+		x, y := lparamPoint(lParam)
+		// Tracked for the guides crosshair and the live size label
+		simpleCursorX = x
+		simpleCursorY = y
+		simpleCursorKnown = true
 		if simpleIsSelecting {
-			x := int32(win.LOWORD(uint32(lParam)))
-			y := int32(win.HIWORD(uint32(lParam)))
 			if simpleSelectionMode == modeLasso {
 				simpleEndX = x
 				simpleEndY = y
@@ -305,22 +426,27 @@ func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 						simpleLassoPoints = append(simpleLassoPoints, newPoint)
 					}
 				}
-			} else {
+			} else if !simpleAdjusting {
 				simpleEndX = x
 				simpleEndY = y
 			}
-
-			// Force immediate repaint to show selection
+		}
+		if simpleIsSelecting || simpleShowGuides || simpleShowMagnifier {
+			// Force immediate repaint to show selection, guides and magnifier
 			win.InvalidateRect(hwnd, nil, false)
 			win.UpdateWindow(hwnd)
 		}
 		return 0
 
 	case win.WM_LBUTTONUP:
+		if simpleIsSelecting && simpleAdjusting {
+			// Keyboard-adjusted rectangle: keep it until ENTER or ESC
+			win.ReleaseCapture()
+			return 0
+		}
 		if simpleIsSelecting {
 			win.ReleaseCapture()
-			x := int32(win.LOWORD(uint32(lParam)))
-			y := int32(win.HIWORD(uint32(lParam)))
+			x, y := lparamPoint(lParam)
 			simpleEndX = x
 			simpleEndY = y
 
@@ -356,48 +482,15 @@ func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 					return 0
 				}
 
-				polygon := make([]screenshot.Point, len(simpleLassoPoints))
-				for i, p := range simpleLassoPoints {
-					polygon[i] = screenshot.Point{
-						X: p.X + int(simpleVirtualScreenX),
-						Y: p.Y + int(simpleVirtualScreenY),
-					}
-				}
-
-				region := screenshot.Region{
-					X:       int(left) + int(simpleVirtualScreenX),
-					Y:       int(top) + int(simpleVirtualScreenY),
-					Width:   int(width),
-					Height:  int(height),
-					Polygon: polygon,
-				}
+				region := selectionRegion(left, top, width, height, simpleVirtualScreenX, simpleVirtualScreenY, simpleLassoPoints)
 				log.Printf("Final lasso region with virtual screen offset: X=%d Y=%d W=%d H=%d points=%d", region.X, region.Y, region.Width, region.Height, len(region.Polygon))
+				region = scaleSelection(region)
 				simpleSelectionResult <- region
 				return 0
 			}
 
-			simpleIsSelecting = false
-
-			// Calculate region
-			left := simpleMin(simpleStartX, simpleEndX)
-			top := simpleMin(simpleStartY, simpleEndY)
-			width := simpleAbs(simpleEndX - simpleStartX)
-			height := simpleAbs(simpleEndY - simpleStartY)
-
-			log.Printf("Mouse up at (%d, %d), selection: %d,%d,%d,%d", x, y, left, top, width, height)
-
-			if width > minSelectionSpan && height > minSelectionSpan {
-				region := screenshot.Region{
-					X:      int(left) + int(simpleVirtualScreenX),
-					Y:      int(top) + int(simpleVirtualScreenY),
-					Width:  int(width),
-					Height: int(height),
-				}
-				log.Printf("Final region with virtual screen offset: X=%d Y=%d W=%d H=%d", region.X, region.Y, region.Width, region.Height)
-				simpleSelectionResult <- region
-			} else {
-				log.Printf("Selection too small, ignoring")
-			}
+			log.Printf("Mouse up at (%d, %d)", x, y)
+			commitRectSelection()
 		}
 		return 0
 
@@ -408,12 +501,14 @@ func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 		log.Printf("WM_PAINT called, isSelecting=%v", simpleIsSelecting)
 
 		// Draw the captured screen as background
-		if screenImage != nil {
-			drawScreenBackground(hdc)
-		}
+		drawScreenBackground(hdc)
 
 		drawSelectionHints(hdc)
 
+		if simpleShowGuides {
+			drawGuides(hdc)
+		}
+
 		if simpleSelectionMode == modeLasso {
 			if simpleIsSelecting && len(simpleLassoPoints) > 1 {
 				drawLassoPolyline(hdc, simpleLassoPoints)
@@ -423,7 +518,19 @@ func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 			drawSelectionRectangle(hdc, simpleStartX, simpleStartY, simpleEndX, simpleEndY)
 		}
 
+		if simpleIsSelecting {
+			drawSelectionLabel(hdc)
+		}
+
+		if simpleShowMagnifier {
+			drawMagnifier(hdc)
+		}
+
 		win.EndPaint(hwnd, &ps)
+		if !overlayCaptureStart.IsZero() {
+			log.Printf("OVERLAY: First paint %v after capture start", time.Since(overlayCaptureStart))
+			overlayCaptureStart = time.Time{}
+		}
 		return 0
 
 	case win.WM_SETCURSOR:
@@ -443,6 +550,13 @@ func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 
 	case win.WM_KEYDOWN:
 		switch wParam {
+		case win.VK_LEFT, win.VK_RIGHT, win.VK_UP, win.VK_DOWN:
+			nudgeRectSelection(hwnd, wParam)
+		case win.VK_RETURN:
+			if simpleIsSelecting && simpleSelectionMode == modeRect {
+				win.ReleaseCapture()
+				commitRectSelection()
+			}
 		case win.VK_ESCAPE:
 			simpleEscapeWasDown = true
 			cancelSelection()
@@ -468,17 +582,122 @@ func workingWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	case win.WM_DESTROY:
 		log.Printf("WM_DESTROY received")
 		win.KillTimer(hwnd, overlayKeyPollTimerID)
+		releaseScreenBackground()
 		// Do NOT PostQuitMessage here. In the success path we return from
 		// StartInteractiveRegionSelection() as soon as we have the region,
 		// and posting WM_QUIT here would leave a leftover WM_QUIT in the
 		// thread queue that the next invocation would consume immediately,
-		// causing an instant "selection cancelled" on the second hotkey.
+		// causing an instant "selection cancelled" on the second hotkey;
+		// cancelSelection signals the loop through simpleSelectionCancel.
 		return 0
 	}
 
 	return win.DefWindowProc(hwnd, msg, wParam, lParam)
 }
 
+// commitRectSelection ends the rectangle drag and reports the region if it
+// is large enough.
+func commitRectSelection() {
+	simpleIsSelecting = false
+	simpleAdjusting = false
+
+	// Calculate region
+	left := simpleMin(simpleStartX, simpleEndX)
+	top := simpleMin(simpleStartY, simpleEndY)
+	width := simpleAbs(simpleEndX - simpleStartX)
+	height := simpleAbs(simpleEndY - simpleStartY)
+
+	log.Printf("Rect selection: %d,%d,%d,%d", left, top, width, height)
+
+	if width > minSelectionSpan && height > minSelectionSpan {
+		region := selectionRegion(left, top, width, height, simpleVirtualScreenX, simpleVirtualScreenY, nil)
+		log.Printf("Final region with virtual screen offset: X=%d Y=%d W=%d H=%d", region.X, region.Y, region.Width, region.Height)
+		region = scaleSelection(region)
+		simpleSelectionResult <- region
+	} else {
+		log.Printf("Selection too small, ignoring")
+	}
+}
+
+// scaleSelection maps a selection from overlay coordinates to physical
+// capture coordinates using the DPI of the monitor under it. When the process
+// is per-monitor DPI aware (the normal case) the scale is 1 and the region is
+// returned unchanged.
+func scaleSelection(region screenshot.Region) screenshot.Region {
+	scale := selectionScale(region)
+	if scale == 1 {
+		return region
+	}
+	scaled := screenshot.ScaleRegion(region, scale)
+	log.Printf("Scaled selection by %.2f for monitor DPI: X=%d Y=%d W=%d H=%d", scale, scaled.X, scaled.Y, scaled.Width, scaled.Height)
+	return scaled
+}
+
+// selectionScale returns the DPI scale for region, or 1 if it can't be
+// determined (e.g. shcore.dll is unavailable before Windows 8.1).
+func selectionScale(region screenshot.Region) float64 {
+	if procGetProcessDpiAwareness.Find() != nil || procGetDpiForMonitor.Find() != nil {
+		return 1
+	}
+
+	var awareness uint32
+	if hr, _, _ := procGetProcessDpiAwareness.Call(0, uintptr(unsafe.Pointer(&awareness))); hr != 0 {
+		return 1
+	}
+	if screenshot.DPIAwareness(awareness) == screenshot.DPIPerMonitorAware {
+		return 1
+	}
+
+	rect := win.RECT{
+		Left: int32(region.X), Top: int32(region.Y),
+		Right: int32(region.X + region.Width), Bottom: int32(region.Y + region.Height),
+	}
+	monitor, _, _ := procMonitorFromRect.Call(uintptr(unsafe.Pointer(&rect)), win.MONITOR_DEFAULTTONEAREST)
+	if monitor == 0 {
+		return 1
+	}
+	var dpiX, dpiY uint32
+	// MDT_EFFECTIVE_DPI = 0
+	if hr, _, _ := procGetDpiForMonitor.Call(monitor, 0, uintptr(unsafe.Pointer(&dpiX)), uintptr(unsafe.Pointer(&dpiY))); hr != 0 {
+		return 1
+	}
+
+	hdc := win.GetDC(0)
+	systemDPI := int(win.GetDeviceCaps(hdc, win.LOGPIXELSY))
+	win.ReleaseDC(0, hdc)
+
+	return screenshot.DPIScale(screenshot.DPIAwareness(awareness), int(dpiY), systemDPI)
+}
+
+// nudgeRectSelection moves the rectangle being selected by 1px per arrow
+// key, or resizes its active corner while Shift is held.
+func nudgeRectSelection(hwnd win.HWND, vk uintptr) {
+	if !simpleIsSelecting || simpleSelectionMode != modeRect {
+		return
+	}
+	var dx, dy int32
+	switch vk {
+	case win.VK_LEFT:
+		dx = -1
+	case win.VK_RIGHT:
+		dx = 1
+	case win.VK_UP:
+		dy = -1
+	case win.VK_DOWN:
+		dy = 1
+	}
+	resize := win.GetKeyState(win.VK_SHIFT) < 0
+
+	var rc win.RECT
+	win.GetClientRect(hwnd, &rc)
+	simpleStartX, simpleStartY, simpleEndX, simpleEndY = nudgeSelection(
+		simpleStartX, simpleStartY, simpleEndX, simpleEndY, dx, dy, rc.Right-rc.Left, rc.Bottom-rc.Top, resize)
+	simpleAdjusting = true
+
+	win.InvalidateRect(hwnd, nil, false)
+	win.UpdateWindow(hwnd)
+}
+
 func selectionModeString(mode selectionMode) string {
 	if mode == modeLasso {
 		return "lasso"
@@ -575,6 +794,7 @@ func toggleSelectionMode(hwnd win.HWND) {
 		simpleIsSelecting = false
 	}
 	simpleLassoPoints = nil
+	simpleAdjusting = false
 	if simpleSelectionMode == modeRect {
 		simpleSelectionMode = modeLasso
 	} else {
@@ -598,9 +818,15 @@ func setModeCursor() {
 	}
 }
 
+// cancelSelection ends the message loop with ErrSelectionCancelled. It
+// signals through a channel rather than WM_QUIT so nothing is left in the
+// thread queue for the next selection.
 func cancelSelection() {
 	log.Printf("Escape pressed, cancelling selection")
-	win.PostQuitMessage(0)
+	select {
+	case simpleSelectionCancel <- struct{}{}:
+	default:
+	}
 }
 
 func pointDistanceSquared(a, b screenshot.Point) int {
@@ -714,9 +940,146 @@ func drawLassoPolyline(hdc win.HDC, points []screenshot.Point) {
 	win.DeleteObject(win.HGDIOBJ(redPen))
 }
 
+// drawGuides draws a full-screen crosshair through the cursor and
+// rule-of-thirds lines inside the rectangle being dragged.
+func drawGuides(hdc win.HDC) {
+	gdi32 := syscall.NewLazyDLL("gdi32.dll")
+	createPen := gdi32.NewProc("CreatePen")
+
+	guidePen, _, _ := createPen.Call(win.PS_DOT, 1, 0xFFFF00)
+	oldPen := win.SelectObject(hdc, win.HGDIOBJ(guidePen))
+	win.SetBkMode(hdc, win.TRANSPARENT)
+
+	if simpleCursorKnown {
+		var rc win.RECT
+		win.GetClientRect(simpleOverlayHwnd, &rc)
+		win.MoveToEx(hdc, int(rc.Left), int(simpleCursorY), nil)
+		win.LineTo(hdc, rc.Right, simpleCursorY)
+		win.MoveToEx(hdc, int(simpleCursorX), int(rc.Top), nil)
+		win.LineTo(hdc, simpleCursorX, rc.Bottom)
+	}
+
+	if simpleIsSelecting && simpleSelectionMode == modeRect {
+		left := simpleMin(simpleStartX, simpleEndX)
+		top := simpleMin(simpleStartY, simpleEndY)
+		right := simpleMax(simpleStartX, simpleEndX)
+		bottom := simpleMax(simpleStartY, simpleEndY)
+		for i := int32(1); i <= 2; i++ {
+			x := left + (right-left)*i/3
+			y := top + (bottom-top)*i/3
+			win.MoveToEx(hdc, int(x), int(top), nil)
+			win.LineTo(hdc, x, bottom)
+			win.MoveToEx(hdc, int(left), int(y), nil)
+			win.LineTo(hdc, right, y)
+		}
+	}
+
+	win.SelectObject(hdc, oldPen)
+	win.DeleteObject(win.HGDIOBJ(guidePen))
+}
+
+// drawSelectionLabel draws the live "WxH  (x, y)" readout next to the cursor
+// on a black box with a white border so it stays legible on any background.
+func drawSelectionLabel(hdc win.HDC) {
+	if !simpleCursorKnown {
+		return
+	}
+	var width, height int32
+	if simpleSelectionMode == modeLasso {
+		if len(simpleLassoPoints) == 0 {
+			return
+		}
+		left, top, right, bottom := polygonBounds(simpleLassoPoints)
+		width, height = right-left, bottom-top
+	} else {
+		width = simpleAbs(simpleEndX - simpleStartX)
+		height = simpleAbs(simpleEndY - simpleStartY)
+	}
+
+	label := selectionLabel(int(width), int(height),
+		int(simpleCursorX+simpleVirtualScreenX), int(simpleCursorY+simpleVirtualScreenY))
+	text := syscall.StringToUTF16Ptr(label)
+	textLen := int32(len(label))
+
+	var size win.SIZE
+	win.GetTextExtentPoint32(hdc, text, textLen, &size)
+	const pad = 4
+	boxW, boxH := size.CX+2*pad, size.CY+2*pad
+
+	var rc win.RECT
+	win.GetClientRect(simpleOverlayHwnd, &rc)
+	x, y := labelPosition(int(simpleCursorX), int(simpleCursorY), int(boxW), int(boxH), int(rc.Right-rc.Left), int(rc.Bottom-rc.Top))
+
+	oldPen := win.SelectObject(hdc, win.GetStockObject(win.WHITE_PEN))
+	oldBrush := win.SelectObject(hdc, win.GetStockObject(win.BLACK_BRUSH))
+	win.Rectangle_(hdc, int32(x), int32(y), int32(x)+boxW, int32(y)+boxH)
+	win.SelectObject(hdc, oldPen)
+	win.SelectObject(hdc, oldBrush)
+
+	win.SetBkMode(hdc, win.TRANSPARENT)
+	win.SetTextColor(hdc, win.COLORREF(0x00FFFF))
+	win.TextOut(hdc, int32(x)+pad, int32(y)+pad, text, textLen)
+}
+
+// drawMagnifier draws a magnifierZoom x view of the captured screen around
+// the cursor in the corner of the cursor's monitor away from it, sampled
+// from the cached background DC, with the cursor pixel outlined.
+func drawMagnifier(hdc win.HDC) {
+	if !simpleCursorKnown || screenHDC == 0 {
+		return
+	}
+
+	// Monitor under the cursor, in client coordinates
+	cursorRect := win.RECT{
+		Left: simpleCursorX + simpleVirtualScreenX, Top: simpleCursorY + simpleVirtualScreenY,
+		Right: simpleCursorX + simpleVirtualScreenX + 1, Bottom: simpleCursorY + simpleVirtualScreenY + 1,
+	}
+	monitor, _, _ := procMonitorFromRect.Call(uintptr(unsafe.Pointer(&cursorRect)), win.MONITOR_DEFAULTTONEAREST)
+	mi := win.MONITORINFO{CbSize: uint32(unsafe.Sizeof(win.MONITORINFO{}))}
+	if monitor == 0 || !win.GetMonitorInfo(win.HMONITOR(monitor), &mi) {
+		return
+	}
+	mon := mi.RcMonitor
+	x, y := magnifierPosition(int(simpleCursorX), int(simpleCursorY),
+		int(mon.Left-simpleVirtualScreenX), int(mon.Top-simpleVirtualScreenY),
+		int(mon.Right-simpleVirtualScreenX), int(mon.Bottom-simpleVirtualScreenY))
+
+	// Map the source square into the (possibly down-scaled) background
+	var rc win.RECT
+	win.GetClientRect(simpleOverlayHwnd, &rc)
+	clientW, clientH := rc.Right-rc.Left, rc.Bottom-rc.Top
+	if clientW <= 0 || clientH <= 0 {
+		return
+	}
+	half := int32(magnifierSource / 2)
+	srcX := (simpleCursorX - half) * screenBgWidth / clientW
+	srcY := (simpleCursorY - half) * screenBgHeight / clientH
+	srcW := simpleMax(1, magnifierSource*screenBgWidth/clientW)
+	srcH := simpleMax(1, magnifierSource*screenBgHeight/clientH)
+
+	win.SetStretchBltMode(hdc, win.COLORONCOLOR)
+	win.StretchBlt(hdc, int32(x), int32(y), magnifierSize, magnifierSize, screenHDC, srcX, srcY, srcW, srcH, win.SRCCOPY)
+
+	gdi32 := syscall.NewLazyDLL("gdi32.dll")
+	createPen := gdi32.NewProc("CreatePen")
+	redPen, _, _ := createPen.Call(0, 1, 0x0000FF)
+	oldBrush := win.SelectObject(hdc, win.GetStockObject(win.NULL_BRUSH))
+	oldPen := win.SelectObject(hdc, win.GetStockObject(win.WHITE_PEN))
+	win.Rectangle_(hdc, int32(x)-1, int32(y)-1, int32(x)+magnifierSize+1, int32(y)+magnifierSize+1)
+
+	// Outline the pixel under the cursor
+	win.SelectObject(hdc, win.HGDIOBJ(redPen))
+	cx, cy := int32(x)+half*magnifierZoom, int32(y)+half*magnifierZoom
+	win.Rectangle_(hdc, cx, cy, cx+magnifierZoom+1, cy+magnifierZoom+1)
+
+	win.SelectObject(hdc, oldPen)
+	win.SelectObject(hdc, oldBrush)
+	win.DeleteObject(win.HGDIOBJ(redPen))
+}
+
 func drawSelectionHints(hdc win.HDC) {
-	line1 := "ESC cancel   SPACE toggle lasso"
-	line2 := "Rect mode: click and drag"
+	line1 := "ESC cancel   SPACE toggle lasso   ENTER confirm"
+	line2 := "Rect mode: click and drag; arrows nudge, Shift+arrows resize"
 	if simpleSelectionMode == modeLasso {
 		line2 = "Lasso mode: drag and release near start to close"
 	}
@@ -727,15 +1090,34 @@ func drawSelectionHints(hdc win.HDC) {
 	win.TextOut(hdc, 16, 38, syscall.StringToUTF16Ptr(line2), int32(len(line2)))
 }
 
-// drawScreenBackground draws the captured screen as background
+// drawScreenBackground draws the captured screen as background.
+// The converted bitmap is cached for the lifetime of the overlay so that
+// repaints driven by mouse movement only cost a BitBlt.
 func drawScreenBackground(hdc win.HDC) {
-	if screenImage == nil {
+	if screenHDC == 0 && (screenImage == nil || !cacheScreenBackground(hdc)) {
 		return
 	}
 
+	var rc win.RECT
+	win.GetClientRect(simpleOverlayHwnd, &rc)
+	if rc.Right-rc.Left == screenBgWidth && rc.Bottom-rc.Top == screenBgHeight {
+		win.BitBlt(hdc, 0, 0, screenBgWidth, screenBgHeight, screenHDC, 0, 0, win.SRCCOPY)
+		return
+	}
+
+	// Down-scaled background (OVERLAY_BG_SCALE): stretch it over the window.
+	win.SetStretchBltMode(hdc, win.COLORONCOLOR)
+	win.StretchBlt(hdc, 0, 0, rc.Right-rc.Left, rc.Bottom-rc.Top, screenHDC, 0, 0, screenBgWidth, screenBgHeight, win.SRCCOPY)
+}
+
+// cacheScreenBackground converts screenImage into a DIB selected into a
+// memory DC kept in screenHDC/screenHBitmap.
+func cacheScreenBackground(hdc win.HDC) bool {
 	// Create a compatible DC and bitmap for the screen image
 	memDC := win.CreateCompatibleDC(hdc)
-	defer win.DeleteDC(memDC)
+	if memDC == 0 {
+		return false
+	}
 
 	// Create bitmap from screen image
 	bounds := screenImage.Bounds()
@@ -757,13 +1139,14 @@ func drawScreenBackground(hdc win.HDC) {
 	var pBits unsafe.Pointer
 	hBitmap := win.CreateDIBSection(memDC, &bitmapInfo.BmiHeader, win.DIB_RGB_COLORS, &pBits, 0, 0)
 	if hBitmap == 0 {
-		return
+		win.DeleteDC(memDC)
+		return false
 	}
-	defer win.DeleteObject(win.HGDIOBJ(hBitmap))
 
 	// Select bitmap into memory DC
-	oldBitmap := win.SelectObject(memDC, win.HGDIOBJ(hBitmap))
-	defer win.SelectObject(memDC, oldBitmap)
+	screenOldBmp = win.SelectObject(memDC, win.HGDIOBJ(hBitmap))
+	screenHDC = memDC
+	screenHBitmap = hBitmap
 
 	// Copy image data to bitmap (convert RGBA to BGRA) with bounds checking
 	// Calculate the proper stride (DWORD-aligned row size)
@@ -791,8 +1174,20 @@ func drawScreenBackground(hdc win.HDC) {
 		}
 	}
 
-	// BitBlt the screen image to the window
-	win.BitBlt(hdc, 0, 0, int32(width), int32(height), memDC, 0, 0, win.SRCCOPY)
+	return true
+}
+
+// releaseScreenBackground frees the cached background bitmap.
+func releaseScreenBackground() {
+	if screenHDC == 0 {
+		return
+	}
+	win.SelectObject(screenHDC, screenOldBmp)
+	win.DeleteObject(win.HGDIOBJ(screenHBitmap))
+	win.DeleteDC(screenHDC)
+	screenHDC = 0
+	screenHBitmap = 0
+	screenOldBmp = 0
 }
 
 // Helper functions
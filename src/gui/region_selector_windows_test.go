@@ -0,0 +1,50 @@
+//go:build windows
+
+package gui
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/lxn/win"
+)
+
+// BenchmarkOverlayBackground measures the work done between the start of a
+// selection and its first paint: capturing the virtual screen and turning it
+// into the cached background bitmap, at full size and at OVERLAY_BG_SCALE=0.5.
+func BenchmarkOverlayBackground(b *testing.B) {
+	vx := win.GetSystemMetrics(win.SM_XVIRTUALSCREEN)
+	vy := win.GetSystemMetrics(win.SM_YVIRTUALSCREEN)
+	vw := win.GetSystemMetrics(win.SM_CXVIRTUALSCREEN)
+	vh := win.GetSystemMetrics(win.SM_CYVIRTUALSCREEN)
+	if vw <= 0 || vh <= 0 {
+		b.Skip("no interactive desktop")
+	}
+	screenDC := win.GetDC(0)
+	if screenDC == 0 {
+		b.Skip("no screen DC")
+	}
+	defer win.ReleaseDC(0, screenDC)
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	defer func() { screenImage = nil }()
+
+	for _, scale := range []float64{1.0, 0.5} {
+		b.Run(fmt.Sprintf("scale=%.1f", scale), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := captureBackground(vx, vy, vw, vh, scale); err != nil {
+					b.Fatalf("captureBackground: %v", err)
+				}
+				// At full size the first WM_PAINT converts the capture
+				if screenHDC == 0 && !cacheScreenBackground(screenDC) {
+					b.Fatal("cacheScreenBackground failed")
+				}
+				releaseScreenBackground()
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package gui
+
+import "screen-ocr-llm/src/screenshot"
+
+// lparamPoint extracts signed client coordinates from a mouse message
+// lParam (GET_X_LPARAM/GET_Y_LPARAM). While the mouse is captured, a drag
+// past the overlay's top/left edge yields negative values, which an unsigned
+// LOWORD/HIWORD would turn into ~65535.
+func lparamPoint(lParam uintptr) (x, y int32) {
+	return int32(int16(uint16(lParam))), int32(int16(uint16(lParam >> 16)))
+}
+
+// selectionRegion converts a selection in overlay client coordinates into a
+// virtual-screen Region. The overlay window starts at the virtual-screen
+// origin (originX, originY), which is negative when a monitor sits left of
+// or above the primary one. Lasso points, if any, are offset the same way.
+func selectionRegion(left, top, width, height, originX, originY int32, lasso []screenshot.Point) screenshot.Region {
+	region := screenshot.Region{
+		X:      int(left + originX),
+		Y:      int(top + originY),
+		Width:  int(width),
+		Height: int(height),
+	}
+	if len(lasso) > 0 {
+		region.Polygon = make([]screenshot.Point, len(lasso))
+		for i, p := range lasso {
+			region.Polygon[i] = screenshot.Point{X: p.X + int(originX), Y: p.Y + int(originY)}
+		}
+	}
+	return region
+}
@@ -0,0 +1,52 @@
+package gui
+
+import (
+	"testing"
+
+	"screen-ocr-llm/src/screenshot"
+)
+
+func TestSelectionRegionNegativeVirtualOrigin(t *testing.T) {
+	// Secondary monitor left of the primary: the virtual screen starts at
+	// (-1920, 0). A selection at client (100, 200) lies on that monitor.
+	got := selectionRegion(100, 200, 300, 150, -1920, 0, nil)
+	want := screenshot.Region{X: -1820, Y: 200, Width: 300, Height: 150}
+	if got.X != want.X || got.Y != want.Y || got.Width != want.Width || got.Height != want.Height || got.Polygon != nil {
+		t.Fatalf("selectionRegion() = %+v, want %+v", got, want)
+	}
+
+	// Client x beyond 1920 is on the primary monitor at a positive screen x
+	got = selectionRegion(2000, 10, 50, 50, -1920, -300, nil)
+	if got.X != 80 || got.Y != -290 {
+		t.Fatalf("selectionRegion() on primary = (%d,%d), want (80,-290)", got.X, got.Y)
+	}
+}
+
+func TestSelectionRegionOffsetsLassoPoints(t *testing.T) {
+	lasso := []screenshot.Point{{X: 10, Y: 20}, {X: 60, Y: 20}, {X: 35, Y: 70}}
+	got := selectionRegion(10, 20, 50, 50, -1280, -200, lasso)
+	if got.X != -1270 || got.Y != -180 {
+		t.Fatalf("selectionRegion() origin = (%d,%d), want (-1270,-180)", got.X, got.Y)
+	}
+	want := []screenshot.Point{{X: -1270, Y: -180}, {X: -1220, Y: -180}, {X: -1245, Y: -130}}
+	for i := range want {
+		if got.Polygon[i] != want[i] {
+			t.Fatalf("Polygon[%d] = %+v, want %+v", i, got.Polygon[i], want[i])
+		}
+	}
+	if lasso[0].X != 10 {
+		t.Fatal("selectionRegion must not modify the input points")
+	}
+}
+
+func TestLparamPointSigned(t *testing.T) {
+	x, y := lparamPoint(uintptr(uint32(uint16(300)) | uint32(uint16(40))<<16))
+	if x != 300 || y != 40 {
+		t.Fatalf("lparamPoint() = (%d,%d), want (300,40)", x, y)
+	}
+	neg := uint32(uint16(0xFFFB)) | uint32(uint16(0xFFF6))<<16 // (-5, -10)
+	x, y = lparamPoint(uintptr(neg))
+	if x != -5 || y != -10 {
+		t.Fatalf("lparamPoint() = (%d,%d), want (-5,-10)", x, y)
+	}
+}
@@ -0,0 +1,32 @@
+package gui
+
+import "fmt"
+
+// selectionLabelOffset is the gap between the cursor and the size label.
+const selectionLabelOffset = 16
+
+// selectionLabel formats the live size readout shown while dragging: the
+// selection size and the cursor position in virtual-screen coordinates.
+func selectionLabel(width, height, cursorX, cursorY int) string {
+	return fmt.Sprintf("%dx%d  (%d, %d)", width, height, cursorX, cursorY)
+}
+
+// labelPosition places a labelW x labelH box below-right of the cursor,
+// flipping to the other side near the right or bottom edge of the area.
+func labelPosition(cursorX, cursorY, labelW, labelH, areaW, areaH int) (x, y int) {
+	x = cursorX + selectionLabelOffset
+	if x+labelW > areaW {
+		x = cursorX - selectionLabelOffset - labelW
+	}
+	y = cursorY + selectionLabelOffset
+	if y+labelH > areaH {
+		y = cursorY - selectionLabelOffset - labelH
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	return x, y
+}
@@ -0,0 +1,30 @@
+package gui
+
+import "testing"
+
+func TestSelectionLabel(t *testing.T) {
+	if got := selectionLabel(640, 480, -1920, 35); got != "640x480  (-1920, 35)" {
+		t.Fatalf("selectionLabel() = %q", got)
+	}
+}
+
+func TestLabelPosition(t *testing.T) {
+	tests := []struct {
+		name             string
+		cursorX, cursorY int
+		wantX, wantY     int
+	}{
+		{name: "below right of cursor", cursorX: 100, cursorY: 100, wantX: 116, wantY: 116},
+		{name: "flips left near right edge", cursorX: 1850, cursorY: 100, wantX: 1714, wantY: 116},
+		{name: "flips up near bottom edge", cursorX: 100, cursorY: 1070, wantX: 116, wantY: 1034},
+		{name: "clamped to area", cursorX: 5, cursorY: 5, wantX: 21, wantY: 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := labelPosition(tt.cursorX, tt.cursorY, 120, 20, 1920, 1080)
+			if x != tt.wantX || y != tt.wantY {
+				t.Fatalf("labelPosition() = (%d,%d), want (%d,%d)", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package gui
+
+// nudgeSelection moves a rectangle selection by (dx, dy), or with resize set
+// moves only its active (end) corner, keeping both corners inside the
+// areaW x areaH overlay.
+func nudgeSelection(startX, startY, endX, endY, dx, dy, areaW, areaH int32, resize bool) (int32, int32, int32, int32) {
+	if resize {
+		return startX, startY, clampInt32(endX+dx, 0, areaW-1), clampInt32(endY+dy, 0, areaH-1)
+	}
+
+	// Limit the move so the whole rectangle stays on screen
+	minX, maxX := min(startX, endX), max(startX, endX)
+	minY, maxY := min(startY, endY), max(startY, endY)
+	dx = clampInt32(dx, -minX, areaW-1-maxX)
+	dy = clampInt32(dy, -minY, areaH-1-maxY)
+	return startX + dx, startY + dy, endX + dx, endY + dy
+}
+
+func clampInt32(v, lo, hi int32) int32 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,30 @@
+package gui
+
+import "testing"
+
+func TestNudgeSelection(t *testing.T) {
+	tests := []struct {
+		name                   string
+		dx, dy                 int32
+		resize                 bool
+		sx, sy, ex, ey         int32
+		wantSX, wantSY, wantEX int32
+		wantEY                 int32
+	}{
+		{name: "move right", dx: 1, sx: 10, sy: 10, ex: 50, ey: 40, wantSX: 11, wantSY: 10, wantEX: 51, wantEY: 40},
+		{name: "move up", dy: -1, sx: 10, sy: 10, ex: 50, ey: 40, wantSX: 10, wantSY: 9, wantEX: 50, wantEY: 39},
+		{name: "resize end corner", dx: 1, dy: 1, resize: true, sx: 10, sy: 10, ex: 50, ey: 40, wantSX: 10, wantSY: 10, wantEX: 51, wantEY: 41},
+		{name: "resize reversed drag", dx: -1, resize: true, sx: 50, sy: 40, ex: 10, ey: 10, wantSX: 50, wantSY: 40, wantEX: 9, wantEY: 10},
+		{name: "move stops at left edge", dx: -1, sx: 0, sy: 10, ex: 40, ey: 40, wantSX: 0, wantSY: 10, wantEX: 40, wantEY: 40},
+		{name: "move stops at right edge", dx: 1, sx: 60, sy: 10, ex: 99, ey: 40, wantSX: 60, wantSY: 10, wantEX: 99, wantEY: 40},
+		{name: "resize clamped to area", dy: 1, resize: true, sx: 10, sy: 10, ex: 50, ey: 99, wantSX: 10, wantSY: 10, wantEX: 50, wantEY: 99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sx, sy, ex, ey := nudgeSelection(tt.sx, tt.sy, tt.ex, tt.ey, tt.dx, tt.dy, 100, 100, tt.resize)
+			if sx != tt.wantSX || sy != tt.wantSY || ex != tt.wantEX || ey != tt.wantEY {
+				t.Fatalf("nudgeSelection() = (%d,%d)-(%d,%d), want (%d,%d)-(%d,%d)", sx, sy, ex, ey, tt.wantSX, tt.wantSY, tt.wantEX, tt.wantEY)
+			}
+		})
+	}
+}
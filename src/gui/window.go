@@ -0,0 +1,90 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"screen-ocr-llm/src/screenshot"
+)
+
+// ErrWindowNotFound is returned by CaptureWindowByTitle when no visible
+// window title contains the search text.
+var ErrWindowNotFound = errors.New("no window found")
+
+// maxListedWindows bounds the candidates named in an ambiguous-match error.
+const maxListedWindows = 8
+
+// Window is a visible top-level window and its client area in screen
+// coordinates.
+type Window struct {
+	Title  string
+	Region screenshot.Region
+}
+
+// CaptureWindowByTitle returns the client area of the visible, non-minimized
+// top-level window whose title contains substr (case-insensitive), ready for
+// screenshot.CaptureRegion. When several titles contain substr, a window
+// titled exactly substr wins; otherwise the error lists the candidates.
+func CaptureWindowByTitle(substr string) (screenshot.Region, error) {
+	w, err := FindWindowByTitle(substr)
+	return w.Region, err
+}
+
+// FindWindowByTitle is like CaptureWindowByTitle but returns the matched
+// window's full title along with its client area.
+func FindWindowByTitle(substr string) (Window, error) {
+	windows, err := listWindows()
+	if err != nil {
+		return Window{}, err
+	}
+	w, err := matchWindow(windows, substr)
+	if err != nil {
+		return Window{}, err
+	}
+	log.Printf("Window %q: client area %dx%d at %d,%d", w.Title, w.Region.Width, w.Region.Height, w.Region.X, w.Region.Y)
+	return w, nil
+}
+
+// matchWindow picks the window for substr from windows as described for
+// CaptureWindowByTitle.
+func matchWindow(windows []Window, substr string) (Window, error) {
+	query := strings.ToLower(strings.TrimSpace(substr))
+	if query == "" {
+		return Window{}, errors.New("window title is empty")
+	}
+
+	var matches []Window
+	for _, w := range windows {
+		if strings.Contains(strings.ToLower(w.Title), query) {
+			matches = append(matches, w)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) == 0 {
+		return Window{}, fmt.Errorf("%w with a title containing %q", ErrWindowNotFound, strings.TrimSpace(substr))
+	}
+
+	var exact []Window
+	for _, w := range matches {
+		if strings.ToLower(strings.TrimSpace(w.Title)) == query {
+			exact = append(exact, w)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0], nil
+	}
+
+	titles := make([]string, 0, maxListedWindows)
+	for i, w := range matches {
+		if i == maxListedWindows {
+			titles = append(titles, fmt.Sprintf("and %d more", len(matches)-i))
+			break
+		}
+		titles = append(titles, fmt.Sprintf("%q", w.Title))
+	}
+	return Window{}, fmt.Errorf("%d windows match %q: %s", len(matches), strings.TrimSpace(substr), strings.Join(titles, ", "))
+}
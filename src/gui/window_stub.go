@@ -0,0 +1,15 @@
+//go:build !windows
+
+package gui
+
+import "errors"
+
+// ForegroundWindowTitle is a stub for non-Windows platforms; it returns "".
+func ForegroundWindowTitle() string {
+	return ""
+}
+
+// listWindows is a stub for non-Windows platforms.
+func listWindows() ([]Window, error) {
+	return nil, errors.New("window capture not supported on this platform")
+}
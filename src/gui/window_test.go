@@ -0,0 +1,49 @@
+package gui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"screen-ocr-llm/src/screenshot"
+)
+
+func TestMatchWindow(t *testing.T) {
+	windows := []Window{
+		{Title: "notes.txt - Notepad", Region: screenshot.Region{X: 1, Width: 10, Height: 10}},
+		{Title: "Notepad", Region: screenshot.Region{X: 2, Width: 10, Height: 10}},
+		{Title: "Inbox - Mail", Region: screenshot.Region{X: 3, Width: 10, Height: 10}},
+		{Title: "Mail settings", Region: screenshot.Region{X: 4, Width: 10, Height: 10}},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantX   int
+		wantErr string
+	}{
+		{name: "unique substring", query: "inbox", wantX: 3},
+		{name: "exact title wins", query: " notepad ", wantX: 2},
+		{name: "ambiguous lists candidates", query: "mail", wantErr: `2 windows match "mail": "Inbox - Mail", "Mail settings"`},
+		{name: "no match", query: "Calculator", wantErr: "no window found"},
+		{name: "empty", query: "  ", wantErr: "window title is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := matchWindow(windows, tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("matchWindow(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || w.Region.X != tt.wantX {
+				t.Fatalf("matchWindow(%q) = %+v, %v; want X=%d", tt.query, w, err, tt.wantX)
+			}
+		})
+	}
+
+	if _, err := matchWindow(windows, "Calculator"); !errors.Is(err, ErrWindowNotFound) {
+		t.Fatalf("expected ErrWindowNotFound, got %v", err)
+	}
+}
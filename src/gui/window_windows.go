@@ -0,0 +1,113 @@
+//go:build windows
+
+package gui
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"screen-ocr-llm/src/screenshot"
+
+	"github.com/lxn/win"
+)
+
+const dwmwaCloaked = 14
+
+var (
+	procEnumWindows          = user32DLL.NewProc("EnumWindows")
+	procGetWindowTextW       = user32DLL.NewProc("GetWindowTextW")
+	procGetWindowTextLengthW = user32DLL.NewProc("GetWindowTextLengthW")
+
+	dwmapiDLL                 = syscall.NewLazyDLL("dwmapi.dll")
+	procDwmGetWindowAttribute = dwmapiDLL.NewProc("DwmGetWindowAttribute")
+)
+
+// EnumWindows results are collected into enumWindows by a single callback;
+// syscall.NewCallback slots are never freed, so it is created once.
+var (
+	enumMu              sync.Mutex
+	enumWindows         []Window
+	enumWindowsCallback = syscall.NewCallback(func(hwnd win.HWND, _ uintptr) uintptr {
+		if w, ok := windowInfo(hwnd); ok {
+			enumWindows = append(enumWindows, w)
+		}
+		return 1 // continue enumeration
+	})
+)
+
+// ForegroundWindowTitle returns the title of the window the user is working
+// in, or "" if there is none or it has no title. Call it before showing the
+// selection overlay, which becomes the foreground window.
+func ForegroundWindowTitle() string {
+	return windowTitle(win.GetForegroundWindow())
+}
+
+// listWindows returns the visible, non-minimized top-level windows that
+// have a title, in z-order (topmost first).
+func listWindows() ([]Window, error) {
+	enumMu.Lock()
+	defer enumMu.Unlock()
+
+	enumWindows = nil
+	if r, _, err := procEnumWindows.Call(enumWindowsCallback, 0); r == 0 {
+		return nil, fmt.Errorf("EnumWindows failed: %v", err)
+	}
+	windows := enumWindows
+	enumWindows = nil
+	return windows, nil
+}
+
+// windowInfo returns the title and client area of hwnd, or false if it is
+// not a window the user can see.
+func windowInfo(hwnd win.HWND) (Window, bool) {
+	if !win.IsWindowVisible(hwnd) || win.IsIconic(hwnd) || isCloaked(hwnd) {
+		return Window{}, false
+	}
+
+	title := windowTitle(hwnd)
+	if title == "" {
+		return Window{}, false
+	}
+
+	var rc win.RECT
+	if !win.GetClientRect(hwnd, &rc) {
+		return Window{}, false
+	}
+	origin := win.POINT{X: rc.Left, Y: rc.Top}
+	if !win.ClientToScreen(hwnd, &origin) {
+		return Window{}, false
+	}
+	width, height := int(rc.Right-rc.Left), int(rc.Bottom-rc.Top)
+	if width <= 0 || height <= 0 {
+		return Window{}, false
+	}
+
+	return Window{
+		Title:  title,
+		Region: screenshot.Region{X: int(origin.X), Y: int(origin.Y), Width: width, Height: height},
+	}, true
+}
+
+// windowTitle returns the title bar text of hwnd.
+func windowTitle(hwnd win.HWND) string {
+	if hwnd == 0 {
+		return ""
+	}
+	n, _, _ := procGetWindowTextLengthW.Call(uintptr(hwnd))
+	if n == 0 {
+		return ""
+	}
+	buf := make([]uint16, n+1)
+	procGetWindowTextW.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	return syscall.UTF16ToString(buf)
+}
+
+// isCloaked reports whether DWM hides hwnd, e.g. suspended UWP apps and
+// windows on other virtual desktops, which are "visible" but not on screen.
+func isCloaked(hwnd win.HWND) bool {
+	var cloaked uint32
+	r, _, _ := procDwmGetWindowAttribute.Call(uintptr(hwnd), dwmwaCloaked, uintptr(unsafe.Pointer(&cloaked)), unsafe.Sizeof(cloaked))
+	return r == 0 && cloaked != 0
+}
@@ -0,0 +1,184 @@
+// Package history keeps an optional append-only log of successful OCR
+// results as JSON lines, one entry per line.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the history file created in the app directory.
+const FileName = "ocr_history.jsonl"
+
+// previewRunes is the length of Entry.Preview before it is truncated.
+const previewRunes = 80
+
+// Entry is one recorded OCR result.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Chars   int       `json:"chars"`
+	Model   string    `json:"model,omitempty"`
+	Preview string    `json:"preview"`
+	Text    string    `json:"text"`
+	// WindowTitle is the title of the window the text was captured from,
+	// when known (Windows only).
+	WindowTitle string `json:"window_title,omitempty"`
+}
+
+var (
+	mu   sync.Mutex
+	path string
+)
+
+// SetPath sets the history file. An empty path disables recording.
+func SetPath(p string) {
+	mu.Lock()
+	defer mu.Unlock()
+	path = p
+}
+
+// Enabled reports whether a history file has been configured.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return path != ""
+}
+
+// DefaultPath returns FileName next to the running executable, falling back
+// to the working directory if the executable path is unknown.
+func DefaultPath() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return FileName
+	}
+	return filepath.Join(filepath.Dir(exe), FileName)
+}
+
+// Record appends text as a new entry. It is a no-op when history is disabled.
+func Record(text, model string) error {
+	return RecordWithWindow(text, model, "")
+}
+
+// RecordWithWindow is like Record but also stores the title of the window
+// the text was captured from.
+func RecordWithWindow(text, model, windowTitle string) error {
+	return Append(Entry{
+		Time:        time.Now(),
+		Chars:       len([]rune(text)),
+		Model:       model,
+		WindowTitle: windowTitle,
+		Preview:     preview(text),
+		Text:        text,
+	})
+}
+
+// Append writes e as a single line in one write call on a file opened with
+// O_APPEND, so concurrent writers (including other processes) never
+// interleave partial entries.
+func Append(e Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	return f.Close()
+}
+
+// List returns up to limit entries, newest first. A limit of 0 or less
+// returns every entry.
+func List(limit int) ([]Entry, error) {
+	return filter(limit, func(Entry) bool { return true })
+}
+
+// Search returns entries whose text contains substr (case-insensitive),
+// newest first.
+func Search(substr string) ([]Entry, error) {
+	needle := strings.ToLower(substr)
+	return filter(0, func(e Entry) bool {
+		return strings.Contains(strings.ToLower(e.Text), needle)
+	})
+}
+
+func filter(limit int, keep func(Entry) bool) ([]Entry, error) {
+	entries, err := readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !keep(entries[i]) {
+			continue
+		}
+		out = append(out, entries[i])
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func readAll() ([]Entry, error) {
+	mu.Lock()
+	p := path
+	mu.Unlock()
+	if p == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		// Skip lines that fail to parse (e.g. a torn write after a crash)
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
+
+func preview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= previewRunes {
+		return text
+	}
+	return string(runes[:previewRunes-1]) + "…"
+}
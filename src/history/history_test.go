@@ -0,0 +1,128 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func useTempHistory(t *testing.T) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), FileName)
+	SetPath(p)
+	t.Cleanup(func() { SetPath("") })
+	return p
+}
+
+func TestRecordDisabledIsNoop(t *testing.T) {
+	SetPath("")
+	if err := Record("hello", "m"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	entries, err := List(0)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("List() = %v, %v; want no entries", entries, err)
+	}
+}
+
+func TestListNewestFirst(t *testing.T) {
+	useTempHistory(t)
+	for _, text := range []string{"first", "second", "third"} {
+		if err := Record(text, "model/a"); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	entries, err := List(2)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Text != "third" || entries[1].Text != "second" {
+		t.Fatalf("List(2) = %+v", entries)
+	}
+	if entries[0].Model != "model/a" || entries[0].Chars != 5 {
+		t.Fatalf("unexpected entry metadata: %+v", entries[0])
+	}
+}
+
+func TestSearch(t *testing.T) {
+	useTempHistory(t)
+	for _, text := range []string{"Invoice 42", "meeting notes", "invoice 43"} {
+		if err := Record(text, ""); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	entries, err := Search("INVOICE")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Text != "invoice 43" || entries[1].Text != "Invoice 42" {
+		t.Fatalf("Search() = %+v", entries)
+	}
+}
+
+func TestPreviewTruncates(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	if got := preview(long); len([]rune(got)) != previewRunes || !strings.HasSuffix(got, "…") {
+		t.Fatalf("preview() = %q", got)
+	}
+	if got := preview("two\nlines"); got != "two lines" {
+		t.Fatalf("preview() = %q, want %q", got, "two lines")
+	}
+}
+
+func TestConcurrentAppendsStayWholeLines(t *testing.T) {
+	p := useTempHistory(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = Record(fmt.Sprintf("entry %d", i), "")
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 20 {
+		t.Fatalf("history has %d lines, want 20", lines)
+	}
+	entries, err := List(0)
+	if err != nil || len(entries) != 20 {
+		t.Fatalf("List(0) returned %d entries, err=%v", len(entries), err)
+	}
+}
+
+func TestRecordWithWindow(t *testing.T) {
+	p := useTempHistory(t)
+	if err := RecordWithWindow("total 42", "m", "invoice.pdf - Viewer"); err != nil {
+		t.Fatalf("RecordWithWindow failed: %v", err)
+	}
+	if err := Record("no window", "m"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	entries, err := List(0)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("List() = %v, %v", entries, err)
+	}
+	if entries[1].WindowTitle != "invoice.pdf - Viewer" || entries[0].WindowTitle != "" {
+		t.Fatalf("unexpected window titles: %q, %q", entries[1].WindowTitle, entries[0].WindowTitle)
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if !strings.Contains(lines[0], `"window_title":"invoice.pdf - Viewer"`) || strings.Contains(lines[1], "window_title") {
+		t.Fatalf("unexpected history lines: %q", lines)
+	}
+}
@@ -0,0 +1,72 @@
+package hotkey
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// reservedCombos lists shortcuts Windows (or the shell) handles before a
+// keyboard hook sees them reliably, so they never or only sometimes fire.
+var reservedCombos = []struct {
+	combo string
+	use   string
+}{
+	{"Ctrl+Alt+Delete", "the Windows security screen"},
+	{"Ctrl+Shift+Esc", "Task Manager"},
+	{"Ctrl+Esc", "the Start menu"},
+	{"Alt+Tab", "window switching"},
+	{"Alt+Esc", "window cycling"},
+	{"Alt+F4", "closing the active window"},
+	{"Win+L", "locking the workstation"},
+	{"Win+D", "Show desktop"},
+	{"Win+E", "File Explorer"},
+	{"Win+R", "the Run dialog"},
+	{"Win+I", "Settings"},
+	{"Win+A", "Quick Settings"},
+	{"Win+X", "the Quick Link menu"},
+	{"Win+V", "clipboard history"},
+	{"Win+Tab", "Task View"},
+	{"Win+Space", "switching input language"},
+	{"Win+.", "the emoji panel"},
+	{"Win+Shift+S", "Snipping Tool"},
+}
+
+// CheckConflict returns human-readable warnings when combo matches a
+// shortcut reserved by Windows. Registration still proceeds; the warnings
+// only explain why such a hotkey may never fire. Key order and aliases
+// (Win/Cmd/Super, Del/Delete) do not matter. For a sequence the first
+// combination is checked.
+func CheckConflict(combo string) []string {
+	sig := comboSignature(splitSequence(combo)[0])
+	if sig == "" {
+		return nil
+	}
+	var warnings []string
+	for _, r := range reservedCombos {
+		if comboSignature(r.combo) == sig {
+			warnings = append(warnings, fmt.Sprintf("%s is reserved by Windows for %s and may never reach this app", combo, r.use))
+		}
+	}
+	return warnings
+}
+
+// comboSignature identifies a combo by its sorted primary rawcodes, or ""
+// when a key cannot be mapped.
+func comboSignature(combo string) string {
+	keys := parseHotkey(combo)
+	codes := make([]int, 0, len(keys))
+	for _, k := range keys {
+		rawcodes := keyNameToRawcodes(k)
+		if len(rawcodes) == 0 {
+			return ""
+		}
+		codes = append(codes, int(rawcodes[0]))
+	}
+	sort.Ints(codes)
+	parts := make([]string, len(codes))
+	for i, c := range codes {
+		parts[i] = fmt.Sprint(c)
+	}
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,26 @@
+package hotkey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckConflict(t *testing.T) {
+	reserved := []string{"Win+L", "win+l", "L+Super", "Ctrl+Alt+Del", "Alt+Ctrl+Delete", "Win+Shift+S", " ctrl + shift + escape ", "Win+L,O"}
+	for _, combo := range reserved {
+		warnings := CheckConflict(combo)
+		if len(warnings) == 0 {
+			t.Errorf("CheckConflict(%q) returned no warnings", combo)
+			continue
+		}
+		if !strings.Contains(warnings[0], "reserved by Windows") {
+			t.Errorf("CheckConflict(%q) warning = %q", combo, warnings[0])
+		}
+	}
+
+	for _, combo := range []string{"Ctrl+Alt+Q", "F13", "Win+Shift+Q", "Ctrl+L", "Ctrl+K,Win+L"} {
+		if warnings := CheckConflict(combo); len(warnings) != 0 {
+			t.Errorf("CheckConflict(%q) = %v, want none", combo, warnings)
+		}
+	}
+}
@@ -1,135 +1,265 @@
 package hotkey
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"strings"
 	"sync"
+	"time"
 
 	gohook "github.com/robotn/gohook"
+
+	"screen-ocr-llm/src/logutil"
+)
+
+// Binding is one hotkey and the callback to run when it is pressed. Combo is
+// either a combination ("Ctrl+Alt+Q") or a sequence of combinations separated
+// by commas ("Ctrl+K,O": press Ctrl+K, then O within sequenceTimeout).
+type Binding struct {
+	Combo    string
+	Callback func()
+}
+
+// sequenceTimeout is how long a sequence waits for its next combination.
+const sequenceTimeout = 1500 * time.Millisecond
+
+var (
+	listenerMu sync.Mutex
+	// listenerDone is closed when the running gohook goroutine exits
+	listenerDone chan struct{}
 )
 
 func Listen(hotkeyConfig string, callback func()) {
-	// Note: This function only registers the hotkey and calls the callback when pressed.
-	// The callback is responsible for triggering the region selection and OCR workflow.
-	// The OCR processing is now handled by the eventloop after region selection completes.
+	ListenAll([]Binding{{Combo: hotkeyConfig, Callback: callback}})
+}
+
+type keyState struct {
+	name     string
+	rawcodes []uint16
+	pressed  bool
+}
+
+// comboState tracks the keys of one binding. steps holds one entry per
+// combination of a sequence, a single one for a plain combo.
+type comboState struct {
+	combo    string
+	callback func()
+	steps    [][]keyState
+	step     int
+	stepAt   time.Time
+}
+
+// keyDown records a key press at now and reports whether it completed the
+// binding. A sequence starts over when its next combination doesn't follow
+// within sequenceTimeout or another key interrupts it.
+func (c *comboState) keyDown(rawcode uint16, now time.Time) bool {
+	if c.step > 0 && now.Sub(c.stepAt) > sequenceTimeout {
+		logutil.Debug("Hotkey sequence %s timed out", c.combo)
+		c.reset()
+	}
+	keys := c.steps[c.step]
+	// Held keys of the previous combination auto-repeat; anything else
+	// breaks the sequence
+	if !markKeys(keys, rawcode, true) && c.step > 0 && !matchesKey(c.steps[c.step-1], rawcode) {
+		logutil.Debug("Hotkey sequence %s interrupted", c.combo)
+		c.reset()
+		keys = c.steps[0]
+		markKeys(keys, rawcode, true)
+	}
+	for i := range keys {
+		if !keys[i].pressed {
+			return false
+		}
+	}
+	for i := range keys {
+		keys[i].pressed = false
+	}
+	if c.step == len(c.steps)-1 {
+		c.step = 0
+		return true
+	}
+	// Modifiers still held count towards the next combination
+	c.step++
+	c.stepAt = now
+	for i := range c.steps[c.step] {
+		next := &c.steps[c.step][i]
+		next.pressed = isModifier(next.name) && hasKey(keys, next.name)
+	}
+	logutil.Debug("Hotkey sequence %s: waiting for step %d of %d", c.combo, c.step+1, len(c.steps))
+	return false
+}
+
+// keyUp records a key release.
+func (c *comboState) keyUp(rawcode uint16) {
+	markKeys(c.steps[c.step], rawcode, false)
+}
+
+func (c *comboState) reset() {
+	for _, keys := range c.steps {
+		for i := range keys {
+			keys[i].pressed = false
+		}
+	}
+	c.step = 0
+}
 
-	// Parse hotkey configuration
-	keys := parseHotkey(hotkeyConfig)
-	log.Printf("Parsed hotkey configuration: %v", keys)
+// markKeys sets the pressed state of the keys matching rawcode and reports
+// whether any did.
+func markKeys(keys []keyState, rawcode uint16, pressed bool) bool {
+	matched := false
+	for i := range keys {
+		for _, rc := range keys[i].rawcodes {
+			if rc == rawcode {
+				keys[i].pressed = pressed
+				matched = true
+				break
+			}
+		}
+	}
+	return matched
+}
 
-	// Build a map of rawcodes to key names for this hotkey combination
-	type keyState struct {
-		name     string
-		rawcodes []uint16
-		pressed  bool
+func matchesKey(keys []keyState, rawcode uint16) bool {
+	for _, k := range keys {
+		for _, rc := range k.rawcodes {
+			if rc == rawcode {
+				return true
+			}
+		}
 	}
+	return false
+}
+
+func hasKey(keys []keyState, name string) bool {
+	for _, k := range keys {
+		if k.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isModifier(name string) bool {
+	switch name {
+	case "ctrl", "alt", "shift", "cmd":
+		return true
+	}
+	return false
+}
+
+// newComboState maps every key of b to its rawcodes. It returns false when a
+// combination of the binding has no usable key.
+func newComboState(b Binding) (comboState, bool) {
+	state := comboState{combo: b.Combo, callback: b.Callback}
+	for _, keys := range parseSequence(b.Combo) {
+		log.Printf("Parsed hotkey configuration: %v", keys)
+
+		var keyStates []keyState
+		for _, keyName := range keys {
+			rawcodes := keyNameToRawcodes(keyName)
+			if len(rawcodes) == 0 {
+				log.Printf("ERROR: Cannot map key '%s' to rawcodes, hotkey may not work correctly", keyName)
+				continue
+			}
+			keyStates = append(keyStates, keyState{
+				name:     keyName,
+				rawcodes: rawcodes,
+				pressed:  false,
+			})
+		}
 
-	var keyStates []keyState
-	for _, keyName := range keys {
-		rawcodes := keyNameToRawcodes(keyName)
-		if len(rawcodes) == 0 {
-			log.Printf("ERROR: Cannot map key '%s' to rawcodes, hotkey may not work correctly", keyName)
+		if len(keyStates) == 0 {
+			log.Printf("ERROR: No valid keys in hotkey configuration '%s'", b.Combo)
+			return comboState{}, false
+		}
+		state.steps = append(state.steps, keyStates)
+	}
+	return state, true
+}
+
+// ListenAll registers several hotkeys on a single gohook event loop;
+// gohook.Start owns a global hook, so it must only be started once.
+func ListenAll(bindings []Binding) {
+	// Note: This function only registers the hotkeys and calls the callbacks when pressed.
+	// The callback is responsible for triggering the region selection and OCR workflow.
+	// The OCR processing is now handled by the eventloop after region selection completes.
+
+	var combos []comboState
+	for _, b := range bindings {
+		state, ok := newComboState(b)
+		if !ok {
 			continue
 		}
-		keyStates = append(keyStates, keyState{
-			name:     keyName,
-			rawcodes: rawcodes,
-			pressed:  false,
-		})
+		log.Printf("Hotkey listener configured for: %s", b.Combo)
+		combos = append(combos, state)
 	}
 
-	if len(keyStates) == 0 {
-		log.Printf("ERROR: No valid keys in hotkey configuration '%s'", hotkeyConfig)
+	if len(combos) == 0 {
 		return
 	}
 
-	log.Printf("Hotkey listener configured for: %s", hotkeyConfig)
+	done := make(chan struct{})
+	listenerMu.Lock()
+	listenerDone = done
+	listenerMu.Unlock()
 
 	// Start a goroutine to listen for hotkey events
 	go func() {
+		defer close(done)
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("PANIC in hotkey goroutine: %v", r)
 			}
 		}()
 
-		log.Printf("Starting gohook goroutine...")
+		logutil.Debug("Starting gohook goroutine...")
 
 		// Track key states for combination detection with mutex protection
 		var mu sync.Mutex
 
 		// Start the event loop
-		log.Printf("Starting gohook event loop...")
+		logutil.Debug("Starting gohook event loop...")
 		evChan := gohook.Start()
 		if evChan == nil {
 			log.Printf("ERROR: gohook.Start() returned nil channel")
 			return
 		}
-		log.Printf("gohook.Start() returned channel successfully")
+		logutil.Debug("gohook.Start() returned channel successfully")
 
 		// Process events from the channel
 		for ev := range evChan {
 			// Only log key events, not mouse events to reduce spam
 			if ev.Kind == gohook.KeyDown || ev.Kind == gohook.KeyUp {
-				log.Printf("Key event: Kind=%v, Rawcode=%d, Keychar=%v", ev.Kind, ev.Rawcode, ev.Keychar)
+				logutil.Debug("Key event: Kind=%v, Rawcode=%d, Keychar=%v", ev.Kind, ev.Rawcode, ev.Keychar)
 
 				// Track key states with mutex protection
 				if ev.Kind == gohook.KeyDown {
 					mu.Lock()
 
-					// Check if this rawcode matches any of our configured keys
-					for i := range keyStates {
-						for _, rawcode := range keyStates[i].rawcodes {
-							if ev.Rawcode == rawcode {
-								keyStates[i].pressed = true
-								log.Printf("%s pressed", keyStates[i].name)
-								break
-							}
-						}
-					}
-
-					// Check if all keys are pressed
-					allPressed := true
-					for i := range keyStates {
-						if !keyStates[i].pressed {
-							allPressed = false
-							break
+					now := time.Now()
+					var fired []func()
+					for c := range combos {
+						if combos[c].keyDown(ev.Rawcode, now) {
+							log.Printf("HOTKEY COMBINATION DETECTED! %s", combos[c].combo)
+							logutil.Debug("Hotkey activated")
+							fired = append(fired, combos[c].callback)
 						}
 					}
+					mu.Unlock()
 
-					if allPressed {
-						log.Printf("HOTKEY COMBINATION DETECTED! %s", hotkeyConfig)
-						log.Printf("Hotkey activated")
-						// Reset states before releasing lock
-						for i := range keyStates {
-							keyStates[i].pressed = false
-						}
-						mu.Unlock()
-
-						// Invoke the callback if provided
-						// The callback is responsible for triggering the region selection workflow
+					// Invoke the callbacks if provided
+					// The callback is responsible for triggering the region selection workflow
+					for _, callback := range fired {
 						if callback != nil {
 							callback()
 						}
-					} else {
-						mu.Unlock()
 					}
 				} else if ev.Kind == gohook.KeyUp {
 					mu.Lock()
-
-					// Check if this rawcode matches any of our configured keys
-					for i := range keyStates {
-						for _, rawcode := range keyStates[i].rawcodes {
-							if ev.Rawcode == rawcode {
-								if keyStates[i].pressed {
-									keyStates[i].pressed = false
-									log.Printf("%s released", keyStates[i].name)
-								}
-								break
-							}
-						}
+					for c := range combos {
+						combos[c].keyUp(ev.Rawcode)
 					}
-
 					mu.Unlock()
 				}
 			}
@@ -138,10 +268,93 @@ func Listen(hotkeyConfig string, callback func()) {
 	}()
 }
 
+// Validate reports whether every key in combo, a combination or a sequence,
+// can be mapped to a rawcode.
+func Validate(combo string) error {
+	if strings.TrimSpace(combo) == "" {
+		return errors.New("hotkey is empty")
+	}
+	for _, keys := range parseSequence(combo) {
+		for _, keyName := range keys {
+			if keyName == "" {
+				return fmt.Errorf("hotkey %q has an empty key", combo)
+			}
+			if len(keyNameToRawcodes(keyName)) == 0 {
+				return fmt.Errorf("hotkey %q: unknown key %q", combo, keyName)
+			}
+		}
+	}
+	return nil
+}
+
+// Restart stops the running listener (gohook.End closes its event channel)
+// and starts a fresh one for bindings, so hotkeys can change without
+// restarting the app. Every combo is validated first; on error the old
+// listener keeps running.
+func Restart(bindings []Binding) error {
+	for _, b := range bindings {
+		if err := Validate(b.Combo); err != nil {
+			return err
+		}
+	}
+
+	listenerMu.Lock()
+	done := listenerDone
+	listenerDone = nil
+	listenerMu.Unlock()
+
+	if done != nil {
+		log.Printf("Stopping hotkey listener for restart...")
+		gohook.End()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			log.Printf("WARNING: hotkey listener did not stop within 2s")
+		}
+	}
+
+	ListenAll(bindings)
+	return nil
+}
+
+// splitSequence splits a sequence like "Ctrl+K,O" into its combinations. A
+// comma right after "+" (or on its own) is the comma key: "Ctrl+," is one
+// combination.
+func splitSequence(hotkeyConfig string) []string {
+	var steps []string
+	start := 0
+	for i := 0; i < len(hotkeyConfig); i++ {
+		if hotkeyConfig[i] != ',' {
+			continue
+		}
+		prev := strings.TrimSpace(hotkeyConfig[start:i])
+		if prev == "" || (strings.HasSuffix(prev, "+") && !strings.HasSuffix(prev, "++")) {
+			continue
+		}
+		steps = append(steps, hotkeyConfig[start:i])
+		start = i + 1
+	}
+	return append(steps, hotkeyConfig[start:])
+}
+
+// parseSequence converts a combination or sequence to the normalized key
+// names of each of its combinations.
+func parseSequence(hotkeyConfig string) [][]string {
+	var steps [][]string
+	for _, step := range splitSequence(hotkeyConfig) {
+		steps = append(steps, parseHotkey(step))
+	}
+	return steps
+}
+
 // parseHotkey converts a hotkey string like "Ctrl+Alt+q" to normalized key names
 func parseHotkey(hotkeyConfig string) []string {
 	// Convert to lowercase and split by +
 	parts := strings.Split(strings.ToLower(hotkeyConfig), "+")
+	// "Ctrl++" ends in two empty parts: the last key is the plus key itself
+	if n := len(parts); n >= 2 && strings.TrimSpace(parts[n-1]) == "" && strings.TrimSpace(parts[n-2]) == "" {
+		parts = append(parts[:n-2], "+")
+	}
 	var keys []string
 
 	for _, part := range parts {
@@ -330,6 +543,62 @@ func keyNameToRawcodes(keyName string) []uint16 {
 	case "pagedown", "pgdn":
 		return []uint16{34} // VK_NEXT
 
+	// Numpad keys (NumLock on) - VK_NUMPAD0-9 (96-105)
+	case "numpad0", "num0":
+		return []uint16{96} // VK_NUMPAD0
+	case "numpad1", "num1":
+		return []uint16{97} // VK_NUMPAD1
+	case "numpad2", "num2":
+		return []uint16{98} // VK_NUMPAD2
+	case "numpad3", "num3":
+		return []uint16{99} // VK_NUMPAD3
+	case "numpad4", "num4":
+		return []uint16{100} // VK_NUMPAD4
+	case "numpad5", "num5":
+		return []uint16{101} // VK_NUMPAD5
+	case "numpad6", "num6":
+		return []uint16{102} // VK_NUMPAD6
+	case "numpad7", "num7":
+		return []uint16{103} // VK_NUMPAD7
+	case "numpad8", "num8":
+		return []uint16{104} // VK_NUMPAD8
+	case "numpad9", "num9":
+		return []uint16{105} // VK_NUMPAD9
+	case "numpadmultiply", "multiply":
+		return []uint16{106} // VK_MULTIPLY
+	case "numpadadd", "add":
+		return []uint16{107} // VK_ADD
+	case "numpadsubtract", "subtract":
+		return []uint16{109} // VK_SUBTRACT
+	case "numpaddecimal", "decimal":
+		return []uint16{110} // VK_DECIMAL
+	case "numpaddivide", "divide":
+		return []uint16{111} // VK_DIVIDE
+
+	// OEM punctuation keys (US layout)
+	case ";", "semicolon":
+		return []uint16{186} // VK_OEM_1
+	case "=", "+", "equals", "plus":
+		return []uint16{187} // VK_OEM_PLUS
+	case ",", "comma":
+		return []uint16{188} // VK_OEM_COMMA
+	case "-", "minus":
+		return []uint16{189} // VK_OEM_MINUS
+	case ".", "period":
+		return []uint16{190} // VK_OEM_PERIOD
+	case "/", "slash":
+		return []uint16{191} // VK_OEM_2
+	case "`", "backtick", "grave":
+		return []uint16{192} // VK_OEM_3
+	case "[", "leftbracket":
+		return []uint16{219} // VK_OEM_4
+	case "\\", "backslash":
+		return []uint16{220} // VK_OEM_5
+	case "]", "rightbracket":
+		return []uint16{221} // VK_OEM_6
+	case "'", "quote":
+		return []uint16{222} // VK_OEM_7
+
 	// Arrow keys
 	case "left":
 		return []uint16{37} // VK_LEFT
@@ -341,7 +610,7 @@ func keyNameToRawcodes(keyName string) []uint16 {
 		return []uint16{40} // VK_DOWN
 
 	default:
-		log.Printf("WARNING: Unknown key name '%s', cannot map to rawcode", keyName)
+		log.Printf("WARNING: Unknown key name '%s', cannot map to rawcode (see HOTKEY in .env.example for supported keys)", keyName)
 		return nil
 	}
 }
@@ -1,7 +1,9 @@
 package hotkey
 
 import (
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestKeyNameToRawcodes(t *testing.T) {
@@ -39,6 +41,30 @@ func TestKeyNameToRawcodes(t *testing.T) {
 		{"enter", []uint16{13}},
 		{"esc", []uint16{27}},
 
+		// Numpad keys
+		{"numpad0", []uint16{96}},
+		{"numpad5", []uint16{101}},
+		{"num9", []uint16{105}},
+		{"numpadmultiply", []uint16{106}},
+		{"numpadadd", []uint16{107}},
+		{"subtract", []uint16{109}},
+		{"numpaddecimal", []uint16{110}},
+		{"divide", []uint16{111}},
+
+		// OEM punctuation keys
+		{";", []uint16{186}},
+		{"=", []uint16{187}},
+		{"+", []uint16{187}},
+		{"comma", []uint16{188}},
+		{"-", []uint16{189}},
+		{".", []uint16{190}},
+		{"/", []uint16{191}},
+		{"`", []uint16{192}},
+		{"[", []uint16{219}},
+		{"backslash", []uint16{220}},
+		{"]", []uint16{221}},
+		{"'", []uint16{222}},
+
 		// Unknown key
 		{"unknown", nil},
 	}
@@ -76,6 +102,12 @@ func TestParseHotkey(t *testing.T) {
 		{"Ctrl+Win+E", []string{"ctrl", "cmd", "e"}},
 		{"Win+Shift+S", []string{"cmd", "shift", "s"}},
 		{"Super+Alt+T", []string{"cmd", "alt", "t"}},
+		{"Ctrl+Numpad5", []string{"ctrl", "numpad5"}},
+		{" Ctrl + Alt + NumpadAdd ", []string{"ctrl", "alt", "numpadadd"}},
+		{"Ctrl+Alt+/", []string{"ctrl", "alt", "/"}},
+		{"Ctrl+Shift+[", []string{"ctrl", "shift", "["}},
+		{"Ctrl++", []string{"ctrl", "+"}},
+		{"Ctrl+Alt+-", []string{"ctrl", "alt", "-"}},
 	}
 
 	for _, tt := range tests {
@@ -95,3 +127,85 @@ func TestParseHotkey(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSequence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected [][]string
+	}{
+		{"Ctrl+Alt+Q", [][]string{{"ctrl", "alt", "q"}}},
+		{"Ctrl+K,O", [][]string{{"ctrl", "k"}, {"o"}}},
+		{" Ctrl+K , Ctrl+O ", [][]string{{"ctrl", "k"}, {"ctrl", "o"}}},
+		{"Win+Shift+S,1,2", [][]string{{"cmd", "shift", "s"}, {"1"}, {"2"}}},
+		{"Ctrl+,", [][]string{{"ctrl", ","}}},
+		{"Ctrl+K,,", [][]string{{"ctrl", "k"}, {","}}},
+		{"Ctrl++,O", [][]string{{"ctrl", "+"}, {"o"}}},
+		{",", [][]string{{","}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := parseSequence(tt.input); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseSequence(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestComboStateSequence(t *testing.T) {
+	const (
+		ctrl = 162
+		k    = 75
+		o    = 79
+		x    = 88
+	)
+	state, ok := newComboState(Binding{Combo: "Ctrl+K,O"})
+	if !ok {
+		t.Fatal("newComboState failed")
+	}
+	now := time.Now()
+	press := func(rawcodes ...uint16) bool {
+		fired := false
+		for _, rc := range rawcodes {
+			fired = state.keyDown(rc, now)
+		}
+		return fired
+	}
+
+	if press(ctrl, k) {
+		t.Fatal("fired on the leader alone")
+	}
+	// Ctrl is still held and auto-repeats before the follow-up key
+	if !press(ctrl, o) {
+		t.Fatal("expected the sequence to fire")
+	}
+
+	press(ctrl, k)
+	if press(x, o) {
+		t.Fatal("fired after another key interrupted the sequence")
+	}
+
+	press(ctrl, k)
+	now = now.Add(2 * sequenceTimeout)
+	if press(o) {
+		t.Fatal("fired after the sequence timed out")
+	}
+
+	plain, _ := newComboState(Binding{Combo: "Ctrl+O"})
+	if plain.keyDown(ctrl, now) || !plain.keyDown(o, now) {
+		t.Fatal("expected a plain combo to fire once all keys are down")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, combo := range []string{"Ctrl+Alt+Q", "F13", "win+shift+s", "Ctrl+K,O", "Ctrl+,"} {
+		if err := Validate(combo); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", combo, err)
+		}
+	}
+	for _, combo := range []string{"", "Ctrl+Alt+", "Ctrl+Bogus", "Ctrl+K,", "Ctrl+K,Bogus"} {
+		if err := Validate(combo); err == nil {
+			t.Errorf("Validate(%q) = nil, want error", combo)
+		}
+	}
+}
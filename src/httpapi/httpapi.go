@@ -0,0 +1,200 @@
+// Package httpapi exposes OCR on the resident process over a loopback-only
+// HTTP endpoint: POST /ocr with PNG bytes returns the recognized text, and
+// GET /metrics returns the resident's OCR counters. Requests must be
+// addressed to 127.0.0.1:<port> or localhost:<port>, so that a web page
+// cannot reach the endpoint through DNS rebinding.
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"screen-ocr-llm/src/metrics"
+)
+
+// Host is the only interface the server binds to.
+const Host = "127.0.0.1"
+
+// maxImageBytes caps the accepted request body.
+const maxImageBytes = 20 << 20
+
+// ErrBusy is returned by a RecognizeFunc when an OCR job is already running.
+// The handler answers it with 503 Service Unavailable.
+var ErrBusy = errors.New("busy, please retry")
+
+// RecognizeFunc performs OCR on PNG image data.
+type RecognizeFunc func(ctx context.Context, image []byte) (string, error)
+
+// MetricsFunc returns the current OCR counters for GET /metrics.
+type MetricsFunc func() metrics.Snapshot
+
+type successResponse struct {
+	Text      string  `json:"text"`
+	Duration  float64 `json:"duration_seconds"`
+	CharCount int     `json:"character_count"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the HTTP handler serving POST /ocr and, when stats is
+// not nil, GET /metrics.
+func NewHandler(recognize RecognizeFunc, stats MetricsFunc) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ocr", func(w http.ResponseWriter, r *http.Request) {
+		handleOCR(w, r, recognize)
+	})
+	if stats != nil {
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			handleMetrics(w, r, stats)
+		})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r) {
+			log.Printf("httpapi: rejected request for host %q", r.Host)
+			wantJSON := strings.Contains(r.Header.Get("Accept"), "application/json")
+			writeError(w, wantJSON, http.StatusForbidden, "forbidden host")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether r is addressed to the endpoint itself, i.e.
+// its Host header is 127.0.0.1 or localhost with the port the request
+// arrived on.
+func allowedHost(r *http.Request) bool {
+	addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if !ok {
+		return false
+	}
+	_, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	return r.Host == net.JoinHostPort(Host, port) || strings.EqualFold(r.Host, net.JoinHostPort("localhost", port))
+}
+
+// handleMetrics answers GET /metrics in the Prometheus text format, or as
+// JSON with Accept: application/json.
+func handleMetrics(w http.ResponseWriter, r *http.Request, stats MetricsFunc) {
+	wantJSON := strings.Contains(r.Header.Get("Accept"), "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, wantJSON, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	snapshot := stats()
+	if wantJSON {
+		writeJSON(w, http.StatusOK, snapshot)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = snapshot.WritePrometheus(w)
+}
+
+func handleOCR(w http.ResponseWriter, r *http.Request, recognize RecognizeFunc) {
+	wantJSON := strings.Contains(r.Header.Get("Accept"), "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, wantJSON, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "image/png" {
+		writeError(w, wantJSON, http.StatusUnsupportedMediaType, "Content-Type must be image/png")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImageBytes))
+	if err != nil {
+		writeError(w, wantJSON, http.StatusRequestEntityTooLarge, fmt.Sprintf("failed to read image: %v", err))
+		return
+	}
+	if len(data) == 0 {
+		writeError(w, wantJSON, http.StatusBadRequest, "empty request body")
+		return
+	}
+	if ct := http.DetectContentType(data); ct != "image/png" {
+		writeError(w, wantJSON, http.StatusUnsupportedMediaType, fmt.Sprintf("expected PNG image, got %s", ct))
+		return
+	}
+
+	start := time.Now()
+	text, err := recognize(r.Context(), data)
+	elapsed := time.Since(start)
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, ErrBusy):
+			status = http.StatusServiceUnavailable
+			w.Header().Set("Retry-After", "1")
+		case errors.Is(err, context.DeadlineExceeded):
+			status = http.StatusGatewayTimeout
+		}
+		log.Printf("httpapi: OCR failed after %v: %v", elapsed, err)
+		writeError(w, wantJSON, status, err.Error())
+		return
+	}
+
+	log.Printf("httpapi: OCR completed in %v, %d characters", elapsed, len(text))
+	if wantJSON {
+		writeJSON(w, http.StatusOK, successResponse{Text: text, Duration: elapsed.Seconds(), CharCount: len(text)})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, text)
+}
+
+func writeError(w http.ResponseWriter, wantJSON bool, status int, msg string) {
+	if wantJSON {
+		writeJSON(w, status, errorResponse{Error: msg})
+		return
+	}
+	http.Error(w, msg, status)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Start serves the OCR endpoint on 127.0.0.1:port until ctx is cancelled.
+// It returns once the listener is bound. stats may be nil (no /metrics).
+func Start(ctx context.Context, port int, recognize RecognizeFunc, stats MetricsFunc) (net.Addr, error) {
+	lis, err := net.Listen("tcp", net.JoinHostPort(Host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind OCR HTTP endpoint: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:           NewHandler(recognize, stats),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("httpapi: server stopped: %v", err)
+		}
+	}()
+	return lis.Addr(), nil
+}
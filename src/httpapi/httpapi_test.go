@@ -0,0 +1,198 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"screen-ocr-llm/src/metrics"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("png.Encode failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// newRequest returns a request as it arrives at the endpoint listening on
+// 127.0.0.1:49560.
+func newRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Host = "127.0.0.1:49560"
+	local := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 49560}
+	return req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, local))
+}
+
+func post(t *testing.T, h http.Handler, body []byte, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := newRequest(http.MethodPost, "/ocr", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "image/png")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerPlainText(t *testing.T) {
+	h := NewHandler(func(ctx context.Context, image []byte) (string, error) { return "Hello", nil }, nil)
+
+	rec := post(t, h, testPNG(t), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if body, _ := io.ReadAll(rec.Body); string(body) != "Hello" {
+		t.Fatalf("body = %q, want %q", body, "Hello")
+	}
+}
+
+func TestHandlerJSON(t *testing.T) {
+	h := NewHandler(func(ctx context.Context, image []byte) (string, error) { return "Hello", nil }, nil)
+
+	rec := post(t, h, testPNG(t), "application/json")
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	var resp successResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp.Text != "Hello" || resp.CharCount != 5 {
+		t.Fatalf("response = %+v", resp)
+	}
+}
+
+func TestHandlerBusy(t *testing.T) {
+	h := NewHandler(func(ctx context.Context, image []byte) (string, error) { return "", ErrBusy }, nil)
+
+	rec := post(t, h, testPNG(t), "application/json")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After header")
+	}
+}
+
+func TestHandlerRejectsBadRequests(t *testing.T) {
+	called := false
+	h := NewHandler(func(ctx context.Context, image []byte) (string, error) {
+		called = true
+		return "", errors.New("unexpected")
+	}, nil)
+
+	if rec := post(t, h, nil, ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty body status = %d, want 400", rec.Code)
+	}
+	if rec := post(t, h, []byte("not an image"), ""); rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("non-PNG status = %d, want 415", rec.Code)
+	}
+
+	req := newRequest(http.MethodGet, "/ocr", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET status = %d, want 405", rec.Code)
+	}
+
+	req = newRequest(http.MethodPost, "/ocr", bytes.NewReader(testPNG(t)))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("missing Content-Type status = %d, want 415", rec.Code)
+	}
+
+	for _, host := range []string{"attacker.example:49560", "127.0.0.1:8080", "127.0.0.1"} {
+		req = newRequest(http.MethodPost, "/ocr", bytes.NewReader(testPNG(t)))
+		req.Host = host
+		req.Header.Set("Content-Type", "image/png")
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("Host %q status = %d, want 403", host, rec.Code)
+		}
+	}
+	if called {
+		t.Fatal("recognize should not be called for rejected requests")
+	}
+}
+
+func TestHandlerAcceptsLocalhost(t *testing.T) {
+	h := NewHandler(func(ctx context.Context, image []byte) (string, error) { return "Hello", nil }, nil)
+
+	req := newRequest(http.MethodPost, "/ocr", bytes.NewReader(testPNG(t)))
+	req.Host = "localhost:49560"
+	req.Header.Set("Content-Type", "image/png")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestHandlerMetrics(t *testing.T) {
+	h := NewHandler(func(ctx context.Context, image []byte) (string, error) { return "", nil }, func() metrics.Snapshot {
+		return metrics.Snapshot{Total: 3, Successes: 2, Failures: 1, LastError: "boom"}
+	})
+
+	req := newRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `screen_ocr_jobs_total{outcome="success"} 2`) {
+		t.Fatalf("text response = %d %q", rec.Code, rec.Body.String())
+	}
+
+	req = newRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var got metrics.Snapshot
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Total != 3 || got.LastError != "boom" {
+		t.Fatalf("JSON response = %+v", got)
+	}
+
+	req = newRequest(http.MethodPost, "/metrics", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d, want 405", rec.Code)
+	}
+}
+
+func TestStartBindsLoopback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := Start(ctx, 0, func(ctx context.Context, image []byte) (string, error) { return "ok", nil }, nil)
+	if err != nil {
+		t.Skipf("loopback unavailable: %v", err)
+	}
+	if !strings.HasPrefix(addr.String(), Host+":") {
+		t.Fatalf("bound to %s, want %s", addr, Host)
+	}
+
+	resp, err := http.Post("http://"+addr.String()+"/ocr", "image/png", bytes.NewReader(testPNG(t)))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("response = %d %q", resp.StatusCode, body)
+	}
+}
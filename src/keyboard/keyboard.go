@@ -0,0 +1,85 @@
+// Package keyboard types text into the focused window as synthesized
+// keystrokes, for applications that do not accept a paste: terminals, games
+// and remote sessions.
+package keyboard
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+	"unicode/utf16"
+)
+
+// DefaultDelay is the pause between typed characters unless SetDelay changes
+// it. Some applications drop keystrokes that arrive faster.
+const DefaultDelay = 5 * time.Millisecond
+
+// ErrUnsupported is returned by Type on platforms that cannot synthesize
+// keystrokes.
+var ErrUnsupported = errors.New("typing is only supported on Windows")
+
+var delay atomic.Int64
+
+func init() {
+	delay.Store(int64(DefaultDelay))
+}
+
+// SetDelay sets the pause between typed characters (TYPE_DELAY_MS). Zero
+// types as fast as the system accepts input.
+func SetDelay(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	delay.Store(int64(d))
+}
+
+// Delay returns the pause between typed characters.
+func Delay() time.Duration {
+	return time.Duration(delay.Load())
+}
+
+// Type sends text to whatever window has the keyboard focus, one character
+// at a time with Delay between them. Line breaks are typed as Enter and tabs
+// as Tab; every other character is sent as a Unicode keystroke, so the
+// keyboard layout does not matter.
+func Type(text string) error {
+	if text == "" {
+		return nil
+	}
+	return typeKeys(keystrokes(text), Delay())
+}
+
+// keystroke is one typed character: a virtual key for Enter and Tab, or the
+// UTF-16 code units of any other character (two for a surrogate pair).
+type keystroke struct {
+	vk    uint16
+	units []uint16
+}
+
+const (
+	vkTab    = 0x09
+	vkReturn = 0x0D
+)
+
+// keystrokes splits text into the keystrokes that type it. "\r\n" is one
+// Enter.
+func keystrokes(text string) []keystroke {
+	var keys []keystroke
+	prevCR := false
+	for _, r := range text {
+		switch r {
+		case '\n':
+			if !prevCR {
+				keys = append(keys, keystroke{vk: vkReturn})
+			}
+		case '\r':
+			keys = append(keys, keystroke{vk: vkReturn})
+		case '\t':
+			keys = append(keys, keystroke{vk: vkTab})
+		default:
+			keys = append(keys, keystroke{units: utf16.AppendRune(nil, r)})
+		}
+		prevCR = r == '\r'
+	}
+	return keys
+}
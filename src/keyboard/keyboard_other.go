@@ -0,0 +1,10 @@
+//go:build !windows
+
+package keyboard
+
+import "time"
+
+// typeKeys types nothing: synthesizing keystrokes is Windows-only.
+func typeKeys([]keystroke, time.Duration) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,36 @@
+package keyboard
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeystrokes(t *testing.T) {
+	got := keystrokes("a\r\nb\n\té😀")
+	want := []keystroke{
+		{units: []uint16{'a'}},
+		{vk: vkReturn},
+		{units: []uint16{'b'}},
+		{vk: vkReturn},
+		{vk: vkTab},
+		{units: []uint16{0xE9}},
+		{units: []uint16{0xD83D, 0xDE00}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("keystrokes() = %v, want %v", got, want)
+	}
+}
+
+func TestSetDelay(t *testing.T) {
+	defer SetDelay(DefaultDelay)
+
+	SetDelay(20 * time.Millisecond)
+	if Delay() != 20*time.Millisecond {
+		t.Fatalf("Delay() = %v, want 20ms", Delay())
+	}
+	SetDelay(-time.Second)
+	if Delay() != 0 {
+		t.Fatalf("Delay() = %v after a negative delay, want 0", Delay())
+	}
+}
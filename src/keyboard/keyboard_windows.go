@@ -0,0 +1,74 @@
+//go:build windows
+
+package keyboard
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	user32        = syscall.NewLazyDLL("user32.dll")
+	procSendInput = user32.NewProc("SendInput")
+)
+
+const (
+	inputKeyboard    = 1
+	keyeventfKeyUp   = 0x0002
+	keyeventfUnicode = 0x0004
+)
+
+// keybdInput is KEYBDINPUT.
+type keybdInput struct {
+	vk        uint16
+	scan      uint16
+	flags     uint32
+	time      uint32
+	extraInfo uintptr
+}
+
+// input is INPUT holding a KEYBDINPUT. The padding makes it as large as the
+// union's biggest member, MOUSEINPUT, since SendInput checks the size.
+type input struct {
+	typ uint32
+	ki  keybdInput
+	_   [8]byte
+}
+
+// typeKeys sends each keystroke as its own SendInput call, pausing delay
+// between them.
+func typeKeys(keys []keystroke, delay time.Duration) error {
+	for i, k := range keys {
+		if i > 0 && delay > 0 {
+			time.Sleep(delay)
+		}
+		inputs := keyInputs(k)
+		n, _, callErr := procSendInput.Call(uintptr(len(inputs)), uintptr(unsafe.Pointer(&inputs[0])), unsafe.Sizeof(inputs[0]))
+		if int(n) != len(inputs) {
+			return fmt.Errorf("SendInput typed %d of %d characters: %v", i, len(keys), callErr)
+		}
+	}
+	return nil
+}
+
+// keyInputs presses and releases a virtual key, or the code units of a
+// character with KEYEVENTF_UNICODE, in which case the window receives the
+// character itself whatever the keyboard layout.
+func keyInputs(k keystroke) []input {
+	if k.units == nil {
+		return []input{
+			{typ: inputKeyboard, ki: keybdInput{vk: k.vk}},
+			{typ: inputKeyboard, ki: keybdInput{vk: k.vk, flags: keyeventfKeyUp}},
+		}
+	}
+	inputs := make([]input, 0, 2*len(k.units))
+	for _, u := range k.units {
+		inputs = append(inputs, input{typ: inputKeyboard, ki: keybdInput{scan: u, flags: keyeventfUnicode}})
+	}
+	for _, u := range k.units {
+		inputs = append(inputs, input{typ: inputKeyboard, ki: keybdInput{scan: u, flags: keyeventfUnicode | keyeventfKeyUp}})
+	}
+	return inputs
+}
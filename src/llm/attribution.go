@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Attribution headers sent when Config.HTTPReferer / Config.AppTitle are
+// empty. OpenRouter uses them to attribute requests to an app.
+const (
+	DefaultHTTPReferer = "https://github.com/cherjr/screen-ocr-llm"
+	DefaultAppTitle    = "Screen OCR Tool"
+)
+
+// validateAttribution checks that HTTP_REFERER and APP_TITLE can be sent as
+// header values: no control characters such as newlines.
+func validateAttribution(cfg *Config) error {
+	for _, h := range []struct{ name, value string }{
+		{"HTTP_REFERER", cfg.HTTPReferer},
+		{"APP_TITLE", cfg.AppTitle},
+	} {
+		if strings.IndexFunc(h.value, unicode.IsControl) >= 0 {
+			return fmt.Errorf("invalid %s %q: must not contain control characters", h.name, h.value)
+		}
+	}
+	return nil
+}
+
+// httpReferer returns the HTTP-Referer header value for the current config.
+func (c *Client) httpReferer() string {
+	cfg := c.currentConfig()
+	if cfg == nil || strings.TrimSpace(cfg.HTTPReferer) == "" {
+		return DefaultHTTPReferer
+	}
+	return strings.TrimSpace(cfg.HTTPReferer)
+}
+
+// appTitle returns the X-Title header value for the current config.
+func (c *Client) appTitle() string {
+	cfg := c.currentConfig()
+	if cfg == nil || strings.TrimSpace(cfg.AppTitle) == "" {
+		return DefaultAppTitle
+	}
+	return strings.TrimSpace(cfg.AppTitle)
+}
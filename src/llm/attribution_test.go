@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAttributionHeaders(t *testing.T) {
+	var referer, title string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		referer, title = r.Header.Get("HTTP-Referer"), r.Header.Get("X-Title")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"."}}]}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if err := Ping(); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if referer != DefaultHTTPReferer || title != DefaultAppTitle {
+		t.Fatalf("default headers = %q, %q", referer, title)
+	}
+
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL, HTTPReferer: "https://example.com/my-fork", AppTitle: " My OCR "}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if err := Ping(); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if referer != "https://example.com/my-fork" || title != "My OCR" {
+		t.Fatalf("configured headers = %q, %q", referer, title)
+	}
+}
+
+func TestInitRejectsControlCharactersInAttribution(t *testing.T) {
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+	defaultClient.cfg = nil
+
+	for _, cfg := range []*Config{
+		{APIKey: "test", Model: "m", HTTPReferer: "https://example.com\r\nX-Evil: 1"},
+		{APIKey: "test", Model: "m", AppTitle: "Title\x00"},
+	} {
+		if err := Init(cfg); err == nil {
+			t.Errorf("Init(%+v) expected error", cfg)
+		}
+	}
+	if defaultClient.cfg != nil {
+		t.Fatal("Init replaced the config despite invalid headers")
+	}
+}
@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Client sends OCR, translation and ping requests using its own Config, so
+// that a program can talk to several endpoints or models at once. Create one
+// with New; the package-level functions use a default client configured by
+// Init. A Client is safe for concurrent use.
+type Client struct {
+	// mu guards cfg: SetModel replaces it while requests read it from
+	// other goroutines.
+	mu  sync.RWMutex
+	cfg *Config
+	// lastModel holds the model that served the most recent successful
+	// request.
+	lastModel atomic.Value
+}
+
+// defaultClient serves the package-level functions.
+var defaultClient = &Client{}
+
+// New returns a client for cfg. It returns an error if cfg is invalid, for
+// the same reasons as Init.
+func New(cfg *Config) (*Client, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	return &Client{cfg: cfg}, nil
+}
+
+// validateConfig checks the parts of cfg that Init and New reject.
+func validateConfig(cfg *Config) error {
+	if cfg == nil {
+		return errors.New("LLM config is required")
+	}
+	if err := validateBaseURL(cfg.BaseURL); err != nil {
+		return err
+	}
+	if err := validateAttribution(cfg); err != nil {
+		return err
+	}
+	if err := validateGeneration(cfg); err != nil {
+		return err
+	}
+	if cfg.RequestTimeout < 0 {
+		return fmt.Errorf("invalid request timeout %v: must be positive", cfg.RequestTimeout)
+	}
+	return nil
+}
+
+// currentConfig returns the configuration set by New, Init or SetModel, or
+// nil before Init. A returned Config is never modified.
+func (c *Client) currentConfig() *Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg
+}
+
+// The package-level functions below call the same method on the default
+// client.
+
+// QueryVision is Client.QueryVision on the default client.
+func QueryVision(imageData []byte) (string, error) {
+	return defaultClient.QueryVision(imageData)
+}
+
+// QueryVisionWithPrompt is Client.QueryVisionWithPrompt on the default client.
+func QueryVisionWithPrompt(imageData []byte, prompt string) (string, error) {
+	return defaultClient.QueryVisionWithPrompt(imageData, prompt)
+}
+
+// QueryVisionWithUsage is Client.QueryVisionWithUsage on the default client.
+func QueryVisionWithUsage(imageData []byte) (string, Usage, error) {
+	return defaultClient.QueryVisionWithUsage(imageData)
+}
+
+// QueryVisionWithContext is Client.QueryVisionWithContext on the default
+// client.
+func QueryVisionWithContext(ctx context.Context, imageData []byte) (string, error) {
+	return defaultClient.QueryVisionWithContext(ctx, imageData)
+}
+
+// QueryVisionContinuation is Client.QueryVisionContinuation on the default
+// client.
+func QueryVisionContinuation(imageData []byte, previousTail string) (string, error) {
+	return defaultClient.QueryVisionContinuation(imageData, previousTail)
+}
+
+// QueryVisionContinuationWithContext is
+// Client.QueryVisionContinuationWithContext on the default client.
+func QueryVisionContinuationWithContext(ctx context.Context, imageData []byte, previousTail string) (string, error) {
+	return defaultClient.QueryVisionContinuationWithContext(ctx, imageData, previousTail)
+}
+
+// QueryVisionWithUncertainty is Client.QueryVisionWithUncertainty on the
+// default client.
+func QueryVisionWithUncertainty(imageData []byte) (string, []string, Usage, error) {
+	return defaultClient.QueryVisionWithUncertainty(imageData)
+}
+
+// QueryVisionStream is Client.QueryVisionStream on the default client.
+func QueryVisionStream(imageData []byte, onChunk func(chunk string)) (string, error) {
+	return defaultClient.QueryVisionStream(imageData, onChunk)
+}
+
+// Translate is Client.Translate on the default client.
+func Translate(text, targetLang string) (string, error) {
+	return defaultClient.Translate(text, targetLang)
+}
+
+// TranslateWithUsage is Client.TranslateWithUsage on the default client.
+func TranslateWithUsage(text, targetLang string) (string, Usage, error) {
+	return defaultClient.TranslateWithUsage(text, targetLang)
+}
+
+// TranslateWithContext is Client.TranslateWithContext on the default client.
+func TranslateWithContext(ctx context.Context, text, targetLang string) (string, Usage, error) {
+	return defaultClient.TranslateWithContext(ctx, text, targetLang)
+}
+
+// Ping is Client.Ping on the default client.
+func Ping() error {
+	return defaultClient.Ping()
+}
+
+// ValidateKey is Client.ValidateKey on the default client.
+func ValidateKey() error {
+	return defaultClient.ValidateKey()
+}
+
+// SetModel is Client.SetModel on the default client.
+func SetModel(model string) error {
+	return defaultClient.SetModel(model)
+}
+
+// CurrentModel is Client.CurrentModel on the default client.
+func CurrentModel() string {
+	return defaultClient.CurrentModel()
+}
+
+// ModelChain is Client.ModelChain on the default client.
+func ModelChain() []string {
+	return defaultClient.ModelChain()
+}
+
+// LastModel is Client.LastModel on the default client.
+func LastModel() string {
+	return defaultClient.LastModel()
+}
+
+// OutputFormat is Client.OutputFormat on the default client.
+func OutputFormat() string {
+	return defaultClient.OutputFormat()
+}
+
+// Mode is Client.Mode on the default client.
+func Mode() string {
+	return defaultClient.Mode()
+}
+
+// MarkUncertain is Client.MarkUncertain on the default client.
+func MarkUncertain() bool {
+	return defaultClient.MarkUncertain()
+}
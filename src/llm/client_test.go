@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// echoModel answers every chat request with the requested model's name.
+var echoModel = RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+	var chat ChatRequest
+	if err := json.NewDecoder(req.Body).Decode(&chat); err != nil {
+		return nil, err
+	}
+	body := fmt.Sprintf(`{"choices":[{"message":{"content":"from %s"}}]}`, chat.Model)
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+})
+
+func TestClientsAreIndependent(t *testing.T) {
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	defaultClient.cfg = nil
+
+	a, err := New(&Config{APIKey: "key", Model: "vendor/a", Transport: echoModel})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b, err := New(&Config{APIKey: "key", Model: "vendor/b", Transport: echoModel, OutputFormat: FormatCSV})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, tc := range []struct {
+			client *Client
+			want   string
+		}{{a, "from vendor/a"}, {b, "from vendor/b"}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if got, err := tc.client.QueryVision([]byte("png")); err != nil || got != tc.want {
+					t.Errorf("QueryVision() = %q, %v; want %q", got, err, tc.want)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if a.LastModel() != "vendor/a" || b.LastModel() != "vendor/b" {
+		t.Fatalf("unexpected last models %q and %q", a.LastModel(), b.LastModel())
+	}
+	if a.OutputFormat() != FormatText || b.OutputFormat() != FormatCSV {
+		t.Fatalf("unexpected output formats %q and %q", a.OutputFormat(), b.OutputFormat())
+	}
+	if err := a.SetModel("vendor/c"); err != nil {
+		t.Fatalf("SetModel failed: %v", err)
+	}
+	if a.CurrentModel() != "vendor/c" || b.CurrentModel() != "vendor/b" {
+		t.Fatalf("SetModel changed another client: %q, %q", a.CurrentModel(), b.CurrentModel())
+	}
+	if CurrentModel() != "" || defaultClient.cfg != nil {
+		t.Fatal("clients changed the default client")
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []*Config{nil, {BaseURL: "ftp://example.com"}, {MaxTokens: -1}, {RequestTimeout: -1}} {
+		if c, err := New(cfg); err == nil || c != nil {
+			t.Errorf("New(%+v) = %v, %v; want an error", cfg, c, err)
+		}
+	}
+}
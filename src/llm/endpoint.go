@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL is the OpenRouter API root used when Config.BaseURL is empty.
+const DefaultBaseURL = "https://openrouter.ai/api/v1"
+
+const chatCompletionsPath = "/chat/completions"
+
+// validateBaseURL checks that baseURL is an absolute http or https URL.
+// An empty value is valid and selects DefaultBaseURL.
+func validateBaseURL(baseURL string) error {
+	if baseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid OPENROUTER_BASE_URL %q: %v", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid OPENROUTER_BASE_URL %q: scheme must be http or https", baseURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid OPENROUTER_BASE_URL %q: missing host", baseURL)
+	}
+	return nil
+}
+
+// chatCompletionsURL builds the chat completions endpoint from baseURL.
+// A base that already ends in /chat/completions is used as-is.
+func chatCompletionsURL(baseURL string) string {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	if strings.HasSuffix(base, chatCompletionsPath) {
+		return base
+	}
+	return base + chatCompletionsPath
+}
+
+// endpoint returns the chat completions URL for the current config.
+func (c *Client) endpoint() string {
+	cfg := c.currentConfig()
+	if cfg == nil {
+		return chatCompletionsURL("")
+	}
+	return chatCompletionsURL(cfg.BaseURL)
+}
@@ -0,0 +1,49 @@
+package llm
+
+import "testing"
+
+func TestChatCompletionsURL(t *testing.T) {
+	tests := []struct {
+		base string
+		want string
+	}{
+		{base: "", want: "https://openrouter.ai/api/v1/chat/completions"},
+		{base: "http://localhost:4000/v1", want: "http://localhost:4000/v1/chat/completions"},
+		{base: "https://gateway.example.com/v1/", want: "https://gateway.example.com/v1/chat/completions"},
+		{base: "https://proxy.example.com/v1/chat/completions", want: "https://proxy.example.com/v1/chat/completions"},
+	}
+
+	for _, tt := range tests {
+		if got := chatCompletionsURL(tt.base); got != tt.want {
+			t.Errorf("chatCompletionsURL(%q) = %q, want %q", tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBaseURL(t *testing.T) {
+	valid := []string{"", "http://localhost:8000/v1", "https://openrouter.ai/api/v1"}
+	for _, base := range valid {
+		if err := validateBaseURL(base); err != nil {
+			t.Errorf("validateBaseURL(%q) unexpected error: %v", base, err)
+		}
+	}
+
+	invalid := []string{"localhost:8000", "ftp://example.com", "https://", "://bad"}
+	for _, base := range invalid {
+		if err := validateBaseURL(base); err == nil {
+			t.Errorf("validateBaseURL(%q) expected error", base)
+		}
+	}
+}
+
+func TestInitRejectsInvalidBaseURL(t *testing.T) {
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+
+	if err := Init(&Config{APIKey: "k", Model: "m", BaseURL: "not a url"}); err == nil {
+		t.Fatal("expected Init to reject an invalid base URL")
+	}
+	if defaultClient.cfg != prev {
+		t.Fatal("expected config to be left unchanged after a rejected Init")
+	}
+}
@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// LastModel returns the model that produced the most recent successful OCR
+// result, or "" if none has succeeded yet.
+func (c *Client) LastModel() string {
+	m, _ := c.lastModel.Load().(string)
+	return m
+}
+
+// modelChain returns the models to try in order: Config.Models when set,
+// otherwise Config.Model on its own.
+func (c *Client) modelChain() []string {
+	cfg := c.currentConfig()
+	if cfg == nil {
+		return nil
+	}
+	if len(cfg.Models) > 0 {
+		return cfg.Models
+	}
+	if cfg.Model == "" {
+		return nil
+	}
+	return []string{cfg.Model}
+}
+
+// ModelChain returns a copy of the models requests try, in order.
+func (c *Client) ModelChain() []string {
+	return append([]string(nil), c.modelChain()...)
+}
+
+// CurrentModel returns the model requests are sent to first, or "" if no
+// model is configured.
+func (c *Client) CurrentModel() string {
+	if models := c.modelChain(); len(models) > 0 {
+		return models[0]
+	}
+	return ""
+}
+
+// SetModel makes model the first model of the fallback chain; the models it
+// replaces stay in the chain after it. Requests started afterwards use it, and
+// requests in flight finish with the previous configuration.
+func (c *Client) SetModel(model string) error {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return errors.New("model is required")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cfg == nil {
+		return fmt.Errorf("LLM client not initialized")
+	}
+	chain := c.cfg.Models
+	if len(chain) == 0 && c.cfg.Model != "" {
+		chain = []string{c.cfg.Model}
+	}
+	next := *c.cfg
+	next.Model = model
+	next.Models = withFirstModel(chain, model)
+	c.cfg = &next
+	log.Printf("LLM: Switched model to %s (chain %v)", model, next.Models)
+	return nil
+}
+
+// withFirstModel returns models reordered so that model comes first.
+func withFirstModel(models []string, model string) []string {
+	chain := []string{model}
+	for _, m := range models {
+		if m != model {
+			chain = append(chain, m)
+		}
+	}
+	return chain
+}
+
+// isModelFailure reports whether err means the model itself is unusable
+// right now (unknown, removed, rate limited or unavailable after retries), so
+// the next model in the chain should be tried. Auth errors and network
+// failures are not model-specific and stop the chain.
+func isModelFailure(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch code := statusErr.StatusCode; {
+	case code == http.StatusNotFound, code == http.StatusGone, code == http.StatusTooManyRequests:
+		return true
+	case code >= 500:
+		return true
+	case code == http.StatusBadRequest:
+		return strings.Contains(strings.ToLower(statusErr.Error()), "not a valid model")
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIsModelFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "not found", err: &StatusError{StatusCode: 404, Err: errors.New("no endpoints")}, want: true},
+		{name: "unavailable", err: &StatusError{StatusCode: 503, Err: errors.New("down")}, want: true},
+		{name: "rate limited", err: &StatusError{StatusCode: 429, Err: errors.New("slow down")}, want: true},
+		{name: "invalid model id", err: &StatusError{StatusCode: 400, Err: errors.New("API error: foo/bar is not a valid model ID")}, want: true},
+		{name: "bad request", err: &StatusError{StatusCode: 400, Err: errors.New("bad image")}, want: false},
+		{name: "unauthorized", err: &StatusError{StatusCode: 401, Err: errors.New("no auth")}, want: false},
+		{name: "network", err: &networkError{err: errors.New("dial tcp")}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isModelFailure(tt.err); got != tt.want {
+				t.Fatalf("isModelFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelChain(t *testing.T) {
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+
+	defaultClient.cfg = &Config{Model: "a"}
+	if got := defaultClient.modelChain(); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("modelChain() = %v, want [a]", got)
+	}
+
+	defaultClient.cfg = &Config{Model: "a", Models: []string{"b", "c"}}
+	if got := defaultClient.modelChain(); len(got) != 2 || got[0] != "b" {
+		t.Fatalf("modelChain() = %v, want [b c]", got)
+	}
+}
+
+func TestSetModel(t *testing.T) {
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+
+	defaultClient.cfg = nil
+	if err := SetModel("a"); err == nil {
+		t.Fatal("expected an error before Init")
+	}
+
+	original := &Config{APIKey: "key", Model: "a", Models: []string{"a", "b", "c"}}
+	defaultClient.cfg = original
+	if err := SetModel(" c "); err != nil {
+		t.Fatalf("SetModel failed: %v", err)
+	}
+	if got := defaultClient.modelChain(); !reflect.DeepEqual(got, []string{"c", "a", "b"}) {
+		t.Fatalf("modelChain() = %v, want [c a b]", got)
+	}
+	if CurrentModel() != "c" || defaultClient.cfg.APIKey != "key" {
+		t.Fatalf("unexpected config after SetModel: %+v", defaultClient.cfg)
+	}
+	if original.Model != "a" || original.Models[0] != "a" {
+		t.Fatal("SetModel modified the previous config")
+	}
+
+	defaultClient.cfg = &Config{Model: "a"}
+	if err := SetModel("b"); err != nil {
+		t.Fatalf("SetModel failed: %v", err)
+	}
+	if got := defaultClient.modelChain(); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Fatalf("modelChain() = %v, want [b a]", got)
+	}
+	if err := SetModel(""); err == nil {
+		t.Fatal("expected an error for an empty model")
+	}
+}
+
+func TestSetModelConcurrentWithRequests(t *testing.T) {
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	defaultClient.cfg = &Config{APIKey: "key", Model: "a"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = SetModel("b")
+		}()
+		go func() {
+			defer wg.Done()
+			if _, _, err := defaultClient.newVisionRequest([]byte("png"), ocrPrompt); err != nil {
+				t.Errorf("newVisionRequest failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestQueryVisionFallsBackToNextModel(t *testing.T) {
+	var tried []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		tried = append(tried, req.Model)
+		if req.Model == "gone/model" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"No endpoints found","code":404}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Hello"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	if err := Init(&Config{
+		APIKey:         "test",
+		Models:         []string{"gone/model", "backup/model"},
+		BaseURL:        server.URL,
+		MaxRetries:     2,
+		RetryBaseDelay: time.Millisecond,
+	}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	text, err := QueryVision([]byte{0x89, 0x50, 0x4E, 0x47})
+	if err != nil {
+		t.Fatalf("QueryVision failed: %v", err)
+	}
+	if text != "Hello" {
+		t.Fatalf("QueryVision() = %q, want %q", text, "Hello")
+	}
+	if len(tried) != 2 || tried[0] != "gone/model" || tried[1] != "backup/model" {
+		t.Fatalf("models tried = %v, want [gone/model backup/model]", tried)
+	}
+}
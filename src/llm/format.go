@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+)
+
+// Output formats accepted by OCR_OUTPUT_FORMAT.
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatCSV      = "csv"
+)
+
+const tablePrompt = "Perform OCR on this image. Return ONLY the extracted content with:\n" +
+	"- Tables as GitHub-flavored markdown tables: a header row, a |---| separator row, one row per line\n" +
+	"- Empty cells left empty; | inside a cell escaped as \\|\n" +
+	"- Any text outside tables as plain lines, preserving line breaks\n" +
+	"- No code fences, no explanations\n" +
+	"If no text found, return 'NO_TEXT_FOUND'"
+
+// OutputFormat returns the configured output format, defaulting to text.
+func (c *Client) OutputFormat() string {
+	cfg := c.currentConfig()
+	if cfg == nil {
+		return FormatText
+	}
+	switch cfg.OutputFormat {
+	case FormatMarkdown, FormatCSV:
+		return cfg.OutputFormat
+	}
+	return FormatText
+}
+
+// basePrompt returns the prompt for the configured mode and output format.
+func (c *Client) basePrompt() string {
+	if c.Mode() == ModeDescribe {
+		return describePrompt
+	}
+	if c.OutputFormat() == FormatText {
+		return ocrPrompt
+	}
+	return tablePrompt
+}
+
+// formatText post-processes model output for the configured format.
+// Descriptions are returned unchanged.
+func (c *Client) formatText(text string) string {
+	if c.OutputFormat() == FormatCSV && c.Mode() != ModeDescribe {
+		return toCSV(text)
+	}
+	return text
+}
+
+// toCSV converts the markdown table rows in text to CSV records. Separator
+// rows and code fences are dropped and lines outside tables become
+// single-field records. Text without any table row is returned unchanged.
+func toCSV(text string) string {
+	var records [][]string
+	hasTable := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "```"):
+			continue
+		case strings.HasPrefix(line, "|"):
+			cells := splitTableRow(line)
+			if isSeparatorRow(cells) {
+				continue
+			}
+			hasTable = true
+			records = append(records, cells)
+		default:
+			records = append(records, []string{line})
+		}
+	}
+	if !hasTable {
+		return text
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.WriteAll(records) // writes to a bytes.Buffer cannot fail
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// splitTableRow splits a markdown table row into trimmed cells, honouring
+// escaped pipes.
+func splitTableRow(line string) []string {
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// isSeparatorRow reports whether cells form a |---|:--:| header separator.
+func isSeparatorRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.Trim(c, ":-") != "" || !strings.Contains(c, "-") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package llm
+
+import "testing"
+
+func TestToCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "simple table",
+			in:   "| Name | Qty |\n|------|----:|\n| Apples | 3 |\n| Pears |  |",
+			want: "Name,Qty\nApples,3\nPears,",
+		},
+		{
+			name: "quotes, commas and escaped pipes",
+			in:   "```\n| Item | Note |\n| :-- | :-: |\n| a, b | say \"hi\" |\n| x \\| y | z |\n```",
+			want: "Item,Note\n\"a, b\",\"say \"\"hi\"\"\"\nx | y,z",
+		},
+		{
+			name: "text around a table",
+			in:   "Q3 totals\n\n| A | B |\n|---|---|\n| 1 | 2 |",
+			want: "Q3 totals\nA,B\n1,2",
+		},
+		{
+			name: "no table is unchanged",
+			in:   "just some\nplain text",
+			want: "just some\nplain text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toCSV(tt.in); got != tt.want {
+				t.Fatalf("toCSV() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBasePromptFollowsOutputFormat(t *testing.T) {
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+
+	for format, want := range map[string]string{
+		"":             ocrPrompt,
+		FormatText:     ocrPrompt,
+		"html":         ocrPrompt,
+		FormatMarkdown: tablePrompt,
+		FormatCSV:      tablePrompt,
+	} {
+		defaultClient.cfg = &Config{OutputFormat: format}
+		if got := defaultClient.basePrompt(); got != want {
+			t.Errorf("basePrompt() with format %q = %q", format, got)
+		}
+	}
+
+	defaultClient.cfg = &Config{OutputFormat: FormatMarkdown}
+	if got := defaultClient.formatText("| a |\n|---|\n| 1 |"); got != "| a |\n|---|\n| 1 |" {
+		t.Errorf("markdown output should be unchanged, got %q", got)
+	}
+}
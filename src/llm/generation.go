@@ -0,0 +1,42 @@
+package llm
+
+import "fmt"
+
+// Defaults and limits for the OCR completion parameters (OCR_MAX_TOKENS,
+// OCR_TEMPERATURE).
+const (
+	DefaultMaxTokens   = 2000
+	MaxMaxTokens       = 32000
+	DefaultTemperature = 0.1
+	MaxTemperature     = 2.0
+)
+
+// validateGeneration checks the completion parameters in cfg. A zero
+// MaxTokens or nil Temperature means the default and is always valid.
+func validateGeneration(cfg *Config) error {
+	if cfg.MaxTokens < 0 || cfg.MaxTokens > MaxMaxTokens {
+		return fmt.Errorf("invalid OCR_MAX_TOKENS %d: must be between 1 and %d", cfg.MaxTokens, MaxMaxTokens)
+	}
+	if t := cfg.Temperature; t != nil && (*t < 0 || *t > MaxTemperature) {
+		return fmt.Errorf("invalid OCR_TEMPERATURE %g: must be between 0 and %g", *t, MaxTemperature)
+	}
+	return nil
+}
+
+// maxTokens returns the max_tokens sent with OCR requests.
+func (c *Client) maxTokens() int {
+	cfg := c.currentConfig()
+	if cfg == nil || cfg.MaxTokens == 0 {
+		return DefaultMaxTokens
+	}
+	return cfg.MaxTokens
+}
+
+// temperature returns the sampling temperature sent with OCR requests.
+func (c *Client) temperature() float64 {
+	cfg := c.currentConfig()
+	if cfg == nil || cfg.Temperature == nil {
+		return DefaultTemperature
+	}
+	return *cfg.Temperature
+}
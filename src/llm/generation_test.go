@@ -0,0 +1,52 @@
+package llm
+
+import "testing"
+
+func TestValidateGeneration(t *testing.T) {
+	temp := func(v float64) *float64 { return &v }
+
+	valid := []*Config{
+		{},
+		{MaxTokens: 1, Temperature: temp(0)},
+		{MaxTokens: MaxMaxTokens, Temperature: temp(MaxTemperature)},
+	}
+	for _, cfg := range valid {
+		if err := validateGeneration(cfg); err != nil {
+			t.Errorf("validateGeneration(%+v) unexpected error: %v", cfg, err)
+		}
+	}
+
+	invalid := []*Config{
+		{MaxTokens: -1},
+		{MaxTokens: MaxMaxTokens + 1},
+		{Temperature: temp(-0.1)},
+		{Temperature: temp(2.5)},
+	}
+	for _, cfg := range invalid {
+		if err := validateGeneration(cfg); err == nil {
+			t.Errorf("validateGeneration(%+v) expected error", cfg)
+		}
+	}
+}
+
+func TestGenerationDefaults(t *testing.T) {
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+
+	defaultClient.cfg = &Config{}
+	if got := defaultClient.maxTokens(); got != DefaultMaxTokens {
+		t.Errorf("maxTokens() = %d, want %d", got, DefaultMaxTokens)
+	}
+	if got := defaultClient.temperature(); got != DefaultTemperature {
+		t.Errorf("temperature() = %g, want %g", got, DefaultTemperature)
+	}
+
+	zero := 0.0
+	defaultClient.cfg = &Config{MaxTokens: 8000, Temperature: &zero}
+	if got := defaultClient.maxTokens(); got != 8000 {
+		t.Errorf("maxTokens() = %d, want 8000", got)
+	}
+	if got := defaultClient.temperature(); got != 0 {
+		t.Errorf("temperature() = %g, want 0", got)
+	}
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Reasons a KeyError can carry; match them with errors.Is.
+var (
+	ErrInvalidKey         = errors.New("invalid API key")
+	ErrInsufficientCredit = errors.New("insufficient credits")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrUnreachable        = errors.New("API unreachable")
+)
+
+// KeyError is returned by ValidateKey when the startup request failed for a
+// reason the user can act on. Error gives a tailored message; errors.Is
+// matches Reason and errors.As reaches the underlying *StatusError.
+type KeyError struct {
+	Reason error
+	Err    error
+	// Endpoint is the URL the request was sent to.
+	Endpoint string
+}
+
+func (e *KeyError) Error() string {
+	var hint string
+	switch e.Reason {
+	case ErrInvalidKey:
+		hint = "The API key was rejected. Check OPENROUTER_API_KEY, the key file or the Credential Manager entry, and that the key is not disabled."
+	case ErrInsufficientCredit:
+		hint = "The OpenRouter account is out of credits. Add credits at https://openrouter.ai/settings/credits."
+	case ErrRateLimited:
+		hint = "The API is rate limiting this key. Wait a minute and try again."
+	case ErrUnreachable:
+		hint = fmt.Sprintf("Cannot reach %s. Check your network connection, proxy or OPENROUTER_BASE_URL.", e.Endpoint)
+	}
+	return fmt.Sprintf("%v: %s (%v)", e.Reason, hint, e.Err)
+}
+
+func (e *KeyError) Unwrap() []error { return []error{e.Reason, e.Err} }
+
+// ValidateKey makes the minimal Ping request and classifies failures as a
+// *KeyError (invalid key, no credits, rate limited, unreachable). Other
+// failures, such as an unknown model, are returned unchanged.
+func (c *Client) ValidateKey() error {
+	err := c.Ping()
+	if err == nil {
+		return nil
+	}
+	if reason := keyFailureReason(err); reason != nil {
+		return &KeyError{Reason: reason, Err: err, Endpoint: c.endpoint()}
+	}
+	return err
+}
+
+// keyFailureReason maps a request error to a KeyError reason, or nil.
+func keyFailureReason(err error) error {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrInvalidKey
+		case http.StatusPaymentRequired:
+			return ErrInsufficientCredit
+		case http.StatusTooManyRequests:
+			return ErrRateLimited
+		}
+		return nil
+	}
+	var netErr *networkError
+	if errors.As(err, &netErr) {
+		return ErrUnreachable
+	}
+	return nil
+}
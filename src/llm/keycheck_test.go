@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateKeyClassifiesFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		reason error
+		hint   string
+	}{
+		{name: "invalid key", status: 401, body: `{"error":{"message":"No auth credentials found","code":401}}`, reason: ErrInvalidKey, hint: "OPENROUTER_API_KEY"},
+		{name: "disabled key", status: 403, body: `{"error":{"message":"Key disabled","code":403}}`, reason: ErrInvalidKey, hint: "OPENROUTER_API_KEY"},
+		{name: "no credits", status: 402, body: `{"error":{"message":"Insufficient credits","code":402}}`, reason: ErrInsufficientCredit, hint: "credits"},
+		{name: "rate limited", status: 429, body: `{"error":{"message":"Rate limit exceeded","code":429}}`, reason: ErrRateLimited, hint: "try again"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			prev := defaultClient.cfg
+			defer func() { defaultClient.cfg = prev }()
+			if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL}); err != nil {
+				t.Fatalf("Init failed: %v", err)
+			}
+
+			err := ValidateKey()
+			if !errors.Is(err, tt.reason) {
+				t.Fatalf("ValidateKey() = %v, want reason %v", err, tt.reason)
+			}
+			var keyErr *KeyError
+			var statusErr *StatusError
+			if !errors.As(err, &keyErr) || !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Fatalf("expected *KeyError wrapping status %d, got %#v", tt.status, err)
+			}
+			if !strings.Contains(err.Error(), tt.hint) {
+				t.Fatalf("message %q does not mention %q", err.Error(), tt.hint)
+			}
+		})
+	}
+}
+
+func TestValidateKeyUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: url}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	if err := ValidateKey(); !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("ValidateKey() = %v, want ErrUnreachable", err)
+	}
+}
+
+func TestValidateKeyPassesThroughOtherErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"message":"No endpoints found","code":404}}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	if err := Init(&Config{APIKey: "test", Model: "gone/model", BaseURL: server.URL}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	err := ValidateKey()
+	var keyErr *KeyError
+	if err == nil || errors.As(err, &keyErr) {
+		t.Fatalf("expected an unclassified error, got %v", err)
+	}
+}
@@ -2,29 +2,92 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	APIKey    string
-	Model     string
+	APIKey string
+	Model  string
+	// Models is the ordered fallback chain tried by QueryVision. When empty,
+	// only Model is used.
+	Models    []string
 	Providers []string
+	// AllowFallbacks lets OpenRouter route to providers outside Providers
+	// when none of them is available. Only sent when Providers is set.
+	AllowFallbacks bool
+	// Quantizations restricts routing to providers serving the model at
+	// one of these quantizations (e.g. "fp16", "int8").
+	Quantizations []string
+	// Language, if set, is added to the OCR prompt as a hint about the
+	// expected script/language (e.g. "Japanese").
+	Language string
+	// BaseURL is the API root requests are sent to, e.g. an OpenAI-compatible
+	// proxy. Empty uses DefaultBaseURL.
+	BaseURL string
+	// MaxRetries is the maximum number of attempts per OCR request,
+	// including the first. Zero or less uses the default of 3.
+	MaxRetries int
+	// RetryBaseDelay is the wait before the first retry; each further retry
+	// waits 1.5x longer. Zero or less uses the default of 1s.
+	RetryBaseDelay time.Duration
+	// RequestTimeout is the HTTP timeout of each OCR request attempt. Zero
+	// uses DefaultRequestTimeout; negative values are rejected by Init.
+	RequestTimeout time.Duration
+	// MaxTokens caps the length of the OCR output (1..32000). Zero uses
+	// DefaultMaxTokens.
+	MaxTokens int
+	// Temperature is the sampling temperature for OCR requests (0..2). Nil
+	// uses DefaultTemperature.
+	Temperature *float64
+	// OutputFormat is FormatText (default), FormatMarkdown or FormatCSV.
+	// Markdown and CSV ask the model for tables; CSV then converts them.
+	OutputFormat string
+	// Mode is ModeOCR (default) or ModeDescribe, which asks for a short
+	// description of the image instead of its text.
+	Mode string
+	// Transport, if set, carries every API request instead of the default
+	// HTTP transport: tests inject canned responses and LLM_BACKEND=mock uses
+	// MockTransport.
+	Transport http.RoundTripper
+	// HTTPReferer and AppTitle are sent as the HTTP-Referer and X-Title
+	// attribution headers. Empty uses DefaultHTTPReferer / DefaultAppTitle.
+	HTTPReferer string
+	AppTitle    string
+	// MarkUncertain asks the model to wrap text it cannot read with
+	// confidence in markers, which are stripped from the result (see
+	// QueryVisionWithUncertainty). Streamed requests are not marked.
+	MarkUncertain bool
 }
 
-var config *Config
-
-func Init(cfg *Config) {
-	config = cfg
+// Init sets the configuration of the default client used by the package-level
+// functions. It returns an error, leaving the previous configuration in
+// place, if cfg.BaseURL is not a valid http/https URL, MaxTokens/Temperature
+// are out of range, RequestTimeout is negative or the attribution headers
+// contain control characters.
+func Init(cfg *Config) error {
+	if err := validateConfig(cfg); err != nil {
+		return err
+	}
+	defaultClient.mu.Lock()
+	defaultClient.cfg = cfg
+	defaultClient.mu.Unlock()
 	if len(cfg.Providers) > 0 {
 		log.Printf("LLM: Initialized with %d provider(s): %v", len(cfg.Providers), cfg.Providers)
 	} else {
 		log.Printf("LLM: Initialized with no specific providers (using OpenRouter default routing)")
 	}
+	log.Printf("LLM: Using endpoint %s", defaultClient.endpoint())
+	log.Printf("LLM: max_tokens=%d temperature=%g", defaultClient.maxTokens(), defaultClient.temperature())
+	log.Printf("LLM: request timeout %v", defaultClient.requestTimeout())
+	return nil
 }
 
 // OpenRouter API structures
@@ -55,59 +118,226 @@ type ChatRequest struct {
 	Temperature float64              `json:"temperature"`
 	MaxTokens   int                  `json:"max_tokens"`
 	Provider    *ProviderPreferences `json:"provider,omitempty"`
+	Stream      bool                 `json:"stream,omitempty"`
 }
 
 type ChatResponse struct {
 	Choices []Choice  `json:"choices"`
+	Usage   Usage     `json:"usage"`
 	Error   *APIError `json:"error,omitempty"`
+	// Model and Provider are what OpenRouter routed the request to.
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// Usage is the token accounting OpenRouter reports for a completion. Cost is
+// only populated when the API includes it (in credits). Provider and Model
+// name who served the request, so routing can be audited; the API reports
+// them next to the usage object rather than in it (see servedUsage).
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost,omitempty"`
+	Provider         string  `json:"provider,omitempty"`
+	Model            string  `json:"model,omitempty"`
 }
 
 type Choice struct {
-	Message ResponseMessage `json:"message"`
+	Message      ResponseMessage `json:"message"`
+	FinishReason string          `json:"finish_reason,omitempty"`
 }
 
 type ResponseMessage struct {
 	Content string `json:"content"`
+	Refusal string `json:"refusal,omitempty"`
 }
 
+// Errors returned when the model produced no usable text. ErrNoTextFound
+// means the image has no text: the model answered NO_TEXT_FOUND or returned
+// an empty response.
+var (
+	ErrNoTextFound     = errors.New("no text found")
+	ErrRefused         = errors.New("model refused the request")
+	ErrContentFiltered = errors.New("response blocked by content filter")
+)
+
 type APIError struct {
 	Message string      `json:"message"`
 	Type    string      `json:"type"`
 	Code    interface{} `json:"code"` // Can be string or number
 }
 
-const (
-	openRouterURL = "https://openrouter.ai/api/v1/chat/completions"
-)
+const ocrPrompt = "Perform OCR on this image. Return ONLY the raw extracted text with:\n" +
+	"- No formatting\n" +
+	"- No XML/HTML tags\n" +
+	"- No markdown\n" +
+	"- No explanations\n" +
+	"- Preserve line breaks accurately from the visual layout.\n" +
+	"If no text found, return 'NO_TEXT_FOUND'"
+
+// withLanguageHint appends the configured language hint to prompt. The hint
+// is additive and leaves the NO_TEXT_FOUND instruction untouched.
+func (c *Client) withLanguageHint(prompt string) string {
+	cfg := c.currentConfig()
+	if cfg == nil || strings.TrimSpace(cfg.Language) == "" {
+		return prompt
+	}
+	return prompt + "\n\nThe text is primarily in " + strings.TrimSpace(cfg.Language) + "."
+}
 
 // getProviderPreferences returns provider preferences based on config
-func getProviderPreferences() *ProviderPreferences {
-	if config == nil || len(config.Providers) == 0 {
+func (c *Client) getProviderPreferences() *ProviderPreferences {
+	cfg := c.currentConfig()
+	prefs := providerPreferences(cfg)
+	if prefs == nil {
 		// No providers specified, use default OpenRouter routing
 		log.Printf("LLM: No provider preferences configured, using OpenRouter default routing")
 		return nil
 	}
+	allowFallbacks := "default"
+	if prefs.AllowFallbacks != nil {
+		allowFallbacks = fmt.Sprint(*prefs.AllowFallbacks)
+	}
+	log.Printf("LLM: Using provider preferences: order=%v, allow_fallbacks=%s, quantizations=%v", prefs.Order, allowFallbacks, prefs.Quantizations)
+	return prefs
+}
 
-	// Use the providers exactly as specified by the user
-	allowFallbacks := false
-	prefs := &ProviderPreferences{
-		Order:          config.Providers,
-		AllowFallbacks: &allowFallbacks,
+// providerPreferences maps cfg to the request's provider object. Providers
+// are used exactly as specified, without fallbacks unless AllowFallbacks is
+// set; allow_fallbacks is left to OpenRouter when no order is given.
+func providerPreferences(cfg *Config) *ProviderPreferences {
+	if cfg == nil || (len(cfg.Providers) == 0 && len(cfg.Quantizations) == 0) {
+		return nil
+	}
+	prefs := &ProviderPreferences{Quantizations: cfg.Quantizations}
+	if len(cfg.Providers) > 0 {
+		allowFallbacks := cfg.AllowFallbacks
+		prefs.Order = cfg.Providers
+		prefs.AllowFallbacks = &allowFallbacks
 	}
-	log.Printf("LLM: Using provider preferences: order=%v, allow_fallbacks=%v", prefs.Order, *prefs.AllowFallbacks)
 	return prefs
 }
 
-// QueryVision sends an image to OpenRouter vision model for OCR
-func QueryVision(imageData []byte) (string, error) {
-	if config == nil {
-		return "", fmt.Errorf("LLM client not initialized")
+// QueryVision sends an image to OpenRouter vision model for OCR, or for a
+// description in ModeDescribe.
+func (c *Client) QueryVision(imageData []byte) (string, error) {
+	return c.QueryVisionWithPrompt(imageData, c.basePrompt())
+}
+
+// QueryVisionWithPrompt sends an image to the vision model with a custom
+// prompt. The response is handled like an OCR result: the language hint is
+// appended to prompt and empty or refused responses become errors.
+func (c *Client) QueryVisionWithPrompt(imageData []byte, prompt string) (string, error) {
+	text, _, err := c.queryVision(context.Background(), imageData, prompt)
+	return text, err
+}
+
+// QueryVisionWithUsage is like QueryVision but also returns the token usage
+// reported for the call. Usage is returned whenever the API responded, even
+// if no text could be extracted.
+func (c *Client) QueryVisionWithUsage(imageData []byte) (string, Usage, error) {
+	return c.queryVision(context.Background(), imageData, c.basePrompt())
+}
+
+// QueryVisionWithContext is like QueryVision but aborts the API request,
+// including retries and fallback models, once ctx is done. The error then
+// wraps ctx.Err().
+func (c *Client) QueryVisionWithContext(ctx context.Context, imageData []byte) (string, error) {
+	text, _, err := c.queryVision(ctx, imageData, c.basePrompt())
+	return text, err
+}
+
+// QueryVisionContinuation performs OCR on an image that continues a previous
+// capture. previousTail is the end of the previous result; it is given to the
+// model as context so that text split across captures continues seamlessly.
+func (c *Client) QueryVisionContinuation(imageData []byte, previousTail string) (string, error) {
+	return c.QueryVisionContinuationWithContext(context.Background(), imageData, previousTail)
+}
+
+// QueryVisionContinuationWithContext is like QueryVisionContinuation but
+// aborts the API request once ctx is done (see QueryVisionWithContext).
+func (c *Client) QueryVisionContinuationWithContext(ctx context.Context, imageData []byte, previousTail string) (string, error) {
+	if previousTail == "" || c.Mode() == ModeDescribe {
+		return c.QueryVisionWithContext(ctx, imageData)
+	}
+	prompt := c.basePrompt() + "\n\n" +
+		"This image continues a document. The previous capture ended with:\n" +
+		"\"\"\"\n" + previousTail + "\n\"\"\"\n" +
+		"Use it only as context to continue the text seamlessly. Do not repeat it."
+	text, _, err := c.queryVision(ctx, imageData, prompt)
+	return text, err
+}
+
+func (c *Client) queryVision(ctx context.Context, imageData []byte, prompt string) (string, Usage, error) {
+	text, _, usage, err := c.queryVisionUncertain(ctx, imageData, prompt)
+	return text, usage, err
+}
+
+// queryVisionUncertain sends an OCR request and returns the text and, when
+// MarkUncertain is on, the spans the model marked as uncertain.
+func (c *Client) queryVisionUncertain(ctx context.Context, imageData []byte, prompt string) (string, []string, Usage, error) {
+	request, models, err := c.newVisionRequest(imageData, c.withUncertainMarking(prompt))
+	if err != nil {
+		return "", nil, Usage{}, err
+	}
+
+	// Transient failures are retried per model inside makeAPIRequest; hard
+	// model failures move on to the next model in the chain.
+	var response *ChatResponse
+	for i, model := range models {
+		request.Model = model
+		response, err = c.makeAPIRequest(ctx, request)
+		if err == nil {
+			c.lastModel.Store(model)
+			if len(models) > 1 {
+				log.Printf("LLM: Model %s succeeded (%d/%d in fallback chain)", model, i+1, len(models))
+			}
+			break
+		}
+		if i == len(models)-1 || !isModelFailure(err) || ctx.Err() != nil {
+			break
+		}
+		log.Printf("LLM: Model %s failed (%v), falling back to %s", model, err, models[i+1])
 	}
-	if config.APIKey == "" {
-		return "", fmt.Errorf("API key is required")
+	if err != nil {
+		log.Printf("LLM: API request failed: %v", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", nil, Usage{}, fmt.Errorf("API request failed: %w", ctxErr)
+		}
+		return "", nil, Usage{}, fmt.Errorf("API request failed: %v", err)
 	}
-	if config.Model == "" {
-		return "", fmt.Errorf("model is required")
+
+	usage := servedUsage(response)
+	log.Printf("LLM: Token usage: prompt=%d completion=%d total=%d", usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+
+	text, err := extractText(response)
+	if err != nil {
+		return "", nil, usage, err
+	}
+	var spans []string
+	if c.MarkUncertain() {
+		text, spans = extractUncertain(text)
+		if len(spans) > 0 {
+			log.Printf("LLM: Model marked %d uncertain span(s)", len(spans))
+		}
+	}
+	return c.formatText(text), spans, usage, nil
+}
+
+// newVisionRequest builds the chat request for an image and prompt, addressed
+// to the first model of the fallback chain, which is also returned.
+func (c *Client) newVisionRequest(imageData []byte, prompt string) (ChatRequest, []string, error) {
+	cfg := c.currentConfig()
+	if cfg == nil {
+		return ChatRequest{}, nil, fmt.Errorf("LLM client not initialized")
+	}
+	if cfg.APIKey == "" {
+		return ChatRequest{}, nil, fmt.Errorf("API key is required")
+	}
+	models := c.modelChain()
+	if len(models) == 0 {
+		return ChatRequest{}, nil, fmt.Errorf("model is required")
 	}
 
 	// Encode image as base64
@@ -116,20 +346,14 @@ func QueryVision(imageData []byte) (string, error) {
 
 	// Create the request payload matching Python implementation
 	request := ChatRequest{
-		Model: config.Model,
+		Model: models[0],
 		Messages: []Message{
 			{
 				Role: "user",
 				Content: []Content{
 					{
 						Type: "text",
-						Text: "Perform OCR on this image. Return ONLY the raw extracted text with:\n" +
-							"- No formatting\n" +
-							"- No XML/HTML tags\n" +
-							"- No markdown\n" +
-							"- No explanations\n" +
-							"- Preserve line breaks accurately from the visual layout.\n" +
-							"If no text found, return 'NO_TEXT_FOUND'",
+						Text: c.withLanguageHint(prompt),
 					},
 					{
 						Type: "image_url",
@@ -140,29 +364,60 @@ func QueryVision(imageData []byte) (string, error) {
 				},
 			},
 		},
-		Temperature: 0.1,
-		MaxTokens:   2000,
-		Provider:    getProviderPreferences(),
+		Temperature: c.temperature(),
+		MaxTokens:   c.maxTokens(),
+		Provider:    c.getProviderPreferences(),
 	}
+	return request, models, nil
+}
 
-	// Single attempt - no retries, hard fail on any error
-	response, err := makeAPIRequest(request)
-	if err != nil {
-		log.Printf("LLM: API request failed: %v", err)
-		return "", fmt.Errorf("API request failed: %v", err)
+// servedUsage returns the response's usage with the provider and model that
+// served it, and logs them: with PROVIDER_STRATEGY=fallback or a routed
+// model they can differ from the configured ones.
+func servedUsage(response *ChatResponse) Usage {
+	usage := response.Usage
+	usage.Provider, usage.Model = response.Provider, response.Model
+	if response.Provider != "" {
+		log.Printf("LLM: Served by provider %s (model %s)", response.Provider, response.Model)
 	}
+	return usage
+}
 
-	// Extract text from response
+// extractText validates a chat response and returns the OCR text. Empty
+// results are classified by finish_reason and content as ErrRefused,
+// ErrContentFiltered or ErrNoTextFound. Text cut short by the content
+// filter is still returned.
+func extractText(response *ChatResponse) (string, error) {
 	if len(response.Choices) == 0 {
 		log.Printf("LLM: API response has no choices")
 		return "", fmt.Errorf("no choices in API response")
 	}
 
-	extractedText := response.Choices[0].Message.Content
-	log.Printf("LLM: API returned text: %d characters", len(extractedText))
-	if extractedText == "" || extractedText == "NO_TEXT_FOUND" {
-		log.Printf("LLM: No text detected in image (response was: %q)", extractedText)
-		return "", fmt.Errorf("no text detected in image")
+	choice := response.Choices[0]
+	extractedText := choice.Message.Content
+	log.Printf("LLM: API returned text: %d characters (finish_reason=%q)", len(extractedText), choice.FinishReason)
+
+	if choice.FinishReason == "content_filter" {
+		if strings.TrimSpace(extractedText) == "" {
+			log.Printf("LLM: Response blocked by content filter")
+			return "", ErrContentFiltered
+		}
+		log.Printf("LLM: Content filter cut the response short, returning the partial text")
+	}
+
+	switch {
+	case choice.Message.Refusal != "":
+		log.Printf("LLM: Model refused: %q", choice.Message.Refusal)
+		return "", fmt.Errorf("%w: %s", ErrRefused, choice.Message.Refusal)
+	case choice.FinishReason == "refusal":
+		log.Printf("LLM: Model refused (finish_reason=refusal)")
+		return "", ErrRefused
+	case strings.TrimSpace(extractedText) == "NO_TEXT_FOUND":
+		log.Printf("LLM: Model found no text in image")
+		return "", ErrNoTextFound
+	case strings.TrimSpace(extractedText) == "":
+		log.Printf("LLM: Empty response (finish_reason=%q)", choice.FinishReason)
+		return "", ErrNoTextFound
 	}
 
 	// Clean up any remaining artifacts
@@ -171,7 +426,36 @@ func QueryVision(imageData []byte) (string, error) {
 	return extractedText, nil
 }
 
-func makeAPIRequest(request ChatRequest) (*ChatResponse, error) {
+// makeAPIRequest sends request, retrying transient failures (HTTP 429/5xx
+// and network errors) with exponential backoff as configured in Config. On a
+// 429 the server's Retry-After is honoured instead of the backoff. Once ctx
+// is done the in-flight request is aborted and no further attempt is made.
+func (c *Client) makeAPIRequest(ctx context.Context, request ChatRequest) (*ChatResponse, error) {
+	attempts, baseDelay := c.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		response, err := c.makeAPIRequestWithTimeout(ctx, request, c.requestTimeout())
+		if err == nil {
+			return response, nil
+		}
+		if attempt >= attempts || !isRetryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		// A Retry-After longer than one request attempt is not worth
+		// waiting for; the next attempt backs off again anyway.
+		delay := retryDelay(err, baseDelay, attempt, c.requestTimeout())
+		log.Printf("LLM: Attempt %d/%d failed (%s), retrying in %v", attempt, attempts, retryReason(err), delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+	}
+}
+
+// makeAPIRequestWithTimeout performs a single request with a custom HTTP timeout (used directly by Ping)
+func (c *Client) makeAPIRequestWithTimeout(ctx context.Context, request ChatRequest, timeout time.Duration) (*ChatResponse, error) {
 	// Marshal request to JSON
 	jsonData, err := json.Marshal(request)
 	if err != nil {
@@ -186,31 +470,37 @@ func makeAPIRequest(request ChatRequest) (*ChatResponse, error) {
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", openRouterURL, bytes.NewBuffer(jsonData))
+	req, err := c.newHTTPRequest(ctx, jsonData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", config.APIKey))
-	req.Header.Set("HTTP-Referer", "https://github.com/cherjr/screen-ocr-llm")
-	req.Header.Set("X-Title", "Screen OCR Tool")
-
-	// Make the request
-	client := &http.Client{Timeout: 45 * time.Second}
-	resp, err := client.Do(req)
+	// Make the request with custom timeout
+	resp, err := c.httpClient(timeout).Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("API request failed: %v", err)
+		return nil, &networkError{err: fmt.Errorf("API request failed: %v", err)}
 	}
 	defer resp.Body.Close()
 
 	log.Printf("LLM: API response status: %d %s", resp.StatusCode, resp.Status)
+	rateLimit, hasRateLimit := recordRateLimit(resp.Header, time.Now())
+	if hasRateLimit && (resp.StatusCode == http.StatusTooManyRequests || rateLimit.Remaining == 0) {
+		log.Printf("LLM: Rate limit: %s", rateLimit)
+	}
 
 	// Parse response
 	var response ChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	decodeErr := json.NewDecoder(resp.Body).Decode(&response)
+
+	if resp.StatusCode != http.StatusOK {
+		if decodeErr == nil && response.Error != nil {
+			log.Printf("LLM: API error response: %s (type: %s, code: %v)", response.Error.Message, response.Error.Type, response.Error.Code)
+			return nil, &StatusError{StatusCode: resp.StatusCode, RetryAfter: rateLimit.RetryAfter, Err: fmt.Errorf("API error: %s (type: %s, code: %v)", response.Error.Message, response.Error.Type, response.Error.Code)}
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, RetryAfter: rateLimit.RetryAfter, Err: fmt.Errorf("API returned status %d", resp.StatusCode)}
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", decodeErr)
 	}
 
 	// Check for API errors
@@ -219,86 +509,55 @@ func makeAPIRequest(request ChatRequest) (*ChatResponse, error) {
 		return nil, fmt.Errorf("API error: %s (type: %s, code: %v)", response.Error.Message, response.Error.Type, response.Error.Code)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
 	log.Printf("LLM: API response parsed successfully, %d choices", len(response.Choices))
 	return &response, nil
 }
 
-// makeAPIRequestWithTimeout is like makeAPIRequest but allows a custom HTTP timeout (used by Ping)
-func makeAPIRequestWithTimeout(request ChatRequest, timeout time.Duration) (*ChatResponse, error) {
-	// Marshal request to JSON
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %v", err)
-	}
-
-	// Log the request for debugging (only log provider preferences)
-	if request.Provider != nil {
-		log.Printf("LLM: API request includes provider preferences: %+v", request.Provider)
-	} else {
-		log.Printf("LLM: API request without provider preferences (using default routing)")
+// httpClient returns a client with the given timeout that uses
+// Config.Transport when one is configured.
+func (c *Client) httpClient(timeout time.Duration) *http.Client {
+	cfg := c.currentConfig()
+	client := &http.Client{Timeout: timeout}
+	if cfg != nil && cfg.Transport != nil {
+		client.Transport = cfg.Transport
 	}
+	return client
+}
 
-	// Create HTTP request
-	req, err := http.NewRequest("POST", openRouterURL, bytes.NewBuffer(jsonData))
+// newHTTPRequest creates the POST to the chat completions endpoint with the
+// JSON body and the auth and attribution headers set.
+func (c *Client) newHTTPRequest(ctx context.Context, jsonData []byte) (*http.Request, error) {
+	cfg := c.currentConfig()
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(), bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", config.APIKey))
-	req.Header.Set("HTTP-Referer", "https://github.com/cherjr/screen-ocr-llm")
-	req.Header.Set("X-Title", "Screen OCR Tool")
-
-	// Make the request with custom timeout
-	client := &http.Client{Timeout: timeout}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("API request failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	log.Printf("LLM: API response status: %d %s", resp.StatusCode, resp.Status)
-
-	// Parse response
-	var response ChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
-	}
-
-	// Check for API errors
-	if response.Error != nil {
-		log.Printf("LLM: API error response: %s (type: %s, code: %v)", response.Error.Message, response.Error.Type, response.Error.Code)
-		return nil, fmt.Errorf("API error: %s (type: %s, code: %v)", response.Error.Message, response.Error.Type, response.Error.Code)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
-	log.Printf("LLM: API response parsed successfully, %d choices", len(response.Choices))
-	return &response, nil
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.APIKey))
+	req.Header.Set("HTTP-Referer", c.httpReferer())
+	req.Header.Set("X-Title", c.appTitle())
+	return req, nil
 }
 
 // Ping performs a minimal LLM validation request with MaxTokens=1
 // It logs success/failure and returns an error on failure. Intended to be fast.
-func Ping() error {
-	if config == nil {
+func (c *Client) Ping() error {
+	cfg := c.currentConfig()
+	if cfg == nil {
 		return fmt.Errorf("LLM client not initialized")
 	}
-	if config.APIKey == "" {
+	if cfg.APIKey == "" {
 		return fmt.Errorf("API key is required")
 	}
-	if config.Model == "" {
+	models := c.modelChain()
+	if len(models) == 0 {
 		return fmt.Errorf("model is required")
 	}
 
 	req := ChatRequest{
-		Model: config.Model,
+		Model: models[0],
 		Messages: []Message{
 			{
 				Role: "user",
@@ -309,11 +568,11 @@ func Ping() error {
 		},
 		Temperature: 0,
 		MaxTokens:   1,
-		Provider:    getProviderPreferences(),
+		Provider:    c.getProviderPreferences(),
 	}
 
 	start := time.Now()
-	resp, err := makeAPIRequestWithTimeout(req, 8*time.Second)
+	resp, err := c.makeAPIRequestWithTimeout(context.Background(), req, 8*time.Second)
 	latency := time.Since(start)
 	if err != nil {
 		log.Printf("LLM: Ping failed after %dms: %v", latency.Milliseconds(), err)
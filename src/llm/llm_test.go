@@ -1,11 +1,19 @@
 package llm
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPingNotInitialized(t *testing.T) {
-	config = nil
+	defaultClient.cfg = nil
 	if err := Ping(); err == nil {
 		t.Error("Expected error when not initialized")
 	}
@@ -40,11 +48,14 @@ func TestQueryVision(t *testing.T) {
 		t.Error("Expected error with missing model")
 	}
 
-	// Test with valid config (will fail due to invalid API key, but tests the request structure)
+	// Test with valid config; the injected transport rejects the key
+	// without network access
 	Init(&Config{
-		APIKey:    "mock_key_for_error_testing", // Safe mock for error testing
-		Model:     "test_model",
-		Providers: []string{},
+		APIKey:     "mock_key_for_error_testing", // Safe mock for error testing
+		Model:      "test_model",
+		Providers:  []string{},
+		MaxRetries: 1,
+		Transport:  cannedTransport(401, `{"error":{"message":"No auth credentials found","code":401}}`),
 	})
 	testImageData := []byte{0xFF, 0xFF, 0xFF, 0xFF}
 	_, err = QueryVision(testImageData)
@@ -53,3 +64,189 @@ func TestQueryVision(t *testing.T) {
 	}
 	t.Logf("QueryVision validation working as expected: %v", err)
 }
+
+func TestExtractTextClassifiesEmptyResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "text returned",
+			body: `{"choices":[{"message":{"content":"Hello"},"finish_reason":"stop"}]}`,
+			want: "Hello",
+		},
+		{
+			name:    "NO_TEXT_FOUND marker",
+			body:    `{"choices":[{"message":{"content":" NO_TEXT_FOUND\n"},"finish_reason":"stop"}]}`,
+			wantErr: ErrNoTextFound,
+		},
+		{
+			name:    "empty content with stop",
+			body:    `{"choices":[{"message":{"content":""},"finish_reason":"stop"}]}`,
+			wantErr: ErrNoTextFound,
+		},
+		{
+			name:    "content filter",
+			body:    `{"choices":[{"message":{"content":""},"finish_reason":"content_filter"}]}`,
+			wantErr: ErrContentFiltered,
+		},
+		{
+			name: "content filter with partial text",
+			body: `{"choices":[{"message":{"content":"Hello"},"finish_reason":"content_filter"}]}`,
+			want: "Hello",
+		},
+		{
+			name:    "refusal message",
+			body:    `{"choices":[{"message":{"content":"","refusal":"I can't help with that."},"finish_reason":"stop"}]}`,
+			wantErr: ErrRefused,
+		},
+		{
+			name:    "refusal finish reason",
+			body:    `{"choices":[{"message":{"content":""},"finish_reason":"refusal"}]}`,
+			wantErr: ErrRefused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ChatResponse
+			if err := json.Unmarshal([]byte(tt.body), &resp); err != nil {
+				t.Fatalf("invalid canned response: %v", err)
+			}
+			got, err := extractText(&resp)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("extractText() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractText() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("extractText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatResponseParsesUsage(t *testing.T) {
+	body := `{"choices":[{"message":{"content":"Hello"},"finish_reason":"stop"}],` +
+		`"usage":{"prompt_tokens":812,"completion_tokens":5,"total_tokens":817,"cost":0.00042}}`
+	var resp ChatResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("invalid canned response: %v", err)
+	}
+	want := Usage{PromptTokens: 812, CompletionTokens: 5, TotalTokens: 817, Cost: 0.00042}
+	if resp.Usage != want {
+		t.Fatalf("Usage = %+v, want %+v", resp.Usage, want)
+	}
+}
+
+func TestQueryVisionWithUsageReportsServingProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"model":"qwen/qwen3-vl","provider":"DeepInfra",` +
+			`"choices":[{"message":{"content":"Hello"}}],"usage":{"total_tokens":7}}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	if err := Init(&Config{APIKey: "test", Model: "qwen/qwen3-vl", BaseURL: server.URL, Providers: []string{"novita", "deepinfra"}, AllowFallbacks: true}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	text, usage, err := QueryVisionWithUsage([]byte("png"))
+	if err != nil {
+		t.Fatalf("QueryVisionWithUsage returned error: %v", err)
+	}
+	if text != "Hello" || usage.Provider != "DeepInfra" || usage.Model != "qwen/qwen3-vl" || usage.TotalTokens != 7 {
+		t.Fatalf("got %q, usage %+v", text, usage)
+	}
+}
+
+func TestProviderPreferences(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		want string
+	}{
+		{name: "none", cfg: &Config{}, want: "null"},
+		{name: "order without fallbacks", cfg: &Config{Providers: []string{"a", "b"}}, want: `{"order":["a","b"],"allow_fallbacks":false}`},
+		{name: "order with fallbacks", cfg: &Config{Providers: []string{"a"}, AllowFallbacks: true}, want: `{"order":["a"],"allow_fallbacks":true}`},
+		{name: "quantizations only", cfg: &Config{Quantizations: []string{"fp16"}, AllowFallbacks: true}, want: `{"quantizations":["fp16"]}`},
+		{name: "order and quantizations", cfg: &Config{Providers: []string{"a"}, Quantizations: []string{"fp16", "bf16"}}, want: `{"order":["a"],"quantizations":["fp16","bf16"],"allow_fallbacks":false}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(providerPreferences(tt.cfg))
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("providerPreferences() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLanguageHint(t *testing.T) {
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+
+	defaultClient.cfg = &Config{}
+	if got := defaultClient.withLanguageHint(ocrPrompt); got != ocrPrompt {
+		t.Fatalf("expected prompt unchanged without language, got %q", got)
+	}
+
+	defaultClient.cfg = &Config{Language: " Japanese "}
+	got := defaultClient.withLanguageHint(ocrPrompt)
+	if !strings.HasPrefix(got, ocrPrompt) || !strings.HasSuffix(got, "The text is primarily in Japanese.") {
+		t.Fatalf("unexpected prompt with language hint: %q", got)
+	}
+}
+
+func TestExtractTextNoChoices(t *testing.T) {
+	if _, err := extractText(&ChatResponse{}); err == nil {
+		t.Fatal("expected error for response without choices")
+	}
+}
+
+func TestQueryVisionWithContextCancelsRequest(t *testing.T) {
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body is read.
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL, MaxRetries: 3}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := QueryVisionWithContext(ctx, []byte("png"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("QueryVisionWithContext error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("QueryVisionWithContext returned after %v", elapsed)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("server request was not cancelled")
+	}
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// RoundTripFunc adapts a function to http.RoundTripper, e.g. to give
+// Config.Transport canned responses in tests.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// MockTransport is a built-in offline backend (LLM_BACKEND=mock). It answers
+// every chat request without network access: image requests get a
+// deterministic text naming the model and image size, text-only requests
+// (ping, translation) a fixed reply. Streamed requests are answered as
+// server-sent events.
+type MockTransport struct{}
+
+func (MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var chat ChatRequest
+	if req.Body != nil {
+		defer req.Body.Close()
+		if err := json.NewDecoder(req.Body).Decode(&chat); err != nil {
+			return mockResponse(http.StatusBadRequest, "application/json",
+				`{"error":{"message":"mock: invalid request body","type":"invalid_request_error","code":400}}`), nil
+		}
+	}
+
+	content := mockContent(chat)
+	if chat.Stream {
+		var b strings.Builder
+		for _, line := range strings.SplitAfter(content, "\n") {
+			data, _ := json.Marshal(map[string]any{
+				"choices": []map[string]any{{"delta": map[string]string{"content": line}}},
+			})
+			b.WriteString("data: " + string(data) + "\n\n")
+		}
+		b.WriteString("data: [DONE]\n\n")
+		return mockResponse(http.StatusOK, "text/event-stream", b.String()), nil
+	}
+
+	body, err := json.Marshal(ChatResponse{
+		Choices: []Choice{{Message: ResponseMessage{Content: content}, FinishReason: "stop"}},
+		Usage:   Usage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mockResponse(http.StatusOK, "application/json", string(body)), nil
+}
+
+// mockContent is the reply MockTransport gives to chat.
+func mockContent(chat ChatRequest) string {
+	if chat.MaxTokens == 1 {
+		return "."
+	}
+	for _, msg := range chat.Messages {
+		for _, c := range msg.Content {
+			if c.ImageURL == nil {
+				continue
+			}
+			_, data, _ := strings.Cut(c.ImageURL.URL, ",")
+			size := base64.StdEncoding.DecodedLen(len(data)) - strings.Count(data, "=")
+			return fmt.Sprintf("Mock OCR result\nModel: %s\nImage: %d bytes", chat.Model, size)
+		}
+	}
+	return "Mock response"
+}
+
+func mockResponse(status int, contentType, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// cannedTransport answers every request with status and body.
+func cannedTransport(status int, body string) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+		}, nil
+	}
+}
+
+func initWithTransport(t *testing.T, transport http.RoundTripper) {
+	t.Helper()
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+	if err := Init(&Config{APIKey: "test", Model: "test/model", MaxRetries: 1, Transport: transport}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+}
+
+func TestInjectedTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{name: "success", status: http.StatusOK, body: `{"choices":[{"message":{"content":"Invoice 42"}}]}`, want: "Invoice 42"},
+		{name: "api error", status: http.StatusUnauthorized, body: `{"error":{"message":"No auth credentials found","code":401}}`, wantErr: "No auth credentials found"},
+		{name: "empty choices", status: http.StatusOK, body: `{"choices":[]}`, wantErr: "no choices in API response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initWithTransport(t, cannedTransport(tt.status, tt.body))
+			text, err := QueryVision([]byte("png"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("QueryVision error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || text != tt.want {
+				t.Fatalf("QueryVision = %q, %v; want %q", text, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectedTransportStatusError(t *testing.T) {
+	initWithTransport(t, cannedTransport(http.StatusPaymentRequired, `{"error":{"message":"Insufficient credits","code":402}}`))
+	err := Ping()
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusPaymentRequired {
+		t.Fatalf("Ping error = %v, want *StatusError 402", err)
+	}
+}
+
+func TestMockTransport(t *testing.T) {
+	initWithTransport(t, MockTransport{})
+
+	if err := Ping(); err != nil {
+		t.Fatalf("Ping returned error: %v", err)
+	}
+	text, err := QueryVision([]byte("12345"))
+	if err != nil {
+		t.Fatalf("QueryVision returned error: %v", err)
+	}
+	want := "Mock OCR result\nModel: test/model\nImage: 5 bytes"
+	if text != want {
+		t.Fatalf("QueryVision = %q, want %q", text, want)
+	}
+	again, _ := QueryVision([]byte("12345"))
+	if again != text {
+		t.Fatalf("mock output is not deterministic: %q vs %q", again, text)
+	}
+
+	var streamed strings.Builder
+	text, err = QueryVisionStream([]byte("12345"), func(c string) { streamed.WriteString(c) })
+	if err != nil || text != want || streamed.String() != want {
+		t.Fatalf("QueryVisionStream = %q (streamed %q), %v", text, streamed.String(), err)
+	}
+
+	if got, err := Translate("Hallo", "en"); err != nil || got != "Mock response" {
+		t.Fatalf("Translate = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,21 @@
+package llm
+
+// Modes accepted by MODE.
+const (
+	ModeOCR      = "ocr"
+	ModeDescribe = "describe"
+)
+
+const describePrompt = "Describe this image concisely for someone who cannot see it. Return ONLY the description with:\n" +
+	"- One to three plain sentences\n" +
+	"- The main subject first, then any important visible text quoted briefly\n" +
+	"- No markdown, no lists, no preamble such as 'This image shows'"
+
+// Mode returns the configured mode, defaulting to ModeOCR.
+func (c *Client) Mode() string {
+	cfg := c.currentConfig()
+	if cfg != nil && cfg.Mode == ModeDescribe {
+		return ModeDescribe
+	}
+	return ModeOCR
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDescribeMode(t *testing.T) {
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+
+	for mode, want := range map[string]string{"": ModeOCR, "ocr": ModeOCR, "caption": ModeOCR, ModeDescribe: ModeDescribe} {
+		defaultClient.cfg = &Config{Mode: mode}
+		if got := Mode(); got != want {
+			t.Errorf("Mode() with %q = %q, want %q", mode, got, want)
+		}
+	}
+
+	defaultClient.cfg = &Config{Mode: ModeDescribe, OutputFormat: FormatCSV}
+	if got := defaultClient.basePrompt(); got != describePrompt {
+		t.Errorf("basePrompt() in describe mode = %q", got)
+	}
+	if got := defaultClient.formatText("A | B"); got != "A | B" {
+		t.Errorf("descriptions should not be converted to CSV, got %q", got)
+	}
+}
+
+func TestQueryVisionWithPrompt(t *testing.T) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		prompts = append(prompts, req.Messages[0].Content[0].Text)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"A cat on a mat."}}]}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL, Mode: ModeDescribe}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	text, err := QueryVision([]byte("png"))
+	if err != nil || text != "A cat on a mat." {
+		t.Fatalf("QueryVision = %q, %v", text, err)
+	}
+	if _, err := QueryVisionWithPrompt([]byte("png"), "Count the cats."); err != nil {
+		t.Fatalf("QueryVisionWithPrompt returned error: %v", err)
+	}
+	if len(prompts) != 2 || prompts[0] != describePrompt || prompts[1] != "Count the cats." {
+		t.Fatalf("unexpected prompts %q", prompts)
+	}
+}
@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit is the rate-limit state reported by the API in the headers of a
+// response.
+type RateLimit struct {
+	// Limit and Remaining are the request quota of the current window
+	// (X-RateLimit-Limit, X-RateLimit-Remaining); -1 when not reported.
+	Limit     int
+	Remaining int
+	// Reset is when the current window ends (X-RateLimit-Reset); zero when
+	// not reported.
+	Reset time.Time
+	// RetryAfter is the wait the server asked for (Retry-After); zero when
+	// not reported.
+	RetryAfter time.Duration
+	// Seen is when the response carrying these headers was received.
+	Seen time.Time
+}
+
+// lastRateLimit holds the rate-limit info of the most recent response that
+// carried any.
+var lastRateLimit atomic.Pointer[RateLimit]
+
+// LastRateLimit returns the rate-limit info of the most recent API response
+// that reported one, and false if none has so far.
+func LastRateLimit() (RateLimit, bool) {
+	rl := lastRateLimit.Load()
+	if rl == nil {
+		return RateLimit{}, false
+	}
+	return *rl, true
+}
+
+// recordRateLimit parses the rate-limit headers of a response, remembers
+// them for LastRateLimit and returns them. ok is false if the response
+// carried none.
+func recordRateLimit(h http.Header, now time.Time) (rl RateLimit, ok bool) {
+	rl, ok = parseRateLimit(h, now)
+	if ok {
+		lastRateLimit.Store(&rl)
+	}
+	return rl, ok
+}
+
+// parseRateLimit reads Retry-After and the X-RateLimit-* headers from h.
+func parseRateLimit(h http.Header, now time.Time) (RateLimit, bool) {
+	rl := RateLimit{Limit: -1, Remaining: -1, Seen: now}
+	ok := false
+	if n, err := strconv.Atoi(strings.TrimSpace(h.Get("X-RateLimit-Limit"))); err == nil {
+		rl.Limit, ok = n, true
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(h.Get("X-RateLimit-Remaining"))); err == nil {
+		rl.Remaining, ok = n, true
+	}
+	if n, err := strconv.ParseInt(strings.TrimSpace(h.Get("X-RateLimit-Reset")), 10, 64); err == nil && n > 0 {
+		// OpenRouter reports milliseconds since the epoch; accept seconds too.
+		if n >= 1e12 {
+			rl.Reset = time.UnixMilli(n)
+		} else {
+			rl.Reset = time.Unix(n, 0)
+		}
+		ok = true
+	}
+	if d, valid := parseRetryAfter(h.Get("Retry-After"), now); valid {
+		rl.RetryAfter, ok = d, true
+	}
+	return rl, ok
+}
+
+// parseRetryAfter parses a Retry-After value, either delay seconds or an
+// HTTP date. Dates in the past yield a zero delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// retryDelay returns the wait before retrying after err on the given failed
+// attempt: the server's Retry-After on a 429, capped at maxWait, otherwise
+// the exponential backoff.
+func retryDelay(err error, baseDelay time.Duration, attempt int, maxWait time.Duration) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, maxWait)
+	}
+	return backoffDelay(baseDelay, attempt)
+}
+
+// String summarises rl for logs, e.g. "3/20 requests left, resets ...".
+func (rl RateLimit) String() string {
+	var parts []string
+	if rl.Remaining >= 0 {
+		if rl.Limit >= 0 {
+			parts = append(parts, strconv.Itoa(rl.Remaining)+"/"+strconv.Itoa(rl.Limit)+" requests left")
+		} else {
+			parts = append(parts, strconv.Itoa(rl.Remaining)+" requests left")
+		}
+	}
+	if !rl.Reset.IsZero() {
+		parts = append(parts, "resets "+rl.Reset.Format(time.RFC3339))
+	}
+	if rl.RetryAfter > 0 {
+		parts = append(parts, "retry after "+rl.RetryAfter.String())
+	}
+	if len(parts) == 0 {
+		return "no quota info"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	h := http.Header{}
+	h.Set("X-RateLimit-Limit", "20")
+	h.Set("X-RateLimit-Remaining", "0")
+	h.Set("X-RateLimit-Reset", "1767366250000")
+	h.Set("Retry-After", "7")
+
+	rl, ok := parseRateLimit(h, now)
+	if !ok {
+		t.Fatal("parseRateLimit reported no headers")
+	}
+	if rl.Limit != 20 || rl.Remaining != 0 || rl.RetryAfter != 7*time.Second {
+		t.Fatalf("parseRateLimit = %+v", rl)
+	}
+	if want := time.UnixMilli(1767366250000); !rl.Reset.Equal(want) {
+		t.Fatalf("Reset = %v, want %v", rl.Reset, want)
+	}
+
+	if _, ok := parseRateLimit(http.Header{}, now); ok {
+		t.Fatal("parseRateLimit reported headers for an empty header set")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: "3", want: 3 * time.Second, ok: true},
+		{value: "0.5", want: 500 * time.Millisecond, ok: true},
+		{value: now.Add(10 * time.Second).Format(http.TimeFormat), want: 10 * time.Second, ok: true},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, ok: true},
+		{value: "", ok: false},
+		{value: "-1", ok: false},
+		{value: "soon", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+	limited := &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second, Err: errors.New("rate limited")}
+	if got := retryDelay(limited, base, 1, time.Minute); got != 2*time.Second {
+		t.Fatalf("retryDelay honoured %v, want 2s", got)
+	}
+	if got := retryDelay(limited, base, 1, time.Second); got != time.Second {
+		t.Fatalf("retryDelay = %v, want it capped at 1s", got)
+	}
+	unavailable := &StatusError{StatusCode: http.StatusServiceUnavailable, RetryAfter: 2 * time.Second, Err: errors.New("unavailable")}
+	if got := retryDelay(unavailable, base, 2, time.Minute); got != backoffDelay(base, 2) {
+		t.Fatalf("retryDelay for 503 = %v, want backoff", got)
+	}
+}
+
+func TestRateLimitedRequestIsRetried(t *testing.T) {
+	lastRateLimit.Store(nil)
+	calls := 0
+	initWithTransport(t, RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+		resp.Header.Set("X-RateLimit-Limit", "20")
+		if calls == 1 {
+			resp.StatusCode = http.StatusTooManyRequests
+			resp.Header.Set("X-RateLimit-Remaining", "0")
+			resp.Header.Set("Retry-After", "0.01")
+			resp.Body = io.NopCloser(bytes.NewBufferString(`{"error":{"message":"Rate limit exceeded","code":429}}`))
+			return resp, nil
+		}
+		resp.Header.Set("X-RateLimit-Remaining", "19")
+		resp.Body = io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"ok"}}]}`))
+		return resp, nil
+	}))
+	defaultClient.cfg.MaxRetries = 2
+	defaultClient.cfg.RetryBaseDelay = time.Hour
+
+	text, err := QueryVision([]byte("png"))
+	if err != nil || text != "ok" {
+		t.Fatalf("QueryVision = %q, %v", text, err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+	rl, ok := LastRateLimit()
+	if !ok || rl.Limit != 20 || rl.Remaining != 19 {
+		t.Fatalf("LastRateLimit = %+v, %v", rl, ok)
+	}
+}
@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = time.Second
+	retryBackoffFactor    = 1.5
+)
+
+// DefaultRequestTimeout is the HTTP timeout of an OCR request attempt when
+// Config.RequestTimeout is unset.
+const DefaultRequestTimeout = 45 * time.Second
+
+// StatusError is returned when the API answers with a non-200 HTTP status.
+type StatusError struct {
+	StatusCode int
+	// RetryAfter is the wait the server asked for with a Retry-After
+	// header; zero when it sent none.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *StatusError) Error() string { return e.Err.Error() }
+
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// networkError marks failures to reach the API at all (DNS, connect, timeout).
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string { return e.err.Error() }
+
+func (e *networkError) Unwrap() error { return e.err }
+
+// retryPolicy returns the attempt limit and base delay from the current config.
+func (c *Client) retryPolicy() (int, time.Duration) {
+	cfg := c.currentConfig()
+	attempts := defaultMaxRetries
+	baseDelay := defaultRetryBaseDelay
+	if cfg != nil {
+		if cfg.MaxRetries > 0 {
+			attempts = cfg.MaxRetries
+		}
+		if cfg.RetryBaseDelay > 0 {
+			baseDelay = cfg.RetryBaseDelay
+		}
+	}
+	return attempts, baseDelay
+}
+
+// requestTimeout returns the HTTP timeout for one OCR request attempt.
+func (c *Client) requestTimeout() time.Duration {
+	cfg := c.currentConfig()
+	if cfg == nil || cfg.RequestTimeout <= 0 {
+		return DefaultRequestTimeout
+	}
+	return cfg.RequestTimeout
+}
+
+// isRetryable reports whether err is transient: HTTP 429, HTTP 5xx or a
+// network error. Client errors such as 400 and 401 are never retried.
+func isRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var netErr *networkError
+	return errors.As(err, &netErr)
+}
+
+// backoffDelay returns the wait after the given failed attempt (1-based):
+// base, base*1.5, base*2.25, ...
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	delay := float64(base)
+	for i := 1; i < attempt; i++ {
+		delay *= retryBackoffFactor
+	}
+	return time.Duration(delay)
+}
+
+func retryReason(err error) string {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return fmt.Sprintf("status %d", statusErr.StatusCode)
+	}
+	return "network error"
+}
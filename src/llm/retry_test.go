@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "429", err: &StatusError{StatusCode: 429, Err: errors.New("rate limited")}, want: true},
+		{name: "500", err: &StatusError{StatusCode: 500, Err: errors.New("server")}, want: true},
+		{name: "503", err: &StatusError{StatusCode: 503, Err: errors.New("unavailable")}, want: true},
+		{name: "400", err: &StatusError{StatusCode: 400, Err: errors.New("bad request")}, want: false},
+		{name: "401", err: &StatusError{StatusCode: 401, Err: errors.New("unauthorized")}, want: false},
+		{name: "network", err: &networkError{err: errors.New("connection reset")}, want: true},
+		{name: "decode", err: errors.New("failed to decode response"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Fatalf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	want := []time.Duration{time.Second, 1500 * time.Millisecond, 2250 * time.Millisecond}
+	for i, w := range want {
+		if got := backoffDelay(time.Second, i+1); got != w {
+			t.Fatalf("backoffDelay(attempt %d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	defaultClient.cfg = &Config{}
+	defer func() { defaultClient.cfg = nil }()
+
+	attempts, delay := defaultClient.retryPolicy()
+	if attempts != 3 || delay != time.Second {
+		t.Fatalf("retryPolicy() = %d, %v; want 3, 1s", attempts, delay)
+	}
+
+	defaultClient.cfg = &Config{MaxRetries: 5, RetryBaseDelay: 200 * time.Millisecond}
+	attempts, delay = defaultClient.retryPolicy()
+	if attempts != 5 || delay != 200*time.Millisecond {
+		t.Fatalf("retryPolicy() = %d, %v; want 5, 200ms", attempts, delay)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	defaultClient.cfg = &Config{}
+	defer func() { defaultClient.cfg = nil }()
+	if got := defaultClient.requestTimeout(); got != DefaultRequestTimeout {
+		t.Fatalf("requestTimeout() = %v, want %v", got, DefaultRequestTimeout)
+	}
+
+	defaultClient.cfg = &Config{RequestTimeout: 2 * time.Minute}
+	if got := defaultClient.requestTimeout(); got != 2*time.Minute {
+		t.Fatalf("requestTimeout() = %v, want 2m", got)
+	}
+
+	defaultClient.cfg = nil
+	if err := Init(&Config{RequestTimeout: -time.Second}); err == nil {
+		t.Fatal("expected Init to reject a negative request timeout")
+	}
+	if defaultClient.cfg != nil {
+		t.Fatal("expected Init to keep the previous configuration on error")
+	}
+}
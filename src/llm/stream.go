@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// noTextMarker is the reply the OCR prompts ask for when there is no text.
+const noTextMarker = "NO_TEXT_FOUND"
+
+// streamChunk is one server-sent event of a streamed chat completion.
+type streamChunk struct {
+	Choices []struct {
+		Delta        ResponseMessage `json:"delta"`
+		FinishReason string          `json:"finish_reason,omitempty"`
+	} `json:"choices"`
+	Usage    *Usage    `json:"usage,omitempty"`
+	Error    *APIError `json:"error,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Provider string    `json:"provider,omitempty"`
+}
+
+// QueryVisionStream is like QueryVision but streams the response: onChunk is
+// called with each piece of text as it arrives, and the complete text is
+// returned at the end. A NO_TEXT_FOUND reply is not passed to onChunk and
+// yields ErrNoTextFound. Only the first model of the fallback chain is used
+// and failed requests are not retried, since output may already have been
+// written. With FormatCSV, chunks are the model's markdown tables while the
+// returned text is converted.
+func (c *Client) QueryVisionStream(imageData []byte, onChunk func(chunk string)) (string, error) {
+	request, models, err := c.newVisionRequest(imageData, c.basePrompt())
+	if err != nil {
+		return "", err
+	}
+	request.Stream = true
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %v", err)
+	}
+	req, err := c.newHTTPRequest(context.Background(), jsonData)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient(c.requestTimeout()).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("API request failed: %w", &networkError{err: err})
+	}
+	defer resp.Body.Close()
+
+	log.Printf("LLM: API stream response status: %d %s", resp.StatusCode, resp.Status)
+	rateLimit, _ := recordRateLimit(resp.Header, time.Now())
+	if resp.StatusCode != http.StatusOK {
+		var response ChatResponse
+		if json.NewDecoder(resp.Body).Decode(&response) == nil && response.Error != nil {
+			return "", fmt.Errorf("API request failed: %w", &StatusError{StatusCode: resp.StatusCode, RetryAfter: rateLimit.RetryAfter, Err: fmt.Errorf("API error: %s (type: %s, code: %v)", response.Error.Message, response.Error.Type, response.Error.Code)})
+		}
+		return "", fmt.Errorf("API request failed: %w", &StatusError{StatusCode: resp.StatusCode, RetryAfter: rateLimit.RetryAfter, Err: fmt.Errorf("API returned status %d", resp.StatusCode)})
+	}
+
+	response, err := readStream(resp.Body, onChunk)
+	if err != nil {
+		return "", err
+	}
+	c.lastModel.Store(models[0])
+	servedUsage(response)
+
+	text, err := extractText(response)
+	if err != nil {
+		return "", err
+	}
+	return c.formatText(text), nil
+}
+
+// readStream reads server-sent events from r until [DONE] or EOF and
+// assembles them into a ChatResponse. Text is passed to onChunk as it
+// arrives, except that output which may still turn out to be the
+// NO_TEXT_FOUND marker is held back until it no longer can.
+func readStream(r io.Reader, onChunk func(chunk string)) (*ChatResponse, error) {
+	var content, refusal strings.Builder
+	var finishReason, model, provider string
+	var usage Usage
+	held := ""
+	holding := true
+	emit := func(s string) {
+		if !holding {
+			onChunk(s)
+			return
+		}
+		held += s
+		if strings.HasPrefix(noTextMarker, strings.TrimSpace(held)) {
+			return
+		}
+		holding = false
+		onChunk(held)
+		held = ""
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// Blank lines separate events; lines starting with ':' are
+		// keep-alive comments.
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode stream chunk: %v", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("API error: %s (type: %s, code: %v)", chunk.Error.Message, chunk.Error.Type, chunk.Error.Code)
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		if chunk.Provider != "" {
+			model, provider = chunk.Model, chunk.Provider
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				content.WriteString(choice.Delta.Content)
+				emit(choice.Delta.Content)
+			}
+			refusal.WriteString(choice.Delta.Refusal)
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &networkError{err: fmt.Errorf("stream interrupted: %v", err)}
+	}
+	if holding && held != "" && strings.TrimSpace(held) != noTextMarker {
+		onChunk(held)
+	}
+
+	return &ChatResponse{
+		Choices: []Choice{{
+			Message:      ResponseMessage{Content: content.String(), Refusal: refusal.String()},
+			FinishReason: finishReason,
+		}},
+		Usage:    usage,
+		Model:    model,
+		Provider: provider,
+	}, nil
+}
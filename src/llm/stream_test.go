@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sseBody(chunks ...string) string {
+	var b strings.Builder
+	b.WriteString(": OPENROUTER PROCESSING\n\n")
+	for _, c := range chunks {
+		data, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"delta": map[string]string{"content": c}}},
+		})
+		b.WriteString("data: " + string(data) + "\n\n")
+	}
+	b.WriteString("data: [DONE]\n\n")
+	return b.String()
+}
+
+func TestQueryVisionStream(t *testing.T) {
+	var req ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(sseBody("Hello", ", ", "world\n", "line two")))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	var chunks []string
+	text, err := QueryVisionStream([]byte("png"), func(chunk string) { chunks = append(chunks, chunk) })
+	if err != nil {
+		t.Fatalf("QueryVisionStream returned error: %v", err)
+	}
+	if !req.Stream {
+		t.Error("request did not set stream: true")
+	}
+	if text != "Hello, world\nline two" {
+		t.Fatalf("text = %q", text)
+	}
+	if strings.Join(chunks, "") != text || len(chunks) != 4 {
+		t.Fatalf("chunks = %q", chunks)
+	}
+}
+
+func TestReadStreamHoldsNoTextMarker(t *testing.T) {
+	var out strings.Builder
+	resp, err := readStream(strings.NewReader(sseBody("NO_", "TEXT_", "FOUND")), func(c string) { out.WriteString(c) })
+	if err != nil {
+		t.Fatalf("readStream returned error: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("marker was streamed: %q", out.String())
+	}
+	if _, err := extractText(resp); !errors.Is(err, ErrNoTextFound) {
+		t.Fatalf("extractText = %v, want ErrNoTextFound", err)
+	}
+
+	out.Reset()
+	if _, err := readStream(strings.NewReader(sseBody("NO", "TE: buy milk")), func(c string) { out.WriteString(c) }); err != nil {
+		t.Fatalf("readStream returned error: %v", err)
+	}
+	if out.String() != "NOTE: buy milk" {
+		t.Fatalf("held text not flushed: %q", out.String())
+	}
+
+	out.Reset()
+	if _, err := readStream(strings.NewReader(sseBody("NO")), func(c string) { out.WriteString(c) }); err != nil {
+		t.Fatalf("readStream returned error: %v", err)
+	}
+	if out.String() != "NO" {
+		t.Fatalf("short text not flushed at end: %q", out.String())
+	}
+}
+
+func TestReadStreamErrors(t *testing.T) {
+	body := "data: {\"error\":{\"message\":\"overloaded\",\"type\":\"server\"}}\n\n"
+	if _, err := readStream(strings.NewReader(body), func(string) {}); err == nil || !strings.Contains(err.Error(), "overloaded") {
+		t.Fatalf("expected stream error, got %v", err)
+	}
+	if _, err := readStream(strings.NewReader("data: {not json\n\n"), func(string) {}); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
+
+func TestQueryVisionStreamStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"No auth credentials found","code":401}}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	t.Cleanup(func() { defaultClient.cfg = prev })
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	_, err := QueryVisionStream([]byte("png"), func(string) { t.Error("unexpected chunk") })
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected *StatusError 401, got %v", err)
+	}
+}
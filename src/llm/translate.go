@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// translatePrompt asks for a bare translation; the text to translate follows
+// it in the same message.
+const translatePrompt = "Translate the following text into %s. Return ONLY the translation with:\n" +
+	"- No explanations or notes\n" +
+	"- No quotes around the result\n" +
+	"- Line breaks preserved as in the original.\n" +
+	"If the text is already in %s, return it unchanged.\n\n"
+
+// Translate asks the model that served the last OCR request (or the first
+// configured model) to translate text into targetLang, a language name or
+// code such as "German" or "de".
+func (c *Client) Translate(text, targetLang string) (string, error) {
+	translated, _, err := c.TranslateWithUsage(text, targetLang)
+	return translated, err
+}
+
+// TranslateWithUsage is like Translate but also returns the token usage
+// reported for the call.
+func (c *Client) TranslateWithUsage(text, targetLang string) (string, Usage, error) {
+	return c.TranslateWithContext(context.Background(), text, targetLang)
+}
+
+// TranslateWithContext is like TranslateWithUsage but the request is bound to
+// ctx: cancelling it aborts the request and any retries.
+func (c *Client) TranslateWithContext(ctx context.Context, text, targetLang string) (string, Usage, error) {
+	cfg := c.currentConfig()
+	targetLang = strings.TrimSpace(targetLang)
+	if targetLang == "" {
+		return "", Usage{}, errors.New("target language is required")
+	}
+	if cfg == nil {
+		return "", Usage{}, fmt.Errorf("LLM client not initialized")
+	}
+	if cfg.APIKey == "" {
+		return "", Usage{}, fmt.Errorf("API key is required")
+	}
+	model := c.LastModel()
+	if model == "" {
+		models := c.modelChain()
+		if len(models) == 0 {
+			return "", Usage{}, fmt.Errorf("model is required")
+		}
+		model = models[0]
+	}
+
+	request := ChatRequest{
+		Model: model,
+		Messages: []Message{
+			{
+				Role: "user",
+				Content: []Content{
+					{Type: "text", Text: fmt.Sprintf(translatePrompt, targetLang, targetLang) + text},
+				},
+			},
+		},
+		Temperature: c.temperature(),
+		MaxTokens:   c.maxTokens(),
+		Provider:    c.getProviderPreferences(),
+	}
+
+	log.Printf("LLM: Translating %d characters into %s with %s", len(text), targetLang, model)
+	response, err := c.makeAPIRequest(ctx, request)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", Usage{}, fmt.Errorf("translation request failed: %w", ctxErr)
+		}
+		return "", Usage{}, fmt.Errorf("translation request failed: %w", err)
+	}
+	usage := servedUsage(response)
+	if len(response.Choices) == 0 {
+		return "", usage, fmt.Errorf("no choices in translation response")
+	}
+	choice := response.Choices[0]
+	switch {
+	case choice.FinishReason == "content_filter" && strings.TrimSpace(choice.Message.Content) == "":
+		return "", usage, ErrContentFiltered
+	case choice.Message.Refusal != "":
+		return "", usage, fmt.Errorf("%w: %s", ErrRefused, choice.Message.Refusal)
+	case strings.TrimSpace(choice.Message.Content) == "":
+		return "", usage, errors.New("empty translation")
+	}
+	return choice.Message.Content, usage, nil
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranslate(t *testing.T) {
+	var got ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Hallo\nWelt"}}],"usage":{"total_tokens":7}}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	defaultClient.lastModel.Store("")
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	text, usage, err := TranslateWithUsage("Hello\nworld", " German ")
+	if err != nil {
+		t.Fatalf("TranslateWithUsage returned error: %v", err)
+	}
+	if text != "Hallo\nWelt" || usage.TotalTokens != 7 {
+		t.Fatalf("got %q, usage %+v", text, usage)
+	}
+	if got.Model != "test/model" || len(got.Messages) != 1 || len(got.Messages[0].Content) != 1 {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	prompt := got.Messages[0].Content[0].Text
+	if !strings.Contains(prompt, "into German.") || !strings.HasSuffix(prompt, "\n\nHello\nworld") {
+		t.Fatalf("unexpected prompt %q", prompt)
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"  "}}]}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	if _, err := Translate("text", ""); err == nil {
+		t.Fatal("expected error for empty target language")
+	}
+	if _, err := Translate("text", "de"); err == nil || !strings.Contains(err.Error(), "empty translation") {
+		t.Fatalf("expected empty translation error, got %v", err)
+	}
+}
+
+func TestTranslateWithContextCancelsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL, MaxRetries: 3}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := TranslateWithContext(ctx, "Hello", "de"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("TranslateWithContext error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("TranslateWithContext returned after %v", elapsed)
+	}
+}
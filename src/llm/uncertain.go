@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// Delimiters the model is asked to wrap unclear text in when
+// Config.MarkUncertain is set. They are rare in real text, so stripping them
+// does not damage ordinary results.
+const (
+	uncertainOpen  = "⟦"
+	uncertainClose = "⟧"
+)
+
+const uncertainPrompt = "Wrap every character or word you cannot read with confidence in " +
+	uncertainOpen + " and " + uncertainClose + ", e.g. " + uncertainOpen + "rn" + uncertainClose +
+	". Mark only genuinely unclear text and use these brackets for nothing else."
+
+// MarkUncertain reports whether OCR requests ask the model to mark
+// low-confidence text (OCR_MARK_UNCERTAIN). Descriptions are never marked.
+func (c *Client) MarkUncertain() bool {
+	cfg := c.currentConfig()
+	return cfg != nil && cfg.MarkUncertain && c.Mode() != ModeDescribe
+}
+
+// QueryVisionWithUncertainty is like QueryVisionWithUsage but also returns
+// the spans the model marked as uncertain, in order of appearance. The text
+// is returned without the markers. Spans are nil unless MarkUncertain is on.
+func (c *Client) QueryVisionWithUncertainty(imageData []byte) (string, []string, Usage, error) {
+	return c.queryVisionUncertain(context.Background(), imageData, c.basePrompt())
+}
+
+// withUncertainMarking appends the marking instruction to prompt when
+// MarkUncertain is on.
+func (c *Client) withUncertainMarking(prompt string) string {
+	if !c.MarkUncertain() {
+		return prompt
+	}
+	return prompt + "\n\n" + uncertainPrompt
+}
+
+// extractUncertain removes the uncertainty markers from text and returns the
+// clean text and the marked spans. An unclosed marker is dropped and the
+// text after it kept; empty spans are not reported.
+func extractUncertain(text string) (string, []string) {
+	var clean strings.Builder
+	var spans []string
+	for {
+		start := strings.Index(text, uncertainOpen)
+		if start < 0 {
+			break
+		}
+		clean.WriteString(text[:start])
+		rest := text[start+len(uncertainOpen):]
+		end := strings.Index(rest, uncertainClose)
+		if end < 0 {
+			text = rest
+			break
+		}
+		span := rest[:end]
+		clean.WriteString(span)
+		if strings.TrimSpace(span) != "" {
+			spans = append(spans, span)
+		}
+		text = rest[end+len(uncertainClose):]
+	}
+	clean.WriteString(strings.ReplaceAll(text, uncertainClose, ""))
+	return clean.String(), spans
+}
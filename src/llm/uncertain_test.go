@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractUncertain(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		wantText  string
+		wantSpans []string
+	}{
+		{"no markers", "plain text", "plain text", nil},
+		{"one span", "he⟦ll⟧o", "hello", []string{"ll"}},
+		{"several spans", "⟦a⟧ b ⟦c d⟧", "a b c d", []string{"a", "c d"}},
+		{"empty span dropped", "x⟦⟧y", "xy", nil},
+		{"unclosed marker", "ab⟦cd", "abcd", nil},
+		{"stray close", "ab⟧cd", "abcd", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, spans := extractUncertain(tt.in)
+			if text != tt.wantText || !reflect.DeepEqual(spans, tt.wantSpans) {
+				t.Fatalf("extractUncertain(%q) = %q, %q; want %q, %q", tt.in, text, spans, tt.wantText, tt.wantSpans)
+			}
+		})
+	}
+}
+
+func TestQueryVisionWithUncertainty(t *testing.T) {
+	var got ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Invoice ⟦N0⟧. 42"}}]}`))
+	}))
+	defer server.Close()
+
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+	if err := Init(&Config{APIKey: "test", Model: "test/model", BaseURL: server.URL, MarkUncertain: true}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	text, spans, _, err := QueryVisionWithUncertainty([]byte("png"))
+	if err != nil {
+		t.Fatalf("QueryVisionWithUncertainty returned error: %v", err)
+	}
+	if text != "Invoice N0. 42" || !reflect.DeepEqual(spans, []string{"N0"}) {
+		t.Fatalf("got %q, spans %q", text, spans)
+	}
+	if len(got.Messages) != 1 || !strings.Contains(got.Messages[0].Content[0].Text, uncertainPrompt) {
+		t.Fatalf("prompt does not ask for uncertainty markers: %+v", got.Messages)
+	}
+}
+
+func TestMarkUncertain(t *testing.T) {
+	prev := defaultClient.cfg
+	defer func() { defaultClient.cfg = prev }()
+
+	defaultClient.cfg = &Config{MarkUncertain: true}
+	if !MarkUncertain() {
+		t.Error("expected MarkUncertain with OCR mode")
+	}
+	defaultClient.cfg = &Config{MarkUncertain: true, Mode: ModeDescribe}
+	if MarkUncertain() {
+		t.Error("expected descriptions not to be marked")
+	}
+	defaultClient.cfg = &Config{}
+	if MarkUncertain() || defaultClient.withUncertainMarking("p") != "p" {
+		t.Error("expected marking off by default")
+	}
+}
@@ -0,0 +1,64 @@
+package logutil
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// Level filters messages written through Debug, Info, Warn and Error.
+// The zero value is LevelInfo.
+type Level int32
+
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var level atomic.Int32
+
+// ParseLevel maps a LOG_LEVEL value (debug, info, warn/warning, error) to a
+// Level. Unknown or empty values return LevelInfo and false.
+func ParseLevel(s string) (Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, true
+	case "info":
+		return LevelInfo, true
+	case "warn", "warning":
+		return LevelWarn, true
+	case "error":
+		return LevelError, true
+	}
+	return LevelInfo, false
+}
+
+// SetLevel sets the minimum level that is logged.
+func SetLevel(l Level) { level.Store(int32(l)) }
+
+// Enabled reports whether messages at l are logged.
+func Enabled(l Level) bool { return int32(l) >= level.Load() }
+
+// Debug logs high-volume diagnostics (window messages, key events); they are
+// dropped unless LOG_LEVEL=debug.
+func Debug(format string, args ...any) { logf(LevelDebug, "DEBUG: ", format, args) }
+
+// Info logs normal operational messages.
+func Info(format string, args ...any) { logf(LevelInfo, "", format, args) }
+
+// Warn logs recoverable problems.
+func Warn(format string, args ...any) { logf(LevelWarn, "WARNING: ", format, args) }
+
+// Error logs failures.
+func Error(format string, args ...any) { logf(LevelError, "ERROR: ", format, args) }
+
+func logf(l Level, prefix, format string, args []any) {
+	if !Enabled(l) {
+		return
+	}
+	// calldepth 3 attributes Lshortfile to the caller of Debug/Info/...
+	_ = log.Output(3, prefix+fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,69 @@
+package logutil
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Level
+		ok   bool
+	}{
+		{"debug", LevelDebug, true},
+		{" INFO ", LevelInfo, true},
+		{"warning", LevelWarn, true},
+		{"warn", LevelWarn, true},
+		{"error", LevelError, true},
+		{"", LevelInfo, false},
+		{"verbose", LevelInfo, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseLevel(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	prevOut, prevFlags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(log.Lshortfile)
+	defer func() {
+		log.SetOutput(prevOut)
+		log.SetFlags(prevFlags)
+		SetLevel(LevelInfo)
+	}()
+
+	SetLevel(LevelInfo)
+	Debug("key event %d", 1)
+	Info("listening on %d", 2)
+	Warn("slow")
+	out := buf.String()
+	if strings.Contains(out, "key event") {
+		t.Errorf("debug message logged at info level: %q", out)
+	}
+	if !strings.Contains(out, "level_test.go") || !strings.Contains(out, "listening on 2") || !strings.Contains(out, "WARNING: slow") {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	buf.Reset()
+	SetLevel(LevelDebug)
+	Debug("key event %d", 3)
+	if !strings.Contains(buf.String(), "DEBUG: key event 3") {
+		t.Errorf("debug message missing at debug level: %q", buf.String())
+	}
+
+	buf.Reset()
+	SetLevel(LevelError)
+	Warn("dropped")
+	Error("failed")
+	if strings.Contains(buf.String(), "dropped") || !strings.Contains(buf.String(), "ERROR: failed") {
+		t.Errorf("unexpected output at error level: %q", buf.String())
+	}
+}
@@ -12,8 +12,32 @@ const (
 	logFileName  = "screen_ocr_debug.log"
 	maxSizeBytes = 10 * 1024 * 1024 // 10 MB
 	maxArchives  = 3
+	appDirName   = "screen-ocr-llm"
 )
 
+var (
+	// logDir is the LOG_DIR setting; empty selects defaultDir.
+	logDir string
+	// logPath is the log file Setup writes to, archives sit next to it.
+	logPath = logFileName
+)
+
+// SetDir sets the directory Setup writes the log file to (LOG_DIR). Empty
+// selects %LOCALAPPDATA%\screen-ocr-llm, or the working directory when
+// LOCALAPPDATA is not set.
+func SetDir(dir string) { logDir = dir }
+
+// Path returns the log file path chosen by the last Setup.
+func Path() string { return logPath }
+
+// defaultDir is the per-user app data directory used when LOG_DIR is unset.
+func defaultDir() string {
+	if base := os.Getenv("LOCALAPPDATA"); base != "" {
+		return filepath.Join(base, appDirName)
+	}
+	return ""
+}
+
 // Setup enables file logging with basic size-based rotation (10MB, max 3 files).
 // When disabled, logs are discarded (keeps stdout clean) to match prior behavior.
 func Setup(enableFileLogging bool) {
@@ -22,14 +46,33 @@ func Setup(enableFileLogging bool) {
 		log.SetFlags(log.LstdFlags | log.Lshortfile)
 		return
 	}
+	dir := logDir
+	if dir == "" {
+		dir = defaultDir()
+	}
+	var dirErr error
+	if dir != "" {
+		if dirErr = os.MkdirAll(dir, 0755); dirErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create log directory %s: %v\n", dir, dirErr)
+		}
+	}
+	if dir == "" || dirErr != nil {
+		logPath = logFileName
+	} else {
+		logPath = filepath.Join(dir, logFileName)
+	}
+
 	rotateIfNeeded()
-	f, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to open log file: %v\n", err)
 		return
 	}
 	log.SetOutput(&rotatingWriter{f: f})
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	if dirErr != nil {
+		log.Printf("WARNING: cannot create log directory %s: %v; logging to the working directory", dir, dirErr)
+	}
 }
 
 type rotatingWriter struct{ f *os.File }
@@ -39,7 +82,7 @@ func (w *rotatingWriter) Write(p []byte) (int, error) {
 	if st, err := w.f.Stat(); err == nil && st.Size()+int64(len(p)) > maxSizeBytes {
 		_ = w.f.Close()
 		rotateIfNeeded()
-		nf, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		nf, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 		if err != nil {
 			return 0, err
 		}
@@ -50,7 +93,7 @@ func (w *rotatingWriter) Write(p []byte) (int, error) {
 
 func rotateIfNeeded() {
 	// If base exceeds max size, rotate: .1, .2, .3 (oldest discarded)
-	if st, err := os.Stat(logFileName); err == nil && st.Size() > maxSizeBytes {
+	if st, err := os.Stat(logPath); err == nil && st.Size() > maxSizeBytes {
 		// remove oldest
 		_ = os.Remove(archiveName(maxArchives))
 		// shift others
@@ -58,11 +101,11 @@ func rotateIfNeeded() {
 			_ = os.Rename(archiveName(i), archiveName(i+1))
 		}
 		// move current to .1
-		_ = os.Rename(logFileName, archiveName(1))
+		_ = os.Rename(logPath, archiveName(1))
 	}
 }
 
-func archiveName(n int) string { return filepath.Join(".", fmt.Sprintf("%s.%d", logFileName, n)) }
+func archiveName(n int) string { return fmt.Sprintf("%s.%d", logPath, n) }
 
 // RedactKey masks an API key, leaving first/last 4 chars: xxxx...yyyy
 func RedactKey(k string) string {
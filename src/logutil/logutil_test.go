@@ -0,0 +1,89 @@
+package logutil
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupForTest runs Setup(true) with dir and restores the global logger.
+func setupForTest(t *testing.T, dir string) {
+	t.Helper()
+	prevOut, prevFlags := log.Writer(), log.Flags()
+	prevDir, prevPath := logDir, logPath
+	t.Cleanup(func() {
+		if w, ok := log.Writer().(*rotatingWriter); ok {
+			_ = w.f.Close()
+		}
+		log.SetOutput(prevOut)
+		log.SetFlags(prevFlags)
+		logDir, logPath = prevDir, prevPath
+	})
+	SetDir(dir)
+	Setup(true)
+}
+
+func TestSetupWritesToLogDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join(t.TempDir(), "nested", "logs")
+	setupForTest(t, dir)
+
+	want := filepath.Join(dir, logFileName)
+	if Path() != want {
+		t.Fatalf("Path() = %q, want %q", Path(), want)
+	}
+	log.Printf("hello")
+	b, err := os.ReadFile(want)
+	if err != nil || !strings.Contains(string(b), "hello") {
+		t.Fatalf("log file %s: %q, %v", want, b, err)
+	}
+	if archiveName(1) != want+".1" {
+		t.Errorf("archiveName(1) = %q", archiveName(1))
+	}
+	if _, err := os.Stat(logFileName); !os.IsNotExist(err) {
+		t.Errorf("expected no log file in the working directory, stat err=%v", err)
+	}
+}
+
+func TestSetupDefaultsToLocalAppData(t *testing.T) {
+	t.Chdir(t.TempDir())
+	base := t.TempDir()
+	t.Setenv("LOCALAPPDATA", base)
+	setupForTest(t, "")
+
+	if want := filepath.Join(base, appDirName, logFileName); Path() != want {
+		t.Fatalf("Path() = %q, want %q", Path(), want)
+	}
+}
+
+func TestSetupFallsBackToWorkingDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	prevStderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stderr = prevStderr }()
+	setupForTest(t, filepath.Join(blocker, "logs"))
+
+	if Path() != logFileName {
+		t.Fatalf("Path() = %q, want %q", Path(), logFileName)
+	}
+	b, err := os.ReadFile(logFileName)
+	if err != nil || !strings.Contains(string(b), "cannot create log directory") {
+		t.Fatalf("expected fallback warning in %s, got %q, %v", logFileName, b, err)
+	}
+}
+
+func TestSetupDisabledDiscards(t *testing.T) {
+	prevOut := log.Writer()
+	defer log.SetOutput(prevOut)
+	Setup(false)
+	if log.Writer() != io.Discard {
+		t.Fatal("expected logs to be discarded when file logging is disabled")
+	}
+}
@@ -0,0 +1,36 @@
+package logutil
+
+import "strings"
+
+// MaxLogChars is the usual limit for text quoted in a log line.
+const MaxLogChars = 100
+
+// SanitizeForLog makes text safe to log on one line: it keeps the first
+// maxChars characters, counting runes so a multibyte character is never
+// split, and appends "..." if text was longer. Line breaks and tabs are
+// escaped and other control characters replaced by "?", so OCR output cannot
+// forge log lines. maxChars <= 0 keeps the whole text.
+func SanitizeForLog(text string, maxChars int) string {
+	var b strings.Builder
+	chars := 0
+	for _, r := range text {
+		if maxChars > 0 && chars == maxChars {
+			b.WriteString("...")
+			break
+		}
+		chars++
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 32 || r == 127:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package logutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeForLog(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want string
+	}{
+		{name: "short text unchanged", text: "hello", max: 10, want: "hello"},
+		{name: "ascii cut", text: "hello world", max: 5, want: "hello..."},
+		{name: "exactly max not marked", text: "日本語", max: 3, want: "日本語"},
+		{name: "cjk cut on rune boundary", text: "日本語のテキスト", max: 4, want: "日本語の..."},
+		{name: "emoji near boundary", text: "ab😀😀cd", max: 3, want: "ab😀..."},
+		{name: "control characters", text: "a\r\nb\tc\x00d\x7f", max: 0, want: `a\r\nb\tc?d?`},
+		{name: "escapes count as one character", text: "a\nbcd", max: 2, want: `a\n...`},
+		{name: "no limit", text: strings.Repeat("é", 300), max: 0, want: strings.Repeat("é", 300)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeForLog(tt.text, tt.max)
+			if got != tt.want {
+				t.Fatalf("SanitizeForLog() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Fatalf("SanitizeForLog() returned invalid UTF-8: %q", got)
+			}
+		})
+	}
+}
+
+func TestSanitizeForLogMultibyteAtByteLimit(t *testing.T) {
+	// 99 ASCII bytes then 3-byte runes: a byte cut at 100 would split one
+	text := strings.Repeat("x", MaxLogChars-1) + strings.Repeat("語", 10)
+	got := SanitizeForLog(text, MaxLogChars)
+	want := strings.Repeat("x", MaxLogChars-1) + "語..."
+	if got != want {
+		t.Fatalf("SanitizeForLog() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/session"
+	"screen-ocr-llm/src/singleinstance"
+)
+
+// Run-once failure stages reported by --error-json. A resident sends the
+// same stages with its ERROR response.
+const (
+	stageConfigFailed    = singleinstance.StageConfigFailed
+	stageRegionCancelled = singleinstance.StageRegionCancelled
+	stageCaptureFailed   = singleinstance.StageCaptureFailed
+	stageAPIFailed       = singleinstance.StageAPIFailed
+	stageClipboardFailed = singleinstance.StageClipboardFailed
+	stageOutputFailed    = singleinstance.StageOutputFailed
+	stageResidentBusy    = singleinstance.StageResidentBusy
+	stageNoTextFound     = singleinstance.StageNoTextFound
+)
+
+// exitNoTextFound is the exit status when OCR succeeded but the image has no
+// text; other failures exit with 1.
+const exitNoTextFound = 2
+
+// runOnceFailure is the --error-json object written to stderr.
+type runOnceFailure struct {
+	Error string `json:"error"`
+	Stage string `json:"stage"`
+}
+
+// failRunOnce reports a run-once failure on stderr and exits with status 1,
+// or exitNoTextFound for stageNoTextFound. message is the human-readable
+// line; with --error-json a single JSON object {"error", "stage"} is written
+// instead.
+func failRunOnce(errorJSON bool, stage, message string, err error) {
+	writeRunOnceFailure(os.Stderr, errorJSON, stage, message, err)
+	if stage == stageNoTextFound {
+		os.Exit(exitNoTextFound)
+	}
+	os.Exit(1)
+}
+
+func writeRunOnceFailure(w io.Writer, errorJSON bool, stage, message string, err error) {
+	if !errorJSON {
+		fmt.Fprintln(w, message)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(runOnceFailure{Error: err.Error(), Stage: stage})
+}
+
+// clipboardImageError marks a failure to read the image for
+// --from-clipboard.
+type clipboardImageError struct {
+	err error
+}
+
+func (e *clipboardImageError) Error() string { return e.err.Error() }
+
+func (e *clipboardImageError) Unwrap() error { return e.err }
+
+// runOnceStage classifies a run-once failure. A failure reported by a
+// resident carries its stage; one from a resident without stages counts as
+// a failed OCR request.
+func runOnceStage(err error) string {
+	var residentErr *singleinstance.ResidentError
+	var clipErr *clipboardImageError
+	switch {
+	case errors.As(err, &residentErr):
+		if residentErr.Stage != "" {
+			return residentErr.Stage
+		}
+		return stageAPIFailed
+	case errors.Is(err, singleinstance.ErrResidentBusy):
+		return stageResidentBusy
+	case errors.As(err, &clipErr):
+		return stageClipboardFailed
+	}
+	return session.FailureStage(err)
+}
+
+// bootstrapStage classifies a runtimeinit.Bootstrap failure: the startup
+// LLM check is an API failure, anything else a configuration problem.
+func bootstrapStage(err error) string {
+	var keyErr *llm.KeyError
+	var statusErr *llm.StatusError
+	if errors.As(err, &keyErr) || errors.As(err, &statusErr) {
+		return stageAPIFailed
+	}
+	return stageConfigFailed
+}
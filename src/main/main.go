@@ -1,13 +1,15 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
-	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"syscall"
@@ -15,21 +17,53 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"screen-ocr-llm/src/autostart"
+	"screen-ocr-llm/src/buildinfo"
+	"screen-ocr-llm/src/clipboard"
 	"screen-ocr-llm/src/config"
 	"screen-ocr-llm/src/eventloop"
+	"screen-ocr-llm/src/gui"
+	"screen-ocr-llm/src/history"
+	"screen-ocr-llm/src/hotkey"
+	"screen-ocr-llm/src/llm"
 	"screen-ocr-llm/src/logutil"
+	"screen-ocr-llm/src/ocr"
 	"screen-ocr-llm/src/overlay"
+	"screen-ocr-llm/src/popup"
 	"screen-ocr-llm/src/runtimeinit"
 	"screen-ocr-llm/src/screenshot"
+	"screen-ocr-llm/src/selftest"
 	"screen-ocr-llm/src/session"
 	"screen-ocr-llm/src/singleinstance"
 	"screen-ocr-llm/src/tray"
 )
 
 type mainOptions struct {
-	runOnce     bool
-	apiKeyPath  string
-	defaultMode string
+	runOnce       bool
+	configPath    string
+	apiKeyPath    string
+	defaultMode   string
+	output        string
+	outputFile    string
+	fromClipboard bool
+	regionPreset  string
+	window        string
+	display       int
+	displaySet    bool
+	saveDir       string
+	errorJSON     bool
+	dryRun        bool
+}
+
+// loadOptions returns the config overrides given on the command line.
+func (o mainOptions) loadOptions() config.LoadOptions {
+	return config.LoadOptions{
+		ConfigPath:                o.configPath,
+		APIKeyPathOverride:        o.apiKeyPath,
+		DefaultModeOverride:       o.defaultMode,
+		SaveScreenshotDirOverride: o.saveDir,
+		DryRunOverride:            o.dryRun,
+	}
 }
 
 func normalizeLegacyArgs(args []string) []string {
@@ -83,17 +117,161 @@ func newRootCmd(opts *mainOptions) *cobra.Command {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.displaySet = cmd.Flags().Changed("display")
 			return runApplication(*opts)
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.runOnce, "run-once", false, "Run OCR once, copy to clipboard, and exit silently")
+	cmd.Flags().StringVar(&opts.configPath, "config", "", "Path to the .env file to load (overrides SCREEN_OCR_LLM and the executable-local .env)")
 	cmd.Flags().StringVar(&opts.apiKeyPath, "api-key-path", "", "Path to API key file (highest precedence)")
 	cmd.Flags().StringVar(&opts.defaultMode, "default-mode", "", "Initial selection mode: rect|rectangle|lasso")
+	cmd.Flags().StringVar(&opts.output, "output", "", "Run OCR once and deliver the result to: clipboard|stdout|file|type (implies --run-once)")
+	cmd.Flags().StringVar(&opts.outputFile, "output-file", "", "Destination path for --output file (implies --output file)")
+	cmd.Flags().StringVar(&opts.regionPreset, "region-preset", "", "Capture the named PRESET_REGIONS rectangle without the selection overlay, then exit")
+	cmd.Flags().StringVar(&opts.window, "window", "", "Capture the client area of the window whose title contains this text, without the selection overlay, then exit")
+	cmd.Flags().IntVar(&opts.display, "display", 0, "Capture the whole of display N (0 is the primary) without the selection overlay, then exit")
+	cmd.Flags().StringVar(&opts.saveDir, "save-screenshot", "", "Save each captured region PNG to this directory before OCR (overrides SAVE_SCREENSHOT_DIR)")
+	cmd.Flags().BoolVar(&opts.fromClipboard, "from-clipboard", false, "OCR the image currently on the clipboard instead of selecting a region, then exit")
+	cmd.Flags().BoolVar(&opts.errorJSON, "error-json", false, "On run-once failure, print {\"error\",\"stage\"} JSON to stderr instead of text")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Select and capture as usual but skip the OCR request; deliver a placeholder with the capture size (same as DRY_RUN=true)")
+
+	cmd.AddCommand(newInstallAutostartCmd(), newUninstallAutostartCmd(), newAutostartStatusCmd(), newSetKeyCmd(), newSelftestCmd(), newVersionCmd())
+
+	return cmd
+}
+
+func newVersionCmd() *cobra.Command {
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, commit and build date",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return buildinfo.Write(cmd.OutOrStdout(), jsonOutput)
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the build information as JSON")
+	return cmd
+}
 
+func newSelftestCmd() *cobra.Command {
+	var loadOptions config.LoadOptions
+	cmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Check the configuration, API key, an OCR request with a bundled image and the clipboard",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := selftest.Run(loadOptions)
+			report.Write(cmd.OutOrStdout())
+			return report.Err()
+		},
+	}
+	cmd.Flags().StringVar(&loadOptions.ConfigPath, "config", "", "Path to the .env file to load (overrides SCREEN_OCR_LLM and the executable-local .env)")
+	cmd.Flags().StringVar(&loadOptions.APIKeyPathOverride, "api-key-path", "", "Path to API key file (highest precedence)")
 	return cmd
 }
 
+func newSetKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-key [api-key]",
+		Short: "Store the OpenRouter API key in Windows Credential Manager",
+		Long: "Store the OpenRouter API key in Windows Credential Manager (target " + config.CredentialTarget + ").\n" +
+			"The key is read from the argument, or from the first line of stdin when omitted.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := readKeyArg(cmd.InOrStdin(), args)
+			if err == nil {
+				err = config.StoreAPIKeyCredential(key)
+			}
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to store API key: %v\n", err)
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "API key stored in Credential Manager (%s)\n", config.CredentialTarget)
+			return nil
+		},
+	}
+}
+
+// readKeyArg returns the key from args, or the first line of r when no
+// argument was given.
+func readKeyArg(r io.Reader, args []string) (string, error) {
+	if len(args) == 1 {
+		key := strings.TrimSpace(args[0])
+		if key == "" {
+			return "", errors.New("API key is empty")
+		}
+		return key, nil
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read API key from stdin: %w", err)
+	}
+	key := strings.TrimSpace(line)
+	if key == "" {
+		return "", errors.New("API key is empty")
+	}
+	return key, nil
+}
+
+func newInstallAutostartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install-autostart",
+		Short: "Start the resident app at login (HKCU Run key)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exePath, err := os.Executable()
+			if err == nil {
+				err = autostart.Install(exePath)
+			}
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to install autostart: %v\n", err)
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Autostart installed: %s\n", autostart.CommandLine(exePath))
+			return nil
+		},
+	}
+}
+
+func newUninstallAutostartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall-autostart",
+		Short: "Stop starting the resident app at login",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := autostart.Uninstall(); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to uninstall autostart: %v\n", err)
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Autostart removed")
+			return nil
+		},
+	}
+}
+
+func newAutostartStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "autostart-status",
+		Short: "Show whether the resident app starts at login",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := autostart.Query()
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to query autostart: %v\n", err)
+				return err
+			}
+			if !status.Enabled {
+				fmt.Fprintln(cmd.OutOrStdout(), "Autostart: disabled")
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Autostart: enabled (%s)\n", status.Command)
+			return nil
+		},
+	}
+}
+
 func main() {
 	if err := run(); err != nil {
 		log.Printf("Application failed: %v", err)
@@ -110,36 +288,61 @@ func runApplication(opts mainOptions) error {
 	// the popup thread's message queue
 	runtime.LockOSThread()
 
+	// Preset regions, windows, displays and the clipboard image need no
+	// region selection, so this process handles them; no delegation
+	if capture := opts.directCapture(); capture.sources() > 0 {
+		if capture.sources() > 1 {
+			return errors.New("--region-preset, --window, --display and --from-clipboard cannot be combined")
+		}
+		req, err := runOnceRequest(opts)
+		if err != nil {
+			return err
+		}
+		runOCROnce(req, opts.loadOptions(), capture, opts.errorJSON)
+		return nil
+	}
+
 	// If run-once mode, prefer delegating to resident via TCP; fallback to standalone
-	if opts.runOnce {
-		handleRunOnceWithDelegation(opts.apiKeyPath, opts.defaultMode, singleinstance.NewClient(), func() {
-			runOCROnce(false, opts.apiKeyPath, opts.defaultMode)
+	if opts.runOnce || opts.output != "" || opts.outputFile != "" {
+		req, err := runOnceRequest(opts)
+		if err != nil {
+			return err
+		}
+		// A resident would make a real OCR request, so dry runs stay local
+		if opts.dryRun {
+			runOCROnce(req, opts.loadOptions(), directCapture{}, opts.errorJSON)
+			return nil
+		}
+		err = handleRunOnceWithDelegation(opts.loadOptions(), singleinstance.NewClient(), req, func() {
+			runOCROnce(req, opts.loadOptions(), directCapture{}, opts.errorJSON)
 		})
-		return nil
+		if err != nil && (opts.errorJSON || runOnceStage(err) == stageNoTextFound) {
+			failRunOnce(opts.errorJSON, runOnceStage(err), "No text found", err)
+		}
+		return err
 	}
 
 	// Load .env early so SINGLEINSTANCE_PORT_* are available for pre-flight
-	_, _ = config.LoadWithOptions(config.LoadOptions{APIKeyPathOverride: opts.apiKeyPath, DefaultModeOverride: opts.defaultMode})
+	_, _ = config.LoadWithOptions(opts.loadOptions())
 	// ---------- SINGLE-INSTANCE NUKE ----------
-	startPort, _ := singleinstance.GetPortRangeForDebug()
-	addr := fmt.Sprintf("127.0.0.1:%d", startPort)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		log.Printf("Pre-flight: port %d busy → resident already exists", startPort)
-		fmt.Printf("one is already running on port %d\n", startPort)
+	// Any port in the range answering PING is a resident; ports held by other
+	// applications are skipped here and by the server when it binds.
+	startPort, endPort := singleinstance.GetPortRangeForDebug()
+	if port, ok := singleinstance.DetectResidentPort(context.Background()); ok {
+		log.Printf("Pre-flight: resident answered on port %d → resident already exists", port)
+		fmt.Printf("one is already running on port %d\n", port)
 		os.Exit(1)
 	}
-	// We claimed the port; release it so the event loop can re-bind.
-	_ = listener.Close()
-	log.Printf("Pre-flight: port %d free → we are the one true resident", startPort)
+	log.Printf("Pre-flight: no resident in ports %d-%d → we are the one true resident", startPort, endPort)
 	// ------------------------------------------
 
 	// Named-pipe single instance enforced by event loop server; PID file removed
 
 	cfg, err := runtimeinit.Bootstrap(runtimeinit.Options{
-		LoadOptions:          config.LoadOptions{APIKeyPathOverride: opts.apiKeyPath, DefaultModeOverride: opts.defaultMode},
+		LoadOptions:          opts.loadOptions(),
 		SetupLogging:         setupLogging,
 		ShowBlockingLLMError: true,
+		ValidateHotkey:       hotkey.Validate,
 	})
 	if err != nil {
 		return err
@@ -147,28 +350,51 @@ func runApplication(opts mainOptions) error {
 
 	log.Printf("Screen OCR LLM Tool initialized")
 	log.Printf("Using model: %s", cfg.Model)
+	if len(cfg.Models) > 1 {
+		log.Printf("Model fallback chain: %v", cfg.Models)
+	}
 	log.Printf("Hotkey: %s", cfg.Hotkey)
 	log.Printf("Default selection mode: %s", cfg.DefaultMode)
 	log.Printf("OCR deadline: %ds", cfg.OCRDeadlineSec)
 
-	// Propagate hotkey to About dialog
+	// Continuation context is tracked across captures by the resident only
+	if cfg.OCRContinuationChars > 0 {
+		ocr.SetContinuation(cfg.OCRContinuationChars)
+		log.Printf("OCR continuation enabled (%d context characters)", cfg.OCRContinuationChars)
+	}
+
+	// Propagate hotkey and model to About dialog
 	tray.SetAboutHotkey(cfg.Hotkey)
+	tray.SetAboutModel(cfg.Model)
 
 	// Event loop + tray + hotkey
 	loop := eventloop.New(cfg)
 	loop.SetDefaultTooltip(fmt.Sprintf("Screen OCR Tool - Press %s to capture", cfg.Hotkey))
+	loop.SetLoadOptions(opts.loadOptions())
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	trayIcon, _ := tray.New(tray.Config{
-		Title:   "Screen OCR Tool",
-		Tooltip: fmt.Sprintf("Screen OCR Tool - Press %s to capture", cfg.Hotkey),
-		OnExit:  func() { cancel() },
+		Title:          "Screen OCR Tool",
+		Tooltip:        fmt.Sprintf("Screen OCR Tool - Press %s to capture", cfg.Hotkey),
+		OnExit:         func() { cancel() },
+		OnCopyLast:     loop.CopyLastResult,
+		OnOCRClipboard: loop.OCRClipboardImage,
+		Presets:        loop.PresetNames(),
+		OnPreset:       loop.CapturePreset,
+		OnChangeHotkey: loop.ChangeHotkey,
+		LogPath:        logFilePath(),
+		ConfigPath:     config.EnvPath(),
+		Models:         cfg.ModelChoices,
+		ActiveModel:    cfg.Model,
+		OnModel:        loop.SwitchModel,
+		OnPauseHotkey:  loop.SetHotkeyPaused,
+		OpenLinks:      cfg.OCRExtract == ocr.ExtractURLs || cfg.OCRExtract == ocr.ExtractAll,
 	})
 	go trayIcon.Run()
 	defer trayIcon.Destroy()
 
-	loop.StartHotkey(cfg.Hotkey)
+	loop.StartHotkeys()
 
 	// Handle SIGINT/SIGTERM
 	go func() {
@@ -189,81 +415,283 @@ func setupLogging(enableFileLogging bool) {
 	logutil.Setup(enableFileLogging)
 }
 
+// logFilePath is the absolute path of the log file for the tray's "Open log
+// file" item.
+func logFilePath() string {
+	path, err := filepath.Abs(logutil.Path())
+	if err != nil {
+		return logutil.Path()
+	}
+	return path
+}
+
+// directCapture names what run-once captures without the selection overlay;
+// with no sources set the user selects a region. fromClipboard reads the
+// image on the clipboard instead of capturing the screen.
+type directCapture struct {
+	regionPreset  string
+	window        string
+	display       int
+	displaySet    bool
+	fromClipboard bool
+}
+
+func (o mainOptions) directCapture() directCapture {
+	return directCapture{regionPreset: o.regionPreset, window: o.window, display: o.display, displaySet: o.displaySet, fromClipboard: o.fromClipboard}
+}
+
+// sources counts the capture sources set in c; at most one is allowed.
+func (c directCapture) sources() int {
+	n := 0
+	for _, set := range []bool{c.regionPreset != "", c.window != "", c.displaySet, c.fromClipboard} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
 // runOCROnce performs a single OCR capture and exits
-func runOCROnce(outputToStdout bool, apiKeyPathOverride, defaultModeOverride string) {
+// When capture.regionPreset is set, that PRESET_REGIONS rectangle is captured
+// directly and the selection overlay is skipped; likewise for the client area
+// of the window whose title contains capture.window and for the whole of
+// display capture.display.
+func runOCROnce(req singleinstance.Request, loadOptions config.LoadOptions, capture directCapture, errorJSON bool) {
 	cfg, err := runtimeinit.Bootstrap(runtimeinit.Options{
-		LoadOptions:          config.LoadOptions{APIKeyPathOverride: apiKeyPathOverride, DefaultModeOverride: defaultModeOverride},
+		LoadOptions:          loadOptions,
 		SetupLogging:         setupLogging,
 		ShowBlockingLLMError: true,
 	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to initialize runtime: %v\n", err)
-		os.Exit(1)
+		failRunOnce(errorJSON, bootstrapStage(err), fmt.Sprintf("Failed to initialize runtime: %v", err), err)
 	}
 
 	log.Printf("Running OCR once (--runonce mode) with OCR deadline %ds", cfg.OCRDeadlineSec)
 
-	selector := overlay.NewSelector(cfg.DefaultMode)
-	var target session.ResultTarget
-	if outputToStdout {
-		target = session.StdoutTarget{Writer: os.Stdout}
-	} else {
-		target = runOnceClipboardTarget{}
+	selector := overlay.NewSelectorWithOptions(overlay.Options{
+		DefaultMode:     cfg.DefaultMode,
+		Guides:          cfg.OverlayGuides,
+		BackgroundScale: cfg.OverlayBGScale,
+		Magnifier:       cfg.OverlayMagnifier,
+	})
+	selectRegion := func(ctx context.Context) (screenshot.Region, bool, error) {
+		region, cancelled, err := selector.Select(ctx)
+		if err != nil {
+			return screenshot.Region{}, false, fmt.Errorf("failed to start region selection: %w", err)
+		}
+		return region, cancelled, nil
+	}
+	if capture.regionPreset != "" {
+		preset, err := config.FindPresetRegion(cfg.PresetRegions, capture.regionPreset)
+		if err != nil {
+			failRunOnce(errorJSON, stageConfigFailed, err.Error(), err)
+		}
+		log.Printf("Capturing preset region %q (%dx%d at %d,%d)", preset.Name, preset.Width, preset.Height, preset.X, preset.Y)
+		selectRegion = func(ctx context.Context) (screenshot.Region, bool, error) {
+			return screenshot.Region{X: preset.X, Y: preset.Y, Width: preset.Width, Height: preset.Height}, false, nil
+		}
+	}
+	// The overlay takes the foreground, so note the user's window first
+	windowTitle := gui.ForegroundWindowTitle()
+	if capture.window != "" {
+		w, err := gui.FindWindowByTitle(capture.window)
+		if err != nil {
+			failRunOnce(errorJSON, stageCaptureFailed, fmt.Sprintf("Window capture failed: %v", err), err)
+		}
+		windowTitle = w.Title
+		selectRegion = func(ctx context.Context) (screenshot.Region, bool, error) {
+			return w.Region, false, nil
+		}
+	}
+	if capture.displaySet {
+		region, err := screenshot.DisplayRegion(capture.display)
+		if err != nil {
+			failRunOnce(errorJSON, stageCaptureFailed, fmt.Sprintf("Display capture failed: %v", err), err)
+		}
+		log.Printf("Capturing display %d (%dx%d at %d,%d)", capture.display, region.Width, region.Height, region.X, region.Y)
+		selectRegion = func(ctx context.Context) (screenshot.Region, bool, error) {
+			return region, false, nil
+		}
 	}
 
-	_, err = session.Execute(context.Background(), session.Options{
-		Deadline: time.Duration(cfg.OCRDeadlineSec) * time.Second,
-		SelectRegion: func(ctx context.Context) (screenshot.Region, bool, error) {
-			region, cancelled, err := selector.Select(ctx)
-			if err != nil {
-				return screenshot.Region{}, false, fmt.Errorf("failed to start region selection: %w", err)
-			}
-			return region, cancelled, nil
-		},
+	var recognize session.RecognizeFunc
+	if capture.fromClipboard {
+		windowTitle = ""
+		selectRegion = func(ctx context.Context) (screenshot.Region, bool, error) {
+			return screenshot.Region{}, false, nil
+		}
+		recognize = recognizeClipboardImage
+	}
+
+	target, err := runOnceTarget(req, cfg)
+	if err != nil {
+		failRunOnce(errorJSON, stageConfigFailed, fmt.Sprintf("Invalid OUTPUT_SINK: %v", err), err)
+	}
+
+	// A sticky popup is waited for below instead of for a fixed time
+	successVisible := time.Duration(cfg.PopupDurationSec) * time.Second
+	if cfg.PopupSticky {
+		successVisible = 0
+	}
+	res, err := session.Execute(context.Background(), session.Options{
+		Deadline:               time.Duration(cfg.OCRDeadlineSec) * time.Second,
+		SelectRegion:           selectRegion,
+		Recognize:              recognize,
 		Target:                 target,
-		SuccessVisibleDuration: 3 * time.Second,
+		SuccessVisibleDuration: successVisible,
 	})
 	if err != nil {
+		var message string
 		switch {
 		case errors.Is(err, session.ErrSelectionCancelled):
-			fmt.Fprintf(os.Stderr, "Selection cancelled\n")
+			message = "Selection cancelled"
+		case errors.Is(err, llm.ErrNoTextFound):
+			message = "No text found"
+		case errors.As(err, new(*clipboardImageError)):
+			message = fmt.Sprintf("Clipboard OCR failed: %v", err)
+		case session.IsDeliveryError(err):
+			message = fmt.Sprintf("Output partially failed: %v", err)
 		case isClipboardWriteError(err):
-			fmt.Fprintf(os.Stderr, "Failed to write to clipboard: %v\n", err)
+			message = fmt.Sprintf("Failed to write to clipboard: %v", err)
 		case isRegionSelectionError(err):
-			fmt.Fprintf(os.Stderr, "Failed to start region selection: %v\n", err)
+			message = fmt.Sprintf("Failed to start region selection: %v", err)
 		default:
-			fmt.Fprintf(os.Stderr, "OCR failed: %v\n", err)
+			message = fmt.Sprintf("OCR failed: %v", err)
 		}
-		os.Exit(1)
+		failRunOnce(errorJSON, runOnceStage(err), message, err)
+	}
+
+	if !ocr.DryRun() {
+		if err := history.RecordWithWindow(res.Text, llm.LastModel(), windowTitle); err != nil {
+			log.Printf("Failed to record OCR history: %v", err)
+		}
+	}
+
+	// POPUP_DURATION_SEC=0 or POPUP_STICKY: keep the process (and its popup)
+	// alive until clicked, then exit normally
+	if cfg.PopupDurationSec == 0 || cfg.PopupSticky {
+		popup.WaitClosed()
 	}
 
 	log.Printf("OCR runonce completed successfully, exiting...")
 	os.Exit(0)
 }
 
-func handleRunOnceWithDelegation(apiKeyPathOverride, defaultModeOverride string, client singleinstance.Client, runFallback func()) {
-	// Load .env early so SINGLEINSTANCE_PORT_* are applied before delegation scan.
-	_, _ = config.LoadWithOptions(config.LoadOptions{APIKeyPathOverride: apiKeyPathOverride, DefaultModeOverride: defaultModeOverride})
+// runOnceTarget picks the result target for a standalone run-once request.
+// Clipboard output honors OUTPUT_SINK and CLIPBOARD_APPEND.
+func runOnceTarget(req singleinstance.Request, cfg *config.Config) (session.ResultTarget, error) {
+	switch {
+	case req.Output == singleinstance.OutputStdout:
+		return session.StdoutTarget{Writer: os.Stdout}, nil
+	case req.Output == singleinstance.OutputFile:
+		return session.FileTarget{Path: req.OutputFile, Overwrite: true}, nil
+	case req.Output == singleinstance.OutputType:
+		return session.TypeTarget{}, nil
+	case strings.EqualFold(strings.TrimSpace(cfg.OutputSink), session.SinkClipboard):
+		return runOnceClipboardTarget{Append: cfg.ClipboardAppend}, nil
+	case cfg.ClipboardAppend:
+		return session.ParseSink(session.WithClipboardAppend(cfg.OutputSink))
+	default:
+		return session.ParseSink(cfg.OutputSink)
+	}
+}
 
-	delegated, _, err := client.TryRunOnce(context.Background(), false)
+// recognizeClipboardImage is the run-once recognition step for
+// --from-clipboard: it reads the image on the clipboard, ignoring the region.
+func recognizeClipboardImage(ctx context.Context, _ screenshot.Region) (string, error) {
+	image, err := clipboard.ReadImage()
+	if err != nil {
+		return "", &clipboardImageError{err: err}
+	}
+	log.Printf("Running OCR on %d-byte clipboard image", len(image))
+	return ocr.RecognizeImageContext(ctx, image)
+}
+
+// runOnceRequest builds the run-once request from --output/--output-file.
+// File paths are made absolute because a resident may run in another directory.
+func runOnceRequest(opts mainOptions) (singleinstance.Request, error) {
+	output, err := singleinstance.ParseOutput(opts.output)
+	if err != nil {
+		return singleinstance.Request{}, err
+	}
+	if opts.output == "" && opts.outputFile != "" {
+		output = singleinstance.OutputFile
+	}
+	if output != singleinstance.OutputFile {
+		if opts.outputFile != "" {
+			return singleinstance.Request{}, fmt.Errorf("--output-file requires --output file")
+		}
+		return singleinstance.NewRequest(output, ""), nil
+	}
+	if opts.outputFile == "" {
+		return singleinstance.Request{}, fmt.Errorf("--output file requires --output-file")
+	}
+	path, err := filepath.Abs(opts.outputFile)
+	if err != nil {
+		return singleinstance.Request{}, fmt.Errorf("invalid --output-file: %w", err)
+	}
+	return singleinstance.NewRequest(singleinstance.OutputFile, path), nil
+}
+
+// busyRetries and busyBackoff bound how long a delegated run-once waits for a
+// busy resident; the backoff doubles after each BUSY response.
+var (
+	busyRetries = 3
+	busyBackoff = 500 * time.Millisecond
+)
+
+// handleRunOnceWithDelegation delegates req to a resident, or runs runFallback
+// when there is none or delegation fails. A BUSY resident is retried with
+// backoff and never triggers the fallback, since a standalone capture would
+// open a second overlay next to the resident's. A failure reported by the
+// resident (e.g. selection cancelled) is returned without a fallback too.
+func handleRunOnceWithDelegation(loadOptions config.LoadOptions, client singleinstance.Client, req singleinstance.Request, runFallback func()) error {
+	// Load .env early so SINGLEINSTANCE_PORT_* are applied before delegation scan.
+	_, _ = config.LoadWithOptions(loadOptions)
+
+	delegated, text, err := client.TryRunOnce(context.Background(), req)
+	backoff := busyBackoff
+	for attempt := 1; errors.Is(err, singleinstance.ErrResidentBusy) && attempt <= busyRetries; attempt++ {
+		log.Printf("Resident busy; retrying delegation in %v (%d/%d)", backoff, attempt, busyRetries)
+		time.Sleep(backoff)
+		backoff *= 2
+		delegated, text, err = client.TryRunOnce(context.Background(), req)
+	}
+	if errors.Is(err, singleinstance.ErrResidentBusy) {
+		log.Printf("Resident still busy after %d retries; not running standalone", busyRetries)
+		return err
+	}
+	var residentErr *singleinstance.ResidentError
+	if errors.As(err, &residentErr) {
+		// The resident already ran the capture; a standalone run would
+		// show a second overlay.
+		log.Printf("Resident reported failure: %v", err)
+		return err
+	}
 	if err != nil {
 		log.Printf("Delegation error: %v; falling back to standalone", err)
 		runFallback()
-		return
+		return nil
 	}
 	if delegated {
-		log.Printf("Delegated to resident")
-		return
+		log.Printf("Delegated to resident (output=%s)", req.Output)
+		if req.Output == singleinstance.OutputStdout {
+			fmt.Print(text)
+		}
+		return nil
 	}
 
 	log.Printf("No resident detected (not delegated), running standalone")
 	runFallback()
+	return nil
 }
 
-type runOnceClipboardTarget struct{}
+type runOnceClipboardTarget struct {
+	Append bool
+}
 
-func (runOnceClipboardTarget) OnSuccess(text string) error {
-	if err := (session.ClipboardTarget{}).OnSuccess(text); err != nil {
+func (t runOnceClipboardTarget) OnSuccess(text string) error {
+	if err := (session.ClipboardTarget{Append: t.Append}).OnSuccess(text); err != nil {
 		return fmt.Errorf("clipboard write: %w", err)
 	}
 	return nil
@@ -276,29 +704,6 @@ func isClipboardWriteError(err error) bool {
 }
 
 func isRegionSelectionError(err error) bool {
-	return strings.Contains(err.Error(), "failed to start region selection")
-}
-
-// sanitizeForLogging removes potentially dangerous characters from text for safe logging
-func sanitizeForLogging(text string) string {
-	// Limit length to prevent log flooding
-	const maxLogLength = 100
-	if len(text) > maxLogLength {
-		text = text[:maxLogLength] + "..."
-	}
-
-	// Replace newlines and other control characters to prevent log injection
-	sanitized := ""
-	for _, r := range text {
-		if r == '\n' || r == '\r' {
-			sanitized += "\\n"
-		} else if r == '\t' {
-			sanitized += "\\t"
-		} else if r < 32 || r == 127 { // Control characters
-			sanitized += "?"
-		} else {
-			sanitized += string(r)
-		}
-	}
-	return sanitized
+	var selErr *session.SelectionError
+	return errors.As(err, &selErr)
 }
@@ -1,9 +1,21 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"screen-ocr-llm/src/config"
+	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/ocr"
+	"screen-ocr-llm/src/screenshot"
+	"screen-ocr-llm/src/session"
+	"screen-ocr-llm/src/singleinstance"
 )
 
 func TestNormalizeLegacyArgs(t *testing.T) {
@@ -22,6 +34,11 @@ func TestNormalizeLegacyArgs(t *testing.T) {
 			in:   []string{"screen-ocr-llm", "-run-once=true", "-api-key-path=/tmp/key", "-default-mode=rect"},
 			out:  []string{"screen-ocr-llm", "--run-once=true", "--api-key-path=/tmp/key", "--default-mode=rect"},
 		},
+		{
+			name: "Leaves newer flags single-dash",
+			in:   []string{"screen-ocr-llm", "-output", "stdout", "-config=/tmp/.env", "-dry-run"},
+			out:  []string{"screen-ocr-llm", "-output", "stdout", "-config=/tmp/.env", "-dry-run"},
+		},
 		{
 			name: "Leaves other flags unchanged",
 			in:   []string{"screen-ocr-llm", "--run-once", "--other"},
@@ -47,36 +64,127 @@ func TestNormalizeLegacyArgs(t *testing.T) {
 func TestNewRootCmdParsesFlags(t *testing.T) {
 	opts := &mainOptions{}
 	cmd := newRootCmd(opts)
-	if err := cmd.ParseFlags([]string{"--run-once", "--api-key-path", "/tmp/key", "--default-mode", "lasso"}); err != nil {
+	if err := cmd.ParseFlags([]string{"--run-once", "--config", "/tmp/profile.env", "--api-key-path", "/tmp/key", "--default-mode", "lasso", "--save-screenshot", "/tmp/shots", "--error-json", "--window", "Notepad", "--display", "1", "--dry-run"}); err != nil {
 		t.Fatalf("ParseFlags failed: %v", err)
 	}
 	if !opts.runOnce {
 		t.Fatal("Expected runOnce=true")
 	}
+	if got := opts.loadOptions().ConfigPath; got != "/tmp/profile.env" {
+		t.Fatalf("Expected ConfigPath=/tmp/profile.env, got %q", got)
+	}
 	if opts.apiKeyPath != "/tmp/key" {
 		t.Fatalf("Expected apiKeyPath=/tmp/key, got %q", opts.apiKeyPath)
 	}
 	if opts.defaultMode != "lasso" {
 		t.Fatalf("Expected defaultMode=lasso, got %q", opts.defaultMode)
 	}
+	if got := opts.loadOptions().SaveScreenshotDirOverride; got != "/tmp/shots" {
+		t.Fatalf("Expected SaveScreenshotDirOverride=/tmp/shots, got %q", got)
+	}
+	if !opts.errorJSON {
+		t.Fatal("Expected errorJSON=true")
+	}
+	if !opts.loadOptions().DryRunOverride {
+		t.Fatal("Expected DryRunOverride=true")
+	}
+	if opts.window != "Notepad" {
+		t.Fatalf("Expected window=Notepad, got %q", opts.window)
+	}
+	if opts.display != 1 {
+		t.Fatalf("Expected display=1, got %d", opts.display)
+	}
+}
+
+func TestDirectCaptureSources(t *testing.T) {
+	tests := []struct {
+		name string
+		opts mainOptions
+		want int
+	}{
+		{name: "none", opts: mainOptions{display: 2}, want: 0},
+		{name: "display 0", opts: mainOptions{displaySet: true}, want: 1},
+		{name: "window", opts: mainOptions{window: "Notepad"}, want: 1},
+		{name: "preset and display", opts: mainOptions{regionPreset: "chat", displaySet: true}, want: 2},
+		{name: "clipboard", opts: mainOptions{fromClipboard: true}, want: 1},
+		{name: "clipboard and window", opts: mainOptions{fromClipboard: true, window: "Notepad"}, want: 2},
+		{name: "clipboard and display", opts: mainOptions{fromClipboard: true, displaySet: true}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.directCapture().sources(); got != tt.want {
+				t.Fatalf("sources() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunOnceRequest(t *testing.T) {
+	req, err := runOnceRequest(mainOptions{runOnce: true})
+	if err != nil || req.Output != singleinstance.OutputClipboard {
+		t.Fatalf("default request = %+v, %v; want clipboard", req, err)
+	}
+
+	req, err = runOnceRequest(mainOptions{output: "stdout"})
+	if err != nil || !req.OutputToStdout {
+		t.Fatalf("stdout request = %+v, %v", req, err)
+	}
+
+	req, err = runOnceRequest(mainOptions{output: "type"})
+	if err != nil || req.Output != singleinstance.OutputType || req.OutputToStdout {
+		t.Fatalf("type request = %+v, %v", req, err)
+	}
+
+	req, err = runOnceRequest(mainOptions{outputFile: "result.txt"})
+	if err != nil || req.Output != singleinstance.OutputFile || !filepath.IsAbs(req.OutputFile) {
+		t.Fatalf("file request = %+v, %v; want absolute file output", req, err)
+	}
+
+	if _, err := runOnceRequest(mainOptions{output: "file"}); err == nil {
+		t.Fatal("expected error when --output file has no --output-file")
+	}
+	if _, err := runOnceRequest(mainOptions{output: "stdout", outputFile: "x.txt"}); err == nil {
+		t.Fatal("expected error when --output-file is combined with --output stdout")
+	}
+	if _, err := runOnceRequest(mainOptions{output: "printer"}); err == nil {
+		t.Fatal("expected error for unknown --output")
+	}
 }
 
 type fakeClient struct {
 	delegated bool
 	err       error
-	called    bool
+	// busy is the number of calls answered with ErrResidentBusy before
+	// the delegated/err result.
+	busy   int
+	calls  int
+	called bool
+	req    singleinstance.Request
 }
 
-func (f *fakeClient) TryRunOnce(ctx context.Context, outputToStdout bool) (bool, string, error) {
+func (f *fakeClient) TryRunOnce(ctx context.Context, req singleinstance.Request) (bool, string, error) {
 	f.called = true
+	f.calls++
+	f.req = req
+	if f.calls <= f.busy {
+		return true, "", singleinstance.ErrResidentBusy
+	}
 	return f.delegated, "", f.err
 }
 
+// fastBusyRetry shortens the busy backoff for the duration of a test.
+func fastBusyRetry(t *testing.T) {
+	t.Helper()
+	prev := busyBackoff
+	busyBackoff = time.Millisecond
+	t.Cleanup(func() { busyBackoff = prev })
+}
+
 func TestHandleRunOnceWithDelegation_Delegated(t *testing.T) {
 	client := &fakeClient{delegated: true}
 	fallbackCalled := false
 
-	handleRunOnceWithDelegation("", "", client, func() {
+	handleRunOnceWithDelegation(config.LoadOptions{}, client, singleinstance.Request{}, func() {
 		fallbackCalled = true
 	})
 
@@ -92,7 +200,7 @@ func TestHandleRunOnceWithDelegation_NoResidentFallback(t *testing.T) {
 	client := &fakeClient{delegated: false}
 	fallbackCalled := false
 
-	handleRunOnceWithDelegation("", "", client, func() {
+	handleRunOnceWithDelegation(config.LoadOptions{}, client, singleinstance.Request{}, func() {
 		fallbackCalled = true
 	})
 
@@ -104,11 +212,51 @@ func TestHandleRunOnceWithDelegation_NoResidentFallback(t *testing.T) {
 	}
 }
 
+func TestHandleRunOnceWithDelegation_BusyRetrySucceeds(t *testing.T) {
+	fastBusyRetry(t)
+	client := &fakeClient{delegated: true, busy: 2}
+	fallbackCalled := false
+
+	err := handleRunOnceWithDelegation(config.LoadOptions{}, client, singleinstance.Request{}, func() {
+		fallbackCalled = true
+	})
+
+	if err != nil {
+		t.Fatalf("Expected delegation to succeed after retries, got %v", err)
+	}
+	if client.calls != 3 {
+		t.Fatalf("Expected 3 delegation attempts, got %d", client.calls)
+	}
+	if fallbackCalled {
+		t.Fatal("Did not expect fallback when a retry succeeds")
+	}
+}
+
+func TestHandleRunOnceWithDelegation_BusyNeverFallsBack(t *testing.T) {
+	fastBusyRetry(t)
+	client := &fakeClient{delegated: true, busy: busyRetries + 1}
+	fallbackCalled := false
+
+	err := handleRunOnceWithDelegation(config.LoadOptions{}, client, singleinstance.Request{}, func() {
+		fallbackCalled = true
+	})
+
+	if !errors.Is(err, singleinstance.ErrResidentBusy) {
+		t.Fatalf("Expected ErrResidentBusy, got %v", err)
+	}
+	if client.calls != busyRetries+1 {
+		t.Fatalf("Expected %d delegation attempts, got %d", busyRetries+1, client.calls)
+	}
+	if fallbackCalled {
+		t.Fatal("Did not expect standalone fallback while the resident is busy")
+	}
+}
+
 func TestHandleRunOnceWithDelegation_DelegationErrorFallback(t *testing.T) {
 	client := &fakeClient{err: errors.New("busy")}
 	fallbackCalled := false
 
-	handleRunOnceWithDelegation("", "", client, func() {
+	handleRunOnceWithDelegation(config.LoadOptions{}, client, singleinstance.Request{}, func() {
 		fallbackCalled = true
 	})
 
@@ -119,3 +267,69 @@ func TestHandleRunOnceWithDelegation_DelegationErrorFallback(t *testing.T) {
 		t.Fatal("Expected fallback when delegation returns an error")
 	}
 }
+
+func TestHandleRunOnceWithDelegation_ResidentErrorNoFallback(t *testing.T) {
+	client := &fakeClient{delegated: true, err: &singleinstance.ResidentError{Message: "selection cancelled"}}
+	fallbackCalled := false
+
+	err := handleRunOnceWithDelegation(config.LoadOptions{}, client, singleinstance.Request{}, func() {
+		fallbackCalled = true
+	})
+
+	var residentErr *singleinstance.ResidentError
+	if !errors.As(err, &residentErr) {
+		t.Fatalf("Expected *ResidentError, got %v", err)
+	}
+	if fallbackCalled {
+		t.Fatal("Did not expect standalone fallback after the resident reported a failure")
+	}
+}
+
+func TestRunOnceStage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"cancelled", session.ErrSelectionCancelled, stageRegionCancelled},
+		{"selection", &session.SelectionError{Err: errors.New("no window")}, stageCaptureFailed},
+		{"capture", &ocr.CaptureError{Err: screenshot.ErrBlankCapture}, stageCaptureFailed},
+		{"api", errors.New("API request failed with status 500"), stageAPIFailed},
+		{"clipboard", &session.OutputError{Err: &session.ClipboardError{Err: errors.New("access denied")}}, stageClipboardFailed},
+		{"file", &session.OutputError{Err: errors.New("open out.txt: permission denied")}, stageOutputFailed},
+		{"clipboard image", &clipboardImageError{err: errors.New("no image on the clipboard")}, stageClipboardFailed},
+		{"busy", singleinstance.ErrResidentBusy, stageResidentBusy},
+		{"resident cancelled", &singleinstance.ResidentError{Stage: stageRegionCancelled, Message: "selection cancelled"}, stageRegionCancelled},
+		{"resident selection", &singleinstance.ResidentError{Stage: stageCaptureFailed, Message: "Failed to select region: boom"}, stageCaptureFailed},
+		{"resident clipboard", &singleinstance.ResidentError{Stage: stageClipboardFailed, Message: "clipboard error: locked"}, stageClipboardFailed},
+		{"resident ocr", &singleinstance.ResidentError{Stage: stageAPIFailed, Message: "context deadline exceeded"}, stageAPIFailed},
+		{"no text", fmt.Errorf("OCR failed: %w", llm.ErrNoTextFound), stageNoTextFound},
+		{"resident no text", &singleinstance.ResidentError{Stage: stageNoTextFound, Message: "no text found"}, stageNoTextFound},
+		{"resident without stage", &singleinstance.ResidentError{Message: "no text found"}, stageAPIFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runOnceStage(tt.err); got != tt.want {
+				t.Errorf("runOnceStage(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteRunOnceFailure(t *testing.T) {
+	var text bytes.Buffer
+	writeRunOnceFailure(&text, false, stageAPIFailed, "OCR failed: boom", errors.New("boom"))
+	if text.String() != "OCR failed: boom\n" {
+		t.Fatalf("text output = %q", text.String())
+	}
+
+	var js bytes.Buffer
+	writeRunOnceFailure(&js, true, stageAPIFailed, "OCR failed: boom", errors.New("boom"))
+	var got runOnceFailure
+	if err := json.Unmarshal(js.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", js.String(), err)
+	}
+	if got.Error != "boom" || got.Stage != stageAPIFailed {
+		t.Fatalf("JSON output = %+v", got)
+	}
+}
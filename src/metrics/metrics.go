@@ -0,0 +1,133 @@
+// Package metrics counts the resident's OCR jobs for GET /metrics on the
+// optional HTTP endpoint. All methods are safe for concurrent use and do
+// nothing on a nil *Metrics.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics holds the counters of one resident.
+type Metrics struct {
+	successes      atomic.Int64
+	failures       atomic.Int64
+	busyRejections atomic.Int64
+	latencyNanos   atomic.Int64
+	busy           atomic.Bool
+
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// Snapshot is a point-in-time copy of the counters, as served in JSON.
+type Snapshot struct {
+	Total          int64   `json:"ocr_total"`
+	Successes      int64   `json:"ocr_successes"`
+	Failures       int64   `json:"ocr_failures"`
+	BusyRejections int64   `json:"busy_rejections"`
+	AverageLatency float64 `json:"average_latency_seconds"`
+	Busy           bool    `json:"busy"`
+	LastError      string  `json:"last_error,omitempty"`
+	LastErrorAt    string  `json:"last_error_at,omitempty"`
+}
+
+// New returns zeroed counters.
+func New() *Metrics {
+	return &Metrics{}
+}
+
+// RecordSuccess counts an OCR job that delivered its result after latency.
+func (m *Metrics) RecordSuccess(latency time.Duration) {
+	if m == nil {
+		return
+	}
+	m.successes.Add(1)
+	m.latencyNanos.Add(int64(latency))
+}
+
+// RecordFailure counts an OCR job that failed with err after latency and
+// remembers err as the last error.
+func (m *Metrics) RecordFailure(latency time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.failures.Add(1)
+	m.latencyNanos.Add(int64(latency))
+	if err == nil {
+		return
+	}
+	m.mu.Lock()
+	m.lastError = err.Error()
+	m.lastErrorAt = time.Now()
+	m.mu.Unlock()
+}
+
+// RecordBusyRejection counts a request turned away because a job was running.
+func (m *Metrics) RecordBusyRejection() {
+	if m == nil {
+		return
+	}
+	m.busyRejections.Add(1)
+}
+
+// SetBusy records whether an OCR job is running.
+func (m *Metrics) SetBusy(busy bool) {
+	if m == nil {
+		return
+	}
+	m.busy.Store(busy)
+}
+
+// Snapshot returns the current counters. The average latency covers
+// successful and failed jobs alike.
+func (m *Metrics) Snapshot() Snapshot {
+	if m == nil {
+		return Snapshot{}
+	}
+	s := Snapshot{
+		Successes:      m.successes.Load(),
+		Failures:       m.failures.Load(),
+		BusyRejections: m.busyRejections.Load(),
+		Busy:           m.busy.Load(),
+	}
+	s.Total = s.Successes + s.Failures
+	if s.Total > 0 {
+		s.AverageLatency = time.Duration(m.latencyNanos.Load() / s.Total).Seconds()
+	}
+	m.mu.Lock()
+	s.LastError = m.lastError
+	if !m.lastErrorAt.IsZero() {
+		s.LastErrorAt = m.lastErrorAt.UTC().Format(time.RFC3339)
+	}
+	m.mu.Unlock()
+	return s
+}
+
+// WritePrometheus writes s in the Prometheus text exposition format. The last
+// error is not a number and is left out.
+func (s Snapshot) WritePrometheus(w io.Writer) error {
+	busy := 0
+	if s.Busy {
+		busy = 1
+	}
+	_, err := fmt.Fprintf(w, `# HELP screen_ocr_jobs_total OCR jobs completed, by outcome.
+# TYPE screen_ocr_jobs_total counter
+screen_ocr_jobs_total{outcome="success"} %d
+screen_ocr_jobs_total{outcome="failure"} %d
+# HELP screen_ocr_busy_rejections_total Requests rejected because an OCR job was running.
+# TYPE screen_ocr_busy_rejections_total counter
+screen_ocr_busy_rejections_total %d
+# HELP screen_ocr_average_latency_seconds Mean duration of completed OCR jobs.
+# TYPE screen_ocr_average_latency_seconds gauge
+screen_ocr_average_latency_seconds %g
+# HELP screen_ocr_busy Whether an OCR job is running.
+# TYPE screen_ocr_busy gauge
+screen_ocr_busy %d
+`, s.Successes, s.Failures, s.BusyRejections, s.AverageLatency, busy)
+	return err
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	m := New()
+	m.RecordSuccess(1 * time.Second)
+	m.RecordSuccess(2 * time.Second)
+	m.RecordFailure(3*time.Second, errors.New("API request failed"))
+	m.RecordBusyRejection()
+	m.SetBusy(true)
+
+	s := m.Snapshot()
+	if s.Total != 3 || s.Successes != 2 || s.Failures != 1 || s.BusyRejections != 1 {
+		t.Fatalf("unexpected counters: %+v", s)
+	}
+	if s.AverageLatency != 2 {
+		t.Fatalf("expected average latency 2s, got %g", s.AverageLatency)
+	}
+	if !s.Busy || s.LastError != "API request failed" || s.LastErrorAt == "" {
+		t.Fatalf("unexpected state: %+v", s)
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.RecordSuccess(time.Second)
+	m.RecordFailure(time.Second, errors.New("boom"))
+	m.RecordBusyRejection()
+	m.SetBusy(true)
+	if s := m.Snapshot(); s != (Snapshot{}) {
+		t.Fatalf("expected empty snapshot, got %+v", s)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	m := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.RecordSuccess(time.Millisecond)
+			m.RecordFailure(time.Millisecond, errors.New("boom"))
+			m.RecordBusyRejection()
+			m.SetBusy(true)
+			_ = m.Snapshot()
+		}()
+	}
+	wg.Wait()
+	if s := m.Snapshot(); s.Successes != 50 || s.Failures != 50 || s.BusyRejections != 50 {
+		t.Fatalf("unexpected counters: %+v", s)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	var b strings.Builder
+	s := Snapshot{Successes: 4, Failures: 1, BusyRejections: 2, AverageLatency: 1.5, Busy: true}
+	if err := s.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+	for _, want := range []string{
+		`screen_ocr_jobs_total{outcome="success"} 4`,
+		`screen_ocr_jobs_total{outcome="failure"} 1`,
+		"screen_ocr_busy_rejections_total 2",
+		"screen_ocr_average_latency_seconds 1.5",
+		"screen_ocr_busy 1",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, b.String())
+		}
+	}
+}
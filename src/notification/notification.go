@@ -1,24 +1,24 @@
 package notification
 
 import (
+	"fmt"
 	"log"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
-// ShowOCRResult displays a temporary popup with OCR results
+// ShowOCRResult displays a temporary popup with OCR results. Text longer
+// than MaxChars is truncated for display (see SetMaxChars).
 func ShowOCRResult(text string) {
-	// Truncate text to 200 characters
-	displayText := text
-	if len(text) > 200 {
-		displayText = text[:200] + "..."
-	}
-
 	// Show platform-specific notification
 	if runtime.GOOS == "windows" {
-		showWindowsNotification(displayText)
+		showWindowsNotification(text)
 	} else {
 		// For other platforms, just log for now
-		log.Printf("OCR Result: %s", displayText)
+		log.Printf("OCR Result: %s", displayText(text, MaxChars()))
 	}
 }
 
@@ -33,3 +33,218 @@ func showWindowsNotification(text string) {
 }
 
 // showWindowsPopup is implemented in notification_windows.go
+
+// Popup positions accepted by PopupConfig.Position (POPUP_POSITION).
+const (
+	PositionBottomLeft  = "bottom-left"
+	PositionBottomRight = "bottom-right"
+	PositionTopLeft     = "top-left"
+	PositionTopRight    = "top-right"
+	PositionCenter      = "center"
+)
+
+// Popup color themes accepted by PopupConfig.Theme (POPUP_THEME).
+const (
+	ThemeAuto  = "auto"
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+)
+
+const (
+	defaultPopupWidth  = 400
+	defaultPopupHeight = 100
+	popupMargin        = 20
+	// popupPadding is the space between the popup border and its text
+	popupPadding = 10
+	// popupFrame accounts for the WS_EX_CLIENTEDGE border on both sides
+	popupFrame = 4
+	// popupMaxHeightPercent caps how far a popup grows to fit long text
+	popupMaxHeightPercent = 60
+)
+
+// PopupConfig controls where the result/countdown popup appears, its size and colors.
+// Zero values select the default 400x100 window in the lower-left corner,
+// following the system light/dark app theme.
+type PopupConfig struct {
+	Position string
+	Width    int
+	Height   int
+	Theme    string
+}
+
+// DefaultResultDuration is how long a recognized result stays on screen.
+const DefaultResultDuration = 3 * time.Second
+
+var (
+	popupConfigMu  sync.Mutex
+	popupConfig    PopupConfig
+	resultDuration = DefaultResultDuration
+	sticky         bool
+	maxChars       = DefaultMaxChars
+)
+
+// DefaultMaxChars is how many characters of a result the popup shows.
+const DefaultMaxChars = 1000
+
+// SetResultDuration sets how long the popup stays open after switching from
+// the countdown to the OCR result. Zero keeps it open until clicked.
+func SetResultDuration(d time.Duration) {
+	popupConfigMu.Lock()
+	defer popupConfigMu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	resultDuration = d
+}
+
+// ResultDuration returns the configured result display duration.
+func ResultDuration() time.Duration {
+	popupConfigMu.Lock()
+	defer popupConfigMu.Unlock()
+	return resultDuration
+}
+
+// SetSticky makes result popups stay open until clicked, whatever their
+// duration, for users who need unlimited reading time (POPUP_STICKY). The
+// countdown shown while OCR runs still closes when it times out.
+func SetSticky(enabled bool) {
+	popupConfigMu.Lock()
+	defer popupConfigMu.Unlock()
+	sticky = enabled
+}
+
+// Sticky reports whether result popups stay open until clicked.
+func Sticky() bool {
+	popupConfigMu.Lock()
+	defer popupConfigMu.Unlock()
+	return sticky
+}
+
+// SetMaxChars sets how many characters of a text the popup shows before
+// cutting it off with a note of how much was left out (POPUP_MAX_CHARS).
+// Zero or less shows the whole text.
+func SetMaxChars(n int) {
+	popupConfigMu.Lock()
+	defer popupConfigMu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	maxChars = n
+}
+
+// MaxChars returns the popup text limit; 0 means no limit.
+func MaxChars() int {
+	popupConfigMu.Lock()
+	defer popupConfigMu.Unlock()
+	return maxChars
+}
+
+// displayText returns the part of text the popup shows: the first max
+// characters, then a line saying how many more there are. The popup only
+// shows results; the full text is still on the clipboard. max <= 0 keeps
+// the whole text.
+func displayText(text string, max int) string {
+	if max <= 0 {
+		return text
+	}
+	chars := 0
+	for i := range text {
+		if chars == max {
+			rest := utf8.RuneCountInString(text[i:])
+			return fmt.Sprintf("%s\n… (+%d chars, on clipboard)", strings.TrimRight(text[:i], " \t\r\n"), rest)
+		}
+		chars++
+	}
+	return text
+}
+
+// closeDelay returns how long a result popup that would close after d stays
+// open; 0 keeps it until clicked.
+func closeDelay(d time.Duration) time.Duration {
+	if Sticky() {
+		return 0
+	}
+	return d
+}
+
+// Configure sets the popup placement used for subsequently created popups.
+func Configure(cfg PopupConfig) {
+	popupConfigMu.Lock()
+	defer popupConfigMu.Unlock()
+	popupConfig = cfg
+}
+
+func currentPopupConfig() PopupConfig {
+	popupConfigMu.Lock()
+	defer popupConfigMu.Unlock()
+	return popupConfig
+}
+
+// fitHeight returns the popup height needed to show textHeight pixels of text,
+// never smaller than base and never taller than popupMaxHeightPercent of the screen.
+func fitHeight(base, textHeight, screenHeight int) int {
+	want := textHeight + 2*popupPadding + popupFrame
+	limit := screenHeight * popupMaxHeightPercent / 100
+	if want > limit {
+		want = limit
+	}
+	if want < base {
+		want = base
+	}
+	return want
+}
+
+// clampScroll keeps a vertical scroll offset within the overflowing part of the text.
+func clampScroll(offset, textHeight, viewHeight int) int {
+	maxOffset := textHeight - viewHeight
+	if offset > maxOffset {
+		offset = maxOffset
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
+
+// useDarkTheme reports whether the popup should be painted dark. Unknown or
+// empty themes behave like auto and follow systemDark.
+func useDarkTheme(theme string, systemDark func() bool) bool {
+	switch strings.ToLower(strings.TrimSpace(theme)) {
+	case ThemeDark:
+		return true
+	case ThemeLight:
+		return false
+	default:
+		return systemDark()
+	}
+}
+
+// popupRect computes the popup window rectangle on a screen of the given size.
+func popupRect(cfg PopupConfig, screenWidth, screenHeight int) (x, y, width, height int) {
+	width, height = cfg.Width, cfg.Height
+	if width <= 0 {
+		width = defaultPopupWidth
+	}
+	if height <= 0 {
+		height = defaultPopupHeight
+	}
+
+	left := popupMargin
+	right := screenWidth - width - popupMargin
+	top := popupMargin
+	bottom := screenHeight - height - popupMargin
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Position)) {
+	case PositionBottomRight:
+		x, y = right, bottom
+	case PositionTopLeft:
+		x, y = left, top
+	case PositionTopRight:
+		x, y = right, top
+	case PositionCenter:
+		x, y = (screenWidth-width)/2, (screenHeight-height)/2
+	default:
+		x, y = left, bottom
+	}
+	return x, y, width, height
+}
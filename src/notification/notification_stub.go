@@ -9,7 +9,30 @@ func ShowBlockingError(title, message string) {
 	log.Printf("%s: %s", title, message)
 }
 
+// StartCountdownPopup logs the countdown start on non-Windows platforms.
+func StartCountdownPopup(timeoutSeconds int) error {
+	log.Printf("OCR in progress... %d seconds remaining", timeoutSeconds)
+	return nil
+}
+
+// UpdatePopupText logs the popup text on non-Windows platforms.
+func UpdatePopupText(text string) error {
+	log.Printf("OCR Result: %s", displayText(text, MaxChars()))
+	return nil
+}
+
+// WaitPopupClosed returns immediately; there are no popups on non-Windows platforms.
+func WaitPopupClosed() {}
+
+// ClosePopup is a no-op on non-Windows platforms.
+func ClosePopup() error {
+	return nil
+}
+
 func showWindowsPopup(text string) error {
 	log.Printf("OCR Result: %s", text)
 	return nil
 }
+
+// PlayCompletionSound is a no-op on non-Windows platforms.
+func PlayCompletionSound(success bool) {}
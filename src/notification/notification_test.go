@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPopupRect(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        PopupConfig
+		x, y, w, h int
+	}{
+		{name: "default lower-left 400x100", cfg: PopupConfig{}, x: 20, y: 960, w: 400, h: 100},
+		{name: "bottom-right", cfg: PopupConfig{Position: "bottom-right"}, x: 1500, y: 960, w: 400, h: 100},
+		{name: "top-left custom size", cfg: PopupConfig{Position: "top-left", Width: 600, Height: 200}, x: 20, y: 20, w: 600, h: 200},
+		{name: "top-right", cfg: PopupConfig{Position: "TOP-RIGHT", Width: 300}, x: 1600, y: 20, w: 300, h: 100},
+		{name: "center", cfg: PopupConfig{Position: "center", Width: 800, Height: 400}, x: 560, y: 340, w: 800, h: 400},
+		{name: "unknown falls back to bottom-left", cfg: PopupConfig{Position: "middle"}, x: 20, y: 960, w: 400, h: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, w, h := popupRect(tt.cfg, 1920, 1080)
+			if x != tt.x || y != tt.y || w != tt.w || h != tt.h {
+				t.Fatalf("popupRect() = (%d,%d %dx%d), want (%d,%d %dx%d)", x, y, w, h, tt.x, tt.y, tt.w, tt.h)
+			}
+		})
+	}
+}
+
+func TestSetResultDuration(t *testing.T) {
+	defer SetResultDuration(DefaultResultDuration)
+
+	SetResultDuration(10 * time.Second)
+	if got := ResultDuration(); got != 10*time.Second {
+		t.Fatalf("ResultDuration() = %v, want 10s", got)
+	}
+	SetResultDuration(-time.Second)
+	if got := ResultDuration(); got != 0 {
+		t.Fatalf("ResultDuration() = %v, want 0 for negative input", got)
+	}
+}
+
+func TestStickyKeepsResultOpen(t *testing.T) {
+	defer SetSticky(false)
+
+	if got := closeDelay(DefaultResultDuration); got != DefaultResultDuration {
+		t.Fatalf("closeDelay() = %v, want %v", got, DefaultResultDuration)
+	}
+	SetSticky(true)
+	if !Sticky() {
+		t.Fatal("Sticky() = false after SetSticky(true)")
+	}
+	if got := closeDelay(DefaultResultDuration); got != 0 {
+		t.Fatalf("closeDelay() = %v, want 0 while sticky", got)
+	}
+}
+
+func TestDisplayText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want string
+	}{
+		{name: "short text unchanged", text: "hello", max: 10, want: "hello"},
+		{name: "exactly max unchanged", text: "hello", max: 5, want: "hello"},
+		{name: "no limit", text: strings.Repeat("a", 5000), max: 0, want: strings.Repeat("a", 5000)},
+		{name: "cut with count", text: "hello world", max: 5, want: "hello\n… (+6 chars, on clipboard)"},
+		{name: "counts characters not bytes", text: "日本語のテキスト", max: 3, want: "日本語\n… (+5 chars, on clipboard)"},
+		{name: "trailing space trimmed", text: "one two\nthree", max: 8, want: "one two\n… (+5 chars, on clipboard)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := displayText(tt.text, tt.max); got != tt.want {
+				t.Fatalf("displayText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetMaxChars(t *testing.T) {
+	defer SetMaxChars(DefaultMaxChars)
+
+	SetMaxChars(50)
+	if got := MaxChars(); got != 50 {
+		t.Fatalf("MaxChars() = %d, want 50", got)
+	}
+	SetMaxChars(-1)
+	if got := MaxChars(); got != 0 {
+		t.Fatalf("MaxChars() = %d, want 0 for negative input", got)
+	}
+}
+
+func TestFitHeight(t *testing.T) {
+	tests := []struct {
+		name                         string
+		base, textHeight, screenH, h int
+	}{
+		{name: "short text keeps base", base: 100, textHeight: 32, screenH: 1080, h: 100},
+		{name: "grows to fit", base: 100, textHeight: 300, screenH: 1080, h: 324},
+		{name: "capped at 60 percent", base: 100, textHeight: 2000, screenH: 1080, h: 648},
+		{name: "base larger than cap", base: 800, textHeight: 2000, screenH: 1080, h: 800},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitHeight(tt.base, tt.textHeight, tt.screenH); got != tt.h {
+				t.Fatalf("fitHeight() = %d, want %d", got, tt.h)
+			}
+		})
+	}
+}
+
+func TestClampScroll(t *testing.T) {
+	if got := clampScroll(-40, 500, 200); got != 0 {
+		t.Fatalf("clampScroll(-40) = %d, want 0", got)
+	}
+	if got := clampScroll(120, 500, 200); got != 120 {
+		t.Fatalf("clampScroll(120) = %d, want 120", got)
+	}
+	if got := clampScroll(900, 500, 200); got != 300 {
+		t.Fatalf("clampScroll(900) = %d, want 300", got)
+	}
+	if got := clampScroll(50, 100, 200); got != 0 {
+		t.Fatalf("clampScroll() with text shorter than view = %d, want 0", got)
+	}
+}
+
+func TestUseDarkTheme(t *testing.T) {
+	systemDark := func() bool { return true }
+	systemLight := func() bool { return false }
+
+	if !useDarkTheme("dark", systemLight) {
+		t.Fatal("dark theme should be dark regardless of system setting")
+	}
+	if useDarkTheme("Light", systemDark) {
+		t.Fatal("light theme should be light regardless of system setting")
+	}
+	if !useDarkTheme("auto", systemDark) || useDarkTheme("auto", systemLight) {
+		t.Fatal("auto theme should follow the system setting")
+	}
+	if !useDarkTheme("", systemDark) || useDarkTheme("bogus", systemLight) {
+		t.Fatal("empty or unknown theme should behave like auto")
+	}
+}
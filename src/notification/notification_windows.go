@@ -10,12 +10,19 @@ import (
 	"syscall"
 	"time"
 	"unsafe"
+
+	"golang.org/x/sys/windows/registry"
+
+	"screen-ocr-llm/src/clipboard"
+	"screen-ocr-llm/src/logutil"
 )
 
 var (
 	user32                 = syscall.NewLazyDLL("user32.dll")
 	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	gdi32                  = syscall.NewLazyDLL("gdi32.dll")
 	procMessageBox         = user32.NewProc("MessageBoxW")
+	procMessageBeep        = user32.NewProc("MessageBeep")
 	procCreateWindowEx     = user32.NewProc("CreateWindowExW")
 	procDefWindowProc      = user32.NewProc("DefWindowProcW")
 	procDestroyWindow      = user32.NewProc("DestroyWindow")
@@ -38,6 +45,15 @@ var (
 	procPostMessage        = user32.NewProc("PostMessageW")
 	procPostThreadMessage  = user32.NewProc("PostThreadMessageW")
 	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+	procGetDC              = user32.NewProc("GetDC")
+	procReleaseDC          = user32.NewProc("ReleaseDC")
+	procIntersectClipRect  = gdi32.NewProc("IntersectClipRect")
+	procCreateSolidBrush   = gdi32.NewProc("CreateSolidBrush")
+	procSetTextColor       = gdi32.NewProc("SetTextColor")
+	procSetBkColor         = gdi32.NewProc("SetBkColor")
+	procFillRect           = user32.NewProc("FillRect")
+	procFrameRect          = user32.NewProc("FrameRect")
+	procGetClientRect      = user32.NewProc("GetClientRect")
 )
 
 const (
@@ -49,12 +65,14 @@ const (
 	WS_EX_CLIENTEDGE = 0x00000200
 	WM_DESTROY       = 0x0002
 	WM_PAINT         = 0x000F
+	WM_ERASEBKGND    = 0x0014
 	WM_TIMER         = 0x0113
 	WM_CLOSE         = 0x0010
 	WM_LBUTTONDOWN   = 0x0201
 	WM_RBUTTONDOWN   = 0x0204
 	WM_NCLBUTTONDOWN = 0x00A1
 	WM_NCRBUTTONDOWN = 0x00A4
+	WM_MOUSEWHEEL    = 0x020A
 	WM_USER          = 0x0400
 	WM_UPDATE_TEXT   = WM_USER + 1
 	WM_EXIT_LOOP     = WM_USER + 2
@@ -62,16 +80,29 @@ const (
 	SWP_NOACTIVATE   = 0x0010
 	SWP_NOMOVE       = 0x0002
 	SWP_NOSIZE       = 0x0001
+	SWP_NOZORDER     = 0x0004
 	HWND_TOPMOST     = ^uintptr(0)
 	SM_CXSCREEN      = 0
 	SM_CYSCREEN      = 1
 	DT_CENTER        = 0x00000001
 	DT_VCENTER       = 0x00000004
 	DT_WORDBREAK     = 0x00000010
+	DT_CALCRECT      = 0x00000400
+	WHEEL_DELTA      = 120
 	COLOR_WINDOW     = 5
 	IDC_ARROW        = 32512
 	TIMER_CLOSE      = 1
 	TIMER_COUNTDOWN  = 2
+
+	// wheelScrollStep is how many pixels one wheel notch scrolls the text
+	wheelScrollStep = 48
+
+	// Dark theme colors (COLORREF, 0x00BBGGRR)
+	darkBackground = 0x00202020
+	darkText       = 0x00E6E6E6
+	darkBorder     = 0x005A5A5A
+
+	personalizeKeyPath = `Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`
 )
 
 type WNDCLASSEX struct {
@@ -116,7 +147,22 @@ type RECT struct {
 }
 
 var (
-	popupText string
+	// popupText is the text shown, popupFullText the untruncated text
+	// right-click copies (see displayText)
+	popupText     string
+	popupFullText string
+	// popupWidth/popupHeight are the size of the current popup, used to lay out text
+	popupWidth  int32 = defaultPopupWidth
+	popupHeight int32 = defaultPopupHeight
+	// popupTextHeight is the measured height of popupText; popupScroll is the
+	// wheel offset into it when the text is taller than the popup
+	popupTextHeight int32
+	popupScroll     int32
+	// popupDark selects the dark palette (POPUP_THEME) for the current popup
+	popupDark bool
+	// dark theme brushes, created once on the popup thread
+	darkBackgroundBrush uintptr
+	darkBorderBrush     uintptr
 
 	// Single popup thread management
 	popupQueue            chan string
@@ -167,8 +213,8 @@ func initPopupThread() {
 
 			// Process popup requests sequentially
 			for text := range popupQueue {
-				log.Printf("Popup: Processing popup request")
-				if err := createAndShowPopup(text); err != nil {
+				logutil.Debug("Popup: Processing popup request")
+				if err := createAndShowPopup(text, currentPopupConfig()); err != nil {
 					log.Printf("Popup: Failed to show popup: %v", err)
 				}
 			}
@@ -182,7 +228,7 @@ func showWindowsPopup(text string) error {
 
 	select {
 	case popupQueue <- text:
-		log.Printf("Popup: Queued popup request")
+		logutil.Debug("Popup: Queued popup request")
 		return nil
 	default:
 		log.Printf("Popup: Queue full, dropping popup request")
@@ -190,6 +236,39 @@ func showWindowsPopup(text string) error {
 	}
 }
 
+// systemUsesDarkTheme reports whether Windows is set to the dark app theme.
+// AppsUseLightTheme is 0 for dark; a missing value means light.
+func systemUsesDarkTheme() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER, personalizeKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer key.Close()
+	v, _, err := key.GetIntegerValue("AppsUseLightTheme")
+	if err != nil {
+		return false
+	}
+	return v == 0
+}
+
+// paintBackground fills the client area with the dark palette and frames it;
+// the light theme keeps the class COLOR_WINDOW background and system edge.
+func paintBackground(hwnd syscall.Handle, hdc uintptr) {
+	if !popupDark {
+		return
+	}
+	if darkBackgroundBrush == 0 {
+		darkBackgroundBrush, _, _ = procCreateSolidBrush.Call(darkBackground)
+		darkBorderBrush, _, _ = procCreateSolidBrush.Call(darkBorder)
+	}
+	var rc RECT
+	procGetClientRect.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&rc)))
+	procFillRect.Call(hdc, uintptr(unsafe.Pointer(&rc)), darkBackgroundBrush)
+	procFrameRect.Call(hdc, uintptr(unsafe.Pointer(&rc)), darkBorderBrush)
+	procSetTextColor.Call(hdc, darkText)
+	procSetBkColor.Call(hdc, darkBackground)
+}
+
 func loadCursor() syscall.Handle {
 	cursor, _, _ := procLoadCursor.Call(0, IDC_ARROW)
 	return syscall.Handle(cursor)
@@ -200,9 +279,18 @@ func wndProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr {
 	case WM_PAINT:
 		var ps PAINTSTRUCT
 		hdc, _, _ := procBeginPaint.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&ps)))
+		paintBackground(hwnd, hdc)
+
+		// Keep scrolled text inside the padded area
+		procIntersectClipRect.Call(hdc, popupPadding, popupPadding, uintptr(popupWidth-popupPadding), uintptr(popupHeight-popupPadding))
 
-		// Draw text (left-aligned, top-aligned, with word wrap)
-		rect := RECT{Left: 10, Top: 10, Right: 390, Bottom: 90}
+		// Draw text (left-aligned, top-aligned, with word wrap), shifted by the wheel offset
+		top := popupPadding - popupScroll
+		bottom := top + popupTextHeight
+		if bottom < popupHeight-popupPadding {
+			bottom = popupHeight - popupPadding
+		}
+		rect := RECT{Left: popupPadding, Top: top, Right: popupWidth - popupPadding, Bottom: bottom}
 		textPtr, _ := syscall.UTF16PtrFromString(popupText)
 		procDrawText.Call(
 			hdc,
@@ -215,6 +303,12 @@ func wndProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr {
 		procEndPaint.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&ps)))
 		return 0
 
+	case WM_ERASEBKGND:
+		if popupDark {
+			// WM_PAINT fills the dark background; skip the light flash
+			return 1
+		}
+
 	case WM_TIMER:
 		timerID := wParam
 		if timerID == TIMER_COUNTDOWN {
@@ -229,7 +323,7 @@ func wndProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr {
 					procInvalidateRect.Call(uintptr(hwnd), 0, 1)
 				} else {
 					// Timeout reached - close popup
-					log.Printf("Popup: Countdown reached zero, closing")
+					logutil.Debug("Popup: Countdown reached zero, closing")
 					isCountdownMode = false
 					currentPopupMutex.Unlock()
 					procKillTimer.Call(uintptr(hwnd), TIMER_COUNTDOWN)
@@ -241,7 +335,7 @@ func wndProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr {
 			return 0
 		} else if timerID == TIMER_CLOSE {
 			// Close timer expired
-			log.Printf("Popup: Close timer expired, closing window")
+			logutil.Debug("Popup: Close timer expired, closing window")
 			procKillTimer.Call(uintptr(hwnd), TIMER_CLOSE)
 			procKillTimer.Call(uintptr(hwnd), TIMER_COUNTDOWN)
 			procDestroyWindow.Call(uintptr(hwnd))
@@ -255,34 +349,55 @@ func wndProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr {
 		if isCountdownMode {
 			isCountdownMode = false
 			procKillTimer.Call(uintptr(hwnd), TIMER_COUNTDOWN)
-			// Set 3-second close timer
-			procSetTimer.Call(uintptr(hwnd), TIMER_CLOSE, 3000, 0)
-			log.Printf("Popup: Switched to result mode, showing for 3 seconds")
+			// Close after POPUP_DURATION_SEC; 0 or POPUP_STICKY keeps the
+			// result until clicked
+			if d := closeDelay(ResultDuration()); d > 0 {
+				procSetTimer.Call(uintptr(hwnd), TIMER_CLOSE, uintptr(d.Milliseconds()), 0)
+				logutil.Debug("Popup: Switched to result mode, showing for %v", d)
+			} else {
+				logutil.Debug("Popup: Switched to result mode, showing until clicked")
+			}
 		}
 		currentPopupMutex.Unlock()
-		// Force repaint with new text
+		// Grow the window for long results, then repaint with the new text
+		fitPopupToText(hwnd)
 		procInvalidateRect.Call(uintptr(hwnd), 0, 1)
 		return 0
 
-	case WM_LBUTTONDOWN, WM_RBUTTONDOWN, WM_NCLBUTTONDOWN, WM_NCRBUTTONDOWN:
-		// Close immediately on any click
-		log.Printf("Popup: Click detected, closing window")
+	case WM_MOUSEWHEEL:
+		// Scroll text that did not fit even after growing the window
+		delta := int32(int16(wParam >> 16))
+		offset := clampScroll(int(popupScroll-delta*wheelScrollStep/WHEEL_DELTA), int(popupTextHeight), int(popupHeight-2*popupPadding-popupFrame))
+		if int32(offset) != popupScroll {
+			popupScroll = int32(offset)
+			procInvalidateRect.Call(uintptr(hwnd), 0, 1)
+		}
+		return 0
+
+	case WM_RBUTTONDOWN, WM_NCRBUTTONDOWN:
+		// Right-click copies the shown text and keeps the popup open
+		copyPopupText()
+		return 0
+
+	case WM_LBUTTONDOWN, WM_NCLBUTTONDOWN:
+		// Close immediately on left click
+		logutil.Debug("Popup: Click detected, closing window")
 		procKillTimer.Call(uintptr(hwnd), TIMER_CLOSE)
 		procKillTimer.Call(uintptr(hwnd), TIMER_COUNTDOWN)
 		procDestroyWindow.Call(uintptr(hwnd))
 		return 0
 
 	case WM_DESTROY:
-		log.Printf("Popup: WM_DESTROY received for hwnd=%d", hwnd)
+		logutil.Debug("Popup: WM_DESTROY received for hwnd=%d", hwnd)
 		currentPopupMutex.Lock()
 		currentPopupHwnd = 0
 		isCountdownMode = false
 		currentPopupMutex.Unlock()
 		// Post custom exit message to thread (not window) to exit message loop
 		threadID, _, _ := procGetCurrentThreadId.Call()
-		log.Printf("Popup: Posting WM_EXIT_LOOP to thread %d", threadID)
+		logutil.Debug("Popup: Posting WM_EXIT_LOOP to thread %d", threadID)
 		ret, _, err := procPostThreadMessage.Call(threadID, WM_EXIT_LOOP, 0, 0)
-		log.Printf("Popup: PostThreadMessage result=%d, err=%v", ret, err)
+		logutil.Debug("Popup: PostThreadMessage result=%d, err=%v", ret, err)
 		return 0
 
 	case WM_CLOSE:
@@ -296,6 +411,27 @@ func wndProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr {
 	return ret
 }
 
+// copyPopupText puts the popup's text on the clipboard (not while counting down).
+func copyPopupText() {
+	currentPopupMutex.Lock()
+	text := popupFullText
+	counting := isCountdownMode
+	currentPopupMutex.Unlock()
+
+	if counting || text == "" {
+		log.Printf("Popup: Right-click ignored, no result to copy")
+		return
+	}
+	// Write off the popup thread so the message loop stays responsive
+	go func() {
+		if err := clipboard.Write(text); err != nil {
+			log.Printf("Popup: Failed to copy text to clipboard: %v", err)
+			return
+		}
+		log.Printf("Popup: Copied %d characters to clipboard", len(text))
+	}()
+}
+
 // registerPopupWindowClass registers the window class once
 func registerPopupWindowClass() error {
 	popupMutex.Lock()
@@ -323,32 +459,92 @@ func registerPopupWindowClass() error {
 	}
 
 	windowClassRegistered = true
-	log.Printf("Popup: Window class registered successfully")
+	logutil.Debug("Popup: Window class registered successfully")
 	return nil
 }
 
-// createAndShowPopup creates and shows a single popup window
-func createAndShowPopup(text string) error {
-	log.Printf("Popup: Creating popup window")
-	popupText = text
+// measureText returns the height DrawText needs for popupText at the current popup width
+func measureText(hwnd syscall.Handle) int32 {
+	hdc, _, _ := procGetDC.Call(uintptr(hwnd))
+	if hdc == 0 {
+		return 0
+	}
+	defer procReleaseDC.Call(uintptr(hwnd), hdc)
+
+	currentPopupMutex.Lock()
+	text := popupText
+	currentPopupMutex.Unlock()
+
+	rect := RECT{Left: popupPadding, Top: popupPadding, Right: popupWidth - popupPadding, Bottom: popupPadding}
+	textPtr, _ := syscall.UTF16PtrFromString(text)
+	procDrawText.Call(
+		hdc,
+		uintptr(unsafe.Pointer(textPtr)),
+		uintptr(^uint32(0)),
+		uintptr(unsafe.Pointer(&rect)),
+		DT_WORDBREAK|DT_CALCRECT,
+	)
+	return rect.Bottom - rect.Top
+}
+
+// fitPopupToText grows the popup (up to popupMaxHeightPercent of the screen) so
+// long results are not cut off; whatever still overflows scrolls with the wheel.
+func fitPopupToText(hwnd syscall.Handle) {
+	popupTextHeight = measureText(hwnd)
+	popupScroll = 0
+
+	cfg := currentPopupConfig()
+	screenWidth, _, _ := procGetSystemMetrics.Call(SM_CXSCREEN)
+	screenHeight, _, _ := procGetSystemMetrics.Call(SM_CYSCREEN)
+	_, _, _, base := popupRect(cfg, int(screenWidth), int(screenHeight))
+
+	height := fitHeight(base, int(popupTextHeight), int(screenHeight))
+	if int32(height) == popupHeight {
+		return
+	}
+	cfg.Height = height
+	x, y, width, height := popupRect(cfg, int(screenWidth), int(screenHeight))
+	popupWidth, popupHeight = int32(width), int32(height)
+
+	logutil.Debug("Popup: Resizing to %dx%d for %dpx of text", width, height, popupTextHeight)
+	procSetWindowPos.Call(
+		uintptr(hwnd),
+		0,
+		uintptr(x), uintptr(y), uintptr(width), uintptr(height),
+		SWP_NOACTIVATE|SWP_NOZORDER,
+	)
+}
+
+// createAndShowPopup creates and shows a single popup window placed per cfg
+func createAndShowPopup(text string, cfg PopupConfig) error {
+	logutil.Debug("Popup: Creating popup window")
+	popupText = displayText(text, MaxChars())
+	popupFullText = text
 
 	className, _ := syscall.UTF16PtrFromString("OCRNotificationClass")
 	windowName, _ := syscall.UTF16PtrFromString("OCR Result")
 
 	// Get screen dimensions
+	screenWidth, _, _ := procGetSystemMetrics.Call(SM_CXSCREEN)
 	screenHeight, _, _ := procGetSystemMetrics.Call(SM_CYSCREEN)
 
-	// Position in lower-left corner (400x100 pixels as requested)
-	x := int32(20)
-	y := int32(screenHeight) - 120 // 100px height + 20px margin
-	width := int32(400)
-	height := int32(100)
+	popupDark = useDarkTheme(cfg.Theme, systemUsesDarkTheme)
+	exStyle := uintptr(WS_EX_NOACTIVATE | WS_EX_TOOLWINDOW | WS_EX_CLIENTEDGE)
+	if popupDark {
+		// The 3D client edge is drawn in light system colors; paintBackground frames instead
+		exStyle = WS_EX_NOACTIVATE | WS_EX_TOOLWINDOW
+	}
+
+	// Default: lower-left corner, 400x100 (POPUP_POSITION / POPUP_WIDTH / POPUP_HEIGHT)
+	px, py, pw, ph := popupRect(cfg, int(screenWidth), int(screenHeight))
+	x, y, width, height := int32(px), int32(py), int32(pw), int32(ph)
+	popupWidth, popupHeight = width, height
 
-	log.Printf("Popup: Creating window at position (%d, %d) with size %dx%d", x, y, width, height)
+	logutil.Debug("Popup: Creating window at position (%d, %d) with size %dx%d", x, y, width, height)
 
 	// Create window (no-activate toolwindow so clicks won't steal focus; we'll close on click)
 	hwnd, _, _ := procCreateWindowEx.Call(
-		WS_EX_NOACTIVATE|WS_EX_TOOLWINDOW|WS_EX_CLIENTEDGE,
+		exStyle,
 		uintptr(unsafe.Pointer(className)),
 		uintptr(unsafe.Pointer(windowName)),
 		WS_POPUP|WS_VISIBLE,
@@ -362,13 +558,16 @@ func createAndShowPopup(text string) error {
 		0,
 	)
 
-	log.Printf("Popup: CreateWindowEx returned hwnd: %d", hwnd)
+	logutil.Debug("Popup: CreateWindowEx returned hwnd: %d", hwnd)
 
 	if hwnd == 0 {
 		log.Printf("Popup: Failed to create popup window")
 		return nil // Don't return error to avoid breaking OCR
 	}
-	log.Printf("Popup: Window created successfully, hwnd: %d", hwnd)
+	logutil.Debug("Popup: Window created successfully, hwnd: %d", hwnd)
+
+	// Grow to fit the text before the window is painted
+	fitPopupToText(syscall.Handle(hwnd))
 
 	// Set window to be topmost but not steal focus
 	procSetWindowPos.Call(
@@ -392,11 +591,13 @@ func createAndShowPopup(text string) error {
 	if inCountdownMode {
 		// Countdown mode - start 1-second timer immediately to ensure reliable ticking
 		timerResult, _, _ := procSetTimer.Call(hwnd, TIMER_COUNTDOWN, 1000, 0)
-		log.Printf("Popup: Countdown mode, 1s timer started, result: %d", timerResult)
-	} else {
+		logutil.Debug("Popup: Countdown mode, 1s timer started, result: %d", timerResult)
+	} else if d := closeDelay(DefaultResultDuration); d > 0 {
 		// Normal mode - set 3-second close timer
-		timerResult, _, _ := procSetTimer.Call(hwnd, TIMER_CLOSE, 3000, 0)
-		log.Printf("Popup: Set 3-second close timer, result: %d", timerResult)
+		timerResult, _, _ := procSetTimer.Call(hwnd, TIMER_CLOSE, uintptr(d.Milliseconds()), 0)
+		logutil.Debug("Popup: Set %v close timer, result: %d", d, timerResult)
+	} else {
+		logutil.Debug("Popup: Sticky popup, showing until clicked")
 	}
 
 	// Message loop: run until WM_QUIT or WM_EXIT_LOOP
@@ -409,18 +610,18 @@ func createAndShowPopup(text string) error {
 			0,
 		)
 		if ret == 0 { // WM_QUIT
-			log.Printf("Popup: Message loop received WM_QUIT, exiting")
+			logutil.Debug("Popup: Message loop received WM_QUIT, exiting")
 			break
 		}
 		if msg.Message == WM_EXIT_LOOP {
-			log.Printf("Popup: Message loop received WM_EXIT_LOOP, exiting")
+			logutil.Debug("Popup: Message loop received WM_EXIT_LOOP, exiting")
 			break
 		}
 		procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
 		procDispatchMessage.Call(uintptr(unsafe.Pointer(&msg)))
 	}
 
-	log.Printf("Popup: Message loop exited, flushing remaining messages")
+	logutil.Debug("Popup: Message loop exited, flushing remaining messages")
 
 	// Flush any remaining messages from the queue to prevent them from affecting next popup
 	procPeekMessage := user32.NewProc("PeekMessageW")
@@ -436,10 +637,10 @@ func createAndShowPopup(text string) error {
 		if ret == 0 {
 			break // No more messages
 		}
-		log.Printf("Popup: Flushed message 0x%x from queue", flushMsg.Message)
+		logutil.Debug("Popup: Flushed message 0x%x from queue", flushMsg.Message)
 	}
 
-	log.Printf("Popup: Message queue flushed")
+	logutil.Debug("Popup: Message queue flushed")
 	return nil
 }
 
@@ -450,7 +651,7 @@ func StartCountdownPopup(timeoutSeconds int) error {
 	currentPopupMutex.Lock()
 	// Close any existing popup
 	if currentPopupHwnd != 0 {
-		log.Printf("Popup: Closing existing popup (hwnd=%d) before starting countdown", currentPopupHwnd)
+		logutil.Debug("Popup: Closing existing popup (hwnd=%d) before starting countdown", currentPopupHwnd)
 		procDestroyWindow.Call(uintptr(currentPopupHwnd))
 		currentPopupHwnd = 0
 	}
@@ -459,7 +660,7 @@ func StartCountdownPopup(timeoutSeconds int) error {
 	initialText := fmt.Sprintf("OCR in progress...\n%d seconds remaining", timeoutSeconds)
 	currentPopupMutex.Unlock()
 
-	log.Printf("Popup: Starting countdown popup with %d seconds", timeoutSeconds)
+	logutil.Debug("Popup: Starting countdown popup with %d seconds", timeoutSeconds)
 
 	// Queue the popup creation
 	select {
@@ -473,7 +674,7 @@ func StartCountdownPopup(timeoutSeconds int) error {
 			if hwnd != 0 {
 				// Set 1-second countdown timer
 				procSetTimer.Call(uintptr(hwnd), TIMER_COUNTDOWN, 1000, 0)
-				log.Printf("Popup: Countdown timer started")
+				logutil.Debug("Popup: Countdown timer started")
 			}
 		}()
 		return nil
@@ -487,7 +688,8 @@ func StartCountdownPopup(timeoutSeconds int) error {
 func UpdatePopupText(text string) error {
 	currentPopupMutex.Lock()
 	hwnd := currentPopupHwnd
-	popupText = text
+	popupText = displayText(text, MaxChars())
+	popupFullText = text
 	currentPopupMutex.Unlock()
 
 	if hwnd == 0 {
@@ -495,12 +697,25 @@ func UpdatePopupText(text string) error {
 		return nil
 	}
 
-	log.Printf("Popup: Updating popup text to %d characters", len(text))
+	logutil.Debug("Popup: Updating popup text to %d characters", len(text))
 	// Send custom message to update text
 	procPostMessage.Call(uintptr(hwnd), WM_UPDATE_TEXT, 0, 0)
 	return nil
 }
 
+// WaitPopupClosed blocks until no popup is open.
+func WaitPopupClosed() {
+	for {
+		currentPopupMutex.Lock()
+		hwnd := currentPopupHwnd
+		currentPopupMutex.Unlock()
+		if hwnd == 0 {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
 // ClosePopup closes the current popup if any
 func ClosePopup() error {
 	currentPopupMutex.Lock()
@@ -511,7 +726,25 @@ func ClosePopup() error {
 		return nil
 	}
 
-	log.Printf("Popup: Closing popup")
+	logutil.Debug("Popup: Closing popup")
 	procDestroyWindow.Call(uintptr(hwnd))
 	return nil
 }
+
+// MessageBeep sound types
+const (
+	mbIconAsterisk = 0x00000040
+	mbIconHand     = 0x00000010
+)
+
+// PlayCompletionSound plays the system "asterisk" sound after a successful
+// OCR and the "critical stop" sound after a failed one.
+func PlayCompletionSound(success bool) {
+	sound := uintptr(mbIconAsterisk)
+	if !success {
+		sound = mbIconHand
+	}
+	if ret, _, err := procMessageBeep.Call(sound); ret == 0 {
+		log.Printf("MessageBeep failed: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package ocr
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// minContinuationOverlap is the shortest repeated run (in runes) treated as
+// overlap between the previous result and a continuation.
+const minContinuationOverlap = 4
+
+// continuation tracks the tail of the previous result for OCR_CONTINUATION.
+var continuation struct {
+	sync.Mutex
+	tailChars int
+	previous  string
+}
+
+// SetContinuation enables continuation OCR: the last tailChars characters of
+// the previous result are passed to the model as context and new results are
+// joined onto it without repeating overlapping text. tailChars <= 0 disables
+// continuation and forgets the previous result.
+func SetContinuation(tailChars int) {
+	continuation.Lock()
+	defer continuation.Unlock()
+	if tailChars < 0 {
+		tailChars = 0
+	}
+	continuation.tailChars = tailChars
+	continuation.previous = ""
+}
+
+func continuationTail() string {
+	continuation.Lock()
+	defer continuation.Unlock()
+	if continuation.tailChars == 0 {
+		return ""
+	}
+	return lastRunes(continuation.previous, continuation.tailChars)
+}
+
+// recordContinuation joins text onto the previous result and remembers the
+// combined tail. It returns the text to deliver. If text only repeats the
+// previous result (e.g. the same area was captured twice) it is returned
+// unchanged.
+func recordContinuation(text string) string {
+	continuation.Lock()
+	defer continuation.Unlock()
+	if continuation.tailChars == 0 {
+		return text
+	}
+	joined := JoinContinuation(continuation.previous, text)
+	if joined == "" {
+		return text
+	}
+	continuation.previous = lastRunes(continuation.previous+joined, continuation.tailChars)
+	return joined
+}
+
+// JoinContinuation returns the part of next that continues previous: text
+// that merely repeats the end of previous is dropped, and a separator is
+// added so the result can be pasted directly after previous. A space is used
+// when previous stops mid-sentence, a newline otherwise.
+func JoinContinuation(previous, next string) string {
+	if previous == "" {
+		return next
+	}
+
+	prev := []rune(previous)
+	nxt := []rune(next)
+	for k := minInt(len(prev), len(nxt)); k >= minContinuationOverlap; k-- {
+		if string(prev[len(prev)-k:]) == string(nxt[:k]) {
+			nxt = nxt[k:]
+			break
+		}
+	}
+
+	rest := string(nxt)
+	if strings.TrimSpace(rest) == "" {
+		return ""
+	}
+	last := prev[len(prev)-1]
+	if unicode.IsSpace(last) || unicode.IsSpace(nxt[0]) {
+		return rest
+	}
+	if strings.ContainsRune(".!?:;", last) {
+		return "\n" + rest
+	}
+	return " " + rest
+}
+
+func lastRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[len(r)-n:])
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
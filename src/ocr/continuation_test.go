@@ -0,0 +1,56 @@
+package ocr
+
+import "testing"
+
+func TestJoinContinuation(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		next     string
+		want     string
+	}{
+		{name: "no previous", previous: "", next: "Hello", want: "Hello"},
+		{name: "mid sentence joins with space", previous: "The quick brown", next: "fox jumps", want: " fox jumps"},
+		{name: "sentence end joins with newline", previous: "First page.", next: "Second page", want: "\nSecond page"},
+		{name: "overlap removed", previous: "the quick brown fox", next: "brown fox jumps over", want: " jumps over"},
+		{name: "full repeat yields nothing", previous: "line one\nline two", next: "line two", want: ""},
+		{name: "short overlap kept", previous: "abc", next: "abc def", want: " abc def"},
+		{name: "existing whitespace kept", previous: "line one\n", next: "line two", want: "line two"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JoinContinuation(tt.previous, tt.next); got != tt.want {
+				t.Fatalf("JoinContinuation(%q, %q) = %q, want %q", tt.previous, tt.next, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordContinuationTracksTail(t *testing.T) {
+	SetContinuation(10)
+	defer SetContinuation(0)
+
+	if got := recordContinuation("page one ends here"); got != "page one ends here" {
+		t.Fatalf("first result = %q", got)
+	}
+	if tail := continuationTail(); tail != " ends here" {
+		t.Fatalf("tail = %q, want last 10 characters", tail)
+	}
+	if got := recordContinuation("ends here and continues"); got != " and continues" {
+		t.Fatalf("continuation = %q", got)
+	}
+	if got := recordContinuation("continues"); got != "continues" {
+		t.Fatalf("repeated capture = %q, want original text", got)
+	}
+}
+
+func TestRecordContinuationDisabled(t *testing.T) {
+	SetContinuation(0)
+	if got := recordContinuation("text"); got != "text" {
+		t.Fatalf("disabled continuation changed text: %q", got)
+	}
+	if tail := continuationTail(); tail != "" {
+		t.Fatalf("disabled continuation tail = %q", tail)
+	}
+}
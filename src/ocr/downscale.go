@@ -0,0 +1,86 @@
+package ocr
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"log"
+	"sync/atomic"
+
+	"golang.org/x/image/draw"
+)
+
+// DefaultMaxImageEdge is the longest image edge, in pixels, sent to the LLM
+// when OCR_MAX_IMAGE_EDGE is not configured.
+const DefaultMaxImageEdge = 2000
+
+var maxImageEdge atomic.Int64
+
+func init() {
+	maxImageEdge.Store(DefaultMaxImageEdge)
+}
+
+// SetMaxImageEdge sets the longest edge, in pixels, of images sent to the
+// LLM. Larger images are shrunk to fit; n <= 0 disables downscaling.
+func SetMaxImageEdge(n int) {
+	maxImageEdge.Store(int64(n))
+}
+
+// downscaleImage shrinks a PNG whose longest edge exceeds the configured
+// maximum, preserving aspect ratio. Images are never enlarged. If the image
+// cannot be decoded it is returned unchanged.
+func downscaleImage(data []byte) []byte {
+	maxEdge := int(maxImageEdge.Load())
+	if maxEdge <= 0 {
+		return data
+	}
+
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("OCR: Skipping downscale, could not decode image: %v", err)
+		return data
+	}
+
+	resized, ok := fitWithin(src, maxEdge)
+	if !ok {
+		return data
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, resized); err != nil {
+		log.Printf("OCR: Skipping downscale, could not encode image: %v", err)
+		return data
+	}
+
+	b, rb := src.Bounds(), resized.Bounds()
+	log.Printf("OCR: Downscaled image from %dx%d to %dx%d (max edge %d, %d -> %d bytes)",
+		b.Dx(), b.Dy(), rb.Dx(), rb.Dy(), maxEdge, len(data), buf.Len())
+	return buf.Bytes()
+}
+
+// fitWithin returns src resized with a Catmull-Rom filter so that its
+// longest edge equals maxEdge. It reports false if src already fits.
+func fitWithin(src image.Image, maxEdge int) (image.Image, bool) {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	longest := w
+	if h > longest {
+		longest = h
+	}
+	if longest <= maxEdge {
+		return src, false
+	}
+
+	newW := w * maxEdge / longest
+	newH := h * maxEdge / longest
+	if newW < 1 {
+		newW = 1
+	}
+	if newH < 1 {
+		newH = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, newW, newH))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst, true
+}
@@ -0,0 +1,67 @@
+package ocr
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func encodeTestPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatalf("png.Encode failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decodedSize(t *testing.T, data []byte) (int, int) {
+	t.Helper()
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.DecodeConfig failed: %v", err)
+	}
+	return cfg.Width, cfg.Height
+}
+
+func TestDownscaleImage(t *testing.T) {
+	defer SetMaxImageEdge(DefaultMaxImageEdge)
+	SetMaxImageEdge(100)
+
+	tests := []struct {
+		name         string
+		w, h         int
+		wantW, wantH int
+	}{
+		{name: "wide image shrinks", w: 400, h: 200, wantW: 100, wantH: 50},
+		{name: "tall image shrinks", w: 150, h: 300, wantW: 50, wantH: 100},
+		{name: "small image unchanged", w: 80, h: 40, wantW: 80, wantH: 40},
+		{name: "exact edge unchanged", w: 100, h: 100, wantW: 100, wantH: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := downscaleImage(encodeTestPNG(t, tt.w, tt.h))
+			if w, h := decodedSize(t, out); w != tt.wantW || h != tt.wantH {
+				t.Fatalf("downscaled to %dx%d, want %dx%d", w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestDownscaleImageDisabledAndInvalid(t *testing.T) {
+	defer SetMaxImageEdge(DefaultMaxImageEdge)
+
+	SetMaxImageEdge(0)
+	in := encodeTestPNG(t, 400, 200)
+	if out := downscaleImage(in); !bytes.Equal(out, in) {
+		t.Fatal("expected image unchanged when downscaling is disabled")
+	}
+
+	SetMaxImageEdge(100)
+	garbage := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	if out := downscaleImage(garbage); !bytes.Equal(out, garbage) {
+		t.Fatal("expected undecodable data to pass through unchanged")
+	}
+}
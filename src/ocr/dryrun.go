@@ -0,0 +1,34 @@
+package ocr
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"log"
+	"sync/atomic"
+)
+
+var dryRun atomic.Bool
+
+// SetDryRun makes Recognize and RecognizeImage stop after the capture and PNG
+// encode: no LLM request is made and a placeholder describing the image is
+// returned instead of its text (DRY_RUN / --dry-run).
+func SetDryRun(enabled bool) {
+	dryRun.Store(enabled)
+}
+
+// DryRun reports whether dry-run mode is on.
+func DryRun() bool {
+	return dryRun.Load()
+}
+
+// dryRunText is the placeholder result for the PNG in data: its size in
+// pixels and bytes, as it would have been sent to the LLM.
+func dryRunText(data []byte) string {
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("OCR: Dry run, could not decode image: %v", err)
+	}
+	log.Printf("OCR: Dry run, skipping LLM request for %dx%d image (%d bytes)", cfg.Width, cfg.Height, len(data))
+	return fmt.Sprintf("[dry run] captured %dx%d, %d bytes PNG", cfg.Width, cfg.Height, len(data))
+}
@@ -0,0 +1,21 @@
+package ocr
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecognizeImageDryRun(t *testing.T) {
+	defer SetDryRun(false)
+	SetDryRun(true)
+
+	data := encodeTestPNG(t, 64, 32)
+	text, err := RecognizeImage(data)
+	if err != nil {
+		t.Fatalf("RecognizeImage returned error: %v", err)
+	}
+	want := fmt.Sprintf("[dry run] captured 64x32, %d bytes PNG", len(data))
+	if text != want {
+		t.Fatalf("got %q, want %q", text, want)
+	}
+}
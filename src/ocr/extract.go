@@ -0,0 +1,122 @@
+package ocr
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Entity kinds accepted by OCR_EXTRACT. ExtractNone turns extraction off.
+const (
+	ExtractNone   = ""
+	ExtractURLs   = "urls"
+	ExtractEmails = "emails"
+	ExtractAll    = "all"
+)
+
+// Entities holds the URLs and email addresses found in an OCR result, each
+// listed once in order of appearance.
+type Entities struct {
+	URLs   []string `json:"urls,omitempty"`
+	Emails []string `json:"emails,omitempty"`
+}
+
+var (
+	urlPattern   = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'` + "`" + `]+`)
+	emailPattern = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}\b`)
+)
+
+var extraction atomic.Value
+
+// SetExtraction sets which entities Extraction reports for OCR_EXTRACT:
+// ExtractURLs, ExtractEmails or ExtractAll. Anything else turns extraction
+// off.
+func SetExtraction(kind string) {
+	extraction.Store(NormalizeExtraction(kind))
+}
+
+// Extraction returns the kind set by SetExtraction, or ExtractNone.
+func Extraction() string {
+	kind, _ := extraction.Load().(string)
+	return kind
+}
+
+// NormalizeExtraction lower-cases kind and maps unknown values to
+// ExtractNone.
+func NormalizeExtraction(kind string) string {
+	switch kind = strings.ToLower(strings.TrimSpace(kind)); kind {
+	case ExtractURLs, ExtractEmails, ExtractAll:
+		return kind
+	}
+	return ExtractNone
+}
+
+// Extract finds the URLs and/or email addresses in text, as selected by
+// kind. URLs starting with "www." get "https://" so they can be opened;
+// punctuation that ends a sentence or closes a bracket is not part of a URL.
+func Extract(text, kind string) Entities {
+	var e Entities
+	kind = NormalizeExtraction(kind)
+	if kind == ExtractURLs || kind == ExtractAll {
+		e.URLs = uniqueMatches(urlPattern, text, cleanURL)
+	}
+	if kind == ExtractEmails || kind == ExtractAll {
+		e.Emails = uniqueMatches(emailPattern, text, nil)
+	}
+	return e
+}
+
+// Empty reports whether nothing was found.
+func (e Entities) Empty() bool {
+	return len(e.URLs) == 0 && len(e.Emails) == 0
+}
+
+// OnlyURL returns the URL when exactly one was found, the case in which
+// it is offered to be opened, and "" otherwise.
+func (e Entities) OnlyURL() string {
+	if len(e.URLs) != 1 {
+		return ""
+	}
+	return e.URLs[0]
+}
+
+// uniqueMatches returns the matches of re in text, passed through clean if
+// it is not nil, without empty results or repeats.
+func uniqueMatches(re *regexp.Regexp, text string, clean func(string) string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, m := range re.FindAllString(text, -1) {
+		if clean != nil {
+			m = clean(m)
+		}
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		out = append(out, m)
+	}
+	return out
+}
+
+// cleanURL drops trailing punctuation the URL pattern swallowed, such as a
+// full stop or the ")" of "(see https://example.com)", and adds the scheme
+// to "www." addresses. A ")" is kept when it closes a "(" in the URL, as in
+// Wikipedia links.
+func cleanURL(u string) string {
+	for u != "" {
+		last := u[len(u)-1]
+		if strings.IndexByte(".,;:!?'\"]}>", last) >= 0 ||
+			last == ')' && strings.Count(u, "(") < strings.Count(u, ")") {
+			u = u[:len(u)-1]
+			continue
+		}
+		break
+	}
+	if strings.HasSuffix(strings.ToLower(u), "://") || strings.EqualFold(u, "www") {
+		return ""
+	}
+	if strings.HasPrefix(strings.ToLower(u), "www.") {
+		u = "https://" + u
+	}
+	return u
+}
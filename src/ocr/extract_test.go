@@ -0,0 +1,63 @@
+package ocr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	text := "Docs: https://example.com/docs?page=2. See (www.example.org) and\n" +
+		"https://en.wikipedia.org/wiki/Go_(programming_language), again https://example.com/docs?page=2!\n" +
+		"Mail bob.smith+ocr@mail.example.co.uk or Support@Example.com; not an@address or https:// alone."
+
+	got := Extract(text, "ALL")
+	want := Entities{
+		URLs: []string{
+			"https://example.com/docs?page=2",
+			"https://www.example.org",
+			"https://en.wikipedia.org/wiki/Go_(programming_language)",
+		},
+		Emails: []string{"bob.smith+ocr@mail.example.co.uk", "Support@Example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Extract() = %#v\nwant %#v", got, want)
+	}
+
+	if got := Extract(text, ExtractURLs); got.Emails != nil || len(got.URLs) != 3 {
+		t.Fatalf("Extract(urls) = %#v", got)
+	}
+	if got := Extract(text, ExtractEmails); got.URLs != nil || len(got.Emails) != 2 {
+		t.Fatalf("Extract(emails) = %#v", got)
+	}
+	if got := Extract(text, "phone numbers"); !got.Empty() {
+		t.Fatalf("Extract with an unknown kind = %#v, want nothing", got)
+	}
+}
+
+func TestEntitiesOnlyURL(t *testing.T) {
+	if got := Extract("open https://example.com/a.", ExtractAll).OnlyURL(); got != "https://example.com/a" {
+		t.Fatalf("OnlyURL() = %q", got)
+	}
+	if got := Extract("https://a.example and https://b.example", ExtractAll).OnlyURL(); got != "" {
+		t.Fatalf("OnlyURL() with two URLs = %q, want none", got)
+	}
+	if got := Extract("https://example.com", ExtractEmails).OnlyURL(); got != "" {
+		t.Fatalf("OnlyURL() with URLs not extracted = %q, want none", got)
+	}
+}
+
+func TestSetExtraction(t *testing.T) {
+	defer SetExtraction(ExtractNone)
+
+	if Extraction() != ExtractNone {
+		t.Fatalf("Extraction() = %q before SetExtraction", Extraction())
+	}
+	SetExtraction(" Emails ")
+	if Extraction() != ExtractEmails {
+		t.Fatalf("Extraction() = %q, want emails", Extraction())
+	}
+	SetExtraction("everything")
+	if Extraction() != ExtractNone {
+		t.Fatalf("Extraction() = %q for an unknown kind, want none", Extraction())
+	}
+}
@@ -0,0 +1,91 @@
+package ocr
+
+import (
+	"strings"
+	"sync"
+)
+
+// Line ending modes accepted by OCR_LINE_ENDINGS.
+const (
+	LineEndingsKeep = "keep"
+	LineEndingsLF   = "lf"
+	LineEndingsCRLF = "crlf"
+)
+
+// normalization holds the OCR_TRIM and OCR_LINE_ENDINGS settings applied by
+// Normalize.
+var normalization struct {
+	sync.Mutex
+	trim        bool
+	lineEndings string
+}
+
+// SetNormalization configures Normalize: trim removes trailing whitespace
+// from every line, and lineEndings converts line breaks to "lf" or "crlf".
+// Unknown lineEndings values keep the model's line breaks.
+func SetNormalization(trim bool, lineEndings string) {
+	normalization.Lock()
+	defer normalization.Unlock()
+	normalization.trim = trim
+	normalization.lineEndings = NormalizeLineEndings(lineEndings)
+}
+
+// NormalizeLineEndings lower-cases mode and maps unknown values to
+// LineEndingsKeep.
+func NormalizeLineEndings(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case LineEndingsLF, LineEndingsCRLF:
+		return mode
+	}
+	return LineEndingsKeep
+}
+
+// Normalize applies the configured trailing-whitespace and line-ending
+// normalization to an OCR result. With the defaults text is returned
+// unchanged.
+func Normalize(text string) string {
+	normalization.Lock()
+	trim, lineEndings := normalization.trim, normalization.lineEndings
+	normalization.Unlock()
+	return normalizeText(text, trim, lineEndings)
+}
+
+func normalizeText(text string, trim bool, lineEndings string) string {
+	if !trim && (lineEndings == "" || lineEndings == LineEndingsKeep) {
+		return text
+	}
+
+	// Split on \n, remembering which lines ended in \r\n so "keep" can
+	// restore them; a lone \r is not treated as a line break.
+	lines := strings.Split(text, "\n")
+	crlf := make([]bool, len(lines))
+	for i := range lines[:len(lines)-1] {
+		if strings.HasSuffix(lines[i], "\r") {
+			lines[i] = strings.TrimSuffix(lines[i], "\r")
+			crlf[i] = true
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	for i, line := range lines {
+		if trim {
+			line = strings.TrimRight(line, " \t\r\f\v")
+		}
+		b.WriteString(line)
+		if i == len(lines)-1 {
+			break
+		}
+		switch {
+		case lineEndings == LineEndingsCRLF:
+			b.WriteString("\r\n")
+		case lineEndings == LineEndingsLF:
+			b.WriteString("\n")
+		case crlf[i]:
+			b.WriteString("\r\n")
+		default:
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package ocr
+
+import "testing"
+
+func TestNormalizeText(t *testing.T) {
+	const mixed = "func main() {  \r\n\tfmt.Println(\"hi\")\t\n}\r\n"
+
+	tests := []struct {
+		name        string
+		text        string
+		trim        bool
+		lineEndings string
+		want        string
+	}{
+		{name: "defaults keep text", text: mixed, lineEndings: LineEndingsKeep, want: mixed},
+		{name: "trim keeps line endings", text: mixed, trim: true, lineEndings: LineEndingsKeep, want: "func main() {\r\n\tfmt.Println(\"hi\")\n}\r\n"},
+		{name: "lf", text: mixed, lineEndings: LineEndingsLF, want: "func main() {  \n\tfmt.Println(\"hi\")\t\n}\n"},
+		{name: "crlf", text: mixed, lineEndings: LineEndingsCRLF, want: "func main() {  \r\n\tfmt.Println(\"hi\")\t\r\n}\r\n"},
+		{name: "trim and lf", text: mixed, trim: true, lineEndings: LineEndingsLF, want: "func main() {\n\tfmt.Println(\"hi\")\n}\n"},
+		{name: "trim last line", text: "a \nb  ", trim: true, lineEndings: LineEndingsKeep, want: "a\nb"},
+		{name: "leading whitespace kept", text: "  indented  ", trim: true, lineEndings: LineEndingsKeep, want: "  indented"},
+		{name: "lone cr is not a line break", text: "a\rb\n", lineEndings: LineEndingsCRLF, want: "a\rb\r\n"},
+		{name: "empty", text: "", trim: true, lineEndings: LineEndingsCRLF, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeText(tt.text, tt.trim, tt.lineEndings); got != tt.want {
+				t.Fatalf("normalizeText(%q, %v, %q) = %q, want %q", tt.text, tt.trim, tt.lineEndings, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetNormalization(t *testing.T) {
+	defer SetNormalization(false, LineEndingsKeep)
+
+	SetNormalization(true, " CRLF ")
+	if got := Normalize("a \nb"); got != "a\r\nb" {
+		t.Fatalf("Normalize = %q, want %q", got, "a\r\nb")
+	}
+
+	SetNormalization(false, "unix")
+	if got := Normalize("a \r\nb"); got != "a \r\nb" {
+		t.Fatalf("unknown OCR_LINE_ENDINGS should keep text, got %q", got)
+	}
+}
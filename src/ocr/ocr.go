@@ -1,6 +1,15 @@
+// Package ocr captures screen regions and reads their text with the
+// configured vision model.
+//
+// RecognizeContext and RecognizeImageContext are the stable API for programs
+// embedding the OCR: they honour cancellation and report ErrNoTextFound and
+// ErrInvalidRegion, matched with errors.Is. Configure the model with llm.Init
+// first.
 package ocr
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -10,18 +19,70 @@ import (
 	"screen-ocr-llm/src/screenshot"
 )
 
+// Errors returned by RecognizeContext and RecognizeImageContext.
+var (
+	// ErrNoTextFound means the model found no text in the image, either by
+	// saying so or by returning an empty response. It is llm.ErrNoTextFound.
+	ErrNoTextFound = llm.ErrNoTextFound
+	// ErrInvalidRegion means the region to capture has no area.
+	ErrInvalidRegion = errors.New("invalid region")
+)
+
+// CaptureError is returned by Recognize when the screen region could not be
+// captured, as opposed to a failed OCR request. It wraps the capture error,
+// e.g. screenshot.ErrBlankCapture.
+type CaptureError struct {
+	Err error
+}
+
+func (e *CaptureError) Error() string { return e.Err.Error() }
+
+func (e *CaptureError) Unwrap() error { return e.Err }
+
 func Init() {
 	// Initialize OCR package if needed
 }
 
 // Recognize performs OCR on a screen region using OpenRouter vision models
 func Recognize(region screenshot.Region) (string, error) {
+	return RecognizeContext(context.Background(), region)
+}
+
+// RecognizeWithContext is RecognizeContext.
+//
+// Deprecated: use RecognizeContext.
+func RecognizeWithContext(ctx context.Context, region screenshot.Region) (string, error) {
+	return RecognizeContext(ctx, region)
+}
+
+// RecognizeContext captures region and returns its text. The OCR request,
+// and the TRANSLATE_TO request if any, are bound to ctx: when ctx is
+// cancelled or its deadline passes, the in-flight API call is aborted and an
+// error wrapping ctx.Err() is returned. A region without area yields
+// ErrInvalidRegion, a failed capture a *CaptureError and an image without
+// text ErrNoTextFound. In dry-run mode the region is captured and encoded but
+// no request is made (see SetDryRun).
+func RecognizeContext(ctx context.Context, region screenshot.Region) (string, error) {
+	if region.Width <= 0 || region.Height <= 0 {
+		return "", fmt.Errorf("%w: %dx%d", ErrInvalidRegion, region.Width, region.Height)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	log.Printf("DEBUG: Capturing region: X=%d Y=%d Width=%d Height=%d", region.X, region.Y, region.Width, region.Height)
 
 	// Capture the specified region
 	imageData, err := screenshot.CaptureRegion(region)
 	if err != nil {
-		return "", err
+		if errors.Is(err, screenshot.ErrBlankCapture) {
+			log.Printf("WARNING: Blank capture for region %dx%d at (%d,%d); content may be protected", region.Width, region.Height, region.X, region.Y)
+		}
+		return "", &CaptureError{Err: err}
+	}
+	saveScreenshot(imageData, region)
+	strips := tileImage(imageData)
+	if strips == nil {
+		imageData = downscaleImage(imageData)
 	}
 
 	// DEBUG: Save the captured image only if debug mode is enabled
@@ -34,11 +95,121 @@ func Recognize(region screenshot.Region) (string, error) {
 		}
 	}
 
-	// Send to OpenRouter vision model for OCR
-	return llm.QueryVision(imageData)
+	if DryRun() {
+		return dryRunText(imageData), nil
+	}
+
+	// Send to OpenRouter vision model for OCR, with the previous result's
+	// tail as context when continuation is enabled
+	var text string
+	if strips != nil {
+		text, _, _, err = recognizeStrips(strips, func(i int, strip []byte) (string, []string, llm.Usage, error) {
+			tail := ""
+			if i == 0 {
+				tail = continuationTail()
+			}
+			text, err := llm.QueryVisionContinuationWithContext(ctx, strip, tail)
+			return text, nil, llm.Usage{}, err
+		})
+	} else {
+		text, err = llm.QueryVisionContinuationWithContext(ctx, imageData, continuationTail())
+	}
+	if err != nil {
+		return "", err
+	}
+	text, _ = translateResult(ctx, recordContinuation(postProcess(text)), llm.Usage{})
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return text, nil
 }
 
-// RecognizeImage performs OCR on provided image data using OpenRouter vision models
+// RecognizeImage performs OCR on provided image data using OpenRouter vision models.
+// Images larger than the configured maximum edge are downscaled first, and
+// over-tall ones are read in strips when tiling is on (see SetTiling).
+// In dry-run mode the placeholder from SetDryRun is returned instead.
 func RecognizeImage(imageData []byte) (string, error) {
-	return llm.QueryVision(imageData)
+	return RecognizeImageContext(context.Background(), imageData)
+}
+
+// RecognizeImageContext is like RecognizeImage but the OCR and translation
+// requests are bound to ctx as in RecognizeContext. An image without text yields
+// ErrNoTextFound.
+func RecognizeImageContext(ctx context.Context, imageData []byte) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if DryRun() {
+		return dryRunText(downscaleImage(imageData)), nil
+	}
+	var text string
+	var err error
+	if strips := tileImage(imageData); strips != nil {
+		text, _, _, err = recognizeStrips(strips, func(_ int, strip []byte) (string, []string, llm.Usage, error) {
+			text, err := llm.QueryVisionWithContext(ctx, strip)
+			return text, nil, llm.Usage{}, err
+		})
+	} else {
+		text, err = llm.QueryVisionWithContext(ctx, downscaleImage(imageData))
+	}
+	if err != nil {
+		return "", err
+	}
+	text, _ = translateResult(ctx, postProcess(text), llm.Usage{})
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// RecognizeImageWithUsage is like RecognizeImage but also returns the token
+// usage reported by the LLM, including the TRANSLATE_TO request if any.
+func RecognizeImageWithUsage(imageData []byte) (string, llm.Usage, error) {
+	var text string
+	var usage llm.Usage
+	var err error
+	if strips := tileImage(imageData); strips != nil {
+		text, _, usage, err = recognizeStrips(strips, func(_ int, strip []byte) (string, []string, llm.Usage, error) {
+			text, usage, err := llm.QueryVisionWithUsage(strip)
+			return text, nil, usage, err
+		})
+	} else {
+		text, usage, err = llm.QueryVisionWithUsage(downscaleImage(imageData))
+	}
+	if err != nil {
+		return "", usage, err
+	}
+	text, usage = translateResult(context.Background(), postProcess(text), usage)
+	return text, usage, nil
+}
+
+// RecognizeImageWithUncertainty is like RecognizeImageWithUsage but also
+// returns the spans the model marked as uncertain when OCR_MARK_UNCERTAIN is
+// on (see llm.QueryVisionWithUncertainty). The spans are as the model
+// returned them, before post-processing and translation.
+func RecognizeImageWithUncertainty(imageData []byte) (string, []string, llm.Usage, error) {
+	var text string
+	var spans []string
+	var usage llm.Usage
+	var err error
+	if strips := tileImage(imageData); strips != nil {
+		text, spans, usage, err = recognizeStrips(strips, func(_ int, strip []byte) (string, []string, llm.Usage, error) {
+			return llm.QueryVisionWithUncertainty(strip)
+		})
+	} else {
+		text, spans, usage, err = llm.QueryVisionWithUncertainty(downscaleImage(imageData))
+	}
+	if err != nil {
+		return "", nil, usage, err
+	}
+	text, usage = translateResult(context.Background(), postProcess(text), usage)
+	return text, spans, usage, nil
+}
+
+// RecognizeImageStream is like RecognizeImage but passes the model's output
+// to onChunk as it arrives (see llm.QueryVisionStream). The streamed text is
+// not post-processed: OCR_REPLACEMENTS, OCR_TRIM/OCR_LINE_ENDINGS and
+// TRANSLATE_TO do not apply, and the image is never tiled.
+func RecognizeImageStream(imageData []byte, onChunk func(chunk string)) (string, error) {
+	return llm.QueryVisionStream(downscaleImage(imageData), onChunk)
 }
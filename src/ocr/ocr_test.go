@@ -1,7 +1,12 @@
 package ocr
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	"screen-ocr-llm/src/llm"
@@ -40,24 +45,93 @@ func TestRecognize(t *testing.T) {
 }
 
 func TestRecognizeImage(t *testing.T) {
-	// Get API key from environment variable
-	apiKey := os.Getenv("TEST_API_KEY")
-	if apiKey == "" {
-		apiKey = "mock_key_for_error_testing" // Safe mock for error testing
-	}
-
-	// Initialize LLM with test config
+	// Initialize LLM against the built-in mock backend (no network)
 	llm.Init(&llm.Config{
-		APIKey:    apiKey,
+		APIKey:    "mock_key_for_error_testing", // Safe mock for error testing
 		Model:     "test_model",
 		Providers: []string{}, // Empty for test
+		Transport: llm.MockTransport{},
 	})
 
-	// Test with image data (will fail due to invalid API key)
+	// Not a PNG, so it is sent without downscaling
 	testImageData := []byte{0xFF, 0xFF, 0xFF, 0xFF}
-	_, err := RecognizeImage(testImageData)
-	if err == nil {
-		t.Error("Expected error with invalid API key")
+	text, err := RecognizeImage(testImageData)
+	if err != nil {
+		t.Fatalf("RecognizeImage returned error: %v", err)
+	}
+	want := "Mock OCR result\nModel: test_model\nImage: 4 bytes"
+	if text != want {
+		t.Fatalf("RecognizeImage = %q, want %q", text, want)
+	}
+}
+
+func TestRecognizeContextInvalidRegion(t *testing.T) {
+	_, err := RecognizeContext(context.Background(), screenshot.Region{Width: 0, Height: 10})
+	if !errors.Is(err, ErrInvalidRegion) {
+		t.Fatalf("expected ErrInvalidRegion, got %v", err)
+	}
+}
+
+// initTransport points the LLM at respond instead of the network.
+func initTransport(t *testing.T, respond func(*http.Request) (*http.Response, error)) {
+	t.Helper()
+	if err := llm.Init(&llm.Config{APIKey: "key", Model: "test_model", MaxRetries: 1, Transport: llm.RoundTripFunc(respond)}); err != nil {
+		t.Fatalf("llm.Init failed: %v", err)
+	}
+}
+
+func chatReply(req *http.Request, content string) (*http.Response, error) {
+	body := `{"choices":[{"message":{"content":` + content + `}}]}`
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+}
+
+func TestRecognizeImageContextCancellation(t *testing.T) {
+	requests := 0
+	initTransport(t, func(req *http.Request) (*http.Response, error) {
+		requests++
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := RecognizeImageContext(ctx, []byte("png")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled before the request, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("expected no request with a cancelled context, got %d", requests)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go cancel()
+	if _, err := RecognizeImageContext(ctx, []byte("png")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the in-flight request to be cancelled, got %v", err)
+	}
+}
+
+func TestRecognizeImageContextErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []error
+	}{
+		{"no text found", `"NO_TEXT_FOUND"`, []error{ErrNoTextFound}},
+		{"empty response", `""`, []error{ErrNoTextFound}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTransport(t, func(req *http.Request) (*http.Response, error) { return chatReply(req, tt.content) })
+			_, err := RecognizeImageContext(context.Background(), []byte("png"))
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("expected %v, got %v", want, err)
+				}
+			}
+		})
+	}
+
+	initTransport(t, func(req *http.Request) (*http.Response, error) { return chatReply(req, `"Hello"`) })
+	if text, err := RecognizeImageContext(context.Background(), []byte("png")); err != nil || text != "Hello" {
+		t.Fatalf("RecognizeImageContext = %q, %v", text, err)
 	}
-	t.Logf("OCR with image data failed as expected: %v", err)
 }
@@ -0,0 +1,96 @@
+package ocr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// replacementSeparator splits a rule line into its pattern and replacement.
+const replacementSeparator = " => "
+
+// Replacement is one OCR_REPLACEMENTS rule: every match of Pattern is
+// replaced with Replace, which may refer to submatches as $1 or ${name}.
+type Replacement struct {
+	Pattern *regexp.Regexp
+	Replace string
+}
+
+var replacements atomic.Pointer[[]Replacement]
+
+// SetReplacements sets the rules applied to every OCR result. Nil or empty
+// rules disable replacement.
+func SetReplacements(rules []Replacement) {
+	replacements.Store(&rules)
+}
+
+// LoadReplacements reads an OCR_REPLACEMENTS rules file. Each non-empty line
+// that does not start with # is "pattern => replacement", where pattern is a
+// Go regular expression; rules are applied in file order. An empty path
+// yields no rules.
+func LoadReplacements(path string) ([]Replacement, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("OCR_REPLACEMENTS: %w", err)
+	}
+	defer f.Close()
+	rules, err := ParseReplacements(f)
+	if err != nil {
+		return nil, fmt.Errorf("OCR_REPLACEMENTS %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseReplacements parses rules in the LoadReplacements format. Errors name
+// the offending line.
+func ParseReplacements(r io.Reader) ([]Replacement, error) {
+	var rules []Replacement
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		pattern, replace, ok := strings.Cut(line, replacementSeparator)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"pattern%sreplacement\"", lineNo, replacementSeparator)
+		}
+		if pattern == "" {
+			return nil, fmt.Errorf("line %d: empty pattern", lineNo)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q: %w", lineNo, pattern, err)
+		}
+		rules = append(rules, Replacement{Pattern: re, Replace: replace})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ApplyReplacements applies rules to text in order, each to the output of the
+// previous one.
+func ApplyReplacements(text string, rules []Replacement) string {
+	for _, rule := range rules {
+		text = rule.Pattern.ReplaceAllString(text, rule.Replace)
+	}
+	return text
+}
+
+// postProcess applies the configured replacements and then Normalize to an
+// OCR result.
+func postProcess(text string) string {
+	if rules := replacements.Load(); rules != nil {
+		text = ApplyReplacements(text, *rules)
+	}
+	return Normalize(text)
+}
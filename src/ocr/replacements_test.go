@@ -0,0 +1,89 @@
+package ocr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseReplacements(t *testing.T) {
+	rules, err := ParseReplacements(strings.NewReader(
+		"# smart quotes\r\n" +
+			"[“”] => \"\n" +
+			"\n" +
+			"ﬁ => fi\n" +
+			`\b(\d+)l(\d*)\b => ${1}1$2` + "\n" +
+			"  trailing  =>  \n"))
+	if err != nil {
+		t.Fatalf("ParseReplacements returned error: %v", err)
+	}
+	if len(rules) != 4 {
+		t.Fatalf("Expected 4 rules, got %d", len(rules))
+	}
+
+	got := ApplyReplacements("“ﬁle” 20l5  trailing  ", rules)
+	want := "\"file\" 2015  "
+	if got != want {
+		t.Fatalf("ApplyReplacements = %q, want %q", got, want)
+	}
+}
+
+func TestParseReplacementsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  string
+	}{
+		{"missing separator", "# ok\nabc\n", "line 2: expected"},
+		{"empty pattern", " => x\n", "line 1: empty pattern"},
+		{"invalid regex", "ok => fine\n([a-z => x\n", "line 2: invalid pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseReplacements(strings.NewReader(tt.rules))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadReplacements(t *testing.T) {
+	rules, err := LoadReplacements("")
+	if err != nil || rules != nil {
+		t.Fatalf("Expected no rules for empty path, got %v, %v", rules, err)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := LoadReplacements(missing); err == nil || !strings.Contains(err.Error(), "OCR_REPLACEMENTS") {
+		t.Fatalf("Expected OCR_REPLACEMENTS error for missing file, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "rules.txt")
+	if err := os.WriteFile(path, []byte("(\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplacements(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("Expected error naming %s, got %v", path, err)
+	}
+}
+
+func TestPostProcess(t *testing.T) {
+	defer SetReplacements(nil)
+	defer SetNormalization(false, LineEndingsKeep)
+
+	if got := postProcess("keep  \r\n"); got != "keep  \r\n" {
+		t.Fatalf("postProcess without rules changed text: %q", got)
+	}
+
+	rules, err := ParseReplacements(strings.NewReader("— => - \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetReplacements(rules)
+	SetNormalization(true, LineEndingsLF)
+	if got := postProcess("a—\r\nb"); got != "a-\nb" {
+		t.Fatalf("postProcess = %q, want %q", got, "a-\nb")
+	}
+}
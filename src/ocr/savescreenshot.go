@@ -0,0 +1,50 @@
+package ocr
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"screen-ocr-llm/src/screenshot"
+)
+
+var screenshotDir atomic.Value
+
+func init() {
+	screenshotDir.Store("")
+}
+
+// SetScreenshotDir makes Recognize write every captured region to dir before
+// it is sent to the LLM. An empty dir disables saving.
+func SetScreenshotDir(dir string) {
+	screenshotDir.Store(dir)
+}
+
+// screenshotFilename names a saved capture after its time and region.
+func screenshotFilename(now time.Time, region screenshot.Region) string {
+	return fmt.Sprintf("capture_%s_x%d_y%d_%dx%d.png",
+		now.Format("20060102-150405.000"), region.X, region.Y, region.Width, region.Height)
+}
+
+// saveScreenshot writes data to the configured screenshot directory and
+// returns the file path. Failures are logged and never abort OCR.
+func saveScreenshot(data []byte, region screenshot.Region) string {
+	dir := screenshotDir.Load().(string)
+	if dir == "" {
+		return ""
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("OCR: Could not create screenshot directory %s: %v", dir, err)
+		return ""
+	}
+	path := filepath.Join(dir, screenshotFilename(time.Now(), region))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		log.Printf("OCR: Could not save screenshot: %v", err)
+		return ""
+	}
+	log.Printf("OCR: Saved captured region to %s (%d bytes)", path, len(data))
+	return path
+}
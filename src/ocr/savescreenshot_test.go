@@ -0,0 +1,51 @@
+package ocr
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"screen-ocr-llm/src/screenshot"
+)
+
+func TestScreenshotFilename(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 7, 89_000_000, time.UTC)
+	region := screenshot.Region{X: -1920, Y: 40, Width: 640, Height: 480}
+	want := "capture_20250304-050607.089_x-1920_y40_640x480.png"
+	if got := screenshotFilename(now, region); got != want {
+		t.Fatalf("screenshotFilename() = %q, want %q", got, want)
+	}
+}
+
+func TestSaveScreenshot(t *testing.T) {
+	t.Cleanup(func() { SetScreenshotDir("") })
+	region := screenshot.Region{X: 1, Y: 2, Width: 3, Height: 4}
+	data := []byte("png bytes")
+
+	if path := saveScreenshot(data, region); path != "" {
+		t.Fatalf("saveScreenshot() with no dir wrote %s", path)
+	}
+
+	dir := filepath.Join(t.TempDir(), "captures")
+	SetScreenshotDir(dir)
+	path := saveScreenshot(data, region)
+	if filepath.Dir(path) != dir {
+		t.Fatalf("saveScreenshot() = %q, want a file in %s", path, dir)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("saved file = %q, %v; want %q", got, err, data)
+	}
+
+	// A file in place of the directory is logged, not fatal
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	SetScreenshotDir(blocker)
+	if path := saveScreenshot(data, region); path != "" {
+		t.Fatalf("saveScreenshot() into a file = %q, want failure", path)
+	}
+}
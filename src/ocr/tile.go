@@ -0,0 +1,187 @@
+package ocr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"screen-ocr-llm/src/llm"
+)
+
+// Tiling splits images taller than tileHeight into horizontal strips of at
+// most tileHeight pixels. Neighbouring strips share tileOverlap pixels, a
+// few lines of text, so a line cut by one strip's edge is whole in the next;
+// stitchText removes the lines read twice.
+const (
+	tileHeight  = 1200
+	tileOverlap = 100
+	// maxOverlapLines is the most lines stitchText looks for in the overlap.
+	maxOverlapLines = 6
+	// minOverlapChars keeps short lines such as "}" or "-" that merely
+	// repeat from being taken for the overlap.
+	minOverlapChars = 8
+)
+
+var tiling atomic.Bool
+
+// SetTiling makes over-tall images (long scrolled captures) be read as
+// overlapping strips whose texts are stitched together, instead of being
+// downscaled into one image whose text may be too small or too long for a
+// single response (OCR_TILE).
+func SetTiling(enabled bool) {
+	tiling.Store(enabled)
+}
+
+// tileImage splits the PNG in data into overlapping strips when tiling is on
+// and the image is taller than tileHeight. It returns nil when the image is
+// to be sent whole.
+func tileImage(data []byte) [][]byte {
+	if !tiling.Load() {
+		return nil
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Height <= tileHeight {
+		return nil
+	}
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("OCR: Skipping tiling, could not decode image: %v", err)
+		return nil
+	}
+	sub, ok := src.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil
+	}
+
+	rects := stripRects(src.Bounds(), tileHeight, tileOverlap)
+	strips := make([][]byte, 0, len(rects))
+	for _, r := range rects {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, sub.SubImage(r)); err != nil {
+			log.Printf("OCR: Skipping tiling, could not encode strip: %v", err)
+			return nil
+		}
+		strips = append(strips, buf.Bytes())
+	}
+	log.Printf("OCR: Tiling %dx%d image into %d strips (%d px overlap)", cfg.Width, cfg.Height, len(strips), tileOverlap)
+	return strips
+}
+
+// stripRects divides b into the fewest strips of at most height pixels that
+// overlap by overlap pixels, all of about the same height.
+func stripRects(b image.Rectangle, height, overlap int) []image.Rectangle {
+	h := b.Dy()
+	if h <= height {
+		return []image.Rectangle{b}
+	}
+	step := height - overlap
+	n := (h - overlap + step - 1) / step
+	stripH := (h + (n-1)*overlap + n - 1) / n
+	rects := make([]image.Rectangle, 0, n)
+	for i := 0; i < n; i++ {
+		top := b.Min.Y + i*(stripH-overlap)
+		bottom := top + stripH
+		if i == n-1 || bottom > b.Max.Y {
+			bottom = b.Max.Y
+		}
+		rects = append(rects, image.Rect(b.Min.X, top, b.Max.X, bottom))
+	}
+	return rects
+}
+
+// recognizeStrips runs query on each strip, downscaled as usual, and stitches
+// the texts. Strips without text are skipped; if no strip has text, their
+// error is returned. Usage and uncertain spans are combined.
+func recognizeStrips(strips [][]byte, query func(i int, strip []byte) (string, []string, llm.Usage, error)) (string, []string, llm.Usage, error) {
+	var texts, spans []string
+	var usage llm.Usage
+	var emptyErr error
+	for i, strip := range strips {
+		text, stripSpans, stripUsage, err := query(i, downscaleImage(strip))
+		usage = addUsage(usage, stripUsage)
+		if errors.Is(err, llm.ErrNoTextFound) {
+			emptyErr = err
+			continue
+		}
+		if err != nil {
+			return "", nil, usage, fmt.Errorf("strip %d/%d: %w", i+1, len(strips), err)
+		}
+		texts = append(texts, text)
+		spans = append(spans, stripSpans...)
+	}
+	if len(texts) == 0 {
+		return "", nil, usage, emptyErr
+	}
+	log.Printf("OCR: Stitched text of %d strips", len(texts))
+	return stitchText(texts), spans, usage, nil
+}
+
+// addUsage adds the token counts of b to a. The provider and model are the
+// first ones reported.
+func addUsage(a, b llm.Usage) llm.Usage {
+	a.PromptTokens += b.PromptTokens
+	a.CompletionTokens += b.CompletionTokens
+	a.TotalTokens += b.TotalTokens
+	a.Cost += b.Cost
+	if a.Provider == "" {
+		a.Provider, a.Model = b.Provider, b.Model
+	}
+	return a
+}
+
+// stitchText joins the texts of consecutive strips. The lines at the end of
+// one text that reappear at the start of the next were read twice from the
+// overlap and are kept once. The last line of a text may instead be a line
+// cut by the strip edge and misread; when the overlap only matches without
+// it, that line is dropped in favour of the next strip's whole one.
+func stitchText(parts []string) string {
+	var lines []string
+	for i, part := range parts {
+		next := strings.Split(strings.TrimRight(strings.ReplaceAll(part, "\r\n", "\n"), "\n"), "\n")
+		if i > 0 {
+			lines, next = dropOverlap(lines, next)
+		}
+		lines = append(lines, next...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dropOverlap returns prev and next without the lines they share: the
+// longest run of up to maxOverlapLines lines ending prev (or ending just
+// before its last line) that also starts next.
+func dropOverlap(prev, next []string) ([]string, []string) {
+	for skip := 0; skip <= 1 && skip < len(prev); skip++ {
+		end := len(prev) - skip
+		for k := min(maxOverlapLines, end, len(next)); k > 0; k-- {
+			if sameLines(prev[end-k:end], next[:k]) {
+				return prev[:end], next[k:]
+			}
+		}
+	}
+	return prev, next
+}
+
+// sameLines reports whether a and b hold the same text, ignoring case and
+// spacing, and enough of it to tell a real overlap from a repeated line.
+func sameLines(a, b []string) bool {
+	chars := 0
+	for i := range a {
+		x, y := normalizeLine(a[i]), normalizeLine(b[i])
+		if x != y {
+			return false
+		}
+		chars += len(x)
+	}
+	return chars >= minOverlapChars
+}
+
+func normalizeLine(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), ""))
+}
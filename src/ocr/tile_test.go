@@ -0,0 +1,131 @@
+package ocr
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"screen-ocr-llm/src/llm"
+)
+
+func TestStripRects(t *testing.T) {
+	rects := stripRects(image.Rect(0, 0, 800, 3000), 1200, 100)
+	if len(rects) != 3 {
+		t.Fatalf("expected 3 strips, got %v", rects)
+	}
+	if rects[0].Min.Y != 0 || rects[len(rects)-1].Max.Y != 3000 {
+		t.Fatalf("strips do not cover the image: %v", rects)
+	}
+	for i, r := range rects {
+		if r.Dx() != 800 || r.Dy() > 1200 {
+			t.Errorf("strip %d has size %dx%d", i, r.Dx(), r.Dy())
+		}
+		if i > 0 && rects[i-1].Max.Y-r.Min.Y != 100 {
+			t.Errorf("strips %d and %d overlap by %d px, want 100", i-1, i, rects[i-1].Max.Y-r.Min.Y)
+		}
+	}
+
+	if rects := stripRects(image.Rect(0, 0, 800, 1000), 1200, 100); len(rects) != 1 {
+		t.Fatalf("expected a short image to stay whole, got %v", rects)
+	}
+}
+
+func TestTileImage(t *testing.T) {
+	defer SetTiling(false)
+
+	tall := encodeTestPNG(t, 40, 2500)
+	if strips := tileImage(tall); strips != nil {
+		t.Fatalf("expected no tiling while disabled, got %d strips", len(strips))
+	}
+
+	SetTiling(true)
+	if strips := tileImage(encodeTestPNG(t, 40, 1000)); strips != nil {
+		t.Fatalf("expected a short image to stay whole, got %d strips", len(strips))
+	}
+	strips := tileImage(tall)
+	if len(strips) != 3 {
+		t.Fatalf("expected 3 strips, got %d", len(strips))
+	}
+	for i, strip := range strips {
+		if w, h := decodedSize(t, strip); w != 40 || h > tileHeight {
+			t.Errorf("strip %d is %dx%d", i, w, h)
+		}
+	}
+}
+
+func TestStitchText(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []string
+		want  string
+	}{
+		{
+			"overlapping lines kept once",
+			[]string{"line one\nline two\nline three", "line two\nline three\nline four"},
+			"line one\nline two\nline three\nline four",
+		},
+		{
+			"cut last line replaced by the whole one",
+			[]string{"alpha beta\ngamma delta\nepsil", "gamma delta\nepsilon zeta\neta theta"},
+			"alpha beta\ngamma delta\nepsilon zeta\neta theta",
+		},
+		{
+			"spacing and case ignored",
+			[]string{"First line\nSecond  Line", "second line\nthird line"},
+			"First line\nSecond  Line\nthird line",
+		},
+		{
+			"short repeated lines are not an overlap",
+			[]string{"if x {\n}", "}\nreturn"},
+			"if x {\n}\n}\nreturn",
+		},
+		{
+			"no overlap",
+			[]string{"top half", "bottom half"},
+			"top half\nbottom half",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stitchText(tt.parts); got != tt.want {
+				t.Fatalf("stitchText() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecognizeStrips(t *testing.T) {
+	strips := [][]byte{encodeTestPNG(t, 10, 10), encodeTestPNG(t, 10, 10), encodeTestPNG(t, 10, 10)}
+	texts := []string{"header line\nshared line here", "", "shared line here\nfooter line"}
+
+	text, spans, usage, err := recognizeStrips(strips, func(i int, strip []byte) (string, []string, llm.Usage, error) {
+		if texts[i] == "" {
+			return "", nil, llm.Usage{TotalTokens: 1}, llm.ErrNoTextFound
+		}
+		return texts[i], []string{texts[i][:6]}, llm.Usage{TotalTokens: 10, Provider: "p"}, nil
+	})
+	if err != nil {
+		t.Fatalf("recognizeStrips returned error: %v", err)
+	}
+	if text != "header line\nshared line here\nfooter line" {
+		t.Fatalf("unexpected text %q", text)
+	}
+	if len(spans) != 2 || usage.TotalTokens != 21 || usage.Provider != "p" {
+		t.Fatalf("unexpected spans %q or usage %+v", spans, usage)
+	}
+
+	_, _, _, err = recognizeStrips(strips, func(int, []byte) (string, []string, llm.Usage, error) {
+		return "", nil, llm.Usage{}, llm.ErrNoTextFound
+	})
+	if !errors.Is(err, llm.ErrNoTextFound) {
+		t.Fatalf("expected ErrNoTextFound when no strip has text, got %v", err)
+	}
+
+	boom := errors.New("boom")
+	_, _, _, err = recognizeStrips(strips, func(int, []byte) (string, []string, llm.Usage, error) {
+		return "", nil, llm.Usage{}, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected the strip error, got %v", err)
+	}
+}
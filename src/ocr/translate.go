@@ -0,0 +1,58 @@
+package ocr
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	"screen-ocr-llm/src/llm"
+)
+
+// translation holds the TRANSLATE_TO settings applied by translateResult.
+var translation struct {
+	sync.Mutex
+	targetLang   string
+	keepOriginal bool
+}
+
+// translateText is llm.TranslateWithContext, replaceable in tests.
+var translateText = llm.TranslateWithContext
+
+// SetTranslation makes every OCR result be translated into targetLang with a
+// second LLM request. With keepOriginal the result is the original text, a
+// blank line and the translation. An empty targetLang disables translation.
+func SetTranslation(targetLang string, keepOriginal bool) {
+	translation.Lock()
+	defer translation.Unlock()
+	translation.targetLang = strings.TrimSpace(targetLang)
+	translation.keepOriginal = keepOriginal
+}
+
+// translateResult translates text as configured by SetTranslation and adds
+// the translation's token usage to usage. If the translation fails, the
+// untranslated text is returned and a warning logged. The request is bound
+// to ctx.
+func translateResult(ctx context.Context, text string, usage llm.Usage) (string, llm.Usage) {
+	translation.Lock()
+	targetLang, keepOriginal := translation.targetLang, translation.keepOriginal
+	translation.Unlock()
+	if targetLang == "" || strings.TrimSpace(text) == "" {
+		return text, usage
+	}
+
+	translated, extra, err := translateText(ctx, text, targetLang)
+	usage.PromptTokens += extra.PromptTokens
+	usage.CompletionTokens += extra.CompletionTokens
+	usage.TotalTokens += extra.TotalTokens
+	usage.Cost += extra.Cost
+	if err != nil {
+		log.Printf("WARNING: Translation into %s failed, returning the original text: %v", targetLang, err)
+		return text, usage
+	}
+	translated = postProcess(translated)
+	if keepOriginal {
+		return text + "\n\n" + translated, usage
+	}
+	return translated, usage
+}
@@ -0,0 +1,53 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"screen-ocr-llm/src/llm"
+)
+
+func TestTranslateResult(t *testing.T) {
+	originalTranslate := translateText
+	defer func() { translateText = originalTranslate }()
+	defer SetTranslation("", false)
+
+	var calls int
+	translateText = func(_ context.Context, text, targetLang string) (string, llm.Usage, error) {
+		calls++
+		if targetLang != "de" {
+			t.Errorf("targetLang = %q, want de", targetLang)
+		}
+		return "Hallo", llm.Usage{TotalTokens: 3}, nil
+	}
+
+	if got, _ := translateResult(context.Background(), "Hello", llm.Usage{}); got != "Hello" || calls != 0 {
+		t.Fatalf("translation disabled: got %q after %d calls", got, calls)
+	}
+
+	SetTranslation(" de ", false)
+	got, usage := translateResult(context.Background(), "Hello", llm.Usage{TotalTokens: 10})
+	if got != "Hallo" || usage.TotalTokens != 13 {
+		t.Fatalf("got %q, usage %+v; want Hallo with 13 tokens", got, usage)
+	}
+
+	SetTranslation("de", true)
+	if got, _ := translateResult(context.Background(), "Hello", llm.Usage{}); got != "Hello\n\nHallo" {
+		t.Fatalf("keep original: got %q", got)
+	}
+}
+
+func TestTranslateResultFallsBackOnError(t *testing.T) {
+	originalTranslate := translateText
+	defer func() { translateText = originalTranslate }()
+	defer SetTranslation("", false)
+
+	translateText = func(_ context.Context, text, targetLang string) (string, llm.Usage, error) {
+		return "", llm.Usage{}, errors.New("API returned status 500")
+	}
+	SetTranslation("de", true)
+	if got, _ := translateResult(context.Background(), "Hello", llm.Usage{}); got != "Hello" {
+		t.Fatalf("expected the original text on failure, got %q", got)
+	}
+}
@@ -12,8 +12,25 @@ type Selector interface {
 	Select(ctx context.Context) (screenshot.Region, bool, error)
 }
 
-// NewSelector returns the platform implementation (Windows in this project).
-// Implementation is provided in a platform-specific file.
+// Options configures the selection overlay.
+type Options struct {
+	DefaultMode string
+	Guides      bool
+	// BackgroundScale down-scales the overlay background (0 < s <= 1).
+	// Selections are still reported in full-resolution coordinates.
+	BackgroundScale float64
+	// Magnifier shows a zoomed view of the pixels around the cursor.
+	Magnifier bool
+}
+
+// NewSelector returns the platform implementation. Only Windows has an
+// overlay; other platforms get a selector that always fails with
+// ErrUnsupported.
 func NewSelector(defaultMode string) Selector {
-	return newWindowsSelector(defaultMode)
+	return NewSelectorWithOptions(Options{DefaultMode: defaultMode})
+}
+
+// NewSelectorWithOptions returns the platform implementation configured by opts.
+func NewSelectorWithOptions(opts Options) Selector {
+	return newPlatformSelector(opts)
 }
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package overlay
+
+import (
+	"context"
+	"errors"
+	"screen-ocr-llm/src/screenshot"
+)
+
+// ErrUnsupported is returned by Select on platforms without an overlay.
+var ErrUnsupported = errors.New("region selection not supported on this platform")
+
+// unsupportedSelector is used on platforms without a selection overlay.
+type unsupportedSelector struct{}
+
+func newPlatformSelector(opts Options) Selector {
+	return unsupportedSelector{}
+}
+
+func (unsupportedSelector) Select(ctx context.Context) (screenshot.Region, bool, error) {
+	return screenshot.Region{}, false, ErrUnsupported
+}
@@ -4,21 +4,30 @@ package overlay
 
 import (
 	"context"
+	"errors"
 	"screen-ocr-llm/src/gui"
 	"screen-ocr-llm/src/screenshot"
 )
 
 // windowsSelector adapts existing gui region selector to the new synchronous API.
 type windowsSelector struct {
-	defaultMode string
+	opts Options
 }
 
-func newWindowsSelector(defaultMode string) Selector {
-	return &windowsSelector{defaultMode: defaultMode}
+func newPlatformSelector(opts Options) Selector {
+	return &windowsSelector{opts: opts}
 }
 
 func (w *windowsSelector) Select(ctx context.Context) (screenshot.Region, bool, error) {
-	region, err := gui.StartRegionSelectionWithMode(w.defaultMode)
+	region, err := gui.StartRegionSelectionWithOptions(gui.SelectionOptions{
+		Mode:            w.opts.DefaultMode,
+		Guides:          w.opts.Guides,
+		BackgroundScale: w.opts.BackgroundScale,
+		Magnifier:       w.opts.Magnifier,
+	})
+	if errors.Is(err, gui.ErrSelectionCancelled) {
+		return screenshot.Region{}, true, nil
+	}
 	if err != nil {
 		return screenshot.Region{}, false, err
 	}
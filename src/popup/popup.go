@@ -3,6 +3,7 @@ package popup
 import (
 	"log"
 	"runtime"
+	"screen-ocr-llm/src/logutil"
 	"screen-ocr-llm/src/notification"
 )
 
@@ -12,22 +13,15 @@ func Show(text string) error {
 	// Get caller information for debugging
 	_, file, line, ok := runtime.Caller(1)
 	if ok {
-		log.Printf("Popup.Show called from %s:%d with %d characters: %q", file, line, len(text), truncateForLog(text, 50))
+		log.Printf("Popup.Show called from %s:%d with %d characters: %s", file, line, len(text), logutil.SanitizeForLog(text, 50))
 	} else {
-		log.Printf("Popup.Show called with %d characters: %q", len(text), truncateForLog(text, 50))
+		log.Printf("Popup.Show called with %d characters: %s", len(text), logutil.SanitizeForLog(text, 50))
 	}
 	// Fire-and-forget: notification layer manages its own lifetime asynchronously.
 	notification.ShowOCRResult(text)
 	return nil
 }
 
-func truncateForLog(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "..."
-}
-
 // StartCountdown displays a countdown popup that updates every second
 func StartCountdown(timeoutSeconds int) error {
 	log.Printf("Popup.StartCountdown called with %d seconds", timeoutSeconds)
@@ -40,6 +34,11 @@ func UpdateText(text string) error {
 	return notification.UpdatePopupText(text)
 }
 
+// WaitClosed blocks until the current popup has been closed (by its timer or a click)
+func WaitClosed() {
+	notification.WaitPopupClosed()
+}
+
 // Close closes the current popup
 func Close() error {
 	log.Printf("Popup.Close called")
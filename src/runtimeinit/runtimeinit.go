@@ -1,12 +1,18 @@
 package runtimeinit
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"time"
 
 	"screen-ocr-llm/src/clipboard"
 	"screen-ocr-llm/src/config"
+	"screen-ocr-llm/src/history"
+	"screen-ocr-llm/src/keyboard"
 	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/logutil"
 	"screen-ocr-llm/src/notification"
 	"screen-ocr-llm/src/ocr"
 	"screen-ocr-llm/src/screenshot"
@@ -16,6 +22,9 @@ type Options struct {
 	LoadOptions          config.LoadOptions
 	SetupLogging         func(bool)
 	ShowBlockingLLMError bool
+	// ValidateHotkey checks HOTKEY and HOTKEYS during config validation;
+	// nil skips them (one-shot runs listen for no hotkeys).
+	ValidateHotkey func(string) error
 }
 
 func Bootstrap(opts Options) (*config.Config, error) {
@@ -25,34 +34,124 @@ func Bootstrap(opts Options) (*config.Config, error) {
 	}
 
 	if opts.SetupLogging != nil {
+		logutil.SetDir(cfg.LogDir)
 		opts.SetupLogging(cfg.EnableFileLogging)
 	}
+	logLevel, _ := logutil.ParseLevel(cfg.LogLevel)
+	logutil.SetLevel(logLevel)
 
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("OPENROUTER_API_KEY is required. Checked key file %s and OPENROUTER_API_KEY env var", cfg.APIKeyPath)
 	}
-	if cfg.Model == "" {
-		return nil, fmt.Errorf("MODEL is required. Please set it in your .env file")
+	if err := cfg.Validate(opts.ValidateHotkey); err != nil {
+		if opts.ShowBlockingLLMError {
+			notification.ShowBlockingError("Configuration error", err.Error())
+		}
+		return nil, err
+	}
+	replacements, err := ocr.LoadReplacements(cfg.OCRReplacements)
+	if err != nil {
+		return nil, err
 	}
 
-	llm.Init(&llm.Config{
-		APIKey:    cfg.APIKey,
-		Model:     cfg.Model,
-		Providers: cfg.Providers,
-	})
-	if err := llm.Ping(); err != nil {
+	if err := llm.Init(LLMConfig(cfg)); err != nil {
+		return nil, err
+	}
+	if cfg.LLMBackend == config.LLMBackendMock {
+		log.Printf("LLM: Using the offline mock backend (LLM_BACKEND=mock)")
+	}
+	if err := llm.ValidateKey(); err != nil {
 		if opts.ShowBlockingLLMError {
-			notification.ShowBlockingError("LLM unavailable", fmt.Sprintf("Startup check failed: %v\n\nPlease verify your API key and network connectivity.", err))
+			notification.ShowBlockingError(startupCheckMessage(err))
 		}
 		return nil, fmt.Errorf("startup check failed: %w", err)
 	}
 	log.Printf("LLM ping succeeded")
 
+	notification.Configure(notification.PopupConfig{
+		Position: cfg.PopupPosition,
+		Width:    cfg.PopupWidth,
+		Height:   cfg.PopupHeight,
+		Theme:    cfg.PopupTheme,
+	})
+	notification.SetResultDuration(time.Duration(cfg.PopupDurationSec) * time.Second)
+	notification.SetSticky(cfg.PopupSticky)
+	notification.SetMaxChars(cfg.PopupMaxChars)
+
 	screenshot.Init()
+	screenshot.SetPreprocess(cfg.OCRPreprocess)
+	screenshot.SetBlankThreshold(cfg.BlankThreshold)
+	screenshot.SetAutoCrop(cfg.OCRAutoCrop)
 	ocr.Init()
+	ocr.SetMaxImageEdge(cfg.OCRMaxImageEdge)
+	ocr.SetTiling(cfg.OCRTile)
+	ocr.SetScreenshotDir(cfg.SaveScreenshotDir)
+	ocr.SetNormalization(cfg.OCRTrim, cfg.OCRLineEndings)
+	ocr.SetReplacements(replacements)
+	ocr.SetTranslation(cfg.TranslateTo, cfg.TranslateKeepOrig)
+	ocr.SetExtraction(cfg.OCRExtract)
+	ocr.SetDryRun(cfg.DryRun)
+	if cfg.DryRun {
+		log.Printf("Dry run: captures are not sent to the LLM")
+	}
+	if len(replacements) > 0 {
+		log.Printf("Loaded %d OCR replacement rules from %s", len(replacements), cfg.OCRReplacements)
+	}
+	if cfg.SaveScreenshotDir != "" {
+		log.Printf("Saving captured regions to %s", cfg.SaveScreenshotDir)
+	}
+	if cfg.OCRHistoryEnabled {
+		history.SetPath(history.DefaultPath())
+		log.Printf("OCR history enabled: %s", history.DefaultPath())
+	}
 	if err := clipboard.Init(); err != nil {
 		return nil, fmt.Errorf("failed to initialize clipboard: %w", err)
 	}
+	clipboard.SetAppendSeparator(cfg.ClipboardAppendSep)
+	clipboard.SetFormat(cfg.ClipboardFormat)
+	keyboard.SetDelay(time.Duration(cfg.TypeDelayMs) * time.Millisecond)
 
 	return cfg, nil
 }
+
+// LLMConfig builds the llm.Init configuration from the loaded config.
+func LLMConfig(cfg *config.Config) *llm.Config {
+	temperature := cfg.OCRTemperature
+	return &llm.Config{
+		APIKey:         cfg.APIKey,
+		Model:          cfg.Model,
+		Models:         cfg.Models,
+		Providers:      cfg.Providers,
+		AllowFallbacks: cfg.ProviderFallbacks,
+		Quantizations:  cfg.ProviderQuants,
+		Language:       cfg.OCRLanguage,
+		BaseURL:        cfg.BaseURL,
+		MaxTokens:      cfg.OCRMaxTokens,
+		Temperature:    &temperature,
+		OutputFormat:   cfg.OCROutputFormat,
+		Mode:           cfg.Mode,
+		Transport:      llmTransport(cfg),
+		HTTPReferer:    cfg.HTTPReferer,
+		AppTitle:       cfg.AppTitle,
+		MarkUncertain:  cfg.OCRMarkUncertain,
+	}
+}
+
+// llmTransport returns llm.MockTransport for LLM_BACKEND=mock and nil (real
+// HTTP) otherwise.
+func llmTransport(cfg *config.Config) http.RoundTripper {
+	if cfg.LLMBackend == config.LLMBackendMock {
+		return llm.MockTransport{}
+	}
+	return nil
+}
+
+// startupCheckMessage returns the title and text of the blocking error shown
+// when the startup LLM check fails. Key problems get their tailored message.
+func startupCheckMessage(err error) (string, string) {
+	var keyErr *llm.KeyError
+	if errors.As(err, &keyErr) {
+		return "LLM unavailable: " + keyErr.Reason.Error(), fmt.Sprintf("Startup check failed.\n\n%v", err)
+	}
+	return "LLM unavailable", fmt.Sprintf("Startup check failed: %v\n\nPlease verify your API key and network connectivity.", err)
+}
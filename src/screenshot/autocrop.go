@@ -0,0 +1,106 @@
+package screenshot
+
+import (
+	"image"
+	"sync/atomic"
+)
+
+const (
+	// AutoCropTolerance is the largest per-channel difference at which
+	// OCR_AUTOCROP still treats a pixel as part of a uniform border.
+	AutoCropTolerance = 16
+	// autoCropMargin is the border, in pixels, kept around the content so
+	// glyphs at its edge aren't clipped.
+	autoCropMargin = 4
+)
+
+var autoCrop atomic.Bool
+
+// SetAutoCrop enables trimming of uniform borders from captures in
+// CaptureRegion (OCR_AUTOCROP).
+func SetAutoCrop(enabled bool) {
+	autoCrop.Store(enabled)
+}
+
+// ContentBounds returns the part of img left after trimming uniform borders
+// from each edge. A side's border colour is the first pixel of its outermost
+// row or column, and rows or columns are trimmed while every pixel in them is
+// within tolerance of it on each of R, G and B. ok is false when the whole
+// image is uniform.
+func ContentBounds(img *image.RGBA, tolerance uint8) (image.Rectangle, bool) {
+	r := img.Bounds()
+	if r.Empty() {
+		return image.Rectangle{}, false
+	}
+	ref := img.PixOffset(r.Min.X, r.Min.Y)
+	for r.Min.Y < r.Max.Y && rowMatches(img, r.Min.Y, r.Min.X, r.Max.X, ref, tolerance) {
+		r.Min.Y++
+	}
+	if r.Empty() {
+		return image.Rectangle{}, false
+	}
+	ref = img.PixOffset(r.Min.X, r.Max.Y-1)
+	for r.Max.Y > r.Min.Y && rowMatches(img, r.Max.Y-1, r.Min.X, r.Max.X, ref, tolerance) {
+		r.Max.Y--
+	}
+	ref = img.PixOffset(r.Min.X, r.Min.Y)
+	for r.Min.X < r.Max.X && columnMatches(img, r.Min.X, r.Min.Y, r.Max.Y, ref, tolerance) {
+		r.Min.X++
+	}
+	if r.Empty() {
+		return image.Rectangle{}, false
+	}
+	ref = img.PixOffset(r.Max.X-1, r.Min.Y)
+	for r.Max.X > r.Min.X && columnMatches(img, r.Max.X-1, r.Min.Y, r.Max.Y, ref, tolerance) {
+		r.Max.X--
+	}
+	return r, !r.Empty()
+}
+
+// AutoCrop returns img cut down to its ContentBounds plus a small margin.
+// It returns img itself if there is no border to trim or no content.
+func AutoCrop(img *image.RGBA, tolerance uint8) *image.RGBA {
+	content, ok := ContentBounds(img, tolerance)
+	if !ok {
+		return img
+	}
+	crop := content.Inset(-autoCropMargin).Intersect(img.Bounds())
+	if crop == img.Bounds() {
+		return img
+	}
+	return img.SubImage(crop).(*image.RGBA)
+}
+
+// rowMatches reports whether every pixel of row y between minX and maxX is
+// within tolerance of the pixel at Pix offset ref.
+func rowMatches(img *image.RGBA, y, minX, maxX, ref int, tolerance uint8) bool {
+	for x := minX; x < maxX; x++ {
+		if !similarPixel(img.Pix, ref, img.PixOffset(x, y), tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// columnMatches is rowMatches for column x between minY and maxY.
+func columnMatches(img *image.RGBA, x, minY, maxY, ref int, tolerance uint8) bool {
+	for y := minY; y < maxY; y++ {
+		if !similarPixel(img.Pix, ref, img.PixOffset(x, y), tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+func similarPixel(pix []uint8, a, b int, tolerance uint8) bool {
+	for c := 0; c < 3; c++ {
+		d := int(pix[a+c]) - int(pix[b+c])
+		if d < 0 {
+			d = -d
+		}
+		if d > int(tolerance) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// framedImage returns a w x h image filled with bg and a fg rectangle at content.
+func framedImage(w, h int, bg, fg color.RGBA, content image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if (image.Point{X: x, Y: y}).In(content) {
+				img.SetRGBA(x, y, fg)
+			} else {
+				img.SetRGBA(x, y, bg)
+			}
+		}
+	}
+	return img
+}
+
+func TestContentBounds(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.RGBA{A: 255}
+	content := image.Rect(10, 5, 30, 15)
+
+	img := framedImage(40, 20, white, black, content)
+	if got, ok := ContentBounds(img, 0); !ok || got != content {
+		t.Fatalf("ContentBounds = %v, %v; want %v", got, ok, content)
+	}
+
+	// Slight noise in the border is within tolerance
+	img.SetRGBA(1, 1, color.RGBA{R: 245, G: 250, B: 255, A: 255})
+	if got, ok := ContentBounds(img, AutoCropTolerance); !ok || got != content {
+		t.Fatalf("ContentBounds with noise = %v, %v; want %v", got, ok, content)
+	}
+	if got, _ := ContentBounds(img, 0); got.Min != (image.Point{X: 1, Y: 1}) {
+		t.Fatalf("ContentBounds with zero tolerance = %v, want it to keep the noisy pixel", got)
+	}
+
+	uniform := framedImage(8, 8, white, white, image.Rectangle{})
+	if got, ok := ContentBounds(uniform, AutoCropTolerance); ok {
+		t.Fatalf("ContentBounds of uniform image = %v, want ok=false", got)
+	}
+}
+
+func TestAutoCrop(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.RGBA{A: 255}
+
+	img := framedImage(40, 20, white, black, image.Rect(10, 5, 30, 15))
+	if got, want := AutoCrop(img, AutoCropTolerance).Bounds(), image.Rect(6, 1, 34, 19); got != want {
+		t.Fatalf("AutoCrop bounds = %v, want %v (content plus margin)", got, want)
+	}
+
+	edge := framedImage(40, 20, white, black, image.Rect(0, 2, 40, 18))
+	if got := AutoCrop(edge, AutoCropTolerance); got != edge {
+		t.Fatalf("AutoCrop with content near every edge = %v, want the image unchanged", got.Bounds())
+	}
+
+	uniform := framedImage(8, 8, white, white, image.Rectangle{})
+	if got := AutoCrop(uniform, AutoCropTolerance); got != uniform {
+		t.Fatal("AutoCrop of uniform image should return it unchanged")
+	}
+}
@@ -0,0 +1,72 @@
+package screenshot
+
+import (
+	"errors"
+	"image"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBlankCapture is returned when a capture is uniformly black. Windows
+// returns black frames for DRM-protected content, the secure desktop and
+// locked displays, so sending such an image to OCR would only yield an
+// unexplained empty result.
+var ErrBlankCapture = errors.New("capture returned blank — content may be protected")
+
+const (
+	// DefaultBlankThreshold is the mean luminance (0-255) at or below which
+	// a near-uniform capture counts as blank.
+	DefaultBlankThreshold = 8.0
+	blankMaxVariance      = 4.0
+
+	// blankRetryDelay is how long CaptureRegion waits before capturing a
+	// blank region again; some GPU drivers return an occasional black frame.
+	blankRetryDelay = 150 * time.Millisecond
+)
+
+// blankThreshold holds the float64 bits of the BLANK_CAPTURE_THRESHOLD value.
+var blankThreshold atomic.Uint64
+
+func init() {
+	blankThreshold.Store(math.Float64bits(DefaultBlankThreshold))
+}
+
+// SetBlankThreshold sets the mean luminance (0-255) at or below which a
+// near-uniform capture is treated as blank. Zero or less disables the check.
+func SetBlankThreshold(maxMeanLuma float64) {
+	blankThreshold.Store(math.Float64bits(maxMeanLuma))
+}
+
+// IsBlank reports whether img is black or near-uniformly black, using the
+// mean and variance of pixel luminance and the SetBlankThreshold limit.
+func IsBlank(img *image.RGBA) bool {
+	maxMeanLuma := math.Float64frombits(blankThreshold.Load())
+	if img == nil || maxMeanLuma <= 0 {
+		return false
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return false
+	}
+
+	var sum, sumSq float64
+	n := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		off := img.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r := float64(img.Pix[off])
+			g := float64(img.Pix[off+1])
+			bl := float64(img.Pix[off+2])
+			luma := 0.299*r + 0.587*g + 0.114*bl
+			sum += luma
+			sumSq += luma * luma
+			n++
+			off += 4
+		}
+	}
+
+	mean := sum / float64(n)
+	variance := sumSq/float64(n) - mean*mean
+	return mean <= maxMeanLuma && variance <= blankMaxVariance
+}
@@ -0,0 +1,103 @@
+package screenshot
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestIsBlank(t *testing.T) {
+	black := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := 3; i < len(black.Pix); i += 4 {
+		black.Pix[i] = 255
+	}
+	if !IsBlank(black) {
+		t.Fatal("expected all-black image to be blank")
+	}
+
+	noisy := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	copy(noisy.Pix, black.Pix)
+	noisy.SetRGBA(1, 1, color.RGBA{R: 3, G: 3, B: 3, A: 255})
+	if !IsBlank(noisy) {
+		t.Fatal("expected near-black image to be blank")
+	}
+
+	withText := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	copy(withText.Pix, black.Pix)
+	for x := 2; x < 8; x++ {
+		withText.SetRGBA(x, 5, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	}
+	if IsBlank(withText) {
+		t.Fatal("expected image with white strokes not to be blank")
+	}
+
+	white := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := range white.Pix {
+		white.Pix[i] = 255
+	}
+	if IsBlank(white) {
+		t.Fatal("expected white image not to be blank")
+	}
+}
+
+func TestSetBlankThreshold(t *testing.T) {
+	t.Cleanup(func() { SetBlankThreshold(DefaultBlankThreshold) })
+
+	dark := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(dark.Pix); i += 4 {
+		dark.Pix[i], dark.Pix[i+1], dark.Pix[i+2], dark.Pix[i+3] = 20, 20, 20, 255
+	}
+	if IsBlank(dark) {
+		t.Fatal("expected dark gray image not to be blank at the default threshold")
+	}
+	SetBlankThreshold(30)
+	if !IsBlank(dark) {
+		t.Fatal("expected dark gray image to be blank at threshold 30")
+	}
+	SetBlankThreshold(0)
+	if IsBlank(image.NewRGBA(image.Rect(0, 0, 4, 4))) {
+		t.Fatal("expected threshold 0 to disable the check")
+	}
+}
+
+func TestCaptureNonBlankRetries(t *testing.T) {
+	origCapture, origSleep := captureRect, sleep
+	t.Cleanup(func() { captureRect, sleep = origCapture, origSleep })
+	sleep = func(time.Duration) {}
+
+	black := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	white := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range white.Pix {
+		white.Pix[i] = 255
+	}
+
+	tests := []struct {
+		name    string
+		frames  []*image.RGBA
+		wantErr error
+		calls   int
+	}{
+		{name: "first frame ok", frames: []*image.RGBA{white}, calls: 1},
+		{name: "retry succeeds", frames: []*image.RGBA{black, white}, calls: 2},
+		{name: "retry blank", frames: []*image.RGBA{black, black}, wantErr: ErrBlankCapture, calls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			captureRect = func(image.Rectangle) (*image.RGBA, error) {
+				img := tt.frames[calls]
+				calls++
+				return img, nil
+			}
+			img, err := captureNonBlank(image.Rect(0, 0, 4, 4))
+			if !errors.Is(err, tt.wantErr) || (err == nil && img != white) {
+				t.Fatalf("captureNonBlank = %p, %v; want white, %v", img, err, tt.wantErr)
+			}
+			if calls != tt.calls {
+				t.Fatalf("captured %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
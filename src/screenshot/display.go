@@ -0,0 +1,52 @@
+package screenshot
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/kbinani/screenshot"
+)
+
+// ErrDisplayNotFound is returned by DisplayRegion for an index outside the
+// active displays.
+var ErrDisplayNotFound = errors.New("display not found")
+
+// DisplayRegion returns the full bounds of active display i, in the same
+// virtual-screen coordinates CaptureRegion takes.
+func DisplayRegion(i int) (Region, error) {
+	n := screenshot.NumActiveDisplays()
+	if n == 0 {
+		return Region{}, ErrNoDisplay
+	}
+	bounds := make([]image.Rectangle, n)
+	for j := range bounds {
+		bounds[j] = screenshot.GetDisplayBounds(j)
+	}
+	return displayRegion(i, bounds)
+}
+
+// CaptureDisplayIndex captures the whole of active display i and returns it
+// as PNG bytes, like CaptureRegion.
+func CaptureDisplayIndex(i int) ([]byte, error) {
+	region, err := DisplayRegion(i)
+	if err != nil {
+		return nil, err
+	}
+	return CaptureRegion(region)
+}
+
+// displayRegion picks display i from bounds; the error for a bad index
+// lists the available displays.
+func displayRegion(i int, bounds []image.Rectangle) (Region, error) {
+	if i < 0 || i >= len(bounds) {
+		available := make([]string, len(bounds))
+		for j, b := range bounds {
+			available[j] = fmt.Sprintf("%d: %dx%d at (%d,%d)", j, b.Dx(), b.Dy(), b.Min.X, b.Min.Y)
+		}
+		return Region{}, fmt.Errorf("%w: index %d; available displays: %s", ErrDisplayNotFound, i, strings.Join(available, ", "))
+	}
+	b := bounds[i]
+	return Region{X: b.Min.X, Y: b.Min.Y, Width: b.Dx(), Height: b.Dy()}, nil
+}
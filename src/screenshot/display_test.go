@@ -0,0 +1,34 @@
+package screenshot
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestDisplayRegion(t *testing.T) {
+	bounds := []image.Rectangle{
+		image.Rect(0, 0, 1920, 1080),
+		image.Rect(-1280, 100, 0, 1124),
+	}
+
+	region, err := displayRegion(1, bounds)
+	if err != nil {
+		t.Fatalf("displayRegion(1) returned error: %v", err)
+	}
+	if region.X != -1280 || region.Y != 100 || region.Width != 1280 || region.Height != 1024 {
+		t.Fatalf("displayRegion(1) = %+v, want 1280x1024 at (-1280,100)", region)
+	}
+
+	for _, i := range []int{-1, 2} {
+		_, err := displayRegion(i, bounds)
+		if !errors.Is(err, ErrDisplayNotFound) {
+			t.Fatalf("displayRegion(%d) error = %v, want ErrDisplayNotFound", i, err)
+		}
+		want := "available displays: 0: 1920x1080 at (0,0), 1: 1280x1024 at (-1280,100)"
+		if !strings.HasSuffix(err.Error(), want) {
+			t.Fatalf("displayRegion(%d) error = %q, want suffix %q", i, err, want)
+		}
+	}
+}
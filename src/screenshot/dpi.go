@@ -0,0 +1,71 @@
+package screenshot
+
+import "math"
+
+// defaultDPI is the 100% scaling DPI.
+const defaultDPI = 96
+
+// DPIAwareness mirrors the Windows PROCESS_DPI_AWARENESS values.
+type DPIAwareness int
+
+const (
+	DPIUnaware         DPIAwareness = 0
+	DPISystemAware     DPIAwareness = 1
+	DPIPerMonitorAware DPIAwareness = 2
+)
+
+// DPIScale returns the factor that maps selection coordinates seen by a
+// process with the given awareness to the physical pixels CaptureRegion
+// reads, for a monitor at monitorDPI (96 = 100%, 144 = 150%, 192 = 200%).
+//
+//   - Per-monitor aware: coordinates are already physical, scale 1.
+//   - System aware: Windows scales by monitorDPI/systemDPI.
+//   - Unaware: Windows scales by monitorDPI/96.
+//
+// Unknown (zero) DPI values yield 1.
+func DPIScale(awareness DPIAwareness, monitorDPI, systemDPI int) float64 {
+	if monitorDPI <= 0 {
+		return 1
+	}
+	switch awareness {
+	case DPIPerMonitorAware:
+		return 1
+	case DPISystemAware:
+		if systemDPI <= 0 {
+			return 1
+		}
+		return float64(monitorDPI) / float64(systemDPI)
+	default:
+		return float64(monitorDPI) / defaultDPI
+	}
+}
+
+// ScaleRegion multiplies a region (and its lasso polygon) by scale, rounding
+// the edges so scaled neighbouring regions still tile. A scale <= 0 or equal
+// to 1 returns region unchanged.
+func ScaleRegion(region Region, scale float64) Region {
+	if scale <= 0 || scale == 1 {
+		return region
+	}
+	s := func(v int) int { return int(math.Round(float64(v) * scale)) }
+
+	scaled := Region{
+		X:      s(region.X),
+		Y:      s(region.Y),
+		Width:  s(region.X+region.Width) - s(region.X),
+		Height: s(region.Y+region.Height) - s(region.Y),
+	}
+	if len(region.Polygon) > 0 {
+		scaled.Polygon = make([]Point, len(region.Polygon))
+		for i, p := range region.Polygon {
+			scaled.Polygon[i] = Point{X: s(p.X), Y: s(p.Y)}
+		}
+	}
+	return scaled
+}
+
+// CaptureRegionScaled captures a region given in logical (DPI-scaled)
+// coordinates by first mapping it to physical pixels with scale.
+func CaptureRegionScaled(region Region, scale float64) ([]byte, error) {
+	return CaptureRegion(ScaleRegion(region, scale))
+}
@@ -0,0 +1,49 @@
+package screenshot
+
+import "testing"
+
+func TestDPIScale(t *testing.T) {
+	tests := []struct {
+		name       string
+		awareness  DPIAwareness
+		monitorDPI int
+		systemDPI  int
+		want       float64
+	}{
+		{name: "per-monitor aware at 150%", awareness: DPIPerMonitorAware, monitorDPI: 144, systemDPI: 96, want: 1},
+		{name: "unaware at 100%", awareness: DPIUnaware, monitorDPI: 96, want: 1},
+		{name: "unaware at 150%", awareness: DPIUnaware, monitorDPI: 144, want: 1.5},
+		{name: "unaware at 200%", awareness: DPIUnaware, monitorDPI: 192, want: 2},
+		{name: "system aware, 200% monitor, 100% system", awareness: DPISystemAware, monitorDPI: 192, systemDPI: 96, want: 2},
+		{name: "system aware, 100% monitor, 150% system", awareness: DPISystemAware, monitorDPI: 96, systemDPI: 144, want: 96.0 / 144.0},
+		{name: "unknown monitor DPI", awareness: DPIUnaware, monitorDPI: 0, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DPIScale(tt.awareness, tt.monitorDPI, tt.systemDPI); got != tt.want {
+				t.Fatalf("DPIScale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleRegion(t *testing.T) {
+	r := Region{X: 100, Y: 50, Width: 201, Height: 99, Polygon: []Point{{X: 100, Y: 50}, {X: 301, Y: 50}, {X: 200, Y: 149}}}
+
+	if got := ScaleRegion(r, 1); got.X != 100 || got.Width != 201 || &got.Polygon[0] != &r.Polygon[0] {
+		t.Fatalf("ScaleRegion(1) should return the region unchanged, got %+v", got)
+	}
+
+	got := ScaleRegion(r, 1.5)
+	if got.X != 150 || got.Y != 75 || got.Width != 302 || got.Height != 149 {
+		t.Fatalf("ScaleRegion(1.5) = %+v, want X=150 Y=75 W=302 H=149", got)
+	}
+	if got.Polygon[1] != (Point{X: 452, Y: 75}) || r.Polygon[1].X != 301 {
+		t.Fatalf("ScaleRegion(1.5) polygon = %+v (input must stay unchanged)", got.Polygon)
+	}
+
+	got = ScaleRegion(Region{X: -1920, Y: 0, Width: 640, Height: 480}, 2)
+	if got.X != -3840 || got.Width != 1280 || got.Height != 960 {
+		t.Fatalf("ScaleRegion(2) = %+v", got)
+	}
+}
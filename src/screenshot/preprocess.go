@@ -0,0 +1,103 @@
+package screenshot
+
+import (
+	"image"
+	"strings"
+	"sync/atomic"
+)
+
+// Preprocess modes accepted by OCR_PREPROCESS.
+const (
+	PreprocessNone      = "none"
+	PreprocessGrayscale = "grayscale"
+	PreprocessContrast  = "contrast"
+	PreprocessBoth      = "both"
+)
+
+var preprocessMode atomic.Value
+
+func init() {
+	preprocessMode.Store(PreprocessNone)
+}
+
+// SetPreprocess sets the transforms CaptureRegion applies before encoding.
+// Unknown modes disable preprocessing.
+func SetPreprocess(mode string) {
+	preprocessMode.Store(NormalizePreprocess(mode))
+}
+
+// NormalizePreprocess lower-cases mode and maps unknown values to
+// PreprocessNone.
+func NormalizePreprocess(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case PreprocessGrayscale, PreprocessContrast, PreprocessBoth:
+		return mode
+	}
+	return PreprocessNone
+}
+
+// Preprocess applies the transforms selected by mode to img in place and
+// returns their names in the order applied. Grayscale uses Rec. 601 luma;
+// contrast stretches the darkest and brightest channel values to 0 and 255.
+func Preprocess(img *image.RGBA, mode string) []string {
+	if img == nil {
+		return nil
+	}
+
+	var applied []string
+	mode = NormalizePreprocess(mode)
+	if mode == PreprocessGrayscale || mode == PreprocessBoth {
+		grayscale(img)
+		applied = append(applied, PreprocessGrayscale)
+	}
+	if mode == PreprocessContrast || mode == PreprocessBoth {
+		if stretchContrast(img) {
+			applied = append(applied, PreprocessContrast)
+		}
+	}
+	return applied
+}
+
+func grayscale(img *image.RGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.PixOffset(b.Min.X, y)
+		for i := row; i < row+b.Dx()*4; i += 4 {
+			r, g, bl := int(img.Pix[i]), int(img.Pix[i+1]), int(img.Pix[i+2])
+			l := uint8((299*r + 587*g + 114*bl + 500) / 1000)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2] = l, l, l
+		}
+	}
+}
+
+// stretchContrast maps the image's channel range onto 0-255. It reports false
+// when the image is flat or already uses the full range.
+func stretchContrast(img *image.RGBA) bool {
+	b := img.Bounds()
+	lo, hi := 255, 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.PixOffset(b.Min.X, y)
+		for i := row; i < row+b.Dx()*4; i += 4 {
+			for _, v := range img.Pix[i : i+3] {
+				lo = min(lo, int(v))
+				hi = max(hi, int(v))
+			}
+		}
+	}
+	if hi <= lo || (lo == 0 && hi == 255) {
+		return false
+	}
+
+	var lut [256]uint8
+	for v := range lut {
+		s := (v - lo) * 255 / (hi - lo)
+		lut[v] = uint8(min(max(s, 0), 255))
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.PixOffset(b.Min.X, y)
+		for i := row; i < row+b.Dx()*4; i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2] = lut[img.Pix[i]], lut[img.Pix[i+1]], lut[img.Pix[i+2]]
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePreprocess(t *testing.T) {
+	for in, want := range map[string]string{
+		"":           PreprocessNone,
+		"none":       PreprocessNone,
+		" Grayscale": PreprocessGrayscale,
+		"CONTRAST":   PreprocessContrast,
+		"both":       PreprocessBoth,
+		"sharpen":    PreprocessNone,
+	} {
+		if got := NormalizePreprocess(in); got != want {
+			t.Errorf("NormalizePreprocess(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPreprocessGrayscale(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+
+	if applied := Preprocess(img, PreprocessGrayscale); !reflect.DeepEqual(applied, []string{PreprocessGrayscale}) {
+		t.Fatalf("applied = %v", applied)
+	}
+	if got := img.RGBAAt(0, 0); got != (color.RGBA{R: 76, G: 76, B: 76, A: 255}) {
+		t.Fatalf("pixel = %v, want gray 76", got)
+	}
+}
+
+func TestPreprocessContrast(t *testing.T) {
+	// Gray-on-gray text: 100 and 150 stretch to 0 and 255
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	img.SetRGBA(1, 0, color.RGBA{R: 125, G: 125, B: 125, A: 255})
+	img.SetRGBA(2, 0, color.RGBA{R: 150, G: 150, B: 150, A: 255})
+
+	if applied := Preprocess(img, PreprocessBoth); !reflect.DeepEqual(applied, []string{PreprocessGrayscale, PreprocessContrast}) {
+		t.Fatalf("applied = %v", applied)
+	}
+	for x, want := range []uint8{0, 127, 255} {
+		if got := img.RGBAAt(x, 0).R; got != want {
+			t.Errorf("pixel %d = %d, want %d", x, got, want)
+		}
+	}
+}
+
+func TestPreprocessNoOp(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 10, G: 20, B: 30, A: 255})
+	img.SetRGBA(1, 0, color.RGBA{R: 40, G: 50, B: 60, A: 255})
+	before := append([]uint8(nil), img.Pix...)
+
+	if applied := Preprocess(img, PreprocessNone); applied != nil {
+		t.Fatalf("applied = %v, want none", applied)
+	}
+	if !reflect.DeepEqual(img.Pix, before) {
+		t.Fatal("PreprocessNone modified the image")
+	}
+
+	// A flat image has no range to stretch
+	flat := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	if applied := Preprocess(flat, PreprocessContrast); applied != nil {
+		t.Fatalf("applied = %v on flat image", applied)
+	}
+}
@@ -2,11 +2,15 @@ package screenshot
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"image/png"
+	"log"
 	"math"
+	"strings"
+	"time"
 
 	"github.com/kbinani/screenshot"
 )
@@ -23,6 +27,10 @@ type Region struct {
 	Polygon []Point
 }
 
+// ErrNoDisplay is returned when no active display can be captured, e.g. on a
+// headless machine or a platform the capture library doesn't support.
+var ErrNoDisplay = errors.New("no active displays found")
+
 type Point struct {
 	X int
 	Y int
@@ -36,7 +44,7 @@ func Init() {
 func Capture() (*image.RGBA, error) {
 	n := screenshot.NumActiveDisplays()
 	if n == 0 {
-		return nil, fmt.Errorf("no active displays found")
+		return nil, ErrNoDisplay
 	}
 	// Compute union of all display bounds
 	union := screenshot.GetDisplayBounds(0)
@@ -58,34 +66,81 @@ func CaptureRegion(region Region) ([]byte, error) {
 	if region.Width <= 0 || region.Height <= 0 {
 		return nil, fmt.Errorf("invalid region dimensions: width=%d, height=%d", region.Width, region.Height)
 	}
+	if screenshot.NumActiveDisplays() == 0 {
+		return nil, ErrNoDisplay
+	}
 
 	// Create bounds for the region
 	bounds := image.Rect(region.X, region.Y, region.X+region.Width, region.Y+region.Height)
 
 	// Capture the region
-	img, err := screenshot.CaptureRect(bounds)
+	img, err := captureNonBlank(bounds)
 	if err != nil {
-		return nil, fmt.Errorf("failed to capture region: %v", err)
+		return nil, err
 	}
 
 	if len(region.Polygon) >= 3 {
 		applyPolygonMask(img, region)
 	}
 
+	if autoCrop.Load() {
+		if cropped := AutoCrop(img, AutoCropTolerance); cropped != img {
+			log.Printf("Screenshot: Auto-cropped capture from %dx%d to %dx%d", img.Bounds().Dx(), img.Bounds().Dy(), cropped.Bounds().Dx(), cropped.Bounds().Dy())
+			img = cropped
+		}
+	}
+
+	applied := Preprocess(img, preprocessMode.Load().(string))
+
 	// Convert to PNG bytes
 	var buf bytes.Buffer
 	if err := png.Encode(&buf, img); err != nil {
 		return nil, fmt.Errorf("failed to encode image as PNG: %v", err)
 	}
 
+	if len(applied) > 0 {
+		log.Printf("Screenshot: Preprocessed %dx%d capture (%s): %d bytes", img.Bounds().Dx(), img.Bounds().Dy(), strings.Join(applied, "+"), buf.Len())
+	}
+
 	return buf.Bytes(), nil
 }
 
+// captureRect and sleep are replaced in tests.
+var (
+	captureRect = screenshot.CaptureRect
+	sleep       = time.Sleep
+)
+
+// captureNonBlank captures bounds, capturing once more after blankRetryDelay
+// if the first frame is blank. It returns ErrBlankCapture if both are.
+func captureNonBlank(bounds image.Rectangle) (*image.RGBA, error) {
+	img, err := captureRect(bounds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture region: %v", err)
+	}
+	if !IsBlank(img) {
+		return img, nil
+	}
+
+	log.Printf("Screenshot: Blank capture of %dx%d at (%d,%d), retrying in %v", bounds.Dx(), bounds.Dy(), bounds.Min.X, bounds.Min.Y, blankRetryDelay)
+	sleep(blankRetryDelay)
+	img, err = captureRect(bounds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture region: %v", err)
+	}
+	if IsBlank(img) {
+		log.Printf("Screenshot: Retry capture is blank too")
+		return nil, ErrBlankCapture
+	}
+	log.Printf("Screenshot: Retry capture succeeded")
+	return img, nil
+}
+
 // GetDisplayBounds returns the bounds of the primary display
 func GetDisplayBounds() (image.Rectangle, error) {
 	n := screenshot.NumActiveDisplays()
 	if n == 0 {
-		return image.Rectangle{}, fmt.Errorf("no active displays found")
+		return image.Rectangle{}, ErrNoDisplay
 	}
 
 	// Get bounds of the primary display (display 0)
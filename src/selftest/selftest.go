@@ -0,0 +1,175 @@
+// Package selftest checks each stage of the OCR pipeline in turn, the
+// configuration, the API key, a vision request with the bundled test image
+// and the clipboard, so that a failure can be pinned on one of them.
+package selftest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	screenocr "screen-ocr-llm"
+	"screen-ocr-llm/src/clipboard"
+	"screen-ocr-llm/src/config"
+	"screen-ocr-llm/src/llm"
+	"screen-ocr-llm/src/runtimeinit"
+)
+
+// Check names, in the order Run performs them.
+const (
+	CheckConfig    = "config"
+	CheckKey       = "key"
+	CheckAPI       = "api"
+	CheckClipboard = "clipboard"
+)
+
+// Result is the outcome of one check. A check that needs an earlier one that
+// failed is Skipped.
+type Result struct {
+	Check   string
+	Err     error
+	Skipped bool
+	// Detail is shown after a passed check, e.g. the model in use.
+	Detail string
+}
+
+// Report lists the results of Run in order.
+type Report []Result
+
+// Err returns an error naming the checks that failed or were skipped, or nil
+// when every check passed.
+func (r Report) Err() error {
+	var failed []string
+	for _, res := range r {
+		if res.Err != nil {
+			failed = append(failed, res.Check)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("selftest failed: %s", strings.Join(failed, ", "))
+}
+
+// Write prints the report as a checklist, one line per check.
+func (r Report) Write(w io.Writer) {
+	for _, res := range r {
+		switch {
+		case res.Skipped:
+			fmt.Fprintf(w, "[SKIP] %s: %v\n", res.Check, res.Err)
+		case res.Err != nil:
+			fmt.Fprintf(w, "[FAIL] %s: %v\n", res.Check, res.Err)
+		case res.Detail != "":
+			fmt.Fprintf(w, "[PASS] %s ok (%s)\n", res.Check, res.Detail)
+		default:
+			fmt.Fprintf(w, "[PASS] %s ok\n", res.Check)
+		}
+	}
+}
+
+// Stages of the pipeline, replaceable in tests.
+var (
+	loadConfig = config.LoadWithOptions
+	initLLM    = func(cfg *config.Config) error {
+		return llm.Init(runtimeinit.LLMConfig(cfg))
+	}
+	validateKey    = llm.ValidateKey
+	queryVision    = llm.QueryVision
+	initClipboard  = clipboard.Init
+	writeClipboard = clipboard.Write
+	readClipboard  = clipboard.ReadText
+)
+
+// Run loads the configuration, checks the API key, reads the bundled test
+// image with the configured model and round-trips a marker through the
+// clipboard. The key and API checks are skipped when an earlier one fails;
+// the clipboard check always runs. Text on the clipboard is put back
+// afterwards, but other contents, such as an image, are not.
+func Run(opts config.LoadOptions) Report {
+	cfg, res := checkConfig(opts)
+	report := Report{res}
+	if res.Err != nil {
+		report = append(report, skipped(CheckKey, CheckConfig), skipped(CheckAPI, CheckConfig))
+	} else if res = checkKey(cfg); res.Err != nil {
+		report = append(report, res, skipped(CheckAPI, CheckKey))
+	} else {
+		report = append(report, res, checkAPI())
+	}
+	return append(report, checkClipboard())
+}
+
+func skipped(check, failed string) Result {
+	return Result{Check: check, Skipped: true, Err: fmt.Errorf("skipped, %s check failed", failed)}
+}
+
+func checkConfig(opts config.LoadOptions) (*config.Config, Result) {
+	res := Result{Check: CheckConfig}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		res.Err = fmt.Errorf("failed to load configuration: %w", err)
+		return nil, res
+	}
+	if cfg.APIKey == "" {
+		res.Err = fmt.Errorf("OPENROUTER_API_KEY is required. Checked key file %s and OPENROUTER_API_KEY env var", cfg.APIKeyPath)
+		return nil, res
+	}
+	if err := cfg.Validate(nil); err != nil {
+		res.Err = err
+		return nil, res
+	}
+	res.Detail = "model " + cfg.Model
+	if cfg.LLMBackend == config.LLMBackendMock {
+		res.Detail += ", offline mock backend"
+	}
+	return cfg, res
+}
+
+func checkKey(cfg *config.Config) Result {
+	res := Result{Check: CheckKey}
+	if err := initLLM(cfg); err != nil {
+		res.Err = err
+	} else {
+		res.Err = validateKey()
+	}
+	return res
+}
+
+func checkAPI() Result {
+	res := Result{Check: CheckAPI}
+	start := time.Now()
+	text, err := queryVision(screenocr.TestImage)
+	if err == nil && text == "" {
+		err = llm.ErrNoTextFound
+	}
+	if err != nil {
+		res.Err = fmt.Errorf("reading the test image failed: %w", err)
+		return res
+	}
+	res.Detail = fmt.Sprintf("read %d characters in %v", len([]rune(text)), time.Since(start).Round(time.Millisecond))
+	return res
+}
+
+func checkClipboard() Result {
+	res := Result{Check: CheckClipboard}
+	if err := initClipboard(); err != nil {
+		res.Err = err
+		return res
+	}
+	previous := readClipboard()
+	marker := fmt.Sprintf("screen-ocr-llm selftest %d", time.Now().UnixNano())
+	if err := writeClipboard(marker); err != nil {
+		res.Err = err
+		return res
+	}
+	if got := readClipboard(); got != marker {
+		res.Err = errors.New("clipboard did not return the text written to it")
+	}
+	if previous != "" {
+		if err := writeClipboard(previous); err != nil && res.Err == nil {
+			res.Err = fmt.Errorf("restoring the previous clipboard text failed: %w", err)
+		}
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+package selftest
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"screen-ocr-llm/src/config"
+)
+
+// fakeClipboard replaces the clipboard stages with an in-memory string.
+func fakeClipboard(t *testing.T, contents string) *string {
+	t.Helper()
+	prevInit, prevWrite, prevRead := initClipboard, writeClipboard, readClipboard
+	t.Cleanup(func() { initClipboard, writeClipboard, readClipboard = prevInit, prevWrite, prevRead })
+	initClipboard = func() error { return nil }
+	writeClipboard = func(text string) error {
+		contents = text
+		return nil
+	}
+	readClipboard = func() string { return contents }
+	return &contents
+}
+
+// mockEnv writes a .env selecting the offline mock backend.
+func mockEnv(t *testing.T) config.LoadOptions {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("LLM_BACKEND=mock\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return config.LoadOptions{ConfigPath: path}
+}
+
+func TestRunWithMockBackend(t *testing.T) {
+	contents := fakeClipboard(t, "user text")
+
+	report := Run(mockEnv(t))
+	var out bytes.Buffer
+	report.Write(&out)
+	if report.Err() != nil {
+		t.Fatalf("expected every check to pass:\n%s", out.String())
+	}
+	for i, check := range []string{CheckConfig, CheckKey, CheckAPI, CheckClipboard} {
+		if report[i].Check != check {
+			t.Fatalf("check %d is %q, want %q", i, report[i].Check, check)
+		}
+	}
+	if !strings.Contains(out.String(), "[PASS] config ok (model mock") || !strings.Contains(out.String(), "[PASS] clipboard ok\n") {
+		t.Fatalf("unexpected checklist:\n%s", out.String())
+	}
+	if *contents != "user text" {
+		t.Fatalf("clipboard text not restored: %q", *contents)
+	}
+}
+
+func TestRunSkipsAfterFailure(t *testing.T) {
+	fakeClipboard(t, "")
+	prevLoad, prevKey := loadConfig, validateKey
+	t.Cleanup(func() { loadConfig, validateKey = prevLoad, prevKey })
+
+	loadConfig = func(config.LoadOptions) (*config.Config, error) { return nil, errors.New("bad .env") }
+	report := Run(config.LoadOptions{})
+	if err := report.Err(); err == nil || err.Error() != "selftest failed: config, key, api" {
+		t.Fatalf("Err() = %v", err)
+	}
+	if report[0].Err == nil || !report[1].Skipped || !report[2].Skipped || report[3].Err != nil {
+		t.Fatalf("unexpected report after a config failure: %+v", report)
+	}
+
+	loadConfig = prevLoad
+	validateKey = func() error { return errors.New("invalid API key") }
+	report = Run(mockEnv(t))
+	var out bytes.Buffer
+	report.Write(&out)
+	if report.Err() == nil || !strings.Contains(out.String(), "[FAIL] key: invalid API key\n[SKIP] api: skipped, key check failed\n") {
+		t.Fatalf("unexpected checklist after a key failure:\n%s", out.String())
+	}
+}
+
+func TestCheckClipboardDetectsMismatch(t *testing.T) {
+	fakeClipboard(t, "")
+	readClipboard = func() string { return "" }
+	if res := checkClipboard(); res.Err == nil {
+		t.Fatal("expected an error when the clipboard does not keep the text")
+	}
+}
@@ -34,11 +34,16 @@ type PopupController interface {
 }
 
 type Options struct {
-	Deadline               time.Duration
-	SelectRegion           RegionSelectorFunc
-	Recognize              RecognizeFunc
-	Target                 ResultTarget
-	Popup                  PopupController
+	Deadline     time.Duration
+	SelectRegion RegionSelectorFunc
+	Recognize    RecognizeFunc
+	Target       ResultTarget
+	Popup        PopupController
+	// SuccessVisibleDuration keeps Execute from returning for this long
+	// after a successful result was delivered and shown in the popup, so a
+	// run-once process does not take its popup down with it. The target
+	// (e.g. stdout) has already received the text by then. Cancelling ctx
+	// ends the wait early.
 	SuccessVisibleDuration time.Duration
 }
 
@@ -56,6 +61,7 @@ func Execute(ctx context.Context, opts Options) (Result, error) {
 
 	region, cancelled, err := opts.SelectRegion(ctx)
 	if err != nil {
+		err = &SelectionError{Err: err}
 		_ = opts.Target.OnFailure(err)
 		return Result{}, err
 	}
@@ -71,7 +77,7 @@ func Execute(ctx context.Context, opts Options) (Result, error) {
 
 	recognize := opts.Recognize
 	if recognize == nil {
-		recognize = recognizeWithContext
+		recognize = ocr.RecognizeContext
 	}
 
 	p := opts.Popup
@@ -96,6 +102,7 @@ func Execute(ctx context.Context, opts Options) (Result, error) {
 	}
 
 	if err := opts.Target.OnSuccess(text); err != nil {
+		err = &OutputError{Err: err}
 		_ = p.Close()
 		_ = opts.Target.OnFailure(err)
 		return Result{}, err
@@ -104,7 +111,12 @@ func Execute(ctx context.Context, opts Options) (Result, error) {
 	_ = p.UpdateText(text)
 
 	if opts.SuccessVisibleDuration > 0 {
-		time.Sleep(opts.SuccessVisibleDuration)
+		timer := time.NewTimer(opts.SuccessVisibleDuration)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
 	}
 
 	return Result{Text: text}, nil
@@ -124,10 +136,21 @@ func (defaultPopupController) Close() error {
 	return popup.Close()
 }
 
-type ClipboardTarget struct{}
+// ClipboardTarget copies the result to the clipboard. With Append set it is
+// added to the end of the current clipboard text instead of replacing it.
+type ClipboardTarget struct {
+	Append bool
+}
 
-func (ClipboardTarget) OnSuccess(text string) error {
-	return clipboard.Write(text)
+func (t ClipboardTarget) OnSuccess(text string) error {
+	write := clipboard.Write
+	if t.Append {
+		write = clipboard.Append
+	}
+	if err := write(text); err != nil {
+		return &ClipboardError{Err: err}
+	}
+	return nil
 }
 
 func (ClipboardTarget) OnFailure(err error) error {
@@ -151,9 +174,19 @@ func (t StdoutTarget) OnFailure(err error) error {
 	return nil
 }
 
+// replier is a sink whose delivery leaves the delegating client something to
+// do, such as moving a staged file into place; DelegatedTarget sends Reply in
+// the SUCCESS response.
+type replier interface {
+	Reply() string
+}
+
 type DelegatedTarget struct {
 	Conn           singleinstance.Conn
 	OutputToStdout bool
+	// Sink receives the result when OutputToStdout is false.
+	// If nil, the result is written to the clipboard.
+	Sink ResultTarget
 }
 
 func (t DelegatedTarget) OnSuccess(text string) error {
@@ -163,8 +196,17 @@ func (t DelegatedTarget) OnSuccess(text string) error {
 	if t.OutputToStdout {
 		return t.Conn.RespondSuccess(text)
 	}
+	if t.Sink != nil {
+		if err := t.Sink.OnSuccess(text); err != nil {
+			return err
+		}
+		if r, ok := t.Sink.(replier); ok {
+			return t.Conn.RespondSuccess(r.Reply())
+		}
+		return t.Conn.RespondSuccess("")
+	}
 	if err := clipboard.Write(text); err != nil {
-		return fmt.Errorf("clipboard error: %w", err)
+		return &ClipboardError{Err: fmt.Errorf("clipboard error: %w", err)}
 	}
 	return t.Conn.RespondSuccess("")
 }
@@ -174,29 +216,7 @@ func (t DelegatedTarget) OnFailure(err error) error {
 		return nil
 	}
 	if err == nil {
-		return t.Conn.RespondError("unknown session error")
-	}
-	return t.Conn.RespondError(err.Error())
-}
-
-func recognizeWithContext(ctx context.Context, region screenshot.Region) (string, error) {
-	resCh := make(chan struct {
-		text string
-		err  error
-	}, 1)
-
-	go func() {
-		text, err := ocr.Recognize(region)
-		resCh <- struct {
-			text string
-			err  error
-		}{text: text, err: err}
-	}()
-
-	select {
-	case r := <-resCh:
-		return r.text, r.err
-	case <-ctx.Done():
-		return "", ctx.Err()
+		return t.Conn.RespondError("", "unknown session error")
 	}
+	return t.Conn.RespondError(FailureStage(err), err.Error())
 }
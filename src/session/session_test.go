@@ -0,0 +1,227 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"screen-ocr-llm/src/ocr"
+	"screen-ocr-llm/src/screenshot"
+	"screen-ocr-llm/src/singleinstance"
+)
+
+type nopPopup struct{ shown string }
+
+func (p *nopPopup) StartCountdown(int) error     { return nil }
+func (p *nopPopup) UpdateText(text string) error { p.shown = text; return nil }
+func (p *nopPopup) Close() error                 { return nil }
+
+// shownPopup closes updated once the result is on the popup, which Execute
+// updates after delivering it to the target.
+type shownPopup struct {
+	nopPopup
+	updated chan struct{}
+}
+
+func (p *shownPopup) UpdateText(text string) error {
+	p.nopPopup.UpdateText(text)
+	close(p.updated)
+	return nil
+}
+
+func testOptions(target ResultTarget, p PopupController, visible time.Duration) Options {
+	return Options{
+		SelectRegion: func(context.Context) (screenshot.Region, bool, error) {
+			return screenshot.Region{Width: 10, Height: 10}, false, nil
+		},
+		Recognize: func(context.Context, screenshot.Region) (string, error) {
+			return "hello", nil
+		},
+		Target:                 target,
+		Popup:                  p,
+		SuccessVisibleDuration: visible,
+	}
+}
+
+func TestExecuteDeliversBeforeSuccessVisibleWait(t *testing.T) {
+	var out bytes.Buffer
+	p := &shownPopup{updated: make(chan struct{})}
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := Execute(context.Background(), testOptions(StdoutTarget{Writer: &out}, p, 200*time.Millisecond)); err != nil {
+			t.Errorf("Execute returned error: %v", err)
+		}
+	}()
+
+	select {
+	case <-p.updated:
+	case <-done:
+		t.Fatal("Execute returned without showing the result")
+	}
+	select {
+	case <-done:
+		t.Fatal("Execute returned before SuccessVisibleDuration elapsed")
+	default:
+	}
+	if out.String() != "hello" || p.shown != "hello" {
+		t.Fatalf("result not delivered before the wait: stdout %q, popup %q", out.String(), p.shown)
+	}
+	<-done
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("Execute returned after %v, want at least 200ms", elapsed)
+	}
+}
+
+func TestExecuteSuccessVisibleWaitEndsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	target := &fakeTarget{}
+	opts := testOptions(target, &nopPopup{}, time.Hour)
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	res, err := Execute(ctx, opts)
+	if err != nil || res.Text != "hello" || target.text != "hello" {
+		t.Fatalf("Execute = %+v, %v (target got %q)", res, err, target.text)
+	}
+}
+
+func TestExecuteCancelledSelectionSkipsRecognition(t *testing.T) {
+	target := &fakeTarget{}
+	opts := testOptions(target, &nopPopup{}, 0)
+	opts.SelectRegion = func(context.Context) (screenshot.Region, bool, error) {
+		return screenshot.Region{}, true, nil
+	}
+	opts.Recognize = func(context.Context, screenshot.Region) (string, error) {
+		t.Fatal("Recognize called after a cancelled selection")
+		return "", nil
+	}
+
+	if _, err := Execute(context.Background(), opts); !errors.Is(err, ErrSelectionCancelled) {
+		t.Fatalf("Execute error = %v, want ErrSelectionCancelled", err)
+	}
+	if target.text != "" {
+		t.Fatalf("target got %q after a cancelled selection", target.text)
+	}
+}
+
+type fakeConn struct {
+	success []string
+	errors  []string
+	stages  []string
+}
+
+func (c *fakeConn) Request() singleinstance.Request { return singleinstance.Request{} }
+func (c *fakeConn) RespondSuccess(text string) error {
+	c.success = append(c.success, text)
+	return nil
+}
+func (c *fakeConn) RespondError(stage, msg string) error {
+	c.errors = append(c.errors, msg)
+	c.stages = append(c.stages, stage)
+	return nil
+}
+func (c *fakeConn) RespondBusy() error { return nil }
+func (c *fakeConn) Close() error       { return nil }
+
+func TestDelegatedTargetResponses(t *testing.T) {
+	t.Run("stdout returns the text", func(t *testing.T) {
+		conn := &fakeConn{}
+		if err := (DelegatedTarget{Conn: conn, OutputToStdout: true}).OnSuccess("hello"); err != nil {
+			t.Fatalf("OnSuccess returned error: %v", err)
+		}
+		if len(conn.success) != 1 || conn.success[0] != "hello" || len(conn.errors) != 0 {
+			t.Fatalf("responses = %q / %q", conn.success, conn.errors)
+		}
+	})
+
+	t.Run("sink gets the text, client an empty success", func(t *testing.T) {
+		conn := &fakeConn{}
+		sink := &fakeTarget{}
+		if err := (DelegatedTarget{Conn: conn, Sink: sink}).OnSuccess("hello"); err != nil {
+			t.Fatalf("OnSuccess returned error: %v", err)
+		}
+		if sink.text != "hello" || len(conn.success) != 1 || conn.success[0] != "" {
+			t.Fatalf("sink %q, responses %q", sink.text, conn.success)
+		}
+	})
+
+	t.Run("sink failure is returned unanswered", func(t *testing.T) {
+		conn := &fakeConn{}
+		sinkErr := errors.New("disk full")
+		err := (DelegatedTarget{Conn: conn, Sink: &fakeTarget{err: sinkErr}}).OnSuccess("hello")
+		if !errors.Is(err, sinkErr) || len(conn.success) != 0 {
+			t.Fatalf("OnSuccess = %v, responses %q", err, conn.success)
+		}
+	})
+
+	t.Run("failure is sent as error message", func(t *testing.T) {
+		conn := &fakeConn{}
+		target := DelegatedTarget{Conn: conn}
+		_ = target.OnFailure(ErrSelectionCancelled)
+		_ = target.OnFailure(nil)
+		if len(conn.errors) != 2 || conn.errors[0] != "selection cancelled" || conn.errors[1] != "unknown session error" {
+			t.Fatalf("errors = %q", conn.errors)
+		}
+		if conn.stages[0] != singleinstance.StageRegionCancelled || conn.stages[1] != "" {
+			t.Fatalf("stages = %q", conn.stages)
+		}
+	})
+
+	t.Run("missing connection", func(t *testing.T) {
+		if err := (DelegatedTarget{}).OnSuccess("hello"); err == nil {
+			t.Fatal("expected error without connection")
+		}
+		if err := (DelegatedTarget{}).OnFailure(errors.New("x")); err != nil {
+			t.Fatalf("OnFailure without connection = %v", err)
+		}
+	})
+}
+
+func TestExecuteDeliveryFailureAnswersDelegatedClient(t *testing.T) {
+	conn := &fakeConn{}
+	target := DelegatedTarget{Conn: conn, Sink: &fakeTarget{err: errors.New("disk full")}}
+	if _, err := Execute(context.Background(), testOptions(target, &nopPopup{}, 0)); err == nil {
+		t.Fatal("expected delivery error")
+	}
+	if len(conn.success) != 0 || len(conn.errors) != 1 || conn.errors[0] != "disk full" {
+		t.Fatalf("responses = %q / %q", conn.success, conn.errors)
+	}
+	if conn.stages[0] != singleinstance.StageOutputFailed {
+		t.Fatalf("stage = %q, want %q", conn.stages[0], singleinstance.StageOutputFailed)
+	}
+}
+
+func TestFailureStage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"cancelled", ErrSelectionCancelled, singleinstance.StageRegionCancelled},
+		{"no text", fmt.Errorf("OCR failed: %w", ocr.ErrNoTextFound), singleinstance.StageNoTextFound},
+		{"selection", &SelectionError{Err: errors.New("no window")}, singleinstance.StageCaptureFailed},
+		{"capture", &ocr.CaptureError{Err: screenshot.ErrBlankCapture}, singleinstance.StageCaptureFailed},
+		{"clipboard", &OutputError{Err: &ClipboardError{Err: errors.New("locked")}}, singleinstance.StageClipboardFailed},
+		{"file", &OutputError{Err: errors.New("open out.txt: permission denied")}, singleinstance.StageOutputFailed},
+		{"clipboard sink of several", &OutputError{Err: &DeliveryError{
+			Delivered: []string{SinkFilePrefix + "x"},
+			Failed:    []SinkFailure{{Sink: SinkClipboard, Err: &ClipboardError{Err: errors.New("locked")}}},
+		}}, singleinstance.StageClipboardFailed},
+		{"file sink beside clipboard", &OutputError{Err: &DeliveryError{
+			Delivered: []string{SinkClipboard},
+			Failed:    []SinkFailure{{Sink: SinkFilePrefix + "x", Err: errors.New("permission denied")}},
+		}}, singleinstance.StageOutputFailed},
+		{"api", errors.New("API request failed with status 500"), singleinstance.StageAPIFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FailureStage(tt.err); got != tt.want {
+				t.Errorf("FailureStage(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"screen-ocr-llm/src/keyboard"
+	"screen-ocr-llm/src/singleinstance"
+)
+
+// Sink names accepted in OUTPUT_SINK specs. File sinks are written as
+// "file:<path>"; several sinks are joined with "+".
+const (
+	SinkClipboard       = "clipboard"
+	SinkClipboardAppend = "clipboard-append"
+	SinkStdout          = "stdout"
+	SinkType            = "type"
+	SinkFilePrefix      = "file:"
+)
+
+// NamedTarget pairs a ResultTarget with the sink name it was built from so
+// that failures can be reported per sink.
+type NamedTarget struct {
+	Name   string
+	Target ResultTarget
+}
+
+// FileTarget appends each result to a file, one result per line. With
+// Overwrite set, the file is replaced by the latest result instead.
+type FileTarget struct {
+	Path      string
+	Overwrite bool
+}
+
+func (t FileTarget) OnSuccess(text string) error {
+	flags := os.O_CREATE | os.O_APPEND | os.O_WRONLY
+	if t.Overwrite {
+		flags = os.O_CREATE | os.O_TRUNC | os.O_WRONLY
+	}
+	f, err := os.OpenFile(t.Path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, text); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (FileTarget) OnFailure(err error) error {
+	return nil
+}
+
+// StagedFileTarget writes the result for a delegated FILE request to a new
+// temporary file next to Path, which the client then moves into place. The
+// resident thus never truncates or replaces an existing file. Staged is the
+// temporary file once written.
+type StagedFileTarget struct {
+	Path   string
+	Staged string
+}
+
+func (t *StagedFileTarget) OnSuccess(text string) error {
+	f, err := os.CreateTemp(filepath.Dir(t.Path), singleinstance.StagedFilePattern)
+	if err != nil {
+		return err
+	}
+	_ = f.Chmod(0o644)
+	if _, err := fmt.Fprintln(f, text); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	t.Staged = f.Name()
+	return nil
+}
+
+func (*StagedFileTarget) OnFailure(err error) error {
+	return nil
+}
+
+// Reply returns the staged file's path for the delegating client.
+func (t *StagedFileTarget) Reply() string {
+	return t.Staged
+}
+
+// TypeTarget types the result into whatever window has the keyboard focus,
+// for applications that do not accept a paste. Windows only.
+type TypeTarget struct{}
+
+func (TypeTarget) OnSuccess(text string) error {
+	log.Printf("Typing %d characters into the focused window", len([]rune(text)))
+	return keyboard.Type(text)
+}
+
+func (TypeTarget) OnFailure(err error) error {
+	return nil
+}
+
+// CompositeTarget delivers a result to every sink, even when an earlier sink
+// fails. If any sink fails, OnSuccess returns a *DeliveryError.
+type CompositeTarget struct {
+	Sinks []NamedTarget
+}
+
+func (t CompositeTarget) OnSuccess(text string) error {
+	var delivered []string
+	var failed []SinkFailure
+	for _, s := range t.Sinks {
+		if err := s.Target.OnSuccess(text); err != nil {
+			failed = append(failed, SinkFailure{Sink: s.Name, Err: err})
+			continue
+		}
+		delivered = append(delivered, s.Name)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &DeliveryError{Delivered: delivered, Failed: failed}
+}
+
+func (t CompositeTarget) OnFailure(err error) error {
+	var firstErr error
+	for _, s := range t.Sinks {
+		if ferr := s.Target.OnFailure(err); ferr != nil && firstErr == nil {
+			firstErr = ferr
+		}
+	}
+	return firstErr
+}
+
+// SinkFailure records the error returned by a single sink.
+type SinkFailure struct {
+	Sink string
+	Err  error
+}
+
+// DeliveryError reports which sinks received a result and which did not,
+// e.g. "copied to clipboard but failed to write log.txt: permission denied".
+type DeliveryError struct {
+	Delivered []string
+	Failed    []SinkFailure
+}
+
+func (e *DeliveryError) Error() string {
+	failures := make([]string, 0, len(e.Failed))
+	for _, f := range e.Failed {
+		failures = append(failures, fmt.Sprintf("%s: %v", describeSinkFailure(f.Sink), f.Err))
+	}
+	if len(e.Delivered) == 0 {
+		return strings.Join(failures, "; ")
+	}
+
+	successes := make([]string, 0, len(e.Delivered))
+	for _, name := range e.Delivered {
+		successes = append(successes, describeSinkSuccess(name))
+	}
+	return strings.Join(successes, " and ") + " but " + strings.Join(failures, "; ")
+}
+
+func (e *DeliveryError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, f := range e.Failed {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// ParseSink builds a ResultTarget from an OUTPUT_SINK spec such as
+// "clipboard" or "clipboard+file:log.txt". A spec naming more than one sink
+// yields a CompositeTarget.
+func ParseSink(spec string) (ResultTarget, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return ClipboardTarget{}, nil
+	}
+
+	var sinks []NamedTarget
+	for _, part := range strings.Split(spec, "+") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.EqualFold(part, SinkClipboard):
+			sinks = append(sinks, NamedTarget{Name: SinkClipboard, Target: ClipboardTarget{}})
+		case strings.EqualFold(part, SinkClipboardAppend):
+			sinks = append(sinks, NamedTarget{Name: SinkClipboardAppend, Target: ClipboardTarget{Append: true}})
+		case strings.EqualFold(part, SinkStdout):
+			sinks = append(sinks, NamedTarget{Name: SinkStdout, Target: StdoutTarget{}})
+		case strings.EqualFold(part, SinkType):
+			sinks = append(sinks, NamedTarget{Name: SinkType, Target: TypeTarget{}})
+		case len(part) > len(SinkFilePrefix) && strings.EqualFold(part[:len(SinkFilePrefix)], SinkFilePrefix):
+			path := strings.TrimSpace(part[len(SinkFilePrefix):])
+			if path == "" {
+				return nil, fmt.Errorf("output sink %q: missing file path", part)
+			}
+			sinks = append(sinks, NamedTarget{Name: SinkFilePrefix + path, Target: FileTarget{Path: path}})
+		default:
+			return nil, fmt.Errorf("unknown output sink %q", part)
+		}
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0].Target, nil
+	}
+	return CompositeTarget{Sinks: sinks}, nil
+}
+
+// WithClipboardAppend rewrites the clipboard sink in an OUTPUT_SINK spec to
+// clipboard-append (CLIPBOARD_APPEND). An empty spec means the clipboard.
+func WithClipboardAppend(spec string) string {
+	if strings.TrimSpace(spec) == "" {
+		return SinkClipboardAppend
+	}
+	parts := strings.Split(spec, "+")
+	for i, part := range parts {
+		if strings.EqualFold(strings.TrimSpace(part), SinkClipboard) {
+			parts[i] = SinkClipboardAppend
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+func describeSinkSuccess(name string) string {
+	switch {
+	case name == SinkClipboard:
+		return "copied to clipboard"
+	case name == SinkClipboardAppend:
+		return "appended to clipboard"
+	case name == SinkStdout:
+		return "wrote to stdout"
+	case name == SinkType:
+		return "typed into the focused window"
+	case strings.HasPrefix(name, SinkFilePrefix):
+		return "wrote " + strings.TrimPrefix(name, SinkFilePrefix)
+	default:
+		return "delivered to " + name
+	}
+}
+
+func describeSinkFailure(name string) string {
+	switch {
+	case name == SinkClipboard, name == SinkClipboardAppend:
+		return "failed to copy to clipboard"
+	case name == SinkStdout:
+		return "failed to write to stdout"
+	case name == SinkType:
+		return "failed to type into the focused window"
+	case strings.HasPrefix(name, SinkFilePrefix):
+		return "failed to write " + strings.TrimPrefix(name, SinkFilePrefix)
+	default:
+		return "failed to deliver to " + name
+	}
+}
+
+// IsDeliveryError reports whether err carries per-sink delivery results.
+func IsDeliveryError(err error) bool {
+	var derr *DeliveryError
+	return errors.As(err, &derr)
+}
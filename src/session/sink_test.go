@@ -0,0 +1,192 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeTarget struct {
+	err  error
+	text string
+}
+
+func (t *fakeTarget) OnSuccess(text string) error {
+	t.text = text
+	return t.err
+}
+
+func (t *fakeTarget) OnFailure(err error) error { return nil }
+
+func TestParseSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+		wantN   int
+	}{
+		{name: "empty defaults to clipboard", spec: "", wantN: 1},
+		{name: "clipboard", spec: "clipboard", wantN: 1},
+		{name: "clipboard plus file", spec: "clipboard+file:log.txt", wantN: 2},
+		{name: "clipboard append", spec: "Clipboard-Append", wantN: 1},
+		{name: "type plus file", spec: "Type+file:log.txt", wantN: 2},
+		{name: "case insensitive", spec: " Clipboard + FILE:out.txt ", wantN: 2},
+		{name: "missing file path", spec: "clipboard+file:", wantErr: true},
+		{name: "unknown sink", spec: "clipboard+printer", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := ParseSink(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSink(%q) expected error", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSink(%q) unexpected error: %v", tt.spec, err)
+			}
+			n := 1
+			if c, ok := target.(CompositeTarget); ok {
+				n = len(c.Sinks)
+			}
+			if n != tt.wantN {
+				t.Fatalf("ParseSink(%q) sinks = %d, want %d", tt.spec, n, tt.wantN)
+			}
+		})
+	}
+}
+
+func TestWithClipboardAppend(t *testing.T) {
+	tests := map[string]string{
+		"":                       "clipboard-append",
+		"clipboard":              "clipboard-append",
+		"Clipboard+file:log.txt": "clipboard-append+file:log.txt",
+		"stdout":                 "stdout",
+	}
+	for spec, want := range tests {
+		if got := WithClipboardAppend(spec); got != want {
+			t.Errorf("WithClipboardAppend(%q) = %q, want %q", spec, got, want)
+		}
+	}
+	target, err := ParseSink(WithClipboardAppend("clipboard"))
+	if err != nil {
+		t.Fatalf("ParseSink: %v", err)
+	}
+	if target != (ClipboardTarget{Append: true}) {
+		t.Fatalf("ParseSink(clipboard-append) = %#v, want appending ClipboardTarget", target)
+	}
+}
+
+func TestParseSinkType(t *testing.T) {
+	if got := WithClipboardAppend("type"); got != "type" {
+		t.Fatalf("WithClipboardAppend(type) = %q", got)
+	}
+	target, err := ParseSink("type")
+	if err != nil || target != (TypeTarget{}) {
+		t.Fatalf("ParseSink(type) = %#v, %v; want TypeTarget", target, err)
+	}
+	derr := &DeliveryError{Delivered: []string{SinkType}, Failed: []SinkFailure{{Sink: SinkFilePrefix + "log.txt", Err: errors.New("disk full")}}}
+	if want := "typed into the focused window but failed to write log.txt: disk full"; derr.Error() != want {
+		t.Fatalf("error = %q, want %q", derr.Error(), want)
+	}
+}
+
+func TestFileTargetAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	target := FileTarget{Path: path}
+
+	if err := target.OnSuccess("first"); err != nil {
+		t.Fatalf("OnSuccess failed: %v", err)
+	}
+	if err := target.OnSuccess("second"); err != nil {
+		t.Fatalf("OnSuccess failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if got := string(data); got != "first\nsecond\n" {
+		t.Fatalf("file content = %q", got)
+	}
+}
+
+func TestFileTargetOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	target := FileTarget{Path: path, Overwrite: true}
+
+	for _, text := range []string{"first", "second"} {
+		if err := target.OnSuccess(text); err != nil {
+			t.Fatalf("OnSuccess failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if got := string(data); got != "second\n" {
+		t.Fatalf("file content = %q", got)
+	}
+}
+
+func TestStagedFileTargetLeavesExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	conn := &fakeConn{}
+	target := DelegatedTarget{Conn: conn, Sink: &StagedFileTarget{Path: path}}
+
+	if err := target.OnSuccess("new"); err != nil {
+		t.Fatalf("OnSuccess failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old\n" {
+		t.Fatalf("existing file changed to %q", data)
+	}
+	if len(conn.success) != 1 || filepath.Dir(conn.success[0]) != filepath.Dir(path) {
+		t.Fatalf("SUCCESS replies = %q, want the staged file next to %s", conn.success, path)
+	}
+	if data, _ := os.ReadFile(conn.success[0]); string(data) != "new\n" {
+		t.Fatalf("staged file content = %q", data)
+	}
+}
+
+func TestCompositeTargetReportsPartialFailure(t *testing.T) {
+	clip := &fakeTarget{}
+	file := &fakeTarget{err: errors.New("permission denied")}
+	target := CompositeTarget{Sinks: []NamedTarget{
+		{Name: SinkClipboard, Target: clip},
+		{Name: SinkFilePrefix + "log.txt", Target: file},
+	}}
+
+	err := target.OnSuccess("hello")
+	if err == nil {
+		t.Fatal("expected partial delivery error")
+	}
+	if clip.text != "hello" || file.text != "hello" {
+		t.Fatalf("expected every sink to receive the result, got clip=%q file=%q", clip.text, file.text)
+	}
+
+	var derr *DeliveryError
+	if !errors.As(err, &derr) {
+		t.Fatalf("expected *DeliveryError, got %T", err)
+	}
+	want := "copied to clipboard but failed to write log.txt: permission denied"
+	if err.Error() != want {
+		t.Fatalf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestCompositeTargetAllSucceed(t *testing.T) {
+	target := CompositeTarget{Sinks: []NamedTarget{
+		{Name: SinkClipboard, Target: &fakeTarget{}},
+		{Name: SinkStdout, Target: &fakeTarget{}},
+	}}
+	if err := target.OnSuccess("ok"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"errors"
+
+	"screen-ocr-llm/src/ocr"
+	"screen-ocr-llm/src/singleinstance"
+)
+
+// SelectionError is returned by Execute when the region could not be
+// selected, e.g. the overlay failed to start.
+type SelectionError struct {
+	Err error
+}
+
+func (e *SelectionError) Error() string { return e.Err.Error() }
+
+func (e *SelectionError) Unwrap() error { return e.Err }
+
+// OutputError marks a failure to deliver a result, so that FailureStage can
+// tell it apart from a failed OCR request.
+type OutputError struct {
+	Err error
+}
+
+func (e *OutputError) Error() string { return e.Err.Error() }
+
+func (e *OutputError) Unwrap() error { return e.Err }
+
+// ClipboardError is returned by the clipboard sinks, so that FailureStage can
+// tell a failed clipboard write from a failure of another sink.
+type ClipboardError struct {
+	Err error
+}
+
+func (e *ClipboardError) Error() string { return e.Err.Error() }
+
+func (e *ClipboardError) Unwrap() error { return e.Err }
+
+// FailureStage classifies a capture failure as one of the singleinstance
+// Stage constants. Anything not recognised is a failed OCR request.
+func FailureStage(err error) string {
+	var outErr *OutputError
+	var clipErr *ClipboardError
+	var selErr *SelectionError
+	var captureErr *ocr.CaptureError
+	switch {
+	case errors.Is(err, ocr.ErrNoTextFound):
+		return singleinstance.StageNoTextFound
+	case errors.Is(err, ErrSelectionCancelled):
+		return singleinstance.StageRegionCancelled
+	case errors.As(err, &outErr):
+		if errors.As(err, &clipErr) {
+			return singleinstance.StageClipboardFailed
+		}
+		return singleinstance.StageOutputFailed
+	case errors.As(err, &selErr), errors.As(err, &captureErr):
+		return singleinstance.StageCaptureFailed
+	}
+	return singleinstance.StageAPIFailed
+}
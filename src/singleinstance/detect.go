@@ -8,11 +8,14 @@ import (
 	"time"
 )
 
+// pingTimeout bounds a single PING probe while scanning the port range.
+const pingTimeout = 300 * time.Millisecond
+
 // DetectResidentPort scans the port range and returns (port, true) if a resident responds to PING.
 func DetectResidentPort(ctx context.Context) (int, bool) {
-	deadline := 300 * time.Millisecond
+	deadline := pingTimeout
 	if dl, ok := ctx.Deadline(); ok {
-		if d := time.Until(dl); d > 0 {
+		if d := time.Until(dl); d > 0 && d < deadline {
 			deadline = d
 		}
 	}
@@ -4,11 +4,14 @@ package singleinstance
 
 import (
 	"context"
+	"fmt"
+	"strings"
 )
 
 // Server owns the TCP endpoint and answers run-once requests.
 type Server interface {
-	// Start begins listening on first available port in [49500,49550] and accepting client requests.
+	// Start begins listening on the first available port in the configured range
+	// (SINGLEINSTANCE_PORT_START..END, default 49500-49550) and accepting client requests.
 	Start(ctx context.Context) error
 	// Port returns the bound TCP port, or 0 if not started.
 	Port() int
@@ -24,22 +27,113 @@ type Conn interface {
 	Request() Request
 	// RespondSuccess sends success. For stdout mode, send text; for clipboard mode, send empty text.
 	RespondSuccess(text string) error
-	// RespondError sends an error with human-readable message.
-	RespondError(msg string) error
+	// RespondError sends an error with its failure stage (one of the Stage
+	// constants, or "" if unknown) and a human-readable message.
+	RespondError(stage, msg string) error
+	// RespondBusy tells the client the resident is already processing a
+	// request; the client reports it as ErrResidentBusy.
+	RespondBusy() error
 	// Close closes the underlying connection.
 	Close() error
 }
 
+// Failure stages sent with an ERROR response. They are the stages a
+// run-once reports with --error-json, so a delegated run-once can report the
+// resident's failure as its own.
+const (
+	StageConfigFailed    = "config-failed"
+	StageRegionCancelled = "region-cancelled"
+	StageCaptureFailed   = "capture-failed"
+	StageAPIFailed       = "api-failed"
+	StageClipboardFailed = "clipboard-failed"
+	StageOutputFailed    = "output-failed"
+	StageResidentBusy    = "resident-busy"
+	StageNoTextFound     = "no-text-found"
+)
+
+// Output selects where a run-once result is delivered.
+type Output int
+
+const (
+	// OutputClipboard writes the result on the resident side (clipboard or OUTPUT_SINK).
+	OutputClipboard Output = iota
+	// OutputStdout returns the result text to the client.
+	OutputStdout
+	// OutputFile writes the result to Request.OutputFile.
+	OutputFile
+	// OutputType types the result into the focused window on the resident
+	// side.
+	OutputType
+)
+
+func (o Output) String() string {
+	switch o {
+	case OutputStdout:
+		return "stdout"
+	case OutputFile:
+		return "file"
+	case OutputType:
+		return "type"
+	default:
+		return "clipboard"
+	}
+}
+
+// ParseOutput parses an output name: clipboard, stdout, file or type.
+func ParseOutput(s string) (Output, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "clipboard":
+		return OutputClipboard, nil
+	case "stdout":
+		return OutputStdout, nil
+	case "file":
+		return OutputFile, nil
+	case "type":
+		return OutputType, nil
+	}
+	return OutputClipboard, fmt.Errorf("invalid output %q (expected clipboard|stdout|file|type)", s)
+}
+
+// LegacyOutput maps the original two-mode stdout flag onto Output.
+func LegacyOutput(outputToStdout bool) Output {
+	if outputToStdout {
+		return OutputStdout
+	}
+	return OutputClipboard
+}
+
 // Request represents a single run-once client request.
 type Request struct {
+	// OutputToStdout mirrors Output == OutputStdout for existing callers.
 	OutputToStdout bool
+	Output         Output
+	// OutputFile is the destination path when Output is OutputFile.
+	OutputFile string
+}
+
+// StagedFilePattern names the temporary file, in the destination's directory,
+// that a resident writes a FILE result to. The client moves it into place, so
+// the resident never replaces a file on another process's behalf.
+const StagedFilePattern = ".screen-ocr-*.tmp"
+
+// NewRequest builds a Request for the given output. file is only used for OutputFile.
+func NewRequest(output Output, file string) Request {
+	req := Request{Output: output, OutputToStdout: output == OutputStdout}
+	if output == OutputFile {
+		req.OutputFile = file
+	}
+	return req
 }
 
 // Client attempts to delegate run-once invocation to a resident server.
 type Client interface {
 	// TryRunOnce scans TCP range [49500,49550], performs handshake, and delegates to resident.
 	// If no resident is found, returns delegated=false, err=nil.
-	TryRunOnce(ctx context.Context, outputToStdout bool) (delegated bool, text string, err error)
+	// The returned text is only populated for OutputStdout requests; for
+	// OutputFile the client moves the file the resident staged to
+	// req.OutputFile. A resident that is already processing a request yields
+	// err=ErrResidentBusy.
+	TryRunOnce(ctx context.Context, req Request) (delegated bool, text string, err error)
 }
 
 // NewServer returns TCP implementation.
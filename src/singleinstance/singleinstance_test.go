@@ -2,7 +2,12 @@ package singleinstance
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -20,7 +25,7 @@ func TestServerClientRoundTrip(t *testing.T) {
 	client := NewClient()
 	errCh := make(chan error, 1)
 	go func() {
-		delegated, _, err := client.TryRunOnce(ctx, true)
+		delegated, _, err := client.TryRunOnce(ctx, NewRequest(OutputStdout, ""))
 		if err != nil {
 			errCh <- fmt.Errorf("client: %w", err)
 			return
@@ -56,3 +61,279 @@ func TestServerClientRoundTrip(t *testing.T) {
 		t.Fatalf("client did not complete: %v", ctx.Err())
 	}
 }
+
+func TestClientReportsResidentBusy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := NewServer()
+	if err := srv.Start(ctx); err != nil {
+		t.Skipf("loopback unavailable in this environment: %v", err)
+	}
+	defer srv.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		delegated, _, err := NewClient().TryRunOnce(ctx, NewRequest(OutputClipboard, ""))
+		if !delegated {
+			err = fmt.Errorf("expected delegated=true, got err=%v", err)
+		}
+		errCh <- err
+	}()
+
+	conn, err := srv.Next(ctx)
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if err := conn.RespondBusy(); err != nil {
+		t.Fatalf("respond busy: %v", err)
+	}
+	_ = conn.Close()
+	if err := <-errCh; !errors.Is(err, ErrResidentBusy) {
+		t.Fatalf("TryRunOnce err = %v, want ErrResidentBusy", err)
+	}
+}
+
+func TestClientReportsResidentError(t *testing.T) {
+	tests := []struct {
+		name  string
+		stage string
+		msg   string
+	}{
+		{"with stage", StageRegionCancelled, "selection cancelled"},
+		// A plain ERROR line, sent when the stage is unknown or by an older
+		// resident, leaves Stage empty.
+		{"without stage", "", "unknown session error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv := NewServer()
+			if err := srv.Start(ctx); err != nil {
+				t.Skipf("loopback unavailable in this environment: %v", err)
+			}
+			defer srv.Close()
+
+			errCh := make(chan error, 1)
+			go func() {
+				_, _, err := NewClient().TryRunOnce(ctx, NewRequest(OutputClipboard, ""))
+				errCh <- err
+			}()
+
+			conn, err := srv.Next(ctx)
+			if err != nil {
+				t.Fatalf("next: %v", err)
+			}
+			if err := conn.RespondError(tt.stage, tt.msg); err != nil {
+				t.Fatalf("respond error: %v", err)
+			}
+			_ = conn.Close()
+			err = <-errCh
+			var residentErr *ResidentError
+			if !errors.As(err, &residentErr) || residentErr.Stage != tt.stage || residentErr.Message != tt.msg {
+				t.Fatalf("TryRunOnce err = %#v, want *ResidentError{Stage: %q, Message: %q}", err, tt.stage, tt.msg)
+			}
+		})
+	}
+}
+
+func TestClientMovesStagedFile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := NewServer()
+	if err := srv.Start(ctx); err != nil {
+		t.Skipf("loopback unavailable in this environment: %v", err)
+	}
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := NewClient().TryRunOnce(ctx, NewRequest(OutputFile, path))
+		errCh <- err
+	}()
+
+	conn, err := srv.Next(ctx)
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	staged, err := os.CreateTemp(filepath.Dir(path), StagedFilePattern)
+	if err != nil {
+		t.Fatalf("CreateTemp failed: %v", err)
+	}
+	_, _ = staged.WriteString("new\n")
+	_ = staged.Close()
+	if err := conn.RespondSuccess(staged.Name()); err != nil {
+		t.Fatalf("respond: %v", err)
+	}
+	_ = conn.Close()
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("TryRunOnce: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new\n" {
+		t.Fatalf("file content = %q, want the staged result", data)
+	}
+	if _, err := os.Stat(staged.Name()); !os.IsNotExist(err) {
+		t.Fatalf("staged file left behind: %v", err)
+	}
+}
+
+func TestServerRejectsInvalidOutputFile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := NewServer()
+	if err := srv.Start(ctx); err != nil {
+		t.Skipf("loopback unavailable in this environment: %v", err)
+	}
+	defer srv.Close()
+
+	for _, path := range []string{"out.txt", filepath.Join(t.TempDir(), "missing", "out.txt")} {
+		_, _, err := NewClient().TryRunOnce(ctx, NewRequest(OutputFile, path))
+		var residentErr *ResidentError
+		if !errors.As(err, &residentErr) || residentErr.Stage != StageOutputFailed {
+			t.Fatalf("TryRunOnce(%q) err = %#v, want *ResidentError with stage %s", path, err, StageOutputFailed)
+		}
+	}
+}
+
+func TestPlaceStagedFileRejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "secret.txt")
+	if err := os.WriteFile(other, []byte("keep"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := placeStagedFile(other, filepath.Join(dir, "out.txt")); err == nil {
+		t.Fatal("expected an error for a file that was not staged")
+	}
+	if err := placeStagedFile(filepath.Join(t.TempDir(), ".screen-ocr-1.tmp"), filepath.Join(dir, "out.txt")); err == nil {
+		t.Fatal("expected an error for a staged file in another directory")
+	}
+	if data, _ := os.ReadFile(other); string(data) != "keep" {
+		t.Fatalf("other file changed to %q", data)
+	}
+}
+
+func TestServerSkipsBusyPorts(t *testing.T) {
+	// A foreign listener that never answers PING holds the start of the range.
+	busy, err := net.Listen("tcp", net.JoinHostPort(residentHost, "0"))
+	if err != nil {
+		t.Skipf("loopback unavailable: %v", err)
+	}
+	defer busy.Close()
+	start := busy.Addr().(*net.TCPAddr).Port
+	if start < 1024 || start > 65535-20 {
+		t.Skipf("ephemeral port %d outside usable range", start)
+	}
+	t.Setenv("SINGLEINSTANCE_PORT_START", strconv.Itoa(start))
+	t.Setenv("SINGLEINSTANCE_PORT_END", strconv.Itoa(start+20))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := NewServer()
+	if err := srv.Start(ctx); err != nil {
+		t.Skipf("no free port near %d: %v", start, err)
+	}
+	defer srv.Close()
+	if srv.Port() == start {
+		t.Fatalf("server bound busy start port %d", start)
+	}
+
+	if port, ok := DetectResidentPort(ctx); !ok || port != srv.Port() {
+		t.Fatalf("DetectResidentPort = %d, %v; want %d, true", port, ok, srv.Port())
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		delegated, text, err := NewClient().TryRunOnce(ctx, NewRequest(OutputStdout, ""))
+		if err == nil && (!delegated || text != "ok") {
+			err = fmt.Errorf("delegated=%v text=%q", delegated, text)
+		}
+		errCh <- err
+	}()
+	conn, err := srv.Next(ctx)
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	_ = conn.RespondSuccess("ok")
+	_ = conn.Close()
+	if err := <-errCh; err != nil {
+		t.Fatalf("client: %v", err)
+	}
+}
+
+func TestListenInRangeAllBusy(t *testing.T) {
+	busy, err := net.Listen("tcp", net.JoinHostPort(residentHost, "0"))
+	if err != nil {
+		t.Skipf("loopback unavailable: %v", err)
+	}
+	defer busy.Close()
+	port := busy.Addr().(*net.TCPAddr).Port
+	if _, _, err := listenInRange(port, port); err == nil {
+		t.Fatal("expected error when every port in the range is busy")
+	}
+}
+
+func TestRequestLineRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		line string
+	}{
+		{name: "clipboard", req: NewRequest(OutputClipboard, ""), line: "CLIPBOARD\n"},
+		{name: "stdout", req: NewRequest(OutputStdout, ""), line: "STDOUT\n"},
+		{name: "type", req: NewRequest(OutputType, ""), line: "TYPE\n"},
+		{name: "file", req: NewRequest(OutputFile, `C:\out\result.txt`), line: "FILE C:\\out\\result.txt\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, err := requestLine(tt.req)
+			if err != nil {
+				t.Fatalf("requestLine: %v", err)
+			}
+			if line != tt.line {
+				t.Fatalf("requestLine = %q, want %q", line, tt.line)
+			}
+			if got := parseRequestLine(line); got != tt.req {
+				t.Fatalf("parseRequestLine(%q) = %+v, want %+v", line, got, tt.req)
+			}
+		})
+	}
+}
+
+func TestParseRequestLineLegacy(t *testing.T) {
+	if got := parseRequestLine("STDOUT\n"); !got.OutputToStdout || got.Output != OutputStdout {
+		t.Fatalf("STDOUT parsed as %+v", got)
+	}
+	if got := parseRequestLine("SOMETHING\n"); got.Output != OutputClipboard || got.OutputToStdout {
+		t.Fatalf("unknown line parsed as %+v", got)
+	}
+	if got := parseRequestLine("FILE \n"); got.Output != OutputClipboard {
+		t.Fatalf("empty FILE path parsed as %+v", got)
+	}
+}
+
+func TestRequestLineRejectsInvalidFile(t *testing.T) {
+	if _, err := requestLine(NewRequest(OutputFile, "")); err == nil {
+		t.Fatal("expected error for missing file path")
+	}
+	if _, err := requestLine(NewRequest(OutputFile, "a\nb")); err == nil {
+		t.Fatal("expected error for path with newline")
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	for in, want := range map[string]Output{"": OutputClipboard, "Clipboard": OutputClipboard, "stdout": OutputStdout, "FILE": OutputFile, "Type": OutputType} {
+		got, err := ParseOutput(in)
+		if err != nil || got != want {
+			t.Errorf("ParseOutput(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseOutput("printer"); err == nil {
+		t.Error("expected error for unknown output")
+	}
+}
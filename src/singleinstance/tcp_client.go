@@ -4,28 +4,54 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// ErrResidentBusy is returned by TryRunOnce when the resident answered BUSY
+// because it is already processing another request.
+var ErrResidentBusy = errors.New("resident is busy, please retry")
+
+// ResidentError is returned by TryRunOnce when the resident handled the
+// request and reported a failure, such as a cancelled selection or a failed
+// OCR request. Stage is the failure stage the resident sent, or "" for a
+// resident that predates stages.
+type ResidentError struct {
+	Stage   string
+	Message string
+}
+
+func (e *ResidentError) Error() string { return e.Message }
+
 type tcpClient struct{}
 
 func newTcpClient() Client { return &tcpClient{} }
 
-func (c *tcpClient) TryRunOnce(ctx context.Context, outputToStdout bool) (bool, string, error) {
+func (c *tcpClient) TryRunOnce(ctx context.Context, req Request) (bool, string, error) {
+	line, err := requestLine(req)
+	if err != nil {
+		return false, "", err
+	}
 	deadline := 2 * time.Second
 	if dl, ok := ctx.Deadline(); ok {
 		if d := time.Until(dl); d > 0 {
 			deadline = d
 		}
 	}
-	// scan configured range for resident using PING then request
+	// scan configured range for resident using PING then request; ports held
+	// by other applications are probed with a short timeout so they cannot
+	// stall the scan.
+	probe := min(deadline, pingTimeout)
 	start, end := getPortRange()
 	for port := start; port <= end; port++ {
 		addr := net.JoinHostPort(residentHost, strconv.Itoa(port))
-		if !ping(addr, deadline) {
+		if !ping(addr, probe) {
 			continue
 		}
 		// connect for request
@@ -34,12 +60,7 @@ func (c *tcpClient) TryRunOnce(ctx context.Context, outputToStdout bool) (bool,
 			continue
 		}
 		w := bufio.NewWriter(conn)
-		if outputToStdout {
-			_, err = w.WriteString("STDOUT\n")
-		} else {
-			_, err = w.WriteString("CLIPBOARD\n")
-		}
-		if err != nil {
+		if _, err := w.WriteString(line); err != nil {
 			conn.Close()
 			return true, "", err
 		}
@@ -53,17 +74,65 @@ func (c *tcpClient) TryRunOnce(ctx context.Context, outputToStdout bool) (bool,
 			conn.Close()
 			return true, "", err
 		}
-		if status == "SUCCESS\n" {
+		switch {
+		case status == successResponse:
 			b, _ := io.ReadAll(br)
 			conn.Close()
+			if req.Output == OutputFile {
+				return true, "", placeStagedFile(string(b), req.OutputFile)
+			}
 			return true, string(b), nil
-		}
-		if status == "ERROR\n" {
+		case status == errorResponse || strings.HasPrefix(status, errorStagePrefix):
 			msg, _ := io.ReadAll(br)
 			conn.Close()
-			return true, "", errors.New(string(msg))
+			var stage string
+			if status != errorResponse {
+				stage = strings.TrimSpace(strings.TrimPrefix(status, errorStagePrefix))
+			}
+			return true, "", &ResidentError{Stage: stage, Message: string(msg)}
+		case status == busyResponse:
+			conn.Close()
+			return true, "", ErrResidentBusy
 		}
 		conn.Close()
 	}
 	return false, "", nil
 }
+
+// placeStagedFile moves the file the resident staged for a FILE request to
+// path. The staged file must be one of the resident's temporary files in the
+// same directory; otherwise nothing is moved.
+func placeStagedFile(staged, path string) error {
+	if filepath.Dir(staged) != filepath.Dir(path) {
+		return fmt.Errorf("resident staged the result outside %s", filepath.Dir(path))
+	}
+	if ok, _ := filepath.Match(StagedFilePattern, filepath.Base(staged)); !ok {
+		return fmt.Errorf("resident staged the result in an unexpected file %q", staged)
+	}
+	if err := os.Rename(staged, path); err != nil {
+		_ = os.Remove(staged)
+		return fmt.Errorf("failed to move the result into place: %w", err)
+	}
+	return nil
+}
+
+// requestLine encodes req as the first protocol line: STDOUT, CLIPBOARD, TYPE
+// or "FILE <path>".
+func requestLine(req Request) (string, error) {
+	switch req.Output {
+	case OutputStdout:
+		return stdoutRequest, nil
+	case OutputType:
+		return typeRequest, nil
+	case OutputFile:
+		if req.OutputFile == "" {
+			return "", errors.New("output file path is required")
+		}
+		if strings.ContainsAny(req.OutputFile, "\r\n") {
+			return "", errors.New("output file path must not contain line breaks")
+		}
+		return fileRequestPrefix + req.OutputFile + "\n", nil
+	default:
+		return clipboardRequest, nil
+	}
+}
@@ -3,9 +3,13 @@ package singleinstance
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"log"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +17,17 @@ const (
 	residentHost = "127.0.0.1"
 	pingRequest  = "PING\n"
 	pongResponse = "PONG\n"
+
+	successResponse = "SUCCESS\n"
+	errorResponse   = "ERROR\n"
+	busyResponse    = "BUSY\n"
+	// errorStagePrefix starts an ERROR response that names the failure stage.
+	errorStagePrefix = "ERROR "
+
+	stdoutRequest     = "STDOUT\n"
+	clipboardRequest  = "CLIPBOARD\n"
+	typeRequest       = "TYPE\n"
+	fileRequestPrefix = "FILE "
 )
 
 // tcpServer implements Server over TCP loopback.
@@ -24,25 +39,43 @@ type tcpServer struct {
 
 func newTcpServer() Server { return &tcpServer{incoming: make(chan *tcpConn, 8)} }
 
-// Start binds ONLY the start port of the configured range. If occupied, fail.
+// Start binds the first free port in the configured range. Ports held by
+// other applications are skipped; it fails only when the whole range is taken.
 func (s *tcpServer) Start(ctx context.Context) error {
 	if s.lis != nil {
 		return nil
 	}
-	start, _ := getPortRange()
-	addr := net.JoinHostPort(residentHost, strconv.Itoa(start))
-	lis, err := net.Listen("tcp", addr)
+	start, end := getPortRange()
+	lis, port, err := listenInRange(start, end)
 	if err != nil {
-		log.Printf("singleinstance: failed to bind %s: %v", addr, err)
+		log.Printf("singleinstance: %v", err)
 		return err
 	}
 	s.lis = lis
-	s.port = start
-	log.Printf("singleinstance: listening on %s", addr)
+	s.port = port
+	log.Printf("singleinstance: listening on %s", lis.Addr())
 	go s.acceptLoop(ctx)
 	return nil
 }
 
+// listenInRange binds the first free loopback port in [start, end].
+func listenInRange(start, end int) (net.Listener, int, error) {
+	var lastErr error
+	for port := start; port <= end; port++ {
+		addr := net.JoinHostPort(residentHost, strconv.Itoa(port))
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if port != start {
+			log.Printf("singleinstance: ports %d-%d busy, using %d", start, port-1, port)
+		}
+		return lis, port, nil
+	}
+	return nil, 0, fmt.Errorf("no free port in range %d-%d: %w", start, end, lastErr)
+}
+
 // Port returns the bound port (0 if not started).
 func (s *tcpServer) Port() int { return s.port }
 
@@ -64,11 +97,19 @@ func (s *tcpServer) acceptLoop(ctx context.Context) {
 			_ = c.Close()
 			continue
 		}
-		// Non-PING: treat first line as request (STDOUT/CLIPBOARD)
+		// Non-PING: treat first line as request (STDOUT/CLIPBOARD/TYPE/FILE <path>)
 		_ = c.SetDeadline(time.Time{})
-		stdout := line == "STDOUT\n"
-		log.Printf("singleinstance: request from %s mode=%s", remote, map[bool]string{true: "STDOUT", false: "CLIPBOARD"}[stdout])
-		req := Request{OutputToStdout: stdout}
+		req := parseRequestLine(line)
+		log.Printf("singleinstance: request from %s mode=%s", remote, strings.ToUpper(req.Output.String()))
+		if req.Output == OutputFile {
+			if err := validateOutputFile(req.OutputFile); err != nil {
+				log.Printf("singleinstance: rejecting FILE request from %s: %v", remote, err)
+				_, _ = bw.WriteString(errorLine(StageOutputFailed) + err.Error())
+				_ = bw.Flush()
+				_ = c.Close()
+				continue
+			}
+		}
 		select {
 		case s.incoming <- &tcpConn{c: c, r: req, w: bw, br: br}:
 		case <-ctx.Done():
@@ -78,6 +119,37 @@ func (s *tcpServer) acceptLoop(ctx context.Context) {
 	}
 }
 
+// parseRequestLine decodes the first request line. Anything unrecognised is
+// treated as CLIPBOARD, matching the original two-mode protocol.
+func parseRequestLine(line string) Request {
+	switch {
+	case line == stdoutRequest:
+		return NewRequest(OutputStdout, "")
+	case line == typeRequest:
+		return NewRequest(OutputType, "")
+	case strings.HasPrefix(line, fileRequestPrefix):
+		path := strings.TrimRight(strings.TrimPrefix(line, fileRequestPrefix), "\r\n")
+		if path != "" {
+			return NewRequest(OutputFile, path)
+		}
+	}
+	return NewRequest(OutputClipboard, "")
+}
+
+// validateOutputFile accepts only an absolute path in an existing directory,
+// so that a FILE request cannot create directories or depend on the
+// resident's working directory.
+func validateOutputFile(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("output file %q is not an absolute path", path)
+	}
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("output directory %q does not exist", filepath.Dir(path))
+	}
+	return nil
+}
+
 func (s *tcpServer) Next(ctx context.Context) (Conn, error) {
 	select {
 	case <-ctx.Done():
@@ -106,7 +178,7 @@ type tcpConn struct {
 func (tc *tcpConn) Request() Request { return tc.r }
 
 func (tc *tcpConn) RespondSuccess(text string) error {
-	if _, err := tc.w.WriteString("SUCCESS\n"); err != nil {
+	if _, err := tc.w.WriteString(successResponse); err != nil {
 		return err
 	}
 	if len(text) > 0 {
@@ -117,8 +189,24 @@ func (tc *tcpConn) RespondSuccess(text string) error {
 	return tc.w.Flush()
 }
 
-func (tc *tcpConn) RespondError(msg string) error {
-	if _, err := tc.w.WriteString("ERROR\n" + msg); err != nil {
+func (tc *tcpConn) RespondError(stage, msg string) error {
+	if _, err := tc.w.WriteString(errorLine(stage) + msg); err != nil {
+		return err
+	}
+	return tc.w.Flush()
+}
+
+// errorLine is the status line of an ERROR response: "ERROR <stage>", or
+// "ERROR" alone when the stage is unknown.
+func errorLine(stage string) string {
+	if stage == "" {
+		return errorResponse
+	}
+	return errorStagePrefix + stage + "\n"
+}
+
+func (tc *tcpConn) RespondBusy() error {
+	if _, err := tc.w.WriteString(busyResponse); err != nil {
 		return err
 	}
 	return tc.w.Flush()
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package tray
+
+// PromptText is a stub for non-Windows platforms: there is no input dialog,
+// so it reports the prompt as cancelled.
+func PromptText(title, prompt, initial string) (text string, ok bool) {
+	return "", false
+}
@@ -0,0 +1,198 @@
+//go:build windows
+
+package tray
+
+import (
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	inputBoxClass  = "ScreenOCRInputBox"
+	inputBoxWidth  = 380
+	inputBoxHeight = 160
+
+	wsOverlapped      = 0x00000000
+	wsCaption         = 0x00C00000
+	wsSysMenu         = 0x00080000
+	wsVisible         = 0x10000000
+	wsChild           = 0x40000000
+	wsBorder          = 0x00800000
+	wsTabStop         = 0x00010000
+	wsExTopmost       = 0x00000008
+	wsExDlgModalFrame = 0x00000001
+	esAutoHScroll     = 0x0080
+	bsDefPushButton   = 0x0001
+	wmDestroy         = 0x0002
+	wmClose           = 0x0010
+	wmSetFont         = 0x0030
+	wmCommand         = 0x0111
+	emSetSel          = 0x00B1
+	idOK              = 1
+	idCancel          = 2
+	idEdit            = 100
+	smCXScreen        = 0
+	smCYScreen        = 1
+	defaultGUIFont    = 17
+	colorBtnFace      = 15
+)
+
+var (
+	gdi32                    = windows.NewLazySystemDLL("gdi32.dll")
+	procRegisterClassExW     = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW      = user32.NewProc("CreateWindowExW")
+	procDefWindowProcW       = user32.NewProc("DefWindowProcW")
+	procDestroyWindow        = user32.NewProc("DestroyWindow")
+	procGetMessageW          = user32.NewProc("GetMessageW")
+	procIsDialogMessageW     = user32.NewProc("IsDialogMessageW")
+	procTranslateMessage     = user32.NewProc("TranslateMessage")
+	procDispatchMessageW     = user32.NewProc("DispatchMessageW")
+	procPostQuitMessage      = user32.NewProc("PostQuitMessage")
+	procGetWindowTextW       = user32.NewProc("GetWindowTextW")
+	procGetWindowTextLengthW = user32.NewProc("GetWindowTextLengthW")
+	procSendMessageW         = user32.NewProc("SendMessageW")
+	procSetFocus             = user32.NewProc("SetFocus")
+	procSetForegroundWindow  = user32.NewProc("SetForegroundWindow")
+	procGetSystemMetrics     = user32.NewProc("GetSystemMetrics")
+	procGetStockObject       = gdi32.NewProc("GetStockObject")
+)
+
+type inputBoxWndClass struct {
+	cbSize        uint32
+	style         uint32
+	lpfnWndProc   uintptr
+	cbClsExtra    int32
+	cbWndExtra    int32
+	hInstance     syscall.Handle
+	hIcon         syscall.Handle
+	hCursor       syscall.Handle
+	hbrBackground syscall.Handle
+	lpszMenuName  *uint16
+	lpszClassName *uint16
+	hIconSm       syscall.Handle
+}
+
+type inputBoxMsg struct {
+	hwnd    syscall.Handle
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+}
+
+var (
+	inputBoxMu       sync.Mutex
+	inputBoxRegister sync.Once
+	inputBoxEdit     uintptr
+	inputBoxText     string
+	inputBoxOK       bool
+)
+
+// PromptText shows a small modal input dialog and returns the entered text.
+// ok is false when the user cancels or closes the dialog. It blocks the
+// calling goroutine until the dialog is dismissed.
+func PromptText(title, prompt, initial string) (text string, ok bool) {
+	inputBoxMu.Lock()
+	defer inputBoxMu.Unlock()
+
+	// The dialog's window and message loop must stay on one OS thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	className, _ := syscall.UTF16PtrFromString(inputBoxClass)
+	inputBoxRegister.Do(func() {
+		wc := inputBoxWndClass{
+			lpfnWndProc:   syscall.NewCallback(inputBoxWndProc),
+			hbrBackground: syscall.Handle(colorBtnFace + 1),
+			lpszClassName: className,
+		}
+		wc.cbSize = uint32(unsafe.Sizeof(wc))
+		procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc)))
+	})
+
+	inputBoxText, inputBoxOK = "", false
+
+	screenWidth, _, _ := procGetSystemMetrics.Call(smCXScreen)
+	screenHeight, _, _ := procGetSystemMetrics.Call(smCYScreen)
+	titlePtr, _ := syscall.UTF16PtrFromString(title)
+	hwnd, _, _ := procCreateWindowExW.Call(
+		wsExTopmost|wsExDlgModalFrame,
+		uintptr(unsafe.Pointer(className)),
+		uintptr(unsafe.Pointer(titlePtr)),
+		wsOverlapped|wsCaption|wsSysMenu|wsVisible,
+		(screenWidth-inputBoxWidth)/2, (screenHeight-inputBoxHeight)/2,
+		inputBoxWidth, inputBoxHeight,
+		0, 0, 0, 0,
+	)
+	if hwnd == 0 {
+		return "", false
+	}
+
+	font, _, _ := procGetStockObject.Call(defaultGUIFont)
+	child := func(class, text string, style, x, y, w, h, id uintptr) uintptr {
+		classPtr, _ := syscall.UTF16PtrFromString(class)
+		textPtr, _ := syscall.UTF16PtrFromString(text)
+		c, _, _ := procCreateWindowExW.Call(0,
+			uintptr(unsafe.Pointer(classPtr)), uintptr(unsafe.Pointer(textPtr)),
+			wsChild|wsVisible|style, x, y, w, h, hwnd, id, 0, 0)
+		procSendMessageW.Call(c, wmSetFont, font, 1)
+		return c
+	}
+	child("STATIC", prompt, 0, 12, 12, 340, 20, 0)
+	inputBoxEdit = child("EDIT", initial, wsBorder|wsTabStop|esAutoHScroll, 12, 36, 340, 24, idEdit)
+	child("BUTTON", "OK", wsTabStop|bsDefPushButton, 186, 74, 80, 26, idOK)
+	child("BUTTON", "Cancel", wsTabStop, 272, 74, 80, 26, idCancel)
+
+	procSetForegroundWindow.Call(hwnd)
+	procSetFocus.Call(inputBoxEdit)
+	procSendMessageW.Call(inputBoxEdit, emSetSel, 0, ^uintptr(0))
+
+	// IsDialogMessage maps Enter to IDOK, Esc to IDCANCEL and handles Tab
+	var msg inputBoxMsg
+	for {
+		ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+		if ret == 0 || int32(ret) == -1 {
+			break
+		}
+		if handled, _, _ := procIsDialogMessageW.Call(hwnd, uintptr(unsafe.Pointer(&msg))); handled != 0 {
+			continue
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&msg)))
+	}
+
+	inputBoxEdit = 0
+	return inputBoxText, inputBoxOK
+}
+
+func inputBoxWndProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case wmCommand:
+		switch wParam & 0xFFFF {
+		case idOK:
+			n, _, _ := procGetWindowTextLengthW.Call(inputBoxEdit)
+			buf := make([]uint16, n+1)
+			procGetWindowTextW.Call(inputBoxEdit, uintptr(unsafe.Pointer(&buf[0])), n+1)
+			inputBoxText = syscall.UTF16ToString(buf)
+			inputBoxOK = true
+			procDestroyWindow.Call(uintptr(hwnd))
+			return 0
+		case idCancel:
+			procDestroyWindow.Call(uintptr(hwnd))
+			return 0
+		}
+	case wmClose:
+		procDestroyWindow.Call(uintptr(hwnd))
+		return 0
+	case wmDestroy:
+		procPostQuitMessage.Call(0)
+		return 0
+	}
+	ret, _, _ := procDefWindowProcW.Call(uintptr(hwnd), uintptr(msg), wParam, lParam)
+	return ret
+}
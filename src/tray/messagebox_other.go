@@ -0,0 +1,11 @@
+//go:build !windows
+
+package tray
+
+import "log"
+
+// showWindowsMessageBox is a stub for non-Windows platforms: it logs the
+// message instead of showing it.
+func showWindowsMessageBox(title, message string) {
+	log.Printf("%s: %s", title, message)
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package tray
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// openFile opens path with the desktop's default application, using open on
+// macOS and xdg-open elsewhere.
+func openFile(path string) error {
+	return openerCommand(path).Start()
+}
+
+// openURL opens url in the default browser.
+func openURL(url string) error {
+	return openerCommand(url).Start()
+}
+
+func openerCommand(target string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("open", target)
+	}
+	return exec.Command("xdg-open", target)
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package tray
+
+import (
+	"os/exec"
+
+	"golang.org/x/sys/windows"
+)
+
+// openFile opens path with the application associated with its extension.
+// Files without an association, such as .env, open in Notepad.
+func openFile(path string) error {
+	verb, _ := windows.UTF16PtrFromString("open")
+	file, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	if err := windows.ShellExecute(0, verb, file, nil, nil, windows.SW_SHOWNORMAL); err == nil {
+		return nil
+	}
+	return exec.Command("notepad.exe", path).Start()
+}
+
+// openURL opens url in the default browser.
+func openURL(url string) error {
+	verb, _ := windows.UTF16PtrFromString("open")
+	file, err := windows.UTF16PtrFromString(url)
+	if err != nil {
+		return err
+	}
+	return windows.ShellExecute(0, verb, file, nil, nil, windows.SW_SHOWNORMAL)
+}
@@ -3,11 +3,17 @@ package tray
 import (
 	"context"
 	_ "embed"
+	"encoding/binary"
 	"fmt"
 	"log"
+	"os"
 	"runtime"
+	"sync"
+	"time"
 
 	"github.com/getlantern/systray"
+
+	"screen-ocr-llm/src/buildinfo"
 )
 
 // Embed the icon file directly into the binary
@@ -24,6 +30,62 @@ func loadEmbeddedIconData() []byte {
 	return iconCopy
 }
 
+// pausedIconData returns the embedded icon in grayscale, shown while the
+// hotkey is paused.
+func pausedIconData() []byte {
+	return recolorIconData(func(r, g, b int) (int, int, int) {
+		gray := (r*299 + g*587 + b*114) / 1000
+		return gray, gray, gray
+	})
+}
+
+// flashIconData returns the embedded icon tinted green for a successful OCR
+// or red for a failed one (NOTIFY_ON_COMPLETE=flash).
+func flashIconData(success bool) []byte {
+	return recolorIconData(func(r, g, b int) (int, int, int) {
+		gray := (r*299 + g*587 + b*114) / 1000
+		if success {
+			return gray / 3, (gray + 255) / 2, gray / 3
+		}
+		return (gray + 255) / 2, gray / 3, gray / 3
+	})
+}
+
+// recolorIconData returns a copy of the embedded icon with every palette
+// color passed through recolor. Only palette-based (<= 8 bpp) BMP images are
+// converted; others are left as they are.
+func recolorIconData(recolor func(r, g, b int) (int, int, int)) []byte {
+	data := loadEmbeddedIconData()
+	if len(data) < 6 {
+		return data
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:6]))
+	for i := 0; i < count; i++ {
+		entry := 6 + 16*i
+		if entry+16 > len(data) {
+			break
+		}
+		offset := int(binary.LittleEndian.Uint32(data[entry+12 : entry+16]))
+		if offset+40 > len(data) || binary.LittleEndian.Uint32(data[offset:offset+4]) != 40 {
+			continue // PNG or unknown image
+		}
+		bitCount := binary.LittleEndian.Uint16(data[offset+14 : offset+16])
+		if bitCount == 0 || bitCount > 8 {
+			continue
+		}
+		colors := int(binary.LittleEndian.Uint32(data[offset+32 : offset+36]))
+		if colors == 0 {
+			colors = 1 << bitCount
+		}
+		palette := data[offset+40:]
+		for c := 0; c < colors && 4*c+3 <= len(palette); c++ {
+			r, g, b := recolor(int(palette[4*c+2]), int(palette[4*c+1]), int(palette[4*c]))
+			palette[4*c], palette[4*c+1], palette[4*c+2] = byte(b), byte(g), byte(r)
+		}
+	}
+	return data
+}
+
 // Tray represents a system tray icon
 type Tray interface {
 	Run()
@@ -37,6 +99,32 @@ type Config struct {
 	Title   string
 	Tooltip string
 	OnExit  func()
+	// OnCopyLast, if set, adds a "Copy last result" menu item that calls it.
+	OnCopyLast func()
+	// OnOCRClipboard, if set, adds an "OCR clipboard image" menu item that calls it.
+	OnOCRClipboard func()
+	// Presets lists PRESET_REGIONS names shown in a "Capture preset" submenu;
+	// clicking one calls OnPreset with its name.
+	Presets  []string
+	OnPreset func(name string)
+	// OnChangeHotkey, if set, adds a "Change hotkey..." menu item. It runs on
+	// its own goroutine since it may block on a dialog.
+	OnChangeHotkey func()
+	// LogPath and ConfigPath, if set, add "Open log file" and "Open config
+	// (.env)" menu items; each is disabled while its file doesn't exist.
+	LogPath    string
+	ConfigPath string
+	// OnPauseHotkey, if set, adds a "Pause OCR hotkey" toggle that calls it
+	// with the new state.
+	OnPauseHotkey func(paused bool)
+	// OpenLinks adds an "Open link" menu item that opens the URL set by
+	// SetLink (OCR_EXTRACT); it is disabled while there is none.
+	OpenLinks bool
+	// Models lists MODEL_CHOICES shown in a "Model" submenu, with a checkmark
+	// on ActiveModel; clicking one checks it and calls OnModel with its name.
+	Models      []string
+	ActiveModel string
+	OnModel     func(name string)
 }
 
 var aboutHotkey string
@@ -44,6 +132,11 @@ var aboutHotkey string
 // SetAboutHotkey sets the hotkey to display in the About dialog.
 func SetAboutHotkey(hk string) { aboutHotkey = hk }
 
+var aboutModel string
+
+// SetAboutModel sets the active model to display in the About dialog.
+func SetAboutModel(model string) { aboutModel = model }
+
 var aboutExtra string
 
 // SetAboutExtra sets extra text to append in the About dialog (e.g., port info).
@@ -88,6 +181,63 @@ func (t *SysTray) onReady() {
 	systrayReady = true
 
 	// Create menu items
+	var copyLastCh, ocrClipboardCh <-chan struct{} // nil (never ready) when the item is absent
+	if t.config.OnOCRClipboard != nil {
+		mOCRClipboard := systray.AddMenuItem("OCR clipboard image", "Recognize text in the image currently on the clipboard")
+		ocrClipboardCh = mOCRClipboard.ClickedCh
+	}
+	if t.config.OnCopyLast != nil {
+		mCopyLast := systray.AddMenuItem("Copy last result", "Copy the most recent OCR result to the clipboard")
+		copyLastCh = mCopyLast.ClickedCh
+	}
+	if len(t.config.Presets) > 0 && t.config.OnPreset != nil {
+		mPresets := systray.AddMenuItem("Capture preset", "OCR a fixed region from PRESET_REGIONS")
+		for _, name := range t.config.Presets {
+			item := mPresets.AddSubMenuItem(name, "OCR the "+name+" region")
+			go t.forwardClicks(item.ClickedCh, func() {
+				log.Printf("Preset %q menu clicked", name)
+				t.config.OnPreset(name)
+			})
+		}
+	}
+	if len(t.config.Models) > 0 && t.config.OnModel != nil {
+		t.addModelMenu()
+	}
+	if t.config.OnPauseHotkey != nil {
+		mPause := systray.AddMenuItemCheckbox("Pause OCR hotkey", "Ignore the capture hotkey, e.g. while gaming", false)
+		go t.forwardClicks(mPause.ClickedCh, func() {
+			paused := !mPause.Checked()
+			log.Printf("Pause OCR hotkey menu clicked (paused=%v)", paused)
+			if paused {
+				mPause.Check()
+			} else {
+				mPause.Uncheck()
+			}
+			t.config.OnPauseHotkey(paused)
+		})
+	}
+	if t.config.OnChangeHotkey != nil {
+		mChangeHotkey := systray.AddMenuItem("Change hotkey...", "Rebind the capture hotkey without restarting")
+		go t.forwardClicks(mChangeHotkey.ClickedCh, func() {
+			log.Printf("Change hotkey menu clicked")
+			t.config.OnChangeHotkey()
+		})
+	}
+	if t.config.OpenLinks {
+		t.addOpenLinkItem()
+	}
+	if t.config.OnOCRClipboard != nil || t.config.OnCopyLast != nil || t.config.OpenLinks || len(t.config.Presets) > 0 || len(t.config.Models) > 0 || t.config.OnPauseHotkey != nil || t.config.OnChangeHotkey != nil {
+		systray.AddSeparator()
+	}
+	if t.config.LogPath != "" {
+		t.addOpenFileItem("Open log file", "Open the log file in the default editor", t.config.LogPath)
+	}
+	if t.config.ConfigPath != "" {
+		t.addOpenFileItem("Open config (.env)", "Open the .env configuration file in the default editor", t.config.ConfigPath)
+	}
+	if t.config.LogPath != "" || t.config.ConfigPath != "" {
+		systray.AddSeparator()
+	}
 	mAbout := systray.AddMenuItem("About Screen OCR", "About this application")
 	systray.AddSeparator()
 	mExit := systray.AddMenuItem("Exit", "Exit the application")
@@ -98,6 +248,12 @@ func (t *SysTray) onReady() {
 	go func() {
 		for {
 			select {
+			case <-ocrClipboardCh:
+				log.Printf("OCR clipboard image menu clicked")
+				t.config.OnOCRClipboard()
+			case <-copyLastCh:
+				log.Printf("Copy last result menu clicked")
+				t.config.OnCopyLast()
 			case <-mAbout.ClickedCh:
 				log.Printf("About menu clicked")
 				showAboutDialog()
@@ -117,6 +273,123 @@ func (t *SysTray) onReady() {
 	}()
 }
 
+// forwardClicks calls onClick for every click on ch until the tray is closed.
+func (t *SysTray) forwardClicks(ch <-chan struct{}, onClick func()) {
+	for {
+		select {
+		case <-ch:
+			onClick()
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// addModelMenu adds the "Model" submenu. Clicking a model moves the
+// checkmark to it right away; the switch itself happens in OnModel.
+func (t *SysTray) addModelMenu() {
+	mModel := systray.AddMenuItem("Model", "Switch the vision model used for OCR")
+	items := make([]*systray.MenuItem, len(t.config.Models))
+	for i, name := range t.config.Models {
+		items[i] = mModel.AddSubMenuItemCheckbox(name, "Use "+name+" for OCR", name == t.config.ActiveModel)
+	}
+	for i, name := range t.config.Models {
+		go t.forwardClicks(items[i].ClickedCh, func() {
+			log.Printf("Model %q menu clicked", name)
+			for j, item := range items {
+				if j == i {
+					item.Check()
+				} else {
+					item.Uncheck()
+				}
+			}
+			t.config.OnModel(name)
+		})
+	}
+}
+
+var (
+	// linkMu guards the URL offered by the "Open link" item and the item
+	// itself, which SetLink may update before the menu exists.
+	linkMu   sync.Mutex
+	link     string
+	linkItem *systray.MenuItem
+)
+
+// maxLinkTitle is how much of a URL the "Open link" item shows.
+const maxLinkTitle = 40
+
+// SetLink offers url, the only URL in the last result, in the "Open link"
+// menu item; an empty url disables the item.
+func SetLink(url string) {
+	linkMu.Lock()
+	defer linkMu.Unlock()
+	link = url
+	updateLinkItem()
+}
+
+// updateLinkItem shows link in the "Open link" item. Callers hold linkMu.
+func updateLinkItem() {
+	if linkItem == nil {
+		return
+	}
+	if link == "" {
+		linkItem.SetTitle("Open link")
+		linkItem.SetTooltip("Open the URL found in the last result")
+		linkItem.Disable()
+		return
+	}
+	title := link
+	if r := []rune(title); len(r) > maxLinkTitle {
+		title = string(r[:maxLinkTitle-1]) + "…"
+	}
+	linkItem.SetTitle("Open link: " + title)
+	linkItem.SetTooltip("Open " + link + " in the browser")
+	linkItem.Enable()
+}
+
+// addOpenLinkItem adds the "Open link" item, which opens the URL set by
+// SetLink.
+func (t *SysTray) addOpenLinkItem() {
+	item := systray.AddMenuItem("Open link", "Open the URL found in the last result")
+	linkMu.Lock()
+	linkItem = item
+	updateLinkItem()
+	linkMu.Unlock()
+	go t.forwardClicks(item.ClickedCh, func() {
+		linkMu.Lock()
+		url := link
+		linkMu.Unlock()
+		log.Printf("Open link menu clicked")
+		if url == "" {
+			return
+		}
+		if err := openURL(url); err != nil {
+			log.Printf("Failed to open link: %v", err)
+		}
+	})
+}
+
+// addOpenFileItem adds a menu item that opens path with its associated
+// application. The item is disabled while path doesn't exist.
+func (t *SysTray) addOpenFileItem(title, tooltip, path string) {
+	item := systray.AddMenuItem(title, tooltip)
+	if _, err := os.Stat(path); err != nil {
+		item.Disable()
+	}
+	go t.forwardClicks(item.ClickedCh, func() {
+		log.Printf("%s menu clicked", title)
+		if _, err := os.Stat(path); err != nil {
+			log.Printf("Cannot open %s: %v", path, err)
+			item.Disable()
+			return
+		}
+		if err := openFile(path); err != nil {
+			log.Printf("Failed to open %s: %v", path, err)
+		}
+	})
+}
+
 func (t *SysTray) onExit() {
 	log.Printf("Systray exiting")
 	t.cancel()
@@ -136,6 +409,55 @@ func UpdateTooltip(tt string) {
 	systray.SetTooltip(tt)
 }
 
+// flashDuration is how long Flash shows the tinted icon.
+const flashDuration = 800 * time.Millisecond
+
+var (
+	// iconMu guards the icon state shared by SetPaused and Flash
+	iconMu     sync.Mutex
+	iconPaused bool
+	flashTimer *time.Timer
+)
+
+// SetPaused shows the grayscale icon while the hotkey is paused and the
+// normal one otherwise. No-op until systray is ready.
+func SetPaused(paused bool) {
+	iconMu.Lock()
+	defer iconMu.Unlock()
+	iconPaused = paused
+	if !systrayReady {
+		return
+	}
+	systray.SetIcon(restingIconData())
+}
+
+// Flash briefly tints the tray icon green after a successful OCR or red
+// after a failed one, then restores it. No-op until systray is ready.
+func Flash(success bool) {
+	iconMu.Lock()
+	defer iconMu.Unlock()
+	if !systrayReady {
+		return
+	}
+	systray.SetIcon(flashIconData(success))
+	if flashTimer != nil {
+		flashTimer.Stop()
+	}
+	flashTimer = time.AfterFunc(flashDuration, func() {
+		iconMu.Lock()
+		defer iconMu.Unlock()
+		systray.SetIcon(restingIconData())
+	})
+}
+
+// restingIconData is the icon shown outside a flash. Callers hold iconMu.
+func restingIconData() []byte {
+	if iconPaused {
+		return pausedIconData()
+	}
+	return loadEmbeddedIconData()
+}
+
 // getIconData returns the icon data for the tray icon
 // Based on the new SVG design with gray background and improved visibility
 func getIconData() []byte {
@@ -230,7 +552,8 @@ func effectiveHotkey() string {
 
 // showAboutDialog displays an about dialog
 func showAboutDialog() {
-	message := fmt.Sprintf(`Screen OCR Tool v2.6.1
+	info := buildinfo.Get()
+	message := fmt.Sprintf(`Screen OCR Tool %s
 
 A powerful screen text extraction tool using AI vision models.
 
@@ -244,10 +567,14 @@ Features:
 • Automatic text extraction using OCR
 • Text copied to clipboard automatically
 • System tray integration
-• Provider routing support (PROVIDERS= in .env)`, effectiveHotkey())
+• Provider routing support (PROVIDERS= in .env)`, info.Version, effectiveHotkey())
+	if aboutModel != "" {
+		message += "\n\nModel: " + aboutModel
+	}
 	if aboutExtra != "" {
 		message += "\n\n" + aboutExtra
 	}
+	message += fmt.Sprintf("\n\nBuild: commit %s, built %s (%s)", info.Commit, info.Date, info.GoVersion)
 	message += "\n\nBuilt with Go and OpenRouter AI models."
 
 	if runtime.GOOS == "windows" {
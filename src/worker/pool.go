@@ -14,38 +14,74 @@ import (
 // The event loop should pass a closure that posts back into the event loop safely.
 type ResultCallback func(text string, err error)
 
-// Pool is a fixed-size OCR worker pool with a 1-slot input queue (strict back-pressure).
+// Pool is a fixed-size OCR worker pool with a bounded input queue (strict
+// back-pressure): it accepts at most one job per worker plus the queue slots.
 type Pool struct {
 	jobs chan job
 	wg   sync.WaitGroup
+
+	// mu guards closed so Submit after Close is rejected instead of
+	// sending on the closed jobs channel, and pending, the accepted jobs
+	// that have not finished, which is capped at capacity.
+	mu       sync.Mutex
+	closed   bool
+	pending  int
+	capacity int
 }
 
 type job struct {
 	ctx    context.Context
 	region screenshot.Region
+	image  []byte // when set, OCR this image instead of capturing region
 	cb     ResultCallback
 }
 
 // New creates a worker pool. Size defaults to NumCPU when size<=0. Queue is 1 slot.
 func New(size int) *Pool {
+	return NewWithQueue(size, 1)
+}
+
+// NewWithQueue creates a worker pool of size workers (NumCPU when size<=0)
+// that also accepts queue jobs waiting for a free worker (OCR_WORKERS and
+// OCR_QUEUE). Submits beyond that are dropped.
+func NewWithQueue(size, queue int) *Pool {
 	if size <= 0 {
 		size = runtime.NumCPU()
 	}
-	p := &Pool{jobs: make(chan job, 1)}
+	if queue < 0 {
+		queue = 0
+	}
+	capacity := size + queue
+	p := &Pool{jobs: make(chan job, capacity), capacity: capacity}
 	p.start(size)
 	return p
 }
 
+// Capacity returns how many jobs the pool accepts at once: its workers plus
+// its queue slots.
+func (p *Pool) Capacity() int {
+	return p.capacity
+}
+
 func (p *Pool) start(n int) {
 	for i := 0; i < n; i++ {
 		p.wg.Add(1)
 		go func() {
 			defer p.wg.Done()
 			for j := range p.jobs {
-				log.Printf("Worker: Starting OCR for region %dx%d", j.region.Width, j.region.Height)
-				// Run OCR with ctx deadline honored inside RecognizeWithContext (to be added)
-				text, err := recognizeWithContext(j.ctx, j.region)
+				var text string
+				var err error
+				if j.image != nil {
+					log.Printf("Worker: Starting OCR for %d-byte image", len(j.image))
+					text, err = recognizeImage(j.ctx, j.image)
+				} else {
+					log.Printf("Worker: Starting OCR for region %dx%d", j.region.Width, j.region.Height)
+					text, err = recognizeRegion(j.ctx, j.region)
+				}
 				log.Printf("Worker: OCR completed, text length=%d, err=%v", len(text), err)
+				// Free the slot before the callback so a submit made while
+				// handling this result is not rejected.
+				p.done()
 				log.Printf("Worker: Invoking callback with text length=%d", len(text))
 				j.cb(text, err)
 				log.Printf("Worker: Callback returned")
@@ -54,46 +90,54 @@ func (p *Pool) start(n int) {
 	}
 }
 
-// Submit enqueues an OCR job if the single-slot queue is free. Returns false if dropped.
+// Submit enqueues an OCR job if the pool has a free slot. Returns false if dropped.
 func (p *Pool) Submit(ctx context.Context, region screenshot.Region, cb ResultCallback) bool {
-	select {
-	case p.jobs <- job{ctx: ctx, region: region, cb: cb}:
-		return true
-	default:
+	return p.enqueue(job{ctx: ctx, region: region, cb: cb})
+}
+
+// SubmitImage enqueues OCR of already-encoded PNG data (e.g. from the HTTP
+// endpoint) with the same back-pressure as Submit. Returns false if dropped.
+func (p *Pool) SubmitImage(ctx context.Context, image []byte, cb ResultCallback) bool {
+	return p.enqueue(job{ctx: ctx, image: image, cb: cb})
+}
+
+func (p *Pool) enqueue(j job) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.pending >= p.capacity {
 		return false
 	}
+	// jobs holds capacity entries, so this send never blocks
+	p.jobs <- j
+	p.pending++
+	return true
+}
+
+// done releases the slot of a finished job.
+func (p *Pool) done() {
+	p.mu.Lock()
+	p.pending--
+	p.mu.Unlock()
 }
 
-// Close stops the pool after draining current work.
+// Close stops the pool after draining current work. Later submits are
+// rejected; calling Close again is a no-op.
 func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
 	close(p.jobs)
+	p.mu.Unlock()
 	p.wg.Wait()
 }
 
-// recognizeWithContext wraps ocr.Recognize with a deadline-aware path.
-func recognizeWithContext(ctx context.Context, region screenshot.Region) (string, error) {
-	// Fast path: if no deadline, call existing Recognize.
-	if _, ok := ctx.Deadline(); !ok {
-		return ocr.Recognize(region)
-	}
-	// Deadline-aware shim: run in a sub-goroutine, respect ctx.Done().
-	// This preserves worker cancellation without touching ocr package yet.
-	resCh := make(chan struct {
-		text string
-		err  error
-	}, 1)
-	go func() {
-		text, err := ocr.Recognize(region)
-		resCh <- struct {
-			text string
-			err  error
-		}{text, err}
-	}()
-	select {
-	case r := <-resCh:
-		return r.text, r.err
-	case <-ctx.Done():
-		// Allow underlying OCR to continue in background; we return timeout.
-		return "", ctx.Err()
-	}
-}
+// recognizeImage and recognizeRegion are the ocr embedding API, replaceable
+// in tests. ctx is passed down to the API request, so a deadline aborts the
+// call instead of leaving it running in the background.
+var (
+	recognizeImage  = ocr.RecognizeImageContext
+	recognizeRegion = ocr.RecognizeContext
+)
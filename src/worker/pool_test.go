@@ -29,3 +29,89 @@ func TestPoolSubmitDropWhenBusy(t *testing.T) {
 	}
 	<-done
 }
+
+func TestPoolSubmitAfterClose(t *testing.T) {
+	p := New(1)
+	p.Close()
+	p.Close() // second Close must not panic
+
+	r := screenshot.Region{X: 0, Y: 0, Width: 1, Height: 1}
+	if p.Submit(context.Background(), r, func(string, error) {}) {
+		t.Fatal("Submit after Close should be rejected")
+	}
+	if p.SubmitImage(context.Background(), []byte{1}, func(string, error) {}) {
+		t.Fatal("SubmitImage after Close should be rejected")
+	}
+}
+
+func TestPoolRunsJobsInParallel(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+	prev := recognizeImage
+	recognizeImage = func(context.Context, []byte) (string, error) {
+		started <- struct{}{}
+		<-release
+		return "ok", nil
+	}
+	defer func() { recognizeImage = prev }()
+
+	p := NewWithQueue(2, 1)
+	defer p.Close()
+	if p.Capacity() != 3 {
+		t.Fatalf("Capacity = %d, want 3", p.Capacity())
+	}
+
+	results := make(chan string, 3)
+	cb := func(text string, err error) { results <- text }
+	for i := 0; i < 3; i++ {
+		if !p.SubmitImage(context.Background(), []byte{1}, cb) {
+			t.Fatalf("submit %d should be accepted", i+1)
+		}
+	}
+	// Both workers are busy and the queue slot is taken
+	if p.SubmitImage(context.Background(), []byte{1}, cb) {
+		t.Fatal("submit beyond workers plus queue should be dropped")
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("expected two jobs to run at once")
+		}
+	}
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-results:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for results")
+		}
+	}
+	if !p.SubmitImage(context.Background(), []byte{1}, cb) {
+		t.Fatal("submit should be accepted once the jobs finished")
+	}
+	<-results
+}
+
+func TestPoolWithoutQueueDropsWhenWorkersBusy(t *testing.T) {
+	release := make(chan struct{})
+	prev := recognizeImage
+	recognizeImage = func(context.Context, []byte) (string, error) {
+		<-release
+		return "ok", nil
+	}
+	defer func() { recognizeImage = prev }()
+
+	p := NewWithQueue(1, 0)
+	defer p.Close()
+	done := make(chan struct{})
+	if !p.SubmitImage(context.Background(), []byte{1}, func(string, error) { close(done) }) {
+		t.Fatal("first submit should succeed")
+	}
+	if p.SubmitImage(context.Background(), []byte{1}, func(string, error) {}) {
+		t.Fatal("second submit should be dropped with no queue slots")
+	}
+	close(release)
+	<-done
+}
@@ -0,0 +1,11 @@
+// Package screenocr bundles files from the repository root into the
+// binaries; go:embed cannot reach them from the packages under src.
+package screenocr
+
+import _ "embed"
+
+// TestImage is test-image.png, a screenshot of text used by the selftest
+// subcommands.
+//
+//go:embed test-image.png
+var TestImage []byte
@@ -646,7 +646,7 @@ func delegateToResident(outputToStdout bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	client := singleinstance.NewClient()
-	delegated, text, err := client.TryRunOnce(ctx, outputToStdout)
+	delegated, text, err := client.TryRunOnce(ctx, singleinstance.NewRequest(singleinstance.LegacyOutput(outputToStdout), ""))
 	if err != nil {
 		return err
 	}
@@ -920,18 +920,3 @@ func (roc *RunOnceCoordinator) handleClipboardComplete(msg messages.ClipboardCom
 	}
 	return true // Workflow completed
 }
-
-// sanitizeForLogging safely formats text for logging to prevent log injection
-func sanitizeForLogging(text string) string {
-	// Replace newlines and control characters
-	safe := strings.ReplaceAll(text, "\n", "\\n")
-	safe = strings.ReplaceAll(safe, "\r", "\\r")
-	safe = strings.ReplaceAll(safe, "\t", "\\t")
-
-	// Truncate if too long
-	if len(safe) > 100 {
-		safe = safe[:100] + "..."
-	}
-
-	return safe
-}